@@ -0,0 +1,31 @@
+module dev.acmcsuf.com/christmas/cmd/christmas-gio
+
+go 1.21
+
+require (
+	dev.acmcsuf.com/christmas v0.0.0
+	gioui.org v0.4.0
+	github.com/spf13/pflag v1.0.5
+)
+
+require (
+	gioui.org/cpu v0.0.0-20210817075930-8d6a761490d2 // indirect
+	gioui.org/shader v1.0.8 // indirect
+	github.com/Jon-Bright/ledctl v0.0.0-20220811175751-98f2a0ba0a4b // indirect
+	github.com/disintegration/imaging v1.6.2 // indirect
+	github.com/edsrzf/mmap-go v1.0.0 // indirect
+	github.com/go-text/typesetting v0.0.0-20230803102845-24e03d8b5372 // indirect
+	github.com/klauspost/compress v1.17.4 // indirect
+	golang.org/x/exp v0.0.0-20230711023510-fffb14384f22 // indirect
+	golang.org/x/exp/shiny v0.0.0-20220827204233-334a2380cb91 // indirect
+	golang.org/x/image v0.9.0 // indirect
+	golang.org/x/sync v0.1.0 // indirect
+	golang.org/x/sys v0.6.0 // indirect
+	golang.org/x/text v0.11.0 // indirect
+	google.golang.org/protobuf v1.31.0 // indirect
+	gopkg.in/typ.v4 v4.3.0 // indirect
+	libdb.so/ledctl v0.0.0-20231130111553-25f5f20677f5 // indirect
+	nhooyr.io/websocket v1.8.10 // indirect
+)
+
+replace dev.acmcsuf.com/christmas => ../..
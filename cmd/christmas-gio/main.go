@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+
+	"dev.acmcsuf.com/christmas/lib/christmasd"
+	"dev.acmcsuf.com/christmas/lib/csvutil"
+	"dev.acmcsuf.com/christmas/lib/leddraw"
+	"dev.acmcsuf.com/christmas/lib/ledout"
+	"dev.acmcsuf.com/christmas/lib/ledsim"
+	"gioui.org/app"
+	"gioui.org/io/system"
+	"gioui.org/op"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"github.com/spf13/pflag"
+)
+
+var (
+	ledPointsFile = "led-points.csv"
+	listenAddr    = "localhost:8080"
+	maxPtDistance = 0.0 // auto
+	ppi           = 72.0
+	glowRadius    = 12
+)
+
+func init() {
+	pflag.StringVarP(&ledPointsFile, "led-points", "i", ledPointsFile, "path to the CSV file containing the LED points")
+	pflag.StringVarP(&listenAddr, "listen-addr", "l", listenAddr, "address to listen on for HTTP")
+	pflag.Float64Var(&maxPtDistance, "max-distance", maxPtDistance, "maximum distance between a point and an LED")
+	pflag.Float64Var(&ppi, "ppi", ppi, "pixels per inch")
+	pflag.IntVar(&glowRadius, "glow-radius", glowRadius, "radius of the glow around each LED in px")
+}
+
+func main() {
+	log.SetFlags(0)
+
+	pflag.Usage = func() {
+		log.Println("christmas-gio is a variant of christmasd that draws the LEDs to a window.")
+		log.Println()
+		log.Println("Usage:")
+		log.Println("  christmas-gio [flags...]")
+		log.Println()
+		log.Println("Flags:")
+		pflag.PrintDefaults()
+	}
+	pflag.Parse()
+
+	ledPoints, err := csvutil.UnmarshalFile[image.Point](ledPointsFile)
+	if err != nil {
+		log.Fatalln("failed to read LED points:", err)
+	}
+
+	log.Println("got", len(ledPoints), "LED points")
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	window := app.NewWindow(
+		app.Title("christmas-gio"),
+		app.Size(unit.Dp(600), unit.Dp(800)),
+	)
+
+	output := newWindowWriter(window, len(ledPoints))
+
+	go func() {
+		if err := serve(ctx, ledPoints, output); err != nil && !errors.Is(err, context.Canceled) {
+			log.Fatalln(err)
+		}
+		os.Exit(0)
+	}()
+
+	go func() {
+		if err := draw(window, ledPoints, output); err != nil {
+			log.Fatalln(err)
+		}
+		os.Exit(0)
+	}()
+
+	app.Main()
+}
+
+func serve(ctx context.Context, ledPoints []image.Point, output ledout.Writer) error {
+	var canvasOpts leddraw.LEDCanvasOpts
+	canvasOpts.PPI = ppi
+	if maxPtDistance > 0 {
+		canvasOpts.Intensity = leddraw.NewCubicIntensity(maxPtDistance)
+	}
+
+	server, err := christmasd.NewServer(christmasd.Opts{
+		LEDPoints:  ledPoints,
+		CanvasOpts: canvasOpts,
+		Output:     output,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create server: %w", err)
+	}
+
+	return server.ListenAndServe(ctx, listenAddr)
+}
+
+func draw(window *app.Window, ledPoints []image.Point, output *windowWriter) error {
+	var ops op.Ops
+	var renderer *ledsim.GlowRenderer
+	var leds leddraw.LEDStrip
+
+	for {
+		switch e := window.NextEvent().(type) {
+		case system.DestroyEvent:
+			return e.Err
+
+		case system.FrameEvent:
+			if renderer == nil || renderer.Size() != e.Size {
+				renderer = ledsim.NewGlowRenderer(ledPoints, e.Size, e.Metric.Dp(unit.Dp(glowRadius)))
+			}
+
+			leds = output.Load(leds)
+
+			ops.Reset()
+			paint.NewImageOp(renderer.Render(leds)).Add(&ops)
+			paint.PaintOp{}.Add(&ops)
+			e.Frame(&ops)
+		}
+	}
+}
+
+// windowWriter is an ledout.Writer that keeps the latest LED colors for
+// the window to draw.
+type windowWriter struct {
+	window *app.Window
+	leds   leddraw.LEDStrip
+	mu     sync.Mutex
+}
+
+func newWindowWriter(window *app.Window, numLEDs int) *windowWriter {
+	return &windowWriter{
+		window: window,
+		leds:   make(leddraw.LEDStrip, numLEDs),
+	}
+}
+
+func (w *windowWriter) Write(leds leddraw.LEDStrip) error {
+	w.mu.Lock()
+	copy(w.leds, leds)
+	w.mu.Unlock()
+
+	w.window.Invalidate()
+	return nil
+}
+
+// Load copies the latest LED colors into dst and returns it.
+func (w *windowWriter) Load(dst leddraw.LEDStrip) leddraw.LEDStrip {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append(dst[:0], w.leds...)
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+
+	"dev.acmcsuf.com/christmas/lib/animation"
+	"dev.acmcsuf.com/christmas/lib/christmasd"
+	"dev.acmcsuf.com/christmas/lib/csvutil"
+	"dev.acmcsuf.com/christmas/lib/leddraw"
+	"dev.acmcsuf.com/christmas/lib/ledsim"
+	"dev.acmcsuf.com/christmas/proto/christmasdpb"
+	"github.com/spf13/pflag"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	ledPointsFile = "led-points.csv"
+	outputFile    = ""
+	serveAddr     = ""
+	maxPtDistance = 0.0 // auto
+	ppi           = 72.0
+	width         = 400
+	height        = 600
+	radius        = 4
+	glow          = false
+	fps           = 30
+	repeats       = 2
+)
+
+func init() {
+	pflag.StringVarP(&ledPointsFile, "led-points", "i", ledPointsFile, "path to the CSV file containing the LED points")
+	pflag.StringVarP(&outputFile, "output", "o", outputFile, "output file: .gif, a video file or a directory for PNGs")
+	pflag.StringVar(&serveAddr, "serve", serveAddr, "run christmasd on this address and record it until interrupted")
+	pflag.Float64Var(&maxPtDistance, "max-distance", maxPtDistance, "maximum distance between a point and an LED")
+	pflag.Float64Var(&ppi, "ppi", ppi, "pixels per inch")
+	pflag.IntVar(&width, "width", width, "width of the output in px")
+	pflag.IntVar(&height, "height", height, "height of the output in px")
+	pflag.IntVar(&radius, "radius", radius, "radius of each LED in px")
+	pflag.BoolVar(&glow, "glow", glow, "draw glowing LEDs instead of solid dots")
+	pflag.IntVar(&fps, "fps", fps, "frame rate of videos and PNGs")
+	pflag.IntVar(&repeats, "repeats", repeats, "number of times to play the loop if it cannot be looped forever")
+}
+
+func main() {
+	log.SetFlags(0)
+
+	pflag.Usage = func() {
+		log.Println("christmas-record renders an animation as it would look on the tree.")
+		log.Println()
+		log.Println("Usage:")
+		log.Println("  christmas-record [flags...] -o output frames.json|frames.pb")
+		log.Println("  christmas-record [flags...] -o output --serve addr")
+		log.Println()
+		log.Println("The frames file is a FramesRequest from proto/christmasd.proto, either as")
+		log.Println("JSON or as binary Protobuf.")
+		log.Println()
+		log.Println("Flags:")
+		pflag.PrintDefaults()
+	}
+	pflag.Parse()
+
+	if outputFile == "" || (serveAddr == "") == (pflag.NArg() != 1) {
+		pflag.Usage()
+		os.Exit(1)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	if err := run(ctx); err != nil {
+		log.Fatalln(err)
+	}
+}
+
+func run(ctx context.Context) error {
+	ledPoints, err := csvutil.UnmarshalFile[image.Point](ledPointsFile)
+	if err != nil {
+		return fmt.Errorf("failed to read LED points: %w", err)
+	}
+
+	var canvasOpts leddraw.LEDCanvasOpts
+	canvasOpts.PPI = ppi
+	if maxPtDistance > 0 {
+		canvasOpts.Intensity = leddraw.NewCubicIntensity(maxPtDistance)
+	}
+
+	recorder := ledsim.NewRecorder()
+
+	server, err := christmasd.NewServer(christmasd.Opts{
+		LEDPoints:  ledPoints,
+		CanvasOpts: canvasOpts,
+		Output:     recorder,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create server: %w", err)
+	}
+
+	var frames []animation.Frame[leddraw.LEDStrip]
+	if serveAddr != "" {
+		err := server.ListenAndServe(ctx, serveAddr)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			return err
+		}
+
+		// The context is canceled at this point, but we still want to
+		// encode what we have.
+		ctx = context.Background()
+		frames = recorder.Frames()
+		log.Println("recorded", len(frames), "frames")
+	} else {
+		frames, err = renderFramesFile(server, pflag.Arg(0))
+		if err != nil {
+			return err
+		}
+	}
+
+	var renderer ledsim.Renderer
+	size := image.Pt(width, height)
+	if glow {
+		renderer = ledsim.NewGlowRenderer(ledPoints, size, radius)
+	} else {
+		renderer = ledsim.NewDotRenderer(ledPoints, size, radius)
+	}
+
+	switch strings.ToLower(filepath.Ext(outputFile)) {
+	case ".gif":
+		err = writeGIF(renderer, frames)
+	case "":
+		err = ledsim.WritePNGs(outputFile, renderer, frames, fps, repeats)
+	default:
+		err = ledsim.EncodeVideo(ctx, outputFile, renderer, frames, fps, repeats)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", outputFile, err)
+	}
+
+	return nil
+}
+
+func renderFramesFile(server *christmasd.Server, path string) ([]animation.Frame[leddraw.LEDStrip], error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read frames: %w", err)
+	}
+
+	var req christmasdpb.FramesRequest
+	if filepath.Ext(path) == ".json" {
+		err = protojson.Unmarshal(b, &req)
+	} else {
+		err = proto.Unmarshal(b, &req)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode frames: %w", err)
+	}
+
+	frames, err := christmasd.FramesFromProto(&req)
+	if err != nil {
+		return nil, err
+	}
+
+	rendered, err := server.RenderFrames(frames, christmasd.RenderOptsFromProto(req.ScaleMode, req.CanvasOptions))
+	if err != nil {
+		return nil, fmt.Errorf("failed to render frames: %w", err)
+	}
+
+	return rendered, nil
+}
+
+func writeGIF(renderer ledsim.Renderer, frames []animation.Frame[leddraw.LEDStrip]) error {
+	f, err := os.Create(outputFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := ledsim.EncodeGIF(f, renderer, frames, repeats); err != nil {
+		return err
+	}
+
+	return f.Close()
+}
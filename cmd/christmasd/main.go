@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"log"
+	"os"
+	"os/signal"
+
+	"dev.acmcsuf.com/christmas/lib/christmasd"
+	"dev.acmcsuf.com/christmas/lib/csvutil"
+	"dev.acmcsuf.com/christmas/lib/dmx"
+	"dev.acmcsuf.com/christmas/lib/leddraw"
+	"dev.acmcsuf.com/christmas/lib/ledout"
+	"dev.acmcsuf.com/christmas/lib/opc"
+	"dev.acmcsuf.com/christmas/lib/wled"
+	"github.com/spf13/pflag"
+	"golang.org/x/sync/errgroup"
+)
+
+var (
+	ledPointsFile = "led-points.csv"
+	listenAddr    = ":8080"
+	maxPtDistance = 0.0 // auto
+	ppi           = 72.0
+
+	e131Addr        = ""
+	artNetAddr      = ""
+	ddpAddr         = ""
+	opcAddr         = ""
+	receiveUniverse = uint16(1)
+
+	wledAddr = ""
+	wledName = "Christmas Tree"
+
+	scheduleFile = ""
+	ledWatts     = christmasd.DefaultLEDWatts
+)
+
+var ledFlags = ledout.RegisterFlags(pflag.CommandLine)
+
+func init() {
+	pflag.StringVarP(&ledPointsFile, "led-points", "i", ledPointsFile, "path to the CSV file containing the LED points")
+	pflag.StringVarP(&listenAddr, "listen-addr", "l", listenAddr, "address to listen on for HTTP")
+	pflag.Float64Var(&maxPtDistance, "max-distance", maxPtDistance, "maximum distance between a point and an LED")
+	pflag.Float64Var(&ppi, "ppi", ppi, "pixels per inch")
+	pflag.StringVar(&e131Addr, "e131-addr", e131Addr, "address to receive E1.31 (sACN) on, e.g. :5568")
+	pflag.StringVar(&artNetAddr, "artnet-addr", artNetAddr, "address to receive Art-Net on, e.g. :6454")
+	pflag.StringVar(&ddpAddr, "ddp-addr", ddpAddr, "address to receive DDP on, e.g. :4048")
+	pflag.StringVar(&opcAddr, "opc-addr", opcAddr, "address to receive Open Pixel Control on, e.g. :7890")
+	pflag.Uint16Var(&receiveUniverse, "receive-universe", receiveUniverse, "DMX universe of the first LED when receiving E1.31 or Art-Net")
+	pflag.StringVar(&wledAddr, "wled-addr", wledAddr, "address to serve the WLED JSON API on, e.g. :80")
+	pflag.StringVar(&wledName, "wled-name", wledName, "device name shown in WLED apps")
+	pflag.StringVar(&scheduleFile, "schedule", scheduleFile, "path to the JSON file that the playlist and schedule are loaded from and saved to")
+	pflag.Float64Var(&ledWatts, "led-watts", ledWatts, "power drawn by one LED at full white, used to estimate the power draw in /metrics")
+}
+
+func main() {
+	log.SetFlags(0)
+
+	pflag.Usage = func() {
+		log.Println("christmasd is the daemon that controls the LEDs.")
+		log.Println()
+		log.Println("Usage:")
+		log.Println("  christmasd [flags...]")
+		log.Println()
+		log.Println("Flags:")
+		pflag.PrintDefaults()
+	}
+	pflag.Parse()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	if err := run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		log.Fatalln(err)
+	}
+}
+
+func run(ctx context.Context) error {
+	ledPoints, err := csvutil.UnmarshalFile[image.Point](ledPointsFile)
+	if err != nil {
+		return fmt.Errorf("failed to read LED points: %w", err)
+	}
+
+	log.Println("got", len(ledPoints), "LED points")
+
+	output, err := ledFlags.Open(len(ledPoints))
+	if err != nil {
+		return err
+	}
+	defer output.Close()
+
+	var canvasOpts leddraw.LEDCanvasOpts
+	canvasOpts.PPI = ppi
+	if maxPtDistance > 0 {
+		canvasOpts.Intensity = leddraw.NewCubicIntensity(maxPtDistance)
+	}
+
+	server, err := christmasd.NewServer(christmasd.Opts{
+		LEDPoints:    ledPoints,
+		CanvasOpts:   canvasOpts,
+		Output:       output,
+		SchedulePath: scheduleFile,
+		LEDWatts:     ledWatts,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create server: %w", err)
+	}
+
+	errg, ctx := errgroup.WithContext(ctx)
+	errg.Go(func() error {
+		return server.ListenAndServe(ctx, listenAddr)
+	})
+
+	receivers := []struct {
+		protocol dmx.Protocol
+		addr     string
+	}{
+		{dmx.E131, e131Addr},
+		{dmx.ArtNet, artNetAddr},
+		{dmx.DDP, ddpAddr},
+	}
+	for _, recv := range receivers {
+		if recv.addr == "" {
+			continue
+		}
+
+		r, err := dmx.NewReceiver(recv.protocol, len(ledPoints), []dmx.Mapping{
+			{Universe: receiveUniverse},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create %s receiver: %w", recv.protocol, err)
+		}
+
+		addr := recv.addr
+		errg.Go(func() error {
+			return r.ListenAndServe(ctx, addr, func(leds leddraw.LEDStrip) error {
+				return server.SetLEDs(ctx, leds)
+			})
+		})
+	}
+
+	if opcAddr != "" {
+		opcServer := opc.NewServer(opc.BroadcastChannel, len(ledPoints))
+		errg.Go(func() error {
+			return opcServer.ListenAndServe(ctx, opcAddr, func(leds leddraw.LEDStrip) error {
+				return server.SetLEDs(ctx, leds)
+			})
+		})
+	}
+
+	if wledAddr != "" {
+		controller := wled.NewController(server, ledPoints, wledName)
+		errg.Go(func() error {
+			return controller.ListenAndServe(ctx, wledAddr)
+		})
+	}
+
+	return errg.Wait()
+}
@@ -2,29 +2,35 @@ package main
 
 import (
 	"context"
-	"flag"
 	"log"
 	"os"
 	"os/signal"
 	"time"
 
 	"dev.acmcsuf.com/christmas/lib/csvutil"
+	"dev.acmcsuf.com/christmas/lib/leddraw"
+	"dev.acmcsuf.com/christmas/lib/ledout"
 	"dev.acmcsuf.com/christmas/lib/xcolor"
-	"libdb.so/ledctl"
+	"github.com/spf13/pflag"
 )
 
+var ledFlags = ledout.RegisterFlags(pflag.CommandLine)
+
 func main() {
 	log.SetFlags(0)
 
-	flag.Usage = func() {
+	pflag.Usage = func() {
 		log.Println("rpi-csv-colors renders a CSV file of colors to LED lights.")
-		log.Println("Usage: rpi-csv-colors <csv-colors-file>")
+		log.Println("Usage: rpi-csv-colors [flags...] <csv-colors-file>")
+		log.Println()
+		log.Println("Flags:")
+		pflag.PrintDefaults()
 	}
-	flag.Parse()
+	pflag.Parse()
 
-	csvColorsFile := flag.Arg(0)
+	csvColorsFile := pflag.Arg(0)
 	if csvColorsFile == "" {
-		flag.Usage()
+		pflag.Usage()
 		os.Exit(2)
 	}
 
@@ -38,21 +44,11 @@ func main() {
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer cancel()
 
-	strip, err := ledctl.NewWS281x(ledctl.WS281xConfig{
-		NumPixels:    len(colors),
-		ColorOrder:   ledctl.BGROrder,
-		ColorModel:   ledctl.RGBModel,
-		PWMFrequency: 800000,
-		DMAChannel:   10,
-		GPIOPins:     []int{12},
-	})
+	strip, err := ledFlags.Open(len(colors))
 	if err != nil {
-		log.Fatalln("failed to create pixarray:", err)
-	}
-
-	for i, color := range colors {
-		strip.SetRGBAt(i, ledctl.RGB(color))
+		log.Fatalln("failed to open LEDs:", err)
 	}
+	defer strip.Close()
 
 	ticker := time.NewTicker(500 * time.Millisecond)
 	defer ticker.Stop()
@@ -62,7 +58,7 @@ func main() {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			if err := strip.Flush(); err != nil {
+			if err := strip.Write(leddraw.LEDStrip(colors)); err != nil {
 				log.Fatalln("failed to write pixels:", err)
 			}
 		}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"time"
+
+	"dev.acmcsuf.com/christmas/lib/audio"
+	"dev.acmcsuf.com/christmas/lib/csvutil"
+	"dev.acmcsuf.com/christmas/lib/fseq"
+	"dev.acmcsuf.com/christmas/lib/leddraw"
+	"dev.acmcsuf.com/christmas/lib/ledout"
+	"dev.acmcsuf.com/christmas/lib/ledseq"
+	"github.com/spf13/pflag"
+	"golang.org/x/sync/errgroup"
+)
+
+var ledFlags = ledout.RegisterFlags(pflag.CommandLine)
+
+var (
+	ledPointsFile = ""
+	startChannel  = 1
+	audioFile     = ""
+	audioDevice   = "default"
+	audioLatency  = time.Duration(0)
+)
+
+func init() {
+	pflag.StringVarP(&ledPointsFile, "led-points", "i", ledPointsFile, "path to the CSV file containing the LED points, which sets the number of LEDs in an FSEQ file")
+	pflag.IntVar(&startChannel, "start-channel", startChannel, "FSEQ channel of the first LED, counting from 1 like xLights")
+	pflag.StringVar(&audioFile, "audio", audioFile, "path to a WAV file to play and synchronize the sequence to")
+	pflag.StringVar(&audioDevice, "audio-device", audioDevice, "ALSA device to play the audio on, or null to only keep time")
+	pflag.DurationVar(&audioLatency, "audio-latency", audioLatency, "how long the audio takes to be heard (default: estimated from the device)")
+}
+
+func main() {
+	log.SetFlags(0)
+
+	pflag.Usage = func() {
+		log.Println("rpi-play plays an LED sequence file from tree-canvas or an FSEQ file")
+		log.Println("from xLights on LED lights.")
+		log.Println("Usage: rpi-play [flags...] <sequence-file>")
+		log.Println()
+		log.Println("Flags:")
+		pflag.PrintDefaults()
+	}
+	pflag.Parse()
+
+	sequenceFile := pflag.Arg(0)
+	if sequenceFile == "" {
+		pflag.Usage()
+		os.Exit(2)
+	}
+
+	seq, err := openSequence(sequenceFile)
+	if err != nil {
+		log.Fatalln("failed to open sequence:", err)
+	}
+	defer seq.Close()
+
+	header := seq.Header()
+	log.Println("got", header.FrameCount, "frames of", header.LEDCount, "LED lights")
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	strip, err := ledFlags.Open(header.LEDCount)
+	if err != nil {
+		log.Fatalln("failed to open LEDs:", err)
+	}
+	defer strip.Close()
+
+	writeLEDs := func(leds leddraw.LEDStrip) error {
+		return strip.Write(leds)
+	}
+
+	if audioFile != "" {
+		err = playWithAudio(ctx, seq, writeLEDs)
+	} else {
+		err = ledseq.Play(ctx, seq, writeLEDs)
+	}
+	if err != nil && !errors.Is(err, context.Canceled) {
+		log.Fatalln("failed to play sequence:", err)
+	}
+}
+
+// playWithAudio plays the audio file and the sequence on the audio's clock.
+// The show ends when the audio does.
+func playWithAudio(ctx context.Context, seq *ledseq.Reader, fn func(leddraw.LEDStrip) error) error {
+	wav, err := audio.OpenWAV(audioFile)
+	if err != nil {
+		return fmt.Errorf("failed to open audio: %w", err)
+	}
+	defer wav.Close()
+
+	var out audio.Output
+	if audioDevice == "null" {
+		out = audio.NewNullOutput(wav.Format)
+	} else {
+		out, err = audio.OpenALSA(wav.Format, audioDevice)
+		if err != nil {
+			return fmt.Errorf("failed to open audio device: %w", err)
+		}
+	}
+	defer out.Close()
+
+	player := audio.NewPlayer(wav.Format, out)
+	if audioLatency > 0 {
+		player.Latency = audioLatency
+	}
+
+	lightsCtx, stopLights := context.WithCancel(ctx)
+	defer stopLights()
+
+	errg, ctx := errgroup.WithContext(ctx)
+	errg.Go(func() error {
+		defer stopLights()
+		return player.Play(ctx, wav.Data)
+	})
+	errg.Go(func() error {
+		err := ledseq.PlayWithClock(lightsCtx, seq, player, fn)
+		if lightsCtx.Err() != nil && ctx.Err() == nil {
+			return nil
+		}
+		return err
+	})
+	return errg.Wait()
+}
+
+// openSequence opens an LED sequence file. FSEQ files are converted to an LED
+// sequence in memory first.
+func openSequence(path string) (*ledseq.Reader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	magic := make([]byte, 4)
+	_, err = io.ReadFull(f, magic)
+	f.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	if !fseq.IsFSEQ(magic) {
+		return ledseq.Open(path)
+	}
+
+	s, err := fseq.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if startChannel < 1 {
+		return nil, fmt.Errorf("invalid start channel %d", startChannel)
+	}
+
+	mapping := fseq.Mapping{StartChannel: startChannel - 1}
+	if ledPointsFile != "" {
+		pts, err := csvutil.UnmarshalFile[image.Point](ledPointsFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read LED points: %w", err)
+		}
+		mapping.LEDCount = len(pts)
+	}
+
+	var buf bytes.Buffer
+	if err := ledseq.Write(&buf, s.LEDFrames(mapping), ledseq.EncodingDelta); err != nil {
+		return nil, err
+	}
+
+	return ledseq.NewReader(buf.Bytes())
+}
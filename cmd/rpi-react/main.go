@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"image"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+
+	"dev.acmcsuf.com/christmas/lib/animation"
+	"dev.acmcsuf.com/christmas/lib/audio"
+	"dev.acmcsuf.com/christmas/lib/audioreact"
+	"dev.acmcsuf.com/christmas/lib/csvutil"
+	"dev.acmcsuf.com/christmas/lib/leddraw"
+	"dev.acmcsuf.com/christmas/lib/ledout"
+	"dev.acmcsuf.com/christmas/lib/ledseq"
+	"dev.acmcsuf.com/christmas/lib/patterns"
+	"github.com/spf13/pflag"
+)
+
+var ledFlags = ledout.RegisterFlags(pflag.CommandLine)
+
+var (
+	ledPointsFile = "led-points.csv"
+	effectName    = audioreact.Spectrum.Name
+	fps           = audioreact.DefaultFPS
+	bands         = audioreact.DefaultBands
+	sequenceFile  = ""
+)
+
+func init() {
+	pflag.StringVarP(&ledPointsFile, "led-points", "i", ledPointsFile, "path to the CSV file containing the LED points")
+	pflag.StringVar(&effectName, "effect", effectName, "effect to draw: "+effectNames())
+	pflag.IntVar(&fps, "fps", fps, "frames per second")
+	pflag.IntVar(&bands, "bands", bands, "number of frequency bands")
+	pflag.StringVar(&sequenceFile, "sequence", sequenceFile, "render the whole audio to an LED sequence file for rpi-play instead of playing it")
+}
+
+func effectNames() string {
+	names := make([]string, len(audioreact.Effects))
+	for i, e := range audioreact.Effects {
+		names[i] = strings.ToLower(e.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+func main() {
+	log.SetFlags(0)
+
+	pflag.Usage = func() {
+		log.Println("rpi-react draws effects on LED lights that react to audio.")
+		log.Println("Usage: rpi-react [flags...] <wav-file or - for stdin>")
+		log.Println()
+		log.Println("To react to a microphone:")
+		log.Println("  arecord -f S16_LE -r 44100 | rpi-react -")
+		log.Println()
+		log.Println("Flags:")
+		pflag.PrintDefaults()
+	}
+	pflag.Parse()
+
+	input := pflag.Arg(0)
+	if input == "" {
+		pflag.Usage()
+		os.Exit(2)
+	}
+
+	effect, ok := audioreact.FindEffect(effectName)
+	if !ok {
+		log.Fatalln("unknown effect", effectName)
+	}
+
+	pts, err := csvutil.UnmarshalFile[image.Point](ledPointsFile)
+	if err != nil {
+		log.Fatalln("failed to read LED points:", err)
+	}
+
+	var wav *audio.WAV
+	if input == "-" {
+		wav, err = audio.DecodeWAV(bufio.NewReader(os.Stdin))
+	} else {
+		wav, err = audio.OpenWAV(input)
+	}
+	if err != nil {
+		log.Fatalln("failed to open audio:", err)
+	}
+	defer wav.Close()
+
+	engine := audioreact.NewEngine(wav.Format.SampleRate, patterns.NewLayout(pts), effect, audioreact.Opts{
+		FPS:   fps,
+		Bands: bands,
+	})
+	samples := audio.NewSampleReader(wav.Format, wav.Data)
+
+	if sequenceFile != "" {
+		if err := writeSequence(engine, samples); err != nil {
+			log.Fatalln("failed to write LED sequence:", err)
+		}
+		return
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	strip, err := ledFlags.Open(len(pts))
+	if err != nil {
+		log.Fatalln("failed to open LEDs:", err)
+	}
+	defer strip.Close()
+
+	err = engine.Run(ctx, samples, animation.SystemClock(), func(leds leddraw.LEDStrip) error {
+		return strip.Write(leds)
+	})
+	if err != nil && !errors.Is(err, context.Canceled) {
+		log.Fatalln("failed to react to audio:", err)
+	}
+}
+
+func writeSequence(engine *audioreact.Engine, samples *audio.SampleReader) error {
+	frames, err := engine.Render(samples)
+	if err != nil {
+		return err
+	}
+
+	log.Println("rendered", len(frames), "frames")
+
+	f, err := os.Create(sequenceFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	if err := ledseq.Write(w, frames, ledseq.EncodingDelta); err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return f.Close()
+}
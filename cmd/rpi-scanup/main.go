@@ -6,7 +6,9 @@ import (
 	"time"
 
 	"dev.acmcsuf.com/christmas/lib/intmath"
-	"github.com/Jon-Bright/ledctl/pixarray"
+	"dev.acmcsuf.com/christmas/lib/leddraw"
+	"dev.acmcsuf.com/christmas/lib/ledout"
+	"github.com/spf13/pflag"
 )
 
 const wormSpeed = 200 * time.Millisecond
@@ -25,30 +27,30 @@ var maxLEDHeight = (func() int {
 	return max
 })()
 
+var ledFlags = ledout.RegisterFlags(pflag.CommandLine)
+
 func main() {
-	strip, err := pixarray.NewWS281x(
-		len(ledOrder), // 50 LEDs
-		3,             // 3 bytes per pixel
-		pixarray.BGR,  // BGR channel order
-		800000,        // 800 KHz
-		10,            // DMA 10
-		[]int{12},     // GPIO 12
-	)
+	pflag.Parse()
+
+	strip, err := ledFlags.Open(len(ledOrder))
 	if err != nil {
-		log.Fatalln("failed to create pixarray:", err)
+		log.Fatalln("failed to open LEDs:", err)
 	}
+	defer strip.Close()
+
+	leds := make(leddraw.LEDStrip, len(ledOrder))
 
 	ticker := time.NewTicker(wormSpeed)
 	defer ticker.Stop()
 
 	var i int
 	for range ticker.C {
-		strip.SetPixel(ledOrder[i], pixarray.Pixel{})
+		leds.SetRGBA(ledOrder[i], color.RGBA{})
 
 		i = (i + 1) % len(ledOrder)
-		strip.SetPixel(ledOrder[i], rgbaToPixel(ledColor(i)))
+		leds.SetRGBA(ledOrder[i], ledColor(i))
 
-		if err := strip.Write(); err != nil {
+		if err := strip.Write(leds); err != nil {
 			log.Println("failed to write:", err)
 		}
 	}
@@ -67,11 +69,3 @@ func ledColor(i int) color.RGBA {
 	c := y * len(transColors) / maxLEDHeight
 	return transColors[c]
 }
-
-func rgbaToPixel(c color.RGBA) pixarray.Pixel {
-	return pixarray.Pixel{
-		R: int(c.R),
-		G: int(c.G),
-		B: int(c.B),
-	}
-}
@@ -5,7 +5,9 @@ import (
 	"log"
 	"time"
 
-	"github.com/Jon-Bright/ledctl/pixarray"
+	"dev.acmcsuf.com/christmas/lib/leddraw"
+	"dev.acmcsuf.com/christmas/lib/ledout"
+	"github.com/spf13/pflag"
 )
 
 const numLEDs = 200
@@ -15,18 +17,18 @@ const wormSpeed = 100 * time.Millisecond
 
 var colorOn = color.RGBA{255, 255, 255, 0}
 
+var ledFlags = ledout.RegisterFlags(pflag.CommandLine)
+
 func main() {
-	strip, err := pixarray.NewWS281x(
-		numLEDs,      // LEDs
-		3,            // 3 bytes per pixel
-		pixarray.BGR, // BGR channel order
-		800000,       // 800 KHz
-		10,           // DMA 10
-		[]int{12},    // GPIO 12
-	)
+	pflag.Parse()
+
+	strip, err := ledFlags.Open(numLEDs)
 	if err != nil {
-		log.Fatalln("failed to create pixarray:", err)
+		log.Fatalln("failed to open LEDs:", err)
 	}
+	defer strip.Close()
+
+	leds := make(leddraw.LEDStrip, numLEDs)
 
 	var tail int // worm tail position
 
@@ -35,16 +37,16 @@ func main() {
 
 	for range ticker.C {
 		// Turn off the tail LED bulb.
-		strip.SetPixel(tail, pixarray.Pixel{})
+		leds.SetRGBA(tail, color.RGBA{})
 
 		// Move the worm tail forward.
 		tail = (tail + 1) % numLEDs
 
 		// Turn on the head LED bulb.
 		head := (tail + wormLength - 1) % numLEDs
-		strip.SetPixel(head, rgbaToPixel(colorOn))
+		leds.SetRGBA(head, colorOn)
 
-		must(strip.Write())
+		must(strip.Write(leds))
 
 		if tail == 0 {
 			// Wait a bit before restarting the worm so it's easier to find it
@@ -59,11 +61,3 @@ func must(err error) {
 		log.Fatalln(err)
 	}
 }
-
-func rgbaToPixel(c color.RGBA) pixarray.Pixel {
-	return pixarray.Pixel{
-		R: int(c.R),
-		G: int(c.G),
-		B: int(c.B),
-	}
-}
@@ -1,8 +1,11 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"encoding/csv"
+	"encoding/json"
 	"fmt"
 	"image"
 	"image/color"
@@ -14,16 +17,15 @@ import (
 	"strings"
 	"time"
 
-	_ "image/jpeg"
-	_ "image/png"
-
+	"dev.acmcsuf.com/christmas/lib/animation"
+	"dev.acmcsuf.com/christmas/lib/animdecode"
+	"dev.acmcsuf.com/christmas/lib/christmasd/client"
 	"dev.acmcsuf.com/christmas/lib/csvutil"
+	"dev.acmcsuf.com/christmas/lib/fseq"
 	"dev.acmcsuf.com/christmas/lib/leddraw"
+	"dev.acmcsuf.com/christmas/lib/ledseq"
 	"dev.acmcsuf.com/christmas/lib/xdraw"
-	"github.com/disintegration/imaging"
 	"github.com/spf13/pflag"
-
-	_ "golang.org/x/image/bmp"
 )
 
 // TODO: make this take in an image and draws this image onto a bunch of LED
@@ -35,9 +37,18 @@ var (
 	pngImageFile  = ""
 	csvColorFile  = ""
 	goCodeFile    = ""
+	christmasdURL = ""
+	framesFile    = ""
+	sequenceFile  = ""
+	sequenceEnc   = ledseq.EncodingDelta.String()
+	fseqFile      = ""
+	fseqStepMs    = fseq.DefaultStepMs
+	fseqChannel   = 1
+	fseqCompress  = fseq.CompressionZstd.String()
 	maxPtDistance = 0.0 // auto
 	ppi           = 72.0
 	fit           = false
+	fps           = animdecode.DefaultFPS
 )
 
 func init() {
@@ -46,9 +57,18 @@ func init() {
 	pflag.StringVar(&pngImageFile, "png-image", pngImageFile, "path to the output PNG image file")
 	pflag.StringVar(&csvColorFile, "csv-color", csvColorFile, "path to the output CSV color file")
 	pflag.StringVar(&goCodeFile, "go-code", goCodeFile, "path to the output Go code file")
+	pflag.StringVar(&christmasdURL, "christmasd", christmasdURL, "URL of a christmasd to send the LED colors to")
+	pflag.StringVar(&framesFile, "frames", framesFile, "path to the output JSON lines file of every LED frame")
+	pflag.StringVar(&sequenceFile, "sequence", sequenceFile, "path to the output LED sequence file, which rpi-play can play")
+	pflag.StringVar(&sequenceEnc, "sequence-encoding", sequenceEnc, "encoding of the LED sequence file (raw or delta)")
+	pflag.StringVar(&fseqFile, "fseq", fseqFile, "path to the output FSEQ file for xLights or Falcon Player")
+	pflag.IntVar(&fseqStepMs, "fseq-step", fseqStepMs, "time between FSEQ frames in milliseconds")
+	pflag.IntVar(&fseqChannel, "fseq-start-channel", fseqChannel, "FSEQ channel of the first LED, counting from 1 like xLights")
+	pflag.StringVar(&fseqCompress, "fseq-compression", fseqCompress, "compression of the FSEQ file (none, zstd or zlib)")
 	pflag.Float64Var(&maxPtDistance, "max-distance", maxPtDistance, "maximum distance between a point and an LED")
 	pflag.Float64Var(&ppi, "ppi", ppi, "pixels per inch")
 	pflag.BoolVar(&fit, "fit", fit, "fill or fit the source image (default: fill)")
+	pflag.IntVar(&fps, "fps", fps, "frame rate to sample videos at")
 }
 
 func main() {
@@ -73,26 +93,37 @@ func main() {
 	// TODO: scale while preserving aspect ratio, and center the image.
 	canvasBounds := ledCanvas.CanvasBounds()
 
-	img, err := decodeImageFile(pflag.Arg(0))
-	if err != nil {
-		log.Fatalln("failed to decode source image:", err)
+	scaleMode := xdraw.ScaleFill
+	if fit {
+		scaleMode = xdraw.ScaleFit
 	}
 
-	var imagedCanvas *image.NRGBA
-	if fit {
-		imagedCanvas = imaging.Fit(
-			img, canvasBounds.Dx(), canvasBounds.Dy(), imaging.NearestNeighbor)
-	} else {
-		imagedCanvas = imaging.Fill(
-			img, canvasBounds.Dx(), canvasBounds.Dy(), imaging.Center, imaging.NearestNeighbor)
+	// The source can be a still image, an animated GIF or APNG, or a video.
+	frames, err := animdecode.Load(context.Background(), pflag.Arg(0), animdecode.Opts{
+		Bounds:    canvasBounds,
+		ScaleMode: scaleMode,
+		FPS:       fps,
+	})
+	if err != nil {
+		log.Fatalln("failed to decode source:", err)
 	}
-	imagedCanvas = imaging.PasteCenter(image.NewNRGBA(canvasBounds), imagedCanvas)
 
 	start := time.Now()
-	if err := ledCanvas.Render((*image.RGBA)(imagedCanvas)); err != nil {
-		log.Fatalln("failed to render image:", err)
+	ledFrames := make([]animation.Frame[leddraw.LEDStrip], len(frames))
+	for i, frame := range frames {
+		if err := ledCanvas.Render(frame.Image); err != nil {
+			log.Fatalln("failed to render image:", err)
+		}
+		ledFrames[i] = animation.WithImage(frame, append(leddraw.LEDStrip(nil), ledCanvas.LEDs()...))
+	}
+	log.Println("rendered", len(frames), "frame(s) in", time.Since(start))
+
+	// The outputs below that take a single image use the first frame.
+	if len(frames) > 1 {
+		if err := ledCanvas.Render(frames[0].Image); err != nil {
+			log.Fatalln("failed to render image:", err)
+		}
 	}
-	log.Println("rendered in", time.Since(start))
 
 	if pngImageFile != "" {
 		if err := writePNGImage(ledCanvas, ledPoints); err != nil {
@@ -112,7 +143,31 @@ func main() {
 		}
 	}
 
-	if pngImageFile == "" && csvColorFile == "" && goCodeFile == "" {
+	if framesFile != "" {
+		if err := writeFrames(ledFrames); err != nil {
+			log.Fatalln("failed to write frames:", err)
+		}
+	}
+
+	if sequenceFile != "" {
+		if err := writeSequence(ledFrames); err != nil {
+			log.Fatalln("failed to write LED sequence:", err)
+		}
+	}
+
+	if fseqFile != "" {
+		if err := writeFSEQ(ledFrames); err != nil {
+			log.Fatalln("failed to write FSEQ:", err)
+		}
+	}
+
+	if christmasdURL != "" {
+		if err := sendToChristmasd(ledCanvas, frames); err != nil {
+			log.Fatalln("failed to send to christmasd:", err)
+		}
+	}
+
+	if pngImageFile == "" && csvColorFile == "" && goCodeFile == "" && framesFile == "" && sequenceFile == "" && fseqFile == "" && christmasdURL == "" {
 		log.Println("Nothing to do.")
 		log.Println()
 		log.Println("Debug Information:")
@@ -201,6 +256,131 @@ func writeGoCode(ledCanvas *leddraw.LEDCanvas, ledPoints []image.Point) error {
 	return nil
 }
 
+// ledFrame is a single line written to the frames file. Frames can only jump
+// back by a number of frames, so they are flattened with animation.Flatten
+// before they are written.
+type ledFrame struct {
+	LEDs           leddraw.LEDStrip       `json:"leds"`
+	DurationMs     animation.Milliseconds `json:"duration_ms"`
+	JumpBackAmount int32                  `json:"jump_back_amount,omitempty"`
+}
+
+func writeFrames(frames []animation.Frame[leddraw.LEDStrip]) error {
+	f, err := createFile(framesFile)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %v", err)
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, frame := range animation.Flatten(frames) {
+		if err := enc.Encode(ledFrame{
+			LEDs:           frame.Image,
+			DurationMs:     frame.DurationMs,
+			JumpBackAmount: frame.JumpBackAmount,
+		}); err != nil {
+			return fmt.Errorf("failed to encode frame: %v", err)
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write to output file: %v", err)
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to close output file: %v", err)
+	}
+
+	return nil
+}
+
+func writeSequence(frames []animation.Frame[leddraw.LEDStrip]) error {
+	enc, err := ledseq.ParseEncoding(sequenceEnc)
+	if err != nil {
+		return err
+	}
+
+	f, err := createFile(sequenceFile)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %v", err)
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	if err := ledseq.Write(w, frames, enc); err != nil {
+		return fmt.Errorf("failed to encode sequence: %v", err)
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write to output file: %v", err)
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to close output file: %v", err)
+	}
+
+	return nil
+}
+
+func writeFSEQ(frames []animation.Frame[leddraw.LEDStrip]) error {
+	compression, err := fseq.ParseCompression(fseqCompress)
+	if err != nil {
+		return err
+	}
+
+	if fseqStepMs < 1 || fseqStepMs > 255 {
+		return fmt.Errorf("invalid step %dms", fseqStepMs)
+	}
+
+	if fseqChannel < 1 {
+		return fmt.Errorf("invalid start channel %d", fseqChannel)
+	}
+
+	f, err := createFile(fseqFile)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %v", err)
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	if err := fseq.Encode(w, frames, fseq.EncodeOpts{
+		StepMs:       animation.Milliseconds(fseqStepMs),
+		StartChannel: fseqChannel - 1,
+		Compression:  compression,
+	}); err != nil {
+		return fmt.Errorf("failed to encode FSEQ: %v", err)
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write to output file: %v", err)
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to close output file: %v", err)
+	}
+
+	return nil
+}
+
+func sendToChristmasd(ledCanvas *leddraw.LEDCanvas, frames []animation.Frame[*image.RGBA]) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	c := client.New(christmasdURL)
+	if len(frames) == 1 {
+		return c.SetLEDs(ctx, ledCanvas.LEDs())
+	}
+
+	// Replace whatever is playing. The frames are already scaled to our
+	// canvas, so christmasd only has to scale them if its canvas differs.
+	images := make([]animation.Frame[image.Image], len(frames))
+	for i, frame := range frames {
+		images[i] = animation.WithImage[image.Image](frame, frame.Image)
+	}
+	return c.ReplaceFrames(ctx, images, xdraw.ScaleFill, leddraw.Transition{})
+}
+
 func createFile(name string) (*os.File, error) {
 	if name == "-" {
 		return os.Stdout, nil
@@ -224,14 +404,3 @@ func readCSVPoints(csvPath string) ([]image.Point, error) {
 	}
 	return pts, nil
 }
-
-func decodeImageFile(path string) (image.Image, error) {
-	f, err := os.Open(path)
-	if err != nil {
-		return nil, err
-	}
-	defer f.Close()
-
-	img, _, err := image.Decode(f)
-	return img, err
-}
@@ -3,24 +3,27 @@ module dev.acmcsuf.com/christmas
 go 1.21
 
 require (
-	github.com/Jon-Bright/ledctl v0.0.0-20220811175751-98f2a0ba0a4b
 	github.com/alecthomas/assert/v2 v2.3.0
 	github.com/disintegration/imaging v1.6.2
+	github.com/edsrzf/mmap-go v1.0.0
 	github.com/fogleman/poissondisc v0.0.0-20190923201222-9b82984c50c5
 	github.com/joho/godotenv v1.5.1
+	github.com/klauspost/compress v1.17.4
 	github.com/pierrre/imageutil v1.0.0
 	github.com/pkg/errors v0.9.1
 	github.com/spf13/pflag v1.0.5
 	golang.org/x/exp v0.0.0-20230711023510-fffb14384f22
 	golang.org/x/image v0.9.0
 	golang.org/x/sync v0.1.0
+	google.golang.org/protobuf v1.31.0
 	gopkg.in/typ.v4 v4.3.0
 	libdb.so/ledctl v0.0.0-20231130111553-25f5f20677f5
+	nhooyr.io/websocket v1.8.10
 )
 
 require (
+	github.com/Jon-Bright/ledctl v0.0.0-20220811175751-98f2a0ba0a4b // indirect
 	github.com/alecthomas/repr v0.2.0 // indirect
-	github.com/edsrzf/mmap-go v1.0.0 // indirect
 	github.com/hexops/gotextdiff v1.0.3 // indirect
 	golang.org/x/sys v0.6.0 // indirect
 )
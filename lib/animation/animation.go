@@ -4,9 +4,11 @@ import (
 	"context"
 	"errors"
 	"expvar"
-	"log"
+	"fmt"
+	"sync"
 	"time"
 
+	"dev.acmcsuf.com/christmas/lib/intmath"
 	"gopkg.in/typ.v4/lists"
 )
 
@@ -20,10 +22,43 @@ func DurationToMs(d time.Duration) Milliseconds {
 }
 
 // Frame is a single frame of an animation.
+//
+// After a frame is played, the animation continues with the next frame unless
+// the frame jumps, either back by JumpBackAmount frames or to the last frame
+// before it with the marker JumpTo. A jump is taken Repeat times before the
+// animation continues past it, or forever if Repeat is 0. Jumps can be nested:
+// jumping back restarts the counts of the jumps in between, so an inner loop
+// is repeated in full every time the outer loop comes around.
 type Frame[Image any] struct {
 	Image          Image
 	JumpBackAmount int32
 	DurationMs     Milliseconds
+
+	// Marker names the frame so that other frames can jump to it.
+	Marker string
+	// JumpTo is the marker of the frame to jump back to. It takes the place
+	// of JumpBackAmount.
+	JumpTo string
+	// Repeat is how many times the jump is taken, or 0 for forever.
+	Repeat int32
+}
+
+// WithImage returns a copy of the frame with another image, keeping its
+// duration and loops.
+func WithImage[To, From any](f Frame[From], image To) Frame[To] {
+	return Frame[To]{
+		Image:          image,
+		JumpBackAmount: f.JumpBackAmount,
+		DurationMs:     f.DurationMs,
+		Marker:         f.Marker,
+		JumpTo:         f.JumpTo,
+		Repeat:         f.Repeat,
+	}
+}
+
+// jumps returns true if the frame jumps after it is played.
+func (f Frame[Image]) jumps() bool {
+	return f.JumpTo != "" || f.JumpBackAmount > 0
 }
 
 // Duration returns the duration of the frame as a time.Duration.
@@ -34,23 +69,63 @@ func (f Frame[Image]) Duration() time.Duration {
 // ErrFramebufferOverflow is returned when the framebuffer is full.
 var ErrFramebufferOverflow = errors.New("framebuffer overflow")
 
+// ErrFrameNotFound is returned when seeking to a frame that the player doesn't
+// have, either because it wasn't added yet or because it was already
+// overwritten by newer frames.
+var ErrFrameNotFound = errors.New("frame not found")
+
 const (
 	metricDroppedFrames = "dropped_frames"
 	metricTotalFrames   = "total_frames"
 	metricFrameJitter   = "frame_jitter"
+	metricFrameDrift    = "frame_drift"
 )
 
 var metrics = expvar.NewMap("animation")
 
+var (
+	// frameJitter is how much the time between two frames differed from the
+	// duration of the first one.
+	frameJitter = NewHistogram(DefaultBuckets)
+	// frameDrift is how late frames were sent compared to the timeline. It
+	// stays bounded since frames are scheduled against the timeline rather
+	// than against the previous frame.
+	frameDrift = NewHistogram(DefaultBuckets)
+)
+
+func init() {
+	metrics.Set(metricFrameJitter, frameJitter)
+	metrics.Set(metricFrameDrift, frameDrift)
+}
+
 // Player is an animation player. It is safe to use from multiple goroutines.
+//
+// Besides adding frames, the player can be paused and resumed, sped up or
+// slowed down, seeked to a frame, cleared and replaced with another animation
+// while it runs.
 type Player[Image any] struct {
 	C <-chan Frame[Image]
 
-	ch    chan Frame[Image]
-	addCh chan Frame[Image] // nil clears
+	ch        chan Frame[Image]
+	addCh     chan Frame[Image]
+	controlCh chan playerControl[Image]
+	adding    sync.Mutex // held while adding frames, so that Replace is atomic
 
 	insert   *lists.Ring[Frame[Image]] // points to empty slot next to last frame
 	playback *lists.Ring[Frame[Image]] // points to current frame
+	size     int
+	added    int        // number of frames added since the last clear
+	played   int        // index of the current frame since the last clear
+	loops    loopCounts // jumps taken by the frames since the last clear
+
+	clock Clock
+}
+
+// playerControl is a change to a running player. It is applied by Run, which
+// sends the result to done.
+type playerControl[Image any] struct {
+	apply func(r *playerRun[Image]) error
+	done  chan error
 }
 
 // NewPlayer creates a new animation player that can hold up to maxFrames
@@ -62,6 +137,13 @@ func NewPlayer[Image any]() *Player[Image] {
 // NewPlayerWithSize creates a new animation player that can hold up to
 // maxFrames frames.
 func NewPlayerWithSize[Image any](maxFrames int) *Player[Image] {
+	return NewPlayerWithClock[Image](maxFrames, SystemClock())
+}
+
+// NewPlayerWithClock creates a new animation player that can hold up to
+// maxFrames frames and plays them on the given clock, such as the clock of
+// the audio that the animation is synchronized to.
+func NewPlayerWithClock[Image any](maxFrames int, clock Clock) *Player[Image] {
 	if maxFrames < 2 {
 		panic("maxFrames must be at least 2")
 	}
@@ -70,17 +152,29 @@ func NewPlayerWithSize[Image any](maxFrames int) *Player[Image] {
 	frames := lists.NewRing[Frame[Image]](maxFrames)
 
 	return &Player[Image]{
-		C:        ch,
-		ch:       ch,
-		addCh:    make(chan Frame[Image]),
-		insert:   frames,
-		playback: frames.Prev(),
+		C:         ch,
+		ch:        ch,
+		addCh:     make(chan Frame[Image]),
+		controlCh: make(chan playerControl[Image]),
+		insert:    frames,
+		playback:  frames.Prev(),
+		size:      maxFrames,
+		played:    -1,
+		loops:     loopCounts{},
+		clock:     clock,
 	}
 }
 
 // AddFrames adds a frame to the animation. If the player is full, the function
 // blocks until there is room for the frame.
 func (p *Player[Image]) AddFrame(ctx context.Context, frame Frame[Image]) error {
+	p.adding.Lock()
+	defer p.adding.Unlock()
+
+	return p.addFrameCtx(ctx, frame)
+}
+
+func (p *Player[Image]) addFrameCtx(ctx context.Context, frame Frame[Image]) error {
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
@@ -92,125 +186,426 @@ func (p *Player[Image]) AddFrame(ctx context.Context, frame Frame[Image]) error
 // AddFrames adds multiple frames to the animation. If the player is full, the
 // function blocks until there is room for the frames.
 func (p *Player[Image]) AddFrames(ctx context.Context, frames []Frame[Image]) error {
+	p.adding.Lock()
+	defer p.adding.Unlock()
+
 	for _, frame := range frames {
-		if err := p.AddFrame(ctx, frame); err != nil {
+		if err := p.addFrameCtx(ctx, frame); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-// Run starts playing the animation. Run returns when the animation is
-// finished or when the context is canceled.
-func (p *Player[Image]) Run(ctx context.Context) error {
-	var frameCh chan Frame[Image]
-	addCh := p.addCh
+// Clear removes all frames from the animation and stops playing it, including
+// looping frames. Frames added afterwards start playing immediately.
+func (p *Player[Image]) Clear(ctx context.Context) error {
+	return p.control(ctx, func(r *playerRun[Image]) error {
+		r.clear()
+		return nil
+	})
+}
 
-	var currentFrame Frame[Image]
-	var nextFrame *Frame[Image]
+// Replace replaces the animation with the given frames, which start playing
+// immediately. The player never plays a mix of the old and new frames, and
+// frames added by other goroutines are added either before the replacement
+// (and dropped) or after all of the new frames. If there are more frames than
+// the player can hold, Replace blocks until the rest are added.
+func (p *Player[Image]) Replace(ctx context.Context, frames []Frame[Image]) error {
+	p.adding.Lock()
+	defer p.adding.Unlock()
+
+	var added int
+	err := p.control(ctx, func(r *playerRun[Image]) error {
+		r.clear()
+		for added < len(frames) && r.addCh != nil {
+			r.add(frames[added])
+			added++
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
 
-	nextFrameTimer := time.NewTimer(0)
-	defer nextFrameTimer.Stop()
+	for _, frame := range frames[added:] {
+		if err := p.addFrameCtx(ctx, frame); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	if !nextFrameTimer.Stop() {
-		<-nextFrameTimer.C
+// Pause pauses the animation. The current frame stays shown, and the rest of
+// its duration is played once the animation is resumed. Frames can still be
+// added while the animation is paused.
+func (p *Player[Image]) Pause(ctx context.Context) error {
+	return p.control(ctx, func(r *playerRun[Image]) error {
+		r.setTimeline(func(t *timeline) { t.paused = true })
+		return nil
+	})
+}
+
+// Resume resumes the animation after Pause.
+func (p *Player[Image]) Resume(ctx context.Context) error {
+	return p.control(ctx, func(r *playerRun[Image]) error {
+		r.setTimeline(func(t *timeline) { t.paused = false })
+		return nil
+	})
+}
+
+// SetSpeed sets the playback speed of the animation, e.g. 2 plays it twice as
+// fast. The frames keep their durations, but are shown for their duration
+// divided by the speed. The speed must be positive.
+func (p *Player[Image]) SetSpeed(ctx context.Context, speed float64) error {
+	if !(speed > 0) {
+		return fmt.Errorf("invalid speed %g, must be positive", speed)
 	}
+	return p.control(ctx, func(r *playerRun[Image]) error {
+		r.setTimeline(func(t *timeline) { t.speed = speed })
+		return nil
+	})
+}
 
-	scheduleNextFrame := func() {
-		if nextFrame != nil {
-			panic("scheduleNextFrame called but nextFrame is still not used")
-		}
+// Seek shows the frame at the given index immediately and continues playing
+// from it. Frames are indexed in the order that they were added since the
+// player was last cleared or replaced. Only the last frames that the player
+// can hold can be seeked to; ErrFrameNotFound is returned for other frames.
+// Loops are repeated again as if they were reached for the first time. A
+// paused animation stays paused.
+func (p *Player[Image]) Seek(ctx context.Context, index int) error {
+	return p.control(ctx, func(r *playerRun[Image]) error {
+		return r.seek(index)
+	})
+}
 
-		f, ok := p.nextFrame()
-		log.Printf("schedule next frame: %v", f)
-		if ok {
-			nextFrameTimer.Reset(f.Duration())
-			nextFrame = f
-		} else {
-			nextFrameTimer.Stop()
-			nextFrame = nil
-		}
+// Queued returns the number of frames that are in the player after the current
+// one. Frames that loops play again are only counted once.
+func (p *Player[Image]) Queued(ctx context.Context) (int, error) {
+	var queued int
+	err := p.control(ctx, func(r *playerRun[Image]) error {
+		queued = r.queued()
+		return nil
+	})
+	return queued, err
+}
 
-		// We can take more frames now, so unblock the addCh.
-		addCh = p.addCh
+func (p *Player[Image]) control(ctx context.Context, apply func(r *playerRun[Image]) error) error {
+	ctrl := playerControl[Image]{
+		apply: apply,
+		done:  make(chan error, 1),
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case p.controlCh <- ctrl:
 	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-ctrl.done:
+		return err
+	}
+}
+
+// Run starts playing the animation. Run returns when the animation is
+// finished or when the context is canceled.
+//
+// Each frame is sent once the clock reaches its start time, which is when
+// the previous frame ends. Frames are scheduled against the clock rather than
+// against when the previous frame was sent, so timing errors don't add up and
+// a late frame makes the next one come sooner. How late each frame is sent is
+// published in the frame_drift expvar histogram, and how much that changes
+// from one frame to the next in frame_jitter.
+func (p *Player[Image]) Run(ctx context.Context) error {
+	r := playerRun[Image]{
+		p:        p,
+		timer:    p.clock.NewTimer(),
+		timeline: timeline{speed: 1},
+		addCh:    p.addCh,
+	}
+	defer r.timer.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
 
-		case frame := <-addCh:
-			p.addFrame(frame)
-			if p.isFull() {
-				addCh = nil
-			}
-
-			if nextFrame == nil {
-				// No frame is currently being played, so start playing the
-				// first frame.
-				scheduleNextFrame()
-			}
-
-		case <-nextFrameTimer.C:
-			if nextFrame == nil {
-				panic("unreachable: nextFrameTimer fired but nextFrame is nil")
-			}
-
-			if frameCh != nil {
-				// Timer for next frame fired, but the previous frame hasn't
-				// been sent yet. This means that the receiver is too slow.
-				metrics.Add(metricDroppedFrames, 1)
-			}
-
-			currentFrame, nextFrame = *nextFrame, nil
-			frameCh = p.ch
-
-			// Advancing the frame here instead of waiting for the receiver
-			// to pick up the frame. This ensures that the animation is
-			// played at the correct speed even if the receiver is slow.
-			scheduleNextFrame()
-
-		case frameCh <- currentFrame:
-			frameCh = nil
+		case frame := <-r.addCh:
+			r.add(frame)
+
+		case ctrl := <-p.controlCh:
+			ctrl.done <- ctrl.apply(&r)
+
+		case <-r.timer.C():
+			r.fire()
+
+		case r.frameCh <- r.currentFrame:
+			r.frameCh = nil
+			r.measure()
 			metrics.Add(metricTotalFrames, 1)
 		}
 	}
 }
 
+// playerRun is the state of a running Player. It is only used by Run.
+//
+// Frames are scheduled on the timeline of the animation rather than on the
+// clock directly. The timeline follows the clock at the playback speed and
+// stands still while paused.
+type playerRun[Image any] struct {
+	p        *Player[Image]
+	timer    Timer
+	timeline timeline
+
+	frameCh chan Frame[Image] // p.ch while currentFrame is waiting to be sent
+	addCh   chan Frame[Image] // p.addCh while there is room for frames
+
+	currentFrame Frame[Image]
+	nextFrame    *Frame[Image]
+	nextFrameAt  time.Duration
+
+	// frameEnd is when the last frame that was sent ends, which is when the
+	// next frame starts.
+	frameEnd time.Duration
+
+	// due is the clock time at which currentFrame was due, if it was played
+	// on schedule rather than while paused.
+	due    time.Duration
+	hasDue bool
+	// lastDrift is the drift of the last frame, if it was on schedule and
+	// nothing changed the timeline since.
+	lastDrift    time.Duration
+	hasLastDrift bool
+}
+
+// measure records the timing of currentFrame, which was just sent.
+func (r *playerRun[Image]) measure() {
+	if !r.hasDue {
+		r.hasLastDrift = false
+		return
+	}
+
+	drift := r.p.clock.Now() - r.due
+	frameDrift.Observe(drift)
+	if r.hasLastDrift {
+		frameJitter.Observe(intmath.Abs(drift - r.lastDrift))
+	}
+	r.lastDrift = drift
+	r.hasLastDrift = true
+}
+
+// now returns the current time on the timeline.
+func (r *playerRun[Image]) now() time.Duration {
+	return r.timeline.now(r.p.clock.Now())
+}
+
+// scheduleNextFrame schedules the frame after the current one to be played at
+// the given time.
+func (r *playerRun[Image]) scheduleNextFrame(at time.Duration) {
+	if r.nextFrame != nil {
+		panic("scheduleNextFrame called but nextFrame is still not used")
+	}
+
+	f, ok := r.p.nextFrame()
+	if ok {
+		r.nextFrame = f
+		r.nextFrameAt = at
+	} else {
+		r.nextFrame = nil
+	}
+	r.resetTimer()
+
+	// We can take more frames now, so unblock the addCh.
+	r.addCh = r.p.addCh
+}
+
+// resetTimer makes the timer fire when the next frame is due.
+func (r *playerRun[Image]) resetTimer() {
+	if r.nextFrame == nil || r.timeline.paused {
+		r.timer.Stop()
+		return
+	}
+	r.timer.Reset(r.timeline.clockTime(r.nextFrameAt))
+}
+
+func (r *playerRun[Image]) add(frame Frame[Image]) {
+	r.p.addFrame(frame)
+	if r.p.isFull() {
+		r.addCh = nil
+	}
+
+	if r.nextFrame == nil {
+		// No frame is waiting to be played, so play this one once the last
+		// frame ends, or now if it already has.
+		r.scheduleNextFrame(max(r.frameEnd, r.now()))
+	}
+}
+
+// queued returns the number of frames after the current one, which are the
+// frames after the one that is scheduled plus the scheduled frame itself.
+func (r *playerRun[Image]) queued() int {
+	n := r.p.added - 1 - r.p.played
+	if r.nextFrame != nil {
+		n++
+	}
+	return n
+}
+
+func (r *playerRun[Image]) clear() {
+	r.p.clearFrames()
+
+	// Drop the frames that are scheduled or not yet sent.
+	r.timer.Stop()
+	r.nextFrame = nil
+	r.frameCh = nil
+	r.frameEnd = 0
+	r.addCh = r.p.addCh
+	r.hasLastDrift = false
+}
+
+func (r *playerRun[Image]) fire() {
+	if r.nextFrame == nil {
+		panic("unreachable: nextFrameTimer fired but nextFrame is nil")
+	}
+
+	if r.frameCh != nil {
+		// Timer for next frame fired, but the previous frame hasn't been
+		// sent yet. This means that the receiver is too slow.
+		metrics.Add(metricDroppedFrames, 1)
+	}
+
+	r.play(*r.nextFrame, r.nextFrameAt)
+}
+
+// play makes frame the current frame, starting at the given time, and
+// schedules the frame after it.
+func (r *playerRun[Image]) play(frame Frame[Image], at time.Duration) {
+	r.currentFrame, r.nextFrame = frame, nil
+	r.frameCh = r.p.ch
+	r.frameEnd = at + frame.Duration()
+
+	r.hasDue = !r.timeline.paused
+	if r.hasDue {
+		r.due = r.timeline.clockTime(at)
+	}
+
+	// Advancing the frame here instead of waiting for the receiver to pick up
+	// the frame. This ensures that the animation is played at the correct
+	// speed even if the receiver is slow.
+	r.scheduleNextFrame(r.frameEnd)
+}
+
+func (r *playerRun[Image]) seek(index int) error {
+	if !r.p.hasFrame(index) {
+		return fmt.Errorf("cannot seek to frame %d: %w", index, ErrFrameNotFound)
+	}
+
+	r.p.playback = r.p.frameAt(index)
+	r.p.played = index
+	// The loops are played again from the start, as if they were reached
+	// for the first time.
+	clear(r.p.loops)
+	if r.p.isFull() {
+		r.addCh = nil
+	}
+
+	r.nextFrame = nil
+	r.hasLastDrift = false
+	r.play(r.p.playback.Value, r.now())
+	return nil
+}
+
+// setTimeline changes the timeline from now on and reschedules the next
+// frame on it.
+func (r *playerRun[Image]) setTimeline(change func(t *timeline)) {
+	r.timeline.rebase(r.p.clock.Now())
+	change(&r.timeline)
+	r.resetTimer()
+	r.hasLastDrift = false
+}
+
+// timeline maps the time of a Clock to the time of an animation, which runs at
+// a speed and can be paused.
+type timeline struct {
+	clockAt time.Duration // clock time of the last change
+	at      time.Duration // animation time of the last change
+	speed   float64
+	paused  bool
+}
+
+// now returns the animation time at the given clock time.
+func (t *timeline) now(clock time.Duration) time.Duration {
+	if t.paused {
+		return t.at
+	}
+	return t.at + time.Duration(float64(clock-t.clockAt)*t.speed)
+}
+
+// clockTime returns the clock time at which the animation reaches at. It must
+// not be called while paused.
+func (t *timeline) clockTime(at time.Duration) time.Duration {
+	return t.clockAt + time.Duration(float64(at-t.at)/t.speed)
+}
+
+// rebase starts the timeline from the given clock time, so that changes to
+// the speed or pause only apply from then on.
+func (t *timeline) rebase(clock time.Duration) {
+	t.at = t.now(clock)
+	t.clockAt = clock
+}
+
 // addFrame adds a frame to the player. If the player is already full, false is
 // returned and the player halts.
 func (p *Player[Image]) addFrame(f Frame[Image]) {
 	p.insert.Value = f
 	p.insert = p.insert.Next()
+	p.added++
 }
 
 func (p *Player[Image]) clearFrames() {
 	p.playback = p.insert.Prev()
+	// Forget the last frame so that its jump doesn't apply to the next one.
+	p.playback.Value = Frame[Image]{}
+	p.added = 0
+	p.played = -1
+	clear(p.loops)
 }
 
 // nextFrame returns the next frame in the animation. False is returned if the
 // animation is finished.
 func (p *Player[Image]) nextFrame() (*Frame[Image], bool) {
-	current := p.playback
-
-	if current.Value.JumpBackAmount > 0 {
-		jumpBackAmount := current.Value.JumpBackAmount
-		for i := int32(0); i < jumpBackAmount; i++ {
-			current = current.Prev()
-			if current == p.insert {
-				return nil, false
-			}
-		}
-	} else {
-		current = current.Next()
-		if current == p.insert {
-			return nil, false
-		}
+	next, ok := nextIndex(p.loops, p.played, p.playback.Value, p.findMarker)
+	if !ok || !p.hasFrame(next) {
+		return nil, false
 	}
 
-	p.playback = current
-	return &current.Value, true
+	p.loops.advance(p.played, next)
+	p.playback = p.frameAt(next)
+	p.played = next
+	return &p.playback.Value, true
+}
+
+// hasFrame returns true if the frame at index i since the last clear is still
+// in the player, which holds the last size frames that were added.
+func (p *Player[Image]) hasFrame(i int) bool {
+	return i >= 0 && i < p.added && p.added-i <= p.size
+}
+
+// frameAt returns the slot of the frame at index i since the last clear.
+func (p *Player[Image]) frameAt(i int) *lists.Ring[Frame[Image]] {
+	return p.insert.Move(i - p.added)
+}
+
+// findMarker returns the index of the last frame up to the current one that
+// has the given marker, or -1 if the player doesn't have such a frame.
+func (p *Player[Image]) findMarker(marker string) int {
+	for i := p.played; i >= 0 && p.hasFrame(i); i-- {
+		if p.frameAt(i).Value.Marker == marker {
+			return i
+		}
+	}
+	return -1
 }
 
 // isFull returns true if the player cannot take in any more frames.
@@ -2,10 +2,13 @@ package animation
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
 	"testing"
 	"time"
 
-	"dev.acmcsuf.com/christmas/lib/intmath"
 	"github.com/alecthomas/assert/v2"
 )
 
@@ -13,116 +16,627 @@ type testFrame struct {
 	data string
 }
 
-// Ensure that each frame gets shown for exactly that long with a margin of
-// this amount. Allow 5ms to allow tests to run on slow machines.
-const frameTimeMargin = 5 * time.Millisecond
+// settleTime is how long to wait in real time for the player to send a frame
+// that is due, or to make sure that it doesn't. The player runs on a virtual
+// clock, so timing is exact and doesn't depend on this.
+const settleTime = 20 * time.Millisecond
 
 func TestPlayer(t *testing.T) {
 	t.Run("short", func(t *testing.T) {
 		p, _ := startPlayer(t, 10)
 		mustAddFrames(t, p, []Frame[testFrame]{
-			{testFrame{"frame 1"}, 0, 100},
-			{testFrame{"frame 2"}, 0, 150},
-			{testFrame{"frame 3"}, 0, 200},
-			{testFrame{"frame 4"}, 0, 250},
+			{Image: testFrame{"frame 1"}, DurationMs: 100},
+			{Image: testFrame{"frame 2"}, DurationMs: 150},
+			{Image: testFrame{"frame 3"}, DurationMs: 200},
+			{Image: testFrame{"frame 4"}, DurationMs: 250},
 		})
 		expectFrames(t, p, []Frame[testFrame]{
-			{testFrame{"frame 1"}, 0, 100},
-			{testFrame{"frame 2"}, 0, 150},
-			{testFrame{"frame 3"}, 0, 200},
-			{testFrame{"frame 4"}, 0, 250},
+			{Image: testFrame{"frame 1"}, DurationMs: 100},
+			{Image: testFrame{"frame 2"}, DurationMs: 150},
+			{Image: testFrame{"frame 3"}, DurationMs: 200},
+			{Image: testFrame{"frame 4"}, DurationMs: 250},
 		})
 	})
 
 	t.Run("loop", func(t *testing.T) {
 		p, _ := startPlayer(t, 10)
 		mustAddFrames(t, p, []Frame[testFrame]{
-			{testFrame{"frame 1"}, 0, 100},
-			{testFrame{"frame 2"}, 0, 150},
-			{testFrame{"frame 3"}, 0, 200},
-			{testFrame{"frame 4"}, 3, 250},
+			{Image: testFrame{"frame 1"}, DurationMs: 100},
+			{Image: testFrame{"frame 2"}, DurationMs: 150},
+			{Image: testFrame{"frame 3"}, DurationMs: 200},
+			{Image: testFrame{"frame 4"}, JumpBackAmount: 3, DurationMs: 250},
 		})
 		expectFrames(t, p, []Frame[testFrame]{
-			{testFrame{"frame 1"}, 0, 100},
-			{testFrame{"frame 2"}, 0, 150},
-			{testFrame{"frame 3"}, 0, 200},
-			{testFrame{"frame 4"}, 3, 250},
-			{testFrame{"frame 1"}, 0, 100},
-			{testFrame{"frame 2"}, 0, 150},
-			{testFrame{"frame 3"}, 0, 200},
-			{testFrame{"frame 4"}, 3, 250},
+			{Image: testFrame{"frame 1"}, DurationMs: 100},
+			{Image: testFrame{"frame 2"}, DurationMs: 150},
+			{Image: testFrame{"frame 3"}, DurationMs: 200},
+			{Image: testFrame{"frame 4"}, JumpBackAmount: 3, DurationMs: 250},
+			{Image: testFrame{"frame 1"}, DurationMs: 100},
+			{Image: testFrame{"frame 2"}, DurationMs: 150},
+			{Image: testFrame{"frame 3"}, DurationMs: 200},
+			{Image: testFrame{"frame 4"}, JumpBackAmount: 3, DurationMs: 250},
 		})
 	})
 
 	t.Run("race", func(t *testing.T) {
 		p, _ := startPlayer(t, 10)
 		go mustAddFrames(t, p, []Frame[testFrame]{
-			{testFrame{"frame 1"}, 0, 100},
-			{testFrame{"frame 2"}, 0, 150},
-			{testFrame{"frame 3"}, 0, 200},
-			{testFrame{"frame 4"}, 0, 250},
+			{Image: testFrame{"frame 1"}, DurationMs: 100},
+			{Image: testFrame{"frame 2"}, DurationMs: 150},
+			{Image: testFrame{"frame 3"}, DurationMs: 200},
+			{Image: testFrame{"frame 4"}, DurationMs: 250},
 		})
 		expectFrames(t, p, []Frame[testFrame]{
-			{testFrame{"frame 1"}, 0, 100},
-			{testFrame{"frame 2"}, 0, 150},
-			{testFrame{"frame 3"}, 0, 200},
-			{testFrame{"frame 4"}, 0, 250},
+			{Image: testFrame{"frame 1"}, DurationMs: 100},
+			{Image: testFrame{"frame 2"}, DurationMs: 150},
+			{Image: testFrame{"frame 3"}, DurationMs: 200},
+			{Image: testFrame{"frame 4"}, DurationMs: 250},
 		})
 	})
 
 	t.Run("interleave", func(t *testing.T) {
 		p, _ := startPlayer(t, 10)
-		mustAddFrames(t, p, []Frame[testFrame]{{testFrame{"frame 1"}, 0, 100}})
-		expectFrames(t, p, []Frame[testFrame]{{testFrame{"frame 1"}, 0, 100}})
-		mustAddFrames(t, p, []Frame[testFrame]{{testFrame{"frame 2"}, 0, 150}})
-		expectFrames(t, p, []Frame[testFrame]{{testFrame{"frame 2"}, 0, 150}})
-		mustAddFrames(t, p, []Frame[testFrame]{{testFrame{"frame 3"}, 0, 200}})
-		expectFrames(t, p, []Frame[testFrame]{{testFrame{"frame 3"}, 0, 200}})
-		mustAddFrames(t, p, []Frame[testFrame]{{testFrame{"frame 4"}, 0, 250}})
-		expectFrames(t, p, []Frame[testFrame]{{testFrame{"frame 4"}, 0, 250}})
+		mustAddFrames(t, p, []Frame[testFrame]{{Image: testFrame{"frame 1"}, DurationMs: 100}})
+		expectFrames(t, p, []Frame[testFrame]{{Image: testFrame{"frame 1"}, DurationMs: 100}})
+		mustAddFrames(t, p, []Frame[testFrame]{{Image: testFrame{"frame 2"}, DurationMs: 150}})
+		expectFrames(t, p, []Frame[testFrame]{{Image: testFrame{"frame 2"}, DurationMs: 150}})
+		mustAddFrames(t, p, []Frame[testFrame]{{Image: testFrame{"frame 3"}, DurationMs: 200}})
+		expectFrames(t, p, []Frame[testFrame]{{Image: testFrame{"frame 3"}, DurationMs: 200}})
+		mustAddFrames(t, p, []Frame[testFrame]{{Image: testFrame{"frame 4"}, DurationMs: 250}})
+		expectFrames(t, p, []Frame[testFrame]{{Image: testFrame{"frame 4"}, DurationMs: 250}})
 	})
 
 	t.Run("overflow", func(t *testing.T) {
 		p, _ := startPlayer(t, 2)
 		go mustAddFrames(t, p, []Frame[testFrame]{
-			{testFrame{"frame 1"}, 0, 100},
-			{testFrame{"frame 2"}, 0, 150},
-			{testFrame{"frame 3"}, 0, 200},
-			{testFrame{"frame 4"}, 0, 250},
+			{Image: testFrame{"frame 1"}, DurationMs: 100},
+			{Image: testFrame{"frame 2"}, DurationMs: 150},
+			{Image: testFrame{"frame 3"}, DurationMs: 200},
+			{Image: testFrame{"frame 4"}, DurationMs: 250},
 		})
 		expectFrames(t, p, []Frame[testFrame]{
-			{testFrame{"frame 1"}, 0, 100},
-			{testFrame{"frame 2"}, 0, 150},
-			{testFrame{"frame 3"}, 0, 200},
-			{testFrame{"frame 4"}, 0, 250},
+			{Image: testFrame{"frame 1"}, DurationMs: 100},
+			{Image: testFrame{"frame 2"}, DurationMs: 150},
+			{Image: testFrame{"frame 3"}, DurationMs: 200},
+			{Image: testFrame{"frame 4"}, DurationMs: 250},
 		})
 	})
 
+	t.Run("clear", func(t *testing.T) {
+		p, _ := startPlayer(t, 10)
+		mustAddFrames(t, p, []Frame[testFrame]{
+			{Image: testFrame{"frame 1"}, DurationMs: 100},
+			{Image: testFrame{"frame 2"}, JumpBackAmount: 1, DurationMs: 100},
+		})
+		expectFrames(t, p, []Frame[testFrame]{
+			{Image: testFrame{"frame 1"}, DurationMs: 100},
+		})
+
+		assert.NoError(t, p.Clear(p.ctx))
+		mustAddFrames(t, p, []Frame[testFrame]{
+			{Image: testFrame{"frame 3"}, DurationMs: 100},
+		})
+		expectFrames(t, p, []Frame[testFrame]{
+			{Image: testFrame{"frame 3"}, DurationMs: 100},
+		})
+	})
+
+	t.Run("repeat", func(t *testing.T) {
+		p, _ := startPlayer(t, 10)
+		mustAddFrames(t, p, []Frame[testFrame]{
+			{Image: testFrame{"frame 1"}, DurationMs: 50},
+			{Image: testFrame{"frame 2"}, JumpBackAmount: 1, Repeat: 2, DurationMs: 50},
+			{Image: testFrame{"frame 3"}, DurationMs: 50},
+		})
+		expectFrames(t, p, []Frame[testFrame]{
+			{Image: testFrame{"frame 1"}, DurationMs: 50},
+			{Image: testFrame{"frame 2"}, JumpBackAmount: 1, Repeat: 2, DurationMs: 50},
+			{Image: testFrame{"frame 1"}, DurationMs: 50},
+			{Image: testFrame{"frame 2"}, JumpBackAmount: 1, Repeat: 2, DurationMs: 50},
+			{Image: testFrame{"frame 1"}, DurationMs: 50},
+			{Image: testFrame{"frame 2"}, JumpBackAmount: 1, Repeat: 2, DurationMs: 50},
+			{Image: testFrame{"frame 3"}, DurationMs: 50},
+		})
+		expectNoFrame(t, p, 100*time.Millisecond)
+	})
+
+	t.Run("marker", func(t *testing.T) {
+		p, _ := startPlayer(t, 10)
+		mustAddFrames(t, p, []Frame[testFrame]{
+			{Image: testFrame{"intro"}, DurationMs: 50},
+			{Image: testFrame{"frame 1"}, Marker: "intro-end", DurationMs: 50},
+			{Image: testFrame{"frame 2"}, JumpTo: "intro-end", DurationMs: 50},
+		})
+		expectFrames(t, p, []Frame[testFrame]{
+			{Image: testFrame{"intro"}, DurationMs: 50},
+			{Image: testFrame{"frame 1"}, Marker: "intro-end", DurationMs: 50},
+			{Image: testFrame{"frame 2"}, JumpTo: "intro-end", DurationMs: 50},
+			{Image: testFrame{"frame 1"}, Marker: "intro-end", DurationMs: 50},
+			{Image: testFrame{"frame 2"}, JumpTo: "intro-end", DurationMs: 50},
+			{Image: testFrame{"frame 1"}, Marker: "intro-end", DurationMs: 50},
+		})
+	})
+
+	t.Run("nested", func(t *testing.T) {
+		p, _ := startPlayer(t, 10)
+		frames := nestedFrames()
+		mustAddFrames(t, p, frames)
+
+		var expect []Frame[testFrame]
+		for _, i := range []int{0, 1, 2, 3, 2, 3, 4, 1, 2, 3, 2, 3, 4, 1, 2, 3, 2, 3, 4, 5} {
+			expect = append(expect, frames[i])
+		}
+		expectFrames(t, p, expect)
+	})
+
+	t.Run("marker_overwritten", func(t *testing.T) {
+		// The marker is no longer in the player by the time it is jumped to,
+		// so the animation ends.
+		p, _ := startPlayer(t, 2)
+		go mustAddFrames(t, p, []Frame[testFrame]{
+			{Image: testFrame{"frame 1"}, Marker: "start", DurationMs: 50},
+			{Image: testFrame{"frame 2"}, DurationMs: 50},
+			{Image: testFrame{"frame 3"}, JumpTo: "start", DurationMs: 50},
+		})
+		expectFrames(t, p, []Frame[testFrame]{
+			{Image: testFrame{"frame 1"}, Marker: "start", DurationMs: 50},
+			{Image: testFrame{"frame 2"}, DurationMs: 50},
+			{Image: testFrame{"frame 3"}, JumpTo: "start", DurationMs: 50},
+		})
+		expectNoFrame(t, p, 100*time.Millisecond)
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		p, _ := startPlayer(t, 10)
+		mustAddFrames(t, p, []Frame[testFrame]{
+			{Image: testFrame{"frame 1"}, DurationMs: 100},
+			{Image: testFrame{"frame 2"}, JumpBackAmount: 1, DurationMs: 100},
+		})
+		expectFrames(t, p, []Frame[testFrame]{
+			{Image: testFrame{"frame 1"}, DurationMs: 100},
+		})
+
+		assert.NoError(t, p.Replace(p.ctx, []Frame[testFrame]{
+			{Image: testFrame{"frame 3"}, DurationMs: 100},
+			{Image: testFrame{"frame 4"}, DurationMs: 150},
+		}))
+		expectFrames(t, p, []Frame[testFrame]{
+			{Image: testFrame{"frame 3"}, DurationMs: 100},
+			{Image: testFrame{"frame 4"}, DurationMs: 150},
+		})
+	})
+
+	t.Run("replace_overflow", func(t *testing.T) {
+		p, _ := startPlayer(t, 2)
+		go func() {
+			assert.NoError(t, p.Replace(p.ctx, []Frame[testFrame]{
+				{Image: testFrame{"frame 1"}, DurationMs: 100},
+				{Image: testFrame{"frame 2"}, DurationMs: 100},
+				{Image: testFrame{"frame 3"}, DurationMs: 100},
+				{Image: testFrame{"frame 4"}, DurationMs: 100},
+			}))
+		}()
+		expectFrames(t, p, []Frame[testFrame]{
+			{Image: testFrame{"frame 1"}, DurationMs: 100},
+			{Image: testFrame{"frame 2"}, DurationMs: 100},
+			{Image: testFrame{"frame 3"}, DurationMs: 100},
+			{Image: testFrame{"frame 4"}, DurationMs: 100},
+		})
+	})
+
+	t.Run("pause", func(t *testing.T) {
+		p, _ := startPlayer(t, 10)
+		mustAddFrames(t, p, []Frame[testFrame]{
+			{Image: testFrame{"frame 1"}, DurationMs: 100},
+			{Image: testFrame{"frame 2"}, DurationMs: 100},
+		})
+		expectFrames(t, p, []Frame[testFrame]{
+			{Image: testFrame{"frame 1"}, DurationMs: 100},
+		})
+
+		p.clock.Advance(50 * time.Millisecond)
+		assert.NoError(t, p.Pause(p.ctx))
+		expectNoFrame(t, p, 200*time.Millisecond)
+		assert.NoError(t, p.Resume(p.ctx))
+
+		// The rest of the first frame is played after resuming.
+		expectFrames(t, p, []Frame[testFrame]{
+			{Image: testFrame{"frame 2"}, DurationMs: 100},
+		})
+		assert.Equal(t, 300*time.Millisecond, p.clock.Now())
+	})
+
+	t.Run("speed", func(t *testing.T) {
+		p, _ := startPlayer(t, 10)
+		assert.NoError(t, p.SetSpeed(p.ctx, 2))
+		assert.Error(t, p.SetSpeed(p.ctx, 0))
+		mustAddFrames(t, p, []Frame[testFrame]{
+			{Image: testFrame{"frame 1"}, DurationMs: 100},
+			{Image: testFrame{"frame 2"}, DurationMs: 200},
+			{Image: testFrame{"frame 3"}, DurationMs: 200},
+		})
+
+		receiveFrame(t, p)
+		receiveFrame(t, p)
+		assert.Equal(t, 50*time.Millisecond, p.clock.Now())
+
+		// The second frame has 200ms left, which takes 400ms at half speed.
+		assert.NoError(t, p.SetSpeed(p.ctx, 0.5))
+		receiveFrame(t, p)
+		assert.Equal(t, 450*time.Millisecond, p.clock.Now())
+	})
+
+	t.Run("seek", func(t *testing.T) {
+		p, _ := startPlayer(t, 3)
+		mustAddFrames(t, p, []Frame[testFrame]{
+			{Image: testFrame{"frame 1"}, DurationMs: 100},
+			{Image: testFrame{"frame 2"}, DurationMs: 100},
+			{Image: testFrame{"frame 3"}, DurationMs: 100},
+		})
+		expectFrames(t, p, []Frame[testFrame]{
+			{Image: testFrame{"frame 1"}, DurationMs: 100},
+			{Image: testFrame{"frame 2"}, DurationMs: 100},
+		})
+
+		assert.NoError(t, p.Seek(p.ctx, 0))
+		expectFrames(t, p, []Frame[testFrame]{
+			{Image: testFrame{"frame 1"}, DurationMs: 100},
+			{Image: testFrame{"frame 2"}, DurationMs: 100},
+			{Image: testFrame{"frame 3"}, DurationMs: 100},
+		})
+
+		assert.IsError(t, p.Seek(p.ctx, 3), ErrFrameNotFound)
+		assert.IsError(t, p.Seek(p.ctx, -1), ErrFrameNotFound)
+	})
+
+	t.Run("seek_repeat", func(t *testing.T) {
+		p, _ := startPlayer(t, 10)
+		mustAddFrames(t, p, []Frame[testFrame]{
+			{Image: testFrame{"frame 1"}, DurationMs: 50},
+			{Image: testFrame{"frame 2"}, JumpBackAmount: 1, Repeat: 1, DurationMs: 50},
+			{Image: testFrame{"frame 3"}, DurationMs: 50},
+		})
+		expectFrames(t, p, []Frame[testFrame]{
+			{Image: testFrame{"frame 1"}, DurationMs: 50},
+			{Image: testFrame{"frame 2"}, JumpBackAmount: 1, Repeat: 1, DurationMs: 50},
+			{Image: testFrame{"frame 1"}, DurationMs: 50},
+		})
+
+		// Seeking back into the loop repeats it again.
+		assert.NoError(t, p.Seek(p.ctx, 0))
+		expectFrames(t, p, []Frame[testFrame]{
+			{Image: testFrame{"frame 1"}, DurationMs: 50},
+			{Image: testFrame{"frame 2"}, JumpBackAmount: 1, Repeat: 1, DurationMs: 50},
+			{Image: testFrame{"frame 1"}, DurationMs: 50},
+			{Image: testFrame{"frame 2"}, JumpBackAmount: 1, Repeat: 1, DurationMs: 50},
+			{Image: testFrame{"frame 3"}, DurationMs: 50},
+		})
+	})
+
+	t.Run("seek_paused", func(t *testing.T) {
+		p, _ := startPlayer(t, 10)
+		assert.NoError(t, p.Pause(p.ctx))
+		mustAddFrames(t, p, []Frame[testFrame]{
+			{Image: testFrame{"frame 1"}, DurationMs: 100},
+			{Image: testFrame{"frame 2"}, DurationMs: 100},
+		})
+		expectNoFrame(t, p, 150*time.Millisecond)
+
+		// Seeking shows the frame but stays paused.
+		assert.NoError(t, p.Seek(p.ctx, 1))
+		expectFrames(t, p, []Frame[testFrame]{
+			{Image: testFrame{"frame 2"}, DurationMs: 100},
+		})
+		expectNoFrame(t, p, 150*time.Millisecond)
+	})
+
+	t.Run("queued", func(t *testing.T) {
+		p, _ := startPlayer(t, 10)
+		mustAddFrames(t, p, []Frame[testFrame]{
+			{Image: testFrame{"frame 1"}, DurationMs: 100},
+			{Image: testFrame{"frame 2"}, DurationMs: 100},
+			{Image: testFrame{"frame 3"}, DurationMs: 100},
+		})
+
+		for want := 2; want >= 0; want-- {
+			receiveFrame(t, p)
+			queued, err := p.Queued(p.ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, want, queued)
+		}
+	})
+
 	t.Run("no_frames", func(t *testing.T) {
 		p, _ := startPlayer(t, 10)
-		select {
-		case <-time.After(100 * time.Millisecond):
-		case frame := <-p.C:
-			t.Errorf("got unexpected frame: %v", frame)
+		expectNoFrame(t, p, time.Hour)
+	})
+}
+
+func TestUnroll(t *testing.T) {
+	t.Run("no_loop", func(t *testing.T) {
+		frames := []Frame[testFrame]{
+			{Image: testFrame{"frame 1"}, DurationMs: 100},
+			{Image: testFrame{"frame 2"}, DurationMs: 100},
+		}
+		end, loopStart := FindLoop(frames)
+		assert.Equal(t, 2, end)
+		assert.Equal(t, -1, loopStart)
+		assert.Equal(t, frames, Unroll(frames, 3))
+	})
+
+	t.Run("loop", func(t *testing.T) {
+		frames := []Frame[testFrame]{
+			{Image: testFrame{"frame 1"}, DurationMs: 100},
+			{Image: testFrame{"frame 2"}, DurationMs: 100},
+			{Image: testFrame{"frame 3"}, JumpBackAmount: 1, DurationMs: 100},
+			{Image: testFrame{"never played"}, DurationMs: 100},
+		}
+		end, loopStart := FindLoop(frames)
+		assert.Equal(t, 3, end)
+		assert.Equal(t, 1, loopStart)
+		assert.Equal(t, []Frame[testFrame]{
+			{Image: testFrame{"frame 1"}, DurationMs: 100},
+			{Image: testFrame{"frame 2"}, DurationMs: 100},
+			{Image: testFrame{"frame 3"}, DurationMs: 100},
+			{Image: testFrame{"frame 2"}, DurationMs: 100},
+			{Image: testFrame{"frame 3"}, DurationMs: 100},
+		}, Unroll(frames, 2))
+	})
+
+	t.Run("jump_too_far", func(t *testing.T) {
+		frames := []Frame[testFrame]{
+			{Image: testFrame{"frame 1"}, DurationMs: 100},
+			{Image: testFrame{"frame 2"}, JumpBackAmount: 5, DurationMs: 100},
+		}
+		end, loopStart := FindLoop(frames)
+		assert.Equal(t, 2, end)
+		assert.Equal(t, -1, loopStart)
+	})
+}
+
+// nestedFrames returns frames that play "a", then "b c" three times with "c"
+// played twice each time, then "d".
+func nestedFrames() []Frame[testFrame] {
+	return []Frame[testFrame]{
+		{Image: testFrame{"a"}, DurationMs: 10},
+		{Image: testFrame{"b"}, Marker: "outer", DurationMs: 10},
+		{Image: testFrame{"c"}, DurationMs: 10},
+		{Image: testFrame{"c'"}, JumpBackAmount: 1, Repeat: 1, DurationMs: 10},
+		{Image: testFrame{"end"}, JumpTo: "outer", Repeat: 2, DurationMs: 10},
+		{Image: testFrame{"d"}, DurationMs: 10},
+	}
+}
+
+func TestLoops(t *testing.T) {
+	names := func(frames []Frame[testFrame]) string {
+		var s []string
+		for _, frame := range frames {
+			s = append(s, frame.Image.data)
+		}
+		return strings.Join(s, " ")
+	}
+
+	t.Run("nested", func(t *testing.T) {
+		frames := nestedFrames()
+		expect := "a" +
+			" b c c' c c' end" +
+			" b c c' c c' end" +
+			" b c c' c c' end d"
+
+		assert.Equal(t, expect, names(Unroll(frames, 1)))
+		assert.Equal(t, expect, names(Flatten(frames)))
+
+		end, loopStart := FindLoop(frames)
+		assert.Equal(t, len(frames), end)
+		assert.Equal(t, -1, loopStart)
+
+		for _, frame := range Unroll(frames, 1) {
+			assert.False(t, frame.jumps())
 		}
 	})
+
+	t.Run("repeat_then_forever", func(t *testing.T) {
+		frames := []Frame[testFrame]{
+			{Image: testFrame{"a"}, DurationMs: 10},
+			{Image: testFrame{"b"}, JumpBackAmount: 1, Repeat: 1, DurationMs: 10},
+			{Image: testFrame{"c"}, Marker: "loop", DurationMs: 10},
+			{Image: testFrame{"d"}, JumpTo: "loop", DurationMs: 10},
+			{Image: testFrame{"never played"}, DurationMs: 10},
+		}
+
+		assert.Equal(t, "a b a b c d c d", names(Unroll(frames, 2)))
+
+		flat := Flatten(frames)
+		assert.Equal(t, "a b a b c d", names(flat))
+		assert.Equal(t, int32(1), flat[len(flat)-1].JumpBackAmount)
+
+		end, loopStart := FindLoop(frames)
+		assert.Equal(t, 4, end)
+		assert.Equal(t, 2, loopStart)
+	})
+
+	t.Run("unknown_marker", func(t *testing.T) {
+		frames := []Frame[testFrame]{
+			{Image: testFrame{"a"}, DurationMs: 10},
+			{Image: testFrame{"b"}, JumpTo: "nowhere", DurationMs: 10},
+			{Image: testFrame{"never played"}, DurationMs: 10},
+		}
+		assert.Equal(t, "a b", names(Unroll(frames, 3)))
+		assert.Equal(t, "a b", names(Flatten(frames)))
+	})
+}
+
+func TestFeed(t *testing.T) {
+	frames := []Frame[testFrame]{
+		{Image: testFrame{"frame 1"}, DurationMs: 100},
+		{Image: testFrame{"frame 2"}, DurationMs: 100},
+		{Image: testFrame{"frame 3"}, JumpBackAmount: 1, DurationMs: 100},
+	}
+	errDone := errors.New("done")
+
+	feed := func(src FrameSource[testFrame], limit int) []Frame[testFrame] {
+		var got []Frame[testFrame]
+		err := Feed(context.Background(), src, func(frame Frame[testFrame]) error {
+			got = append(got, frame)
+			if len(got) == limit {
+				return errDone
+			}
+			return nil
+		})
+		if err != nil {
+			assert.IsError(t, err, errDone)
+		}
+		return got
+	}
+
+	t.Run("slice", func(t *testing.T) {
+		got := feed(NewSliceSource(frames), 7)
+		assert.Equal(t, Unroll(frames, 3), got)
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		// Streams can't seek, so jumps end them.
+		ch := make(chan Frame[testFrame], len(frames))
+		for _, frame := range frames {
+			ch <- frame
+		}
+		close(ch)
+		assert.Equal(t, Unroll(frames, 1), feed(NewStreamSource(ch), 100))
+	})
+
+	t.Run("generator", func(t *testing.T) {
+		src := NewGeneratorSource(func(ctx context.Context, i int) (Frame[testFrame], error) {
+			return Frame[testFrame]{Image: testFrame{fmt.Sprint("frame ", i+1)}, DurationMs: 10}, nil
+		})
+		got := feed(src, 1000)
+		assert.Equal(t, 1000, len(got))
+		assert.Equal(t, Frame[testFrame]{Image: testFrame{"frame 1000"}, DurationMs: 10}, got[999])
+	})
+
+	t.Run("loop", func(t *testing.T) {
+		src := NewLoopSource[testFrame](NewSliceSource(frames[:2]), 1)
+		assert.Equal(t, []Frame[testFrame]{
+			{Image: testFrame{"frame 1"}, DurationMs: 100},
+			{Image: testFrame{"frame 2"}, DurationMs: 100},
+			{Image: testFrame{"frame 2"}, DurationMs: 100},
+			{Image: testFrame{"frame 2"}, DurationMs: 100},
+		}, feed(src, 4))
+	})
+
+	t.Run("nested", func(t *testing.T) {
+		frames := nestedFrames()
+		assert.Equal(t, Unroll(frames, 1), feed(NewSliceSource(frames), 1000))
+	})
+
+	t.Run("zero_duration_loop", func(t *testing.T) {
+		// The loop is played once more before it is found to take no time.
+		src := NewSliceSource([]Frame[testFrame]{
+			{Image: testFrame{"frame 1"}, DurationMs: 100},
+			{Image: testFrame{"frame 2"}, DurationMs: 0},
+			{Image: testFrame{"frame 3"}, JumpBackAmount: 1, DurationMs: 0},
+		})
+		assert.Equal(t, 5, len(feed(src, 100)))
+	})
+
+	t.Run("map", func(t *testing.T) {
+		// The jumps of the mapped frames still loop the source.
+		src := MapSource[testFrame](NewSliceSource(frames), func(frame Frame[testFrame]) Frame[testFrame] {
+			frame.Image.data += "!"
+			return frame
+		})
+		got := feed(src, 4)
+		assert.Equal(t, 4, len(got))
+		assert.Equal(t, Frame[testFrame]{Image: testFrame{"frame 2!"}, DurationMs: 100}, got[3])
+	})
+}
+
+func TestPlaySource(t *testing.T) {
+	// The animation is much longer than the player can hold.
+	p, _ := startPlayer(t, 2)
+	mustAddFrames(t, p, []Frame[testFrame]{{Image: testFrame{"old frame"}, DurationMs: 100}})
+
+	go func() {
+		assert.NoError(t, p.PlaySource(p.ctx, NewGeneratorSource(func(ctx context.Context, i int) (Frame[testFrame], error) {
+			if i == 20 {
+				return Frame[testFrame]{}, io.EOF
+			}
+			return Frame[testFrame]{Image: testFrame{fmt.Sprint("frame ", i+1)}, DurationMs: 20}, nil
+		})))
+	}()
+
+	var expect []Frame[testFrame]
+	for i := 0; i < 20; i++ {
+		expect = append(expect, Frame[testFrame]{Image: testFrame{fmt.Sprint("frame ", i+1)}, DurationMs: 20})
+	}
+
+	// The old frame may or may not have been played before the source.
+	frame := receiveFrame(t, p)
+	if frame.Image.data == "old frame" {
+		frame = receiveFrame(t, p)
+	}
+	assert.Equal(t, expect[0], frame)
+	expectFrames(t, p, expect[1:])
+}
+
+func TestPlayerMetrics(t *testing.T) {
+	before := ReadStats()
+
+	p, done := startPlayer(t, 10)
+	mustAddFrames(t, p, []Frame[testFrame]{
+		{Image: testFrame{"frame 1"}, DurationMs: 100},
+		{Image: testFrame{"frame 2"}, DurationMs: 150},
+		{Image: testFrame{"frame 3"}, DurationMs: 200},
+		{Image: testFrame{"frame 4"}, DurationMs: 250},
+	})
+	receiveFrames(t, p, 4)
+	done(nil)
+
+	// On a virtual clock, every frame is exactly on time.
+	after := ReadStats()
+	assert.Equal(t, before.TotalFrames+4, after.TotalFrames)
+	assert.Equal(t, before.DroppedFrames, after.DroppedFrames)
+	assert.Equal(t, before.FrameDrift.Count+4, after.FrameDrift.Count)
+	assert.Equal(t, before.FrameDrift.Sum, after.FrameDrift.Sum)
+	assert.Equal(t, before.FrameJitter.Count+3, after.FrameJitter.Count)
+	assert.Equal(t, before.FrameJitter.Sum, after.FrameJitter.Sum)
+}
+
+func TestHistogram(t *testing.T) {
+	h := NewHistogram([]time.Duration{time.Millisecond, 10 * time.Millisecond})
+	h.Observe(500 * time.Microsecond)
+	h.Observe(time.Millisecond)
+	h.Observe(5 * time.Millisecond)
+	h.Observe(time.Second)
+
+	assert.Equal(t, HistogramSnapshot{
+		Buckets: []time.Duration{time.Millisecond, 10 * time.Millisecond},
+		Counts:  []uint64{2, 3},
+		Count:   4,
+		Sum:     1006500 * time.Microsecond,
+	}, h.Snapshot())
+
+	assert.Equal(t,
+		`{"buckets":[{"le":0.001,"count":2},{"le":0.01,"count":3}],"count":4,"sum":1.0065}`,
+		h.String())
 }
 
 type testPlayer[Image any] struct {
 	*Player[Image]
-	ctx context.Context
+	ctx   context.Context
+	clock *VirtualClock
 }
 
 func startPlayer(t *testing.T, maxFrames int) (player *testPlayer[testFrame], done func(error)) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	t.Cleanup(cancel)
 
-	p := NewPlayerWithSize[testFrame](maxFrames)
+	clock := NewVirtualClock()
+	p := NewPlayerWithClock[testFrame](maxFrames, clock)
 
 	errCh := make(chan error, 1)
 	go func() { errCh <- p.Run(ctx) }()
 
-	return &testPlayer[testFrame]{p, ctx}, func(expectErr error) {
+	return &testPlayer[testFrame]{p, ctx, clock}, func(expectErr error) {
 		if ctx.Err() != nil {
 			return
 		}
@@ -138,38 +652,70 @@ func startPlayer(t *testing.T, maxFrames int) (player *testPlayer[testFrame], do
 	}
 }
 
+// expectFrames receives the given frames and checks that each of them is
+// shown once the previous frame's duration is over.
 func expectFrames(t *testing.T, p *testPlayer[testFrame], frames []Frame[testFrame]) {
 	t.Helper()
 
-	ctx, cancel := context.WithCancel(p.ctx)
-	defer cancel()
+	var lastFrame Frame[testFrame]
+	var lastTime time.Duration
+
+	for i, expect := range frames {
+		frame := receiveFrame(t, p)
+		now := p.clock.Now()
+		if i > 0 {
+			assert.Equal(t, lastFrame.Duration(), now-lastTime, "time between frames %d and %d", i-1, i)
+		}
+		lastFrame, lastTime = frame, now
+
+		assert.Equal(t, expect, frame)
+	}
+}
 
-	var lastTime time.Time
-	var frame Frame[testFrame]
+// receiveFrame receives the next frame. If no frame is due, the clock is
+// advanced to the time that the player is waiting for.
+func receiveFrame(t *testing.T, p *testPlayer[testFrame]) Frame[testFrame] {
+	t.Helper()
 
-	for len(frames) > 0 {
+	for {
 		select {
-		case <-ctx.Done():
-			t.Error("timed out")
-			return
-		case frame = <-p.C:
+		case <-p.ctx.Done():
+			t.Fatal("timed out")
+		case frame := <-p.C:
+			t.Log("got", frame, "at", p.clock.Now())
+			return frame
+		case <-time.After(settleTime):
 		}
 
-		now := time.Now()
-		if !lastTime.IsZero() {
-			offset := now.Sub(lastTime)
-			latency := intmath.Abs(offset - frame.Duration())
-			t.Logf("+%v (jitter %v)", offset, latency)
-			if latency > frameTimeMargin {
-				t.Error("frame duration was off by", latency)
+		if at, ok := p.clock.Next(); ok {
+			// Once the clock reaches the timer, a frame is always sent.
+			p.clock.Advance(at - p.clock.Now())
+			select {
+			case <-p.ctx.Done():
+				t.Fatal("timed out")
+			case frame := <-p.C:
+				t.Log("got", frame, "at", p.clock.Now())
+				return frame
 			}
 		}
-		lastTime = now
+	}
+}
 
-		t.Log("got", frame)
+func receiveFrames(t *testing.T, p *testPlayer[testFrame], n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		receiveFrame(t, p)
+	}
+}
 
-		assert.Equal(t, frames[0], frame)
-		frames = frames[1:]
+// expectNoFrame advances the clock by d and checks that no frame was shown.
+func expectNoFrame(t *testing.T, p *testPlayer[testFrame], d time.Duration) {
+	t.Helper()
+	p.clock.Advance(d)
+	select {
+	case <-time.After(settleTime):
+	case frame := <-p.C:
+		t.Errorf("got unexpected frame: %v", frame)
 	}
 }
 
@@ -0,0 +1,213 @@
+package animation
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is the timebase that a Player schedules frames on. Its time only has
+// to make sense relative to itself, such as the position of the audio that is
+// being played along with the animation.
+type Clock interface {
+	// Now returns the current time of the clock. It must never go backwards.
+	Now() time.Duration
+	// NewTimer returns a new stopped Timer on the clock.
+	NewTimer() Timer
+}
+
+// Timer fires once its Clock reaches a given time.
+type Timer interface {
+	// C returns the channel that receives when the timer fires.
+	C() <-chan struct{}
+	// Reset makes the timer fire once the clock reaches at. A firing that
+	// wasn't received yet is dropped.
+	Reset(at time.Duration)
+	// Stop stops the timer. A firing that wasn't received yet is dropped.
+	Stop()
+}
+
+// SystemClock returns a Clock that follows the system's monotonic clock,
+// starting at 0.
+func SystemClock() Clock {
+	return systemClock{start: time.Now()}
+}
+
+type systemClock struct {
+	start time.Time
+}
+
+func (c systemClock) Now() time.Duration { return time.Since(c.start) }
+func (c systemClock) NewTimer() Timer    { return NewSleepTimer(c.Now) }
+
+// NewSleepTimer returns a Timer for a clock that runs at about the speed of
+// real time. The timer sleeps for the time that is left and then checks the
+// clock again, so it follows clocks that drift away from the system clock,
+// such as the position of an audio device.
+func NewSleepTimer(now func() time.Duration) Timer {
+	t := &sleepTimer{
+		now: now,
+		c:   make(chan struct{}, 1),
+	}
+	t.timer = time.AfterFunc(time.Hour, t.check)
+	t.timer.Stop()
+	return t
+}
+
+type sleepTimer struct {
+	now   func() time.Duration
+	c     chan struct{}
+	timer *time.Timer
+
+	mu    sync.Mutex
+	at    time.Duration
+	armed bool
+}
+
+func (t *sleepTimer) C() <-chan struct{} { return t.c }
+
+func (t *sleepTimer) Reset(at time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.drain()
+	t.at = at
+	t.armed = true
+	t.timer.Reset(at - t.now())
+}
+
+func (t *sleepTimer) Stop() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.drain()
+	t.armed = false
+	t.timer.Stop()
+}
+
+func (t *sleepTimer) check() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.armed {
+		return
+	}
+
+	if left := t.at - t.now(); left > 0 {
+		// The clock is running slower than the system clock.
+		t.timer.Reset(left)
+		return
+	}
+
+	t.armed = false
+	select {
+	case t.c <- struct{}{}:
+	default:
+	}
+}
+
+func (t *sleepTimer) drain() {
+	select {
+	case <-t.c:
+	default:
+	}
+}
+
+// VirtualClock is a Clock that only advances when told to. It is useful for
+// testing timing without waiting in real time.
+type VirtualClock struct {
+	mu     sync.Mutex
+	now    time.Duration
+	timers map[*virtualTimer]struct{}
+}
+
+// NewVirtualClock creates a new VirtualClock at time 0.
+func NewVirtualClock() *VirtualClock {
+	return &VirtualClock{timers: make(map[*virtualTimer]struct{})}
+}
+
+// Now implements Clock.
+func (c *VirtualClock) Now() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance advances the clock by d, firing the timers that are due.
+func (c *VirtualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now += d
+	for t := range c.timers {
+		c.fireIfDue(t)
+	}
+}
+
+// Next returns the time at which the earliest timer that is waiting fires.
+// False is returned if no timer is waiting.
+func (c *VirtualClock) Next() (time.Duration, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var next time.Duration
+	var ok bool
+	for t := range c.timers {
+		if !ok || t.at < next {
+			next, ok = t.at, true
+		}
+	}
+	return next, ok
+}
+
+// NewTimer implements Clock.
+func (c *VirtualClock) NewTimer() Timer {
+	return &virtualTimer{
+		clock: c,
+		c:     make(chan struct{}, 1),
+	}
+}
+
+func (c *VirtualClock) fireIfDue(t *virtualTimer) {
+	if t.at > c.now {
+		return
+	}
+
+	delete(c.timers, t)
+	select {
+	case t.c <- struct{}{}:
+	default:
+	}
+}
+
+type virtualTimer struct {
+	clock *VirtualClock
+	c     chan struct{}
+	at    time.Duration
+}
+
+func (t *virtualTimer) C() <-chan struct{} { return t.c }
+
+func (t *virtualTimer) Reset(at time.Duration) {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	t.drain()
+	t.at = at
+	t.clock.timers[t] = struct{}{}
+	t.clock.fireIfDue(t)
+}
+
+func (t *virtualTimer) Stop() {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	t.drain()
+	delete(t.clock.timers, t)
+}
+
+func (t *virtualTimer) drain() {
+	select {
+	case <-t.c:
+	default:
+	}
+}
@@ -0,0 +1,157 @@
+package animation
+
+// loopCounts counts how many times the jumps of an animation were taken, by
+// the index of the frame that jumps.
+type loopCounts map[int]int32
+
+// nextIndex returns the index of the frame that is played after frame, which
+// is at index i. find returns the index of the last frame up to i with the
+// given marker, or -1 if there is none. ok is false if the frame jumps to a
+// frame that doesn't exist, which ends the animation.
+func nextIndex[Image any](counts loopCounts, i int, frame Frame[Image], find func(marker string) int) (next int, ok bool) {
+	if !frame.jumps() || (frame.Repeat > 0 && counts[i] >= frame.Repeat) {
+		return i + 1, true
+	}
+	target := jumpTarget(i, frame, find)
+	return target, target >= 0
+}
+
+// jumpTarget returns the index of the frame that the frame at index i jumps
+// to, which is negative if there is no such frame.
+func jumpTarget[Image any](i int, frame Frame[Image], find func(marker string) int) int {
+	if frame.JumpTo != "" {
+		return find(frame.JumpTo)
+	}
+	return i - int(frame.JumpBackAmount)
+}
+
+// advance records that the animation went from the frame at index i to the
+// frame at index next.
+func (c loopCounts) advance(i, next int) {
+	if next > i {
+		// The loop is done, so it starts over if it is played again.
+		delete(c, i)
+		return
+	}
+
+	c[i]++
+	// Restart the loops nested in this one.
+	for j := range c {
+		if j >= next && j < i {
+			delete(c, j)
+		}
+	}
+}
+
+// sliceFinder returns a function that finds the last frame up to index i with
+// a marker.
+func sliceFinder[Image any](frames []Frame[Image], i int) func(marker string) int {
+	return func(marker string) int {
+		for j := min(i, len(frames)-1); j >= 0; j-- {
+			if frames[j].Marker == marker {
+				return j
+			}
+		}
+		return -1
+	}
+}
+
+// withoutJump returns the frame without its jump.
+func withoutJump[Image any](frame Frame[Image]) Frame[Image] {
+	frame.JumpBackAmount = 0
+	frame.JumpTo = ""
+	frame.Repeat = 0
+	return frame
+}
+
+// FindLoop finds out how the given frames would be played by a Player.
+//
+// end is the number of frames that are played before the animation either
+// ends or loops forever. If the animation loops forever, loopStart is the
+// index of the frame that the last played frame jumps back to, and the frames
+// in [loopStart, end) are repeated forever. Otherwise, loopStart is -1. Loops
+// that are repeated a number of times are played along the way; use Flatten
+// first to get rid of them.
+func FindLoop[Image any](frames []Frame[Image]) (end, loopStart int) {
+	for i, frame := range frames {
+		if !frame.jumps() {
+			continue
+		}
+
+		target := jumpTarget(i, frame, sliceFinder(frames, i))
+		if target < 0 {
+			// There is no frame to jump back to, so treat the animation as
+			// ended.
+			return i + 1, -1
+		}
+
+		if frame.Repeat > 0 {
+			continue
+		}
+		return i + 1, target
+	}
+	return len(frames), -1
+}
+
+// Unroll returns the frames in the order that a Player would play them, with
+// the loop that repeats forever (if any) played repeats times. The returned
+// frames never jump back. Unroll is useful for formats that cannot express
+// loops themselves.
+func Unroll[Image any](frames []Frame[Image], repeats int) []Frame[Image] {
+	repeats = max(repeats, 1)
+
+	var unrolled []Frame[Image]
+	counts := loopCounts{}
+
+	for i, loops := 0, 0; i < len(frames); {
+		frame := frames[i]
+		unrolled = append(unrolled, withoutJump(frame))
+
+		next, ok := nextIndex(counts, i, frame, sliceFinder(frames, i))
+		if !ok {
+			break
+		}
+		if next <= i && frame.Repeat <= 0 {
+			if loops++; loops >= repeats {
+				break
+			}
+		}
+
+		counts.advance(i, next)
+		i = next
+	}
+
+	return unrolled
+}
+
+// Flatten returns the frames in the order that a Player would play them,
+// except for the loop that repeats forever (if any), which is kept as a jump
+// back from the last frame. Loops that are repeated a number of times are
+// unrolled and jumps to markers are resolved, so the returned frames only use
+// JumpBackAmount. Flatten is useful for formats that can only loop forever.
+func Flatten[Image any](frames []Frame[Image]) []Frame[Image] {
+	var flat []Frame[Image]
+	positions := make([]int, len(frames)) // last index of each frame in flat
+	counts := loopCounts{}
+
+	for i := 0; i < len(frames); {
+		frame := frames[i]
+		positions[i] = len(flat)
+		flat = append(flat, withoutJump(frame))
+
+		next, ok := nextIndex(counts, i, frame, sliceFinder(frames, i))
+		if !ok {
+			break
+		}
+		if next <= i && frame.Repeat <= 0 {
+			last := len(flat) - 1
+			flat[last].JumpBackAmount = int32(last - positions[next])
+			break
+		}
+
+		counts.advance(i, next)
+		i = next
+	}
+
+	return flat
+}
@@ -0,0 +1,144 @@
+package animation
+
+import (
+	"encoding/json"
+	"expvar"
+	"sync"
+	"time"
+)
+
+// DefaultBuckets are the upper bounds of the buckets of a Histogram of frame
+// timings, from well within a frame to several frames late.
+var DefaultBuckets = []time.Duration{
+	100 * time.Microsecond,
+	500 * time.Microsecond,
+	time.Millisecond,
+	2 * time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	20 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+}
+
+// Histogram counts durations into buckets. It is an expvar.Var, so it can be
+// published with expvar. It is safe to use from multiple goroutines.
+type Histogram struct {
+	buckets []time.Duration
+
+	mu     sync.Mutex
+	counts []uint64 // counts[i] is the number of durations <= buckets[i]
+	count  uint64
+	sum    time.Duration
+}
+
+// NewHistogram creates a Histogram with the given bucket upper bounds, which
+// must be sorted.
+func NewHistogram(buckets []time.Duration) *Histogram {
+	return &Histogram{
+		buckets: buckets,
+		counts:  make([]uint64, len(buckets)),
+	}
+}
+
+// Observe adds a duration to the histogram.
+func (h *Histogram) Observe(d time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for i, le := range h.buckets {
+		if d <= le {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += d
+}
+
+// HistogramSnapshot is the state of a Histogram at one point in time.
+type HistogramSnapshot struct {
+	// Buckets are the upper bounds of the buckets.
+	Buckets []time.Duration
+	// Counts are the number of durations in each bucket. Like Prometheus
+	// histograms, the buckets are cumulative: Counts[i] is the number of
+	// durations that are at most Buckets[i].
+	Counts []uint64
+	// Count is the number of durations, including the ones that are larger
+	// than every bucket.
+	Count uint64
+	// Sum is the sum of the durations.
+	Sum time.Duration
+}
+
+// Snapshot returns the current state of the histogram.
+func (h *Histogram) Snapshot() HistogramSnapshot {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return HistogramSnapshot{
+		Buckets: h.buckets,
+		Counts:  append([]uint64(nil), h.counts...),
+		Count:   h.count,
+		Sum:     h.sum,
+	}
+}
+
+// String implements expvar.Var. The histogram is written as JSON with the
+// bucket bounds and the sum in seconds.
+func (h *Histogram) String() string {
+	s := h.Snapshot()
+
+	type bucket struct {
+		LE    float64 `json:"le"`
+		Count uint64  `json:"count"`
+	}
+
+	v := struct {
+		Buckets []bucket `json:"buckets"`
+		Count   uint64   `json:"count"`
+		Sum     float64  `json:"sum"`
+	}{
+		Buckets: make([]bucket, len(s.Buckets)),
+		Count:   s.Count,
+		Sum:     s.Sum.Seconds(),
+	}
+	for i, le := range s.Buckets {
+		v.Buckets[i] = bucket{LE: le.Seconds(), Count: s.Counts[i]}
+	}
+
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+// Stats are the metrics of all players, which are also published with expvar
+// in the "animation" map.
+type Stats struct {
+	// TotalFrames is the number of frames that players have sent.
+	TotalFrames int64
+	// DroppedFrames is the number of frames that were due while the frame
+	// before them still wasn't received, because the receiver was too slow.
+	DroppedFrames int64
+	// FrameJitter is how much the time between two frames differed from the
+	// duration of the first one.
+	FrameJitter HistogramSnapshot
+	// FrameDrift is how late frames were sent.
+	FrameDrift HistogramSnapshot
+}
+
+// ReadStats returns the current metrics of all players.
+func ReadStats() Stats {
+	return Stats{
+		TotalFrames:   metricValue(metricTotalFrames),
+		DroppedFrames: metricValue(metricDroppedFrames),
+		FrameJitter:   frameJitter.Snapshot(),
+		FrameDrift:    frameDrift.Snapshot(),
+	}
+}
+
+func metricValue(name string) int64 {
+	v, _ := metrics.Get(name).(*expvar.Int)
+	if v == nil {
+		return 0
+	}
+	return v.Value()
+}
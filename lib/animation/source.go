@@ -0,0 +1,284 @@
+package animation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// FrameSource produces the frames of an animation one at a time, so that
+// animations don't have to fit in a Player. Sources can be backed by a file,
+// generated as they are played or streamed from the network, and they can be
+// endless.
+type FrameSource[Image any] interface {
+	// NextFrame returns the next frame of the animation. It returns io.EOF
+	// once the animation has ended.
+	NextFrame(ctx context.Context) (Frame[Image], error)
+}
+
+// SeekableFrameSource is a FrameSource that can go back to an earlier frame,
+// which lets its frames jump back and loop.
+type SeekableFrameSource[Image any] interface {
+	FrameSource[Image]
+	// SeekFrame makes the frame at index the next frame returned by
+	// NextFrame.
+	SeekFrame(index int) error
+}
+
+// Feed reads the frames of src and passes them to add, one at a time, until
+// src ends or add returns an error. add usually blocks until there is room for
+// the frame, such as Player.AddFrame does, so frames are read just before
+// they are played.
+//
+// The jumps of the frames, including repeats and markers, are followed in src
+// rather than passed to add, so loops can be longer than any buffer and loop
+// forever. Jumps in sources that can't seek end the animation, as do loops
+// that take no time.
+func Feed[Image any](ctx context.Context, src FrameSource[Image], add func(Frame[Image]) error) error {
+	seeker, _ := src.(SeekableFrameSource[Image])
+
+	var index int
+	var sinceJump time.Duration
+	counts := loopCounts{}
+	markers := make(map[string]int)
+
+	for {
+		frame, err := src.NextFrame(ctx)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("cannot get frame %d: %w", index, err)
+		}
+
+		if frame.Marker != "" {
+			markers[frame.Marker] = index
+		}
+
+		next, ok := nextIndex(counts, index, frame, func(marker string) int {
+			if i, ok := markers[marker]; ok && i <= index {
+				return i
+			}
+			return -1
+		})
+
+		if err := add(withoutJump(frame)); err != nil {
+			return err
+		}
+		sinceJump += frame.Duration()
+
+		switch {
+		case !ok:
+			return nil
+		case next > index:
+			counts.advance(index, next)
+			index = next
+			continue
+		case seeker == nil:
+			return nil
+		case sinceJump == 0 && frame.Repeat <= 0:
+			// The loop would be played forever without taking any time.
+			return nil
+		}
+
+		if err := seeker.SeekFrame(next); err != nil {
+			return fmt.Errorf("cannot jump back to frame %d: %w", next, err)
+		}
+		counts.advance(index, next)
+		index = next
+		sinceJump = 0
+	}
+}
+
+// PlaySource replaces the animation with the frames of src and plays them
+// until src ends or the context is canceled. Frames are pulled from src as
+// there is room for them, and frames can't be added by other goroutines in
+// the meantime.
+func (p *Player[Image]) PlaySource(ctx context.Context, src FrameSource[Image]) error {
+	p.adding.Lock()
+	defer p.adding.Unlock()
+
+	if err := p.Clear(ctx); err != nil {
+		return err
+	}
+
+	return Feed(ctx, src, func(frame Frame[Image]) error {
+		return p.addFrameCtx(ctx, frame)
+	})
+}
+
+// SliceSource is a FrameSource of frames that are in memory.
+type SliceSource[Image any] struct {
+	frames []Frame[Image]
+	next   int
+}
+
+var _ SeekableFrameSource[any] = (*SliceSource[any])(nil)
+
+// NewSliceSource creates a SliceSource for the given frames.
+func NewSliceSource[Image any](frames []Frame[Image]) *SliceSource[Image] {
+	return &SliceSource[Image]{frames: frames}
+}
+
+// NextFrame implements FrameSource.
+func (s *SliceSource[Image]) NextFrame(ctx context.Context) (Frame[Image], error) {
+	if s.next >= len(s.frames) {
+		return Frame[Image]{}, io.EOF
+	}
+	s.next++
+	return s.frames[s.next-1], nil
+}
+
+// SeekFrame implements SeekableFrameSource.
+func (s *SliceSource[Image]) SeekFrame(index int) error {
+	if index < 0 || index >= len(s.frames) {
+		return fmt.Errorf("frame %d out of range", index)
+	}
+	s.next = index
+	return nil
+}
+
+// GeneratorFunc generates the frame at the given index of an animation. It
+// returns io.EOF if the animation has no such frame.
+type GeneratorFunc[Image any] func(ctx context.Context, index int) (Frame[Image], error)
+
+// GeneratorSource is a FrameSource that generates its frames as they are
+// needed, such as a procedural pattern that never ends.
+type GeneratorSource[Image any] struct {
+	generate GeneratorFunc[Image]
+	next     int
+}
+
+var _ SeekableFrameSource[any] = (*GeneratorSource[any])(nil)
+
+// NewGeneratorSource creates a GeneratorSource that generates frames with fn.
+func NewGeneratorSource[Image any](fn GeneratorFunc[Image]) *GeneratorSource[Image] {
+	return &GeneratorSource[Image]{generate: fn}
+}
+
+// NextFrame implements FrameSource.
+func (s *GeneratorSource[Image]) NextFrame(ctx context.Context) (Frame[Image], error) {
+	frame, err := s.generate(ctx, s.next)
+	if err != nil {
+		return Frame[Image]{}, err
+	}
+	s.next++
+	return frame, nil
+}
+
+// SeekFrame implements SeekableFrameSource.
+func (s *GeneratorSource[Image]) SeekFrame(index int) error {
+	if index < 0 {
+		return fmt.Errorf("frame %d out of range", index)
+	}
+	s.next = index
+	return nil
+}
+
+// StreamSource is a FrameSource of frames received from a channel, such as
+// frames streamed over the network. The animation ends when the channel is
+// closed. A stream can't seek, so its frames can't jump back.
+type StreamSource[Image any] struct {
+	ch <-chan Frame[Image]
+}
+
+// NewStreamSource creates a StreamSource that receives frames from ch.
+func NewStreamSource[Image any](ch <-chan Frame[Image]) StreamSource[Image] {
+	return StreamSource[Image]{ch: ch}
+}
+
+// NextFrame implements FrameSource.
+func (s StreamSource[Image]) NextFrame(ctx context.Context) (Frame[Image], error) {
+	select {
+	case <-ctx.Done():
+		return Frame[Image]{}, ctx.Err()
+	case frame, ok := <-s.ch:
+		if !ok {
+			return Frame[Image]{}, io.EOF
+		}
+		return frame, nil
+	}
+}
+
+// LoopSource loops a SeekableFrameSource forever: whenever the source ends,
+// it is played again from its loop start. This is an explicit loop marker for
+// sources whose frames don't jump back themselves.
+type LoopSource[Image any] struct {
+	src       SeekableFrameSource[Image]
+	loopStart int
+	next      int
+	sinceLoop time.Duration
+}
+
+var _ SeekableFrameSource[any] = (*LoopSource[any])(nil)
+
+// NewLoopSource creates a LoopSource that plays src and then loops it from the
+// frame at loopStart.
+func NewLoopSource[Image any](src SeekableFrameSource[Image], loopStart int) *LoopSource[Image] {
+	return &LoopSource[Image]{src: src, loopStart: loopStart}
+}
+
+// NextFrame implements FrameSource. A loop that takes no time ends the
+// animation instead of looping forever.
+func (s *LoopSource[Image]) NextFrame(ctx context.Context) (Frame[Image], error) {
+	frame, err := s.src.NextFrame(ctx)
+	if errors.Is(err, io.EOF) && s.next > s.loopStart && s.sinceLoop > 0 {
+		if err := s.SeekFrame(s.loopStart); err != nil {
+			return Frame[Image]{}, err
+		}
+		frame, err = s.src.NextFrame(ctx)
+	}
+	if err != nil {
+		return Frame[Image]{}, err
+	}
+
+	s.next++
+	if s.next > s.loopStart {
+		s.sinceLoop += frame.Duration()
+	}
+	return frame, nil
+}
+
+// SeekFrame implements SeekableFrameSource.
+func (s *LoopSource[Image]) SeekFrame(index int) error {
+	if err := s.src.SeekFrame(index); err != nil {
+		return err
+	}
+	s.next = index
+	s.sinceLoop = 0
+	return nil
+}
+
+// MapSource returns a FrameSource with the frames of src changed by fn, such
+// as to dim them. The source can seek if src can.
+func MapSource[Image any](src FrameSource[Image], fn func(Frame[Image]) Frame[Image]) FrameSource[Image] {
+	m := mapSource[Image]{src, fn}
+	if seeker, ok := src.(SeekableFrameSource[Image]); ok {
+		return seekableMapSource[Image]{m, seeker}
+	}
+	return m
+}
+
+type mapSource[Image any] struct {
+	src FrameSource[Image]
+	fn  func(Frame[Image]) Frame[Image]
+}
+
+func (s mapSource[Image]) NextFrame(ctx context.Context) (Frame[Image], error) {
+	frame, err := s.src.NextFrame(ctx)
+	if err != nil {
+		return Frame[Image]{}, err
+	}
+	return s.fn(frame), nil
+}
+
+type seekableMapSource[Image any] struct {
+	mapSource[Image]
+	seeker SeekableFrameSource[Image]
+}
+
+func (s seekableMapSource[Image]) SeekFrame(index int) error {
+	return s.seeker.SeekFrame(index)
+}
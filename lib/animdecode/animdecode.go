@@ -0,0 +1,146 @@
+// Package animdecode decodes animated images and videos into animation frames
+// that are ready to be rendered onto the LEDs.
+package animdecode
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"os"
+
+	_ "image/jpeg"
+
+	"dev.acmcsuf.com/christmas/lib/animation"
+	"dev.acmcsuf.com/christmas/lib/xdraw"
+
+	_ "golang.org/x/image/bmp"
+)
+
+// Frame durations shorter than minDelay are treated as defaultDelay. Like
+// browsers do, this is because many GIFs use 0 to mean "as fast as
+// reasonable".
+const (
+	minDelay     = 20  // ms
+	defaultDelay = 100 // ms
+)
+
+// Opts are the options for Load.
+type Opts struct {
+	// Bounds is the bounds that the frames are scaled to, usually the canvas
+	// bounds of the LED canvas.
+	Bounds image.Rectangle
+	// ScaleMode is how the frames are scaled to Bounds.
+	ScaleMode xdraw.ScaleMode
+	// FPS is the rate at which videos are sampled. It defaults to
+	// DefaultFPS.
+	FPS int
+}
+
+// DefaultFPS is the default rate at which videos are sampled.
+const DefaultFPS = 30
+
+// Load loads the image, animated image or video at path and scales its
+// frames. Still images become a single frame with no duration. Videos are
+// decoded using ffmpeg and are played once.
+func Load(ctx context.Context, path string, opts Opts) ([]animation.Frame[*image.RGBA], error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	head, err := r.Peek(8)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	if !isImage(head) {
+		f.Close()
+		return DecodeVideo(ctx, path, opts)
+	}
+
+	frames, err := Decode(r)
+	if err != nil {
+		return nil, err
+	}
+	return Scale(frames, opts.Bounds, opts.ScaleMode), nil
+}
+
+// Decode decodes an animated GIF, an APNG or a still image into frames. A
+// still image becomes a single frame with no duration.
+func Decode(r io.Reader) ([]animation.Frame[image.Image], error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case bytes.HasPrefix(b, gifSignature):
+		return DecodeGIF(bytes.NewReader(b))
+	case bytes.HasPrefix(b, pngSignature):
+		return DecodeAPNG(bytes.NewReader(b))
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode image: %w", err)
+	}
+	return []animation.Frame[image.Image]{{Image: img}}, nil
+}
+
+// Scale scales every frame to the given bounds.
+func Scale(frames []animation.Frame[image.Image], bounds image.Rectangle, mode xdraw.ScaleMode) []animation.Frame[*image.RGBA] {
+	scaled := make([]animation.Frame[*image.RGBA], len(frames))
+	for i, frame := range frames {
+		scaled[i] = animation.WithImage(frame, xdraw.ScaleImage(frame.Image, bounds, mode))
+	}
+	return scaled
+}
+
+// isImage returns true if the file header belongs to an image format that
+// Decode supports. Anything else is assumed to be a video.
+func isImage(head []byte) bool {
+	for _, magic := range [][]byte{
+		gifSignature,
+		pngSignature,
+		{0xFF, 0xD8, 0xFF}, // JPEG
+		[]byte("BM"),       // BMP
+	} {
+		if bytes.HasPrefix(head, magic) {
+			return true
+		}
+	}
+	return false
+}
+
+// loop makes the frames play plays times, where 0 means forever.
+func loop[Image any](frames []animation.Frame[Image], plays int) []animation.Frame[Image] {
+	if len(frames) < 2 || plays == 1 {
+		return frames
+	}
+
+	last := &frames[len(frames)-1]
+	last.JumpBackAmount = int32(len(frames) - 1)
+	if plays > 1 {
+		last.Repeat = int32(plays - 1)
+	}
+	return frames
+}
+
+func frameDelay(ms int) animation.Milliseconds {
+	if ms < minDelay {
+		return defaultDelay
+	}
+	return animation.Milliseconds(ms)
+}
+
+func cloneRGBA(img *image.RGBA) *image.RGBA {
+	clone := image.NewRGBA(img.Rect)
+	copy(clone.Pix, img.Pix)
+	return clone
+}
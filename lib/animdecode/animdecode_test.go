@@ -0,0 +1,208 @@
+package animdecode
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"dev.acmcsuf.com/christmas/lib/animation"
+	"dev.acmcsuf.com/christmas/lib/xdraw"
+	"github.com/alecthomas/assert/v2"
+)
+
+var (
+	red   = color.RGBA{0xFF, 0, 0, 0xFF}
+	green = color.RGBA{0, 0xFF, 0, 0xFF}
+	blue  = color.RGBA{0, 0, 0xFF, 0xFF}
+)
+
+func TestDecodeGIF(t *testing.T) {
+	palette := color.Palette{color.Transparent, red, green}
+
+	full := image.NewPaletted(image.Rect(0, 0, 4, 4), palette)
+	for i := range full.Pix {
+		full.Pix[i] = 1 // red
+	}
+	// The second frame only covers the top left pixel.
+	patch := image.NewPaletted(image.Rect(0, 0, 1, 1), palette)
+	patch.Pix[0] = 2 // green
+
+	encode := func(loopCount int) []animation.Frame[image.Image] {
+		var buf bytes.Buffer
+		assert.NoError(t, gif.EncodeAll(&buf, &gif.GIF{
+			Image:     []*image.Paletted{full, patch},
+			Delay:     []int{5, 0},
+			LoopCount: loopCount,
+		}))
+
+		frames, err := Decode(&buf)
+		assert.NoError(t, err)
+		return frames
+	}
+
+	frames := encode(0)
+	assert.Equal(t, 2, len(frames))
+	assert.Equal(t, animation.Milliseconds(50), frames[0].DurationMs)
+	assert.Equal(t, animation.Milliseconds(defaultDelay), frames[1].DurationMs)
+	assert.Equal(t, int32(1), frames[1].JumpBackAmount)
+
+	// The patch is drawn on top of the first frame.
+	assert.Equal(t, color.Color(green), color.RGBAModel.Convert(frames[1].Image.At(0, 0)))
+	assert.Equal(t, color.Color(red), color.RGBAModel.Convert(frames[1].Image.At(3, 3)))
+
+	frames = encode(-1)
+	assert.Equal(t, 2, len(frames))
+	assert.Equal(t, int32(0), frames[1].JumpBackAmount)
+
+	// The frames are played three times by jumping back twice.
+	frames = encode(2)
+	assert.Equal(t, 2, len(frames))
+	assert.Equal(t, int32(1), frames[1].JumpBackAmount)
+	assert.Equal(t, int32(2), frames[1].Repeat)
+}
+
+func TestDecodeAPNG(t *testing.T) {
+	b := encodeAPNG(t, []image.Image{uniform(4, 4, red), uniform(2, 2, blue)}, 0)
+
+	frames, err := Decode(bytes.NewReader(b))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(frames))
+	assert.Equal(t, animation.Milliseconds(250), frames[0].DurationMs)
+	assert.Equal(t, int32(1), frames[1].JumpBackAmount)
+
+	assert.Equal(t, color.Color(red), color.RGBAModel.Convert(frames[0].Image.At(0, 0)))
+	// The second frame is blended at (1, 1).
+	assert.Equal(t, color.Color(red), color.RGBAModel.Convert(frames[1].Image.At(0, 0)))
+	assert.Equal(t, color.Color(blue), color.RGBAModel.Convert(frames[1].Image.At(1, 1)))
+	assert.Equal(t, color.Color(blue), color.RGBAModel.Convert(frames[1].Image.At(2, 2)))
+	assert.Equal(t, color.Color(red), color.RGBAModel.Convert(frames[1].Image.At(3, 3)))
+
+	b = encodeAPNG(t, []image.Image{uniform(4, 4, red), uniform(2, 2, blue)}, 3)
+	frames, err = Decode(bytes.NewReader(b))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(frames))
+	assert.Equal(t, int32(1), frames[1].JumpBackAmount)
+	assert.Equal(t, int32(2), frames[1].Repeat)
+}
+
+func TestDecodeStill(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, uniform(4, 4, red)))
+
+	frames, err := Decode(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(frames))
+	assert.Equal(t, animation.Milliseconds(0), frames[0].DurationMs)
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "anim.png")
+	b := encodeAPNG(t, []image.Image{uniform(4, 4, red), uniform(2, 2, blue)}, 1)
+	assert.NoError(t, os.WriteFile(path, b, 0644))
+
+	bounds := image.Rect(0, 0, 8, 16)
+	frames, err := Load(context.Background(), path, Opts{Bounds: bounds, ScaleMode: xdraw.ScaleFill})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(frames))
+	assert.Equal(t, bounds, frames[0].Image.Bounds())
+	assert.Equal(t, int32(0), frames[1].JumpBackAmount)
+}
+
+func TestDecodeVideo(t *testing.T) {
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		t.Skip("ffmpeg not found")
+	}
+
+	path := filepath.Join(t.TempDir(), "video.mp4")
+	cmd := exec.Command("ffmpeg", "-hide_banner", "-loglevel", "error",
+		"-f", "lavfi", "-i", "color=c=red:s=64x32:d=1:r=10", path)
+	out, err := cmd.CombinedOutput()
+	assert.NoError(t, err, string(out))
+
+	bounds := image.Rect(0, 0, 8, 8)
+	frames, err := Load(context.Background(), path, Opts{Bounds: bounds, FPS: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, 10, len(frames))
+	assert.Equal(t, animation.Milliseconds(100), frames[0].DurationMs)
+	assert.Equal(t, bounds, frames[0].Image.Bounds())
+}
+
+func TestVideoFilter(t *testing.T) {
+	assert.Equal(t,
+		"fps=30,scale=40:60:force_original_aspect_ratio=increase,crop=40:60",
+		videoFilter(30, 40, 60, xdraw.ScaleFill))
+	assert.Equal(t,
+		"fps=24,scale=40:60:force_original_aspect_ratio=decrease,pad=40:60:(ow-iw)/2:(oh-ih)/2:color=black",
+		videoFilter(24, 40, 60, xdraw.ScaleFit))
+}
+
+func uniform(w, h int, c color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i := 0; i < len(img.Pix); i += 4 {
+		copy(img.Pix[i:], []byte{c.R, c.G, c.B, c.A})
+	}
+	return img
+}
+
+// encodeAPNG encodes the images as an APNG. Every frame is shown for 250ms,
+// and every frame after the first is placed at (1, 1).
+func encodeAPNG(t *testing.T, imgs []image.Image, plays int) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	buf.Write(pngSignature)
+
+	be32 := binary.BigEndian.AppendUint32
+	be16 := binary.BigEndian.AppendUint16
+
+	seq := uint32(0)
+	for i, img := range imgs {
+		var encoded bytes.Buffer
+		assert.NoError(t, png.Encode(&encoded, img))
+		chunks, err := readPNGChunks(encoded.Bytes())
+		assert.NoError(t, err)
+
+		if i == 0 {
+			writePNGChunk(&buf, "IHDR", chunks[0].data)
+			writePNGChunk(&buf, "acTL", be32(be32(nil, uint32(len(imgs))), uint32(plays)))
+		}
+
+		offset := uint32(0)
+		if i > 0 {
+			offset = 1
+		}
+		fctl := be32(nil, seq)
+		fctl = be32(fctl, uint32(img.Bounds().Dx()))
+		fctl = be32(fctl, uint32(img.Bounds().Dy()))
+		fctl = be32(fctl, offset)
+		fctl = be32(fctl, offset)
+		fctl = be16(fctl, 1)
+		fctl = be16(fctl, 4)
+		fctl = append(fctl, apngDisposeNone, apngBlendOver)
+		writePNGChunk(&buf, "fcTL", fctl)
+		seq++
+
+		for _, chunk := range chunks {
+			if chunk.typ != "IDAT" {
+				continue
+			}
+			if i == 0 {
+				writePNGChunk(&buf, "IDAT", chunk.data)
+			} else {
+				writePNGChunk(&buf, "fdAT", append(be32(nil, seq), chunk.data...))
+				seq++
+			}
+		}
+	}
+
+	writePNGChunk(&buf, "IEND", nil)
+	return buf.Bytes()
+}
@@ -0,0 +1,244 @@
+package animdecode
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/draw"
+	"image/png"
+	"io"
+
+	"dev.acmcsuf.com/christmas/lib/animation"
+)
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// APNG frame control values. See
+// https://wiki.mozilla.org/APNG_Specification.
+const (
+	apngDisposeNone       = 0
+	apngDisposeBackground = 1
+	apngDisposePrevious   = 2
+
+	apngBlendSource = 0
+	apngBlendOver   = 1
+)
+
+type pngChunk struct {
+	typ  string
+	data []byte
+}
+
+// apngFrame is a frame control chunk (fcTL) and the image data of its frame.
+type apngFrame struct {
+	bounds   image.Rectangle
+	delayMs  int
+	dispose  byte
+	blend    byte
+	idatData [][]byte
+}
+
+// DecodeAPNG decodes an APNG into frames. Each frame is drawn on top of the
+// previous ones as the APNG describes, and its number of plays is kept. A
+// PNG that isn't animated becomes a single frame.
+func DecodeAPNG(r io.Reader) ([]animation.Frame[image.Image], error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	chunks, err := readPNGChunks(b)
+	if err != nil {
+		return nil, err
+	}
+
+	var ihdr []byte
+	var shared []pngChunk // chunks that every frame needs, such as PLTE
+	var frames []*apngFrame
+	var plays int
+	var animated, seenIDAT, defaultIsFrame bool
+
+	for _, chunk := range chunks {
+		switch chunk.typ {
+		case "IHDR":
+			ihdr = chunk.data
+		case "acTL":
+			if len(chunk.data) != 8 {
+				return nil, errors.New("invalid acTL chunk")
+			}
+			animated = true
+			plays = int(binary.BigEndian.Uint32(chunk.data[4:]))
+		case "fcTL":
+			frame, err := parseFCTL(chunk.data)
+			if err != nil {
+				return nil, err
+			}
+			frames = append(frames, frame)
+		case "IDAT":
+			// The default image is only part of the animation if its fcTL
+			// comes before it.
+			if !seenIDAT {
+				defaultIsFrame = len(frames) == 1
+				seenIDAT = true
+			}
+			if defaultIsFrame {
+				frames[0].idatData = append(frames[0].idatData, chunk.data)
+			}
+		case "fdAT":
+			if len(frames) == 0 || len(chunk.data) < 4 {
+				return nil, errors.New("unexpected fdAT chunk")
+			}
+			frame := frames[len(frames)-1]
+			frame.idatData = append(frame.idatData, chunk.data[4:])
+		case "IEND":
+		default:
+			if !seenIDAT {
+				shared = append(shared, chunk)
+			}
+		}
+	}
+
+	if !animated || len(frames) == 0 {
+		img, err := png.Decode(bytes.NewReader(b))
+		if err != nil {
+			return nil, err
+		}
+		return []animation.Frame[image.Image]{{Image: img}}, nil
+	}
+
+	if len(ihdr) != 13 {
+		return nil, errors.New("invalid IHDR chunk")
+	}
+	width := int(binary.BigEndian.Uint32(ihdr[0:]))
+	height := int(binary.BigEndian.Uint32(ihdr[4:]))
+	canvas := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	decoded := make([]animation.Frame[image.Image], 0, len(frames))
+	for i, frame := range frames {
+		if len(frame.idatData) == 0 {
+			return nil, fmt.Errorf("frame %d has no image data", i)
+		}
+
+		img, err := decodeAPNGFrame(ihdr, shared, frame)
+		if err != nil {
+			return nil, fmt.Errorf("cannot decode frame %d: %w", i, err)
+		}
+
+		dispose := frame.dispose
+		if i == 0 && dispose == apngDisposePrevious {
+			// There is no previous frame, so the spec says to treat it as
+			// disposing to the background.
+			dispose = apngDisposeBackground
+		}
+
+		var previous *image.RGBA
+		if dispose == apngDisposePrevious {
+			previous = cloneRGBA(canvas)
+		}
+
+		op := draw.Over
+		if frame.blend == apngBlendSource {
+			op = draw.Src
+		}
+		draw.Draw(canvas, frame.bounds, img, image.Point{}, op)
+
+		decoded = append(decoded, animation.Frame[image.Image]{
+			Image:      cloneRGBA(canvas),
+			DurationMs: frameDelay(frame.delayMs),
+		})
+
+		switch dispose {
+		case apngDisposeBackground:
+			draw.Draw(canvas, frame.bounds, image.Transparent, image.Point{}, draw.Src)
+		case apngDisposePrevious:
+			canvas = previous
+		}
+	}
+
+	return loop(decoded, plays), nil
+}
+
+func readPNGChunks(b []byte) ([]pngChunk, error) {
+	if !bytes.HasPrefix(b, pngSignature) {
+		return nil, errors.New("not a PNG")
+	}
+	b = b[len(pngSignature):]
+
+	var chunks []pngChunk
+	for len(b) >= 12 {
+		n := binary.BigEndian.Uint32(b)
+		if uint64(len(b)) < 12+uint64(n) {
+			return nil, errors.New("truncated PNG chunk")
+		}
+
+		chunk := pngChunk{typ: string(b[4:8]), data: b[8 : 8+n]}
+		chunks = append(chunks, chunk)
+		b = b[12+n:]
+
+		if chunk.typ == "IEND" {
+			break
+		}
+	}
+	return chunks, nil
+}
+
+func parseFCTL(data []byte) (*apngFrame, error) {
+	if len(data) != 26 {
+		return nil, errors.New("invalid fcTL chunk")
+	}
+
+	width := int(binary.BigEndian.Uint32(data[4:]))
+	height := int(binary.BigEndian.Uint32(data[8:]))
+	x := int(binary.BigEndian.Uint32(data[12:]))
+	y := int(binary.BigEndian.Uint32(data[16:]))
+	delayNum := int(binary.BigEndian.Uint16(data[20:]))
+	delayDen := int(binary.BigEndian.Uint16(data[22:]))
+	if delayDen == 0 {
+		delayDen = 100
+	}
+
+	return &apngFrame{
+		bounds:  image.Rect(x, y, x+width, y+height),
+		delayMs: delayNum * 1000 / delayDen,
+		dispose: data[24],
+		blend:   data[25],
+	}, nil
+}
+
+// decodeAPNGFrame decodes a single frame by putting it into a PNG of its
+// own, since image/png doesn't know about APNG.
+func decodeAPNGFrame(ihdr []byte, shared []pngChunk, frame *apngFrame) (image.Image, error) {
+	var buf bytes.Buffer
+	buf.Write(pngSignature)
+
+	frameIHDR := append([]byte(nil), ihdr...)
+	binary.BigEndian.PutUint32(frameIHDR[0:], uint32(frame.bounds.Dx()))
+	binary.BigEndian.PutUint32(frameIHDR[4:], uint32(frame.bounds.Dy()))
+	writePNGChunk(&buf, "IHDR", frameIHDR)
+
+	for _, chunk := range shared {
+		writePNGChunk(&buf, chunk.typ, chunk.data)
+	}
+	writePNGChunk(&buf, "IDAT", bytes.Join(frame.idatData, nil))
+	writePNGChunk(&buf, "IEND", nil)
+
+	return png.Decode(&buf)
+}
+
+func writePNGChunk(buf *bytes.Buffer, typ string, data []byte) {
+	var n [4]byte
+	binary.BigEndian.PutUint32(n[:], uint32(len(data)))
+	buf.Write(n[:])
+
+	crc := crc32.NewIEEE()
+	crc.Write([]byte(typ))
+	crc.Write(data)
+
+	buf.WriteString(typ)
+	buf.Write(data)
+	binary.BigEndian.PutUint32(n[:], crc.Sum32())
+	buf.Write(n[:])
+}
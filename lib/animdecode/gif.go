@@ -0,0 +1,75 @@
+package animdecode
+
+import (
+	"image"
+	"image/draw"
+	"image/gif"
+	"io"
+
+	"dev.acmcsuf.com/christmas/lib/animation"
+)
+
+var gifSignature = []byte("GIF8")
+
+// DecodeGIF decodes a GIF into frames. Each frame is drawn on top of the
+// previous ones as the GIF describes, and the GIF's loop count is kept.
+func DecodeGIF(r io.Reader) ([]animation.Frame[image.Image], error) {
+	g, err := gif.DecodeAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// GIF frames are patches on top of the previous frames, so they have to
+	// be drawn onto a canvas to get the full frames.
+	canvas := image.NewRGBA(image.Rect(0, 0, g.Config.Width, g.Config.Height))
+
+	frames := make([]animation.Frame[image.Image], len(g.Image))
+	for i, img := range g.Image {
+		var disposal byte
+		if i < len(g.Disposal) {
+			disposal = g.Disposal[i]
+		}
+
+		var previous *image.RGBA
+		if disposal == gif.DisposalPrevious {
+			previous = cloneRGBA(canvas)
+		}
+
+		draw.Draw(canvas, img.Bounds(), img, img.Bounds().Min, draw.Over)
+
+		var delay int
+		if i < len(g.Delay) {
+			delay = g.Delay[i] * 10
+		}
+
+		frames[i] = animation.Frame[image.Image]{
+			Image:      cloneRGBA(canvas),
+			DurationMs: frameDelay(delay),
+		}
+
+		switch disposal {
+		case gif.DisposalBackground:
+			draw.Draw(canvas, img.Bounds(), image.Transparent, image.Point{}, draw.Src)
+		case gif.DisposalPrevious:
+			canvas = previous
+		}
+	}
+
+	if len(frames) == 1 {
+		// A still GIF has no duration like any other still image.
+		frames[0].DurationMs = 0
+		return frames, nil
+	}
+
+	// LoopCount is the number of times the GIF is repeated after it is first
+	// played, 0 to repeat it forever or -1 to play it once.
+	plays := 0
+	switch {
+	case g.LoopCount < 0:
+		plays = 1
+	case g.LoopCount > 0:
+		plays = g.LoopCount + 1
+	}
+
+	return loop(frames, plays), nil
+}
@@ -0,0 +1,105 @@
+package animdecode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"os/exec"
+	"strings"
+
+	"dev.acmcsuf.com/christmas/lib/animation"
+	"dev.acmcsuf.com/christmas/lib/ffutil"
+	"dev.acmcsuf.com/christmas/lib/xdraw"
+)
+
+// DecodeVideo decodes the video at path into frames using ffmpeg. The frames
+// are sampled at opts.FPS and scaled to opts.Bounds by ffmpeg itself, which is
+// much faster than scaling them afterwards.
+func DecodeVideo(ctx context.Context, path string, opts Opts) ([]animation.Frame[*image.RGBA], error) {
+	fps := opts.FPS
+	if fps <= 0 {
+		fps = DefaultFPS
+	}
+
+	w := opts.Bounds.Dx()
+	h := opts.Bounds.Dy()
+	if w <= 0 || h <= 0 {
+		return nil, fmt.Errorf("invalid bounds %v", opts.Bounds)
+	}
+
+	args := ffutil.FFmpegArgs{
+		"-hide_banner", "-loglevel", "error",
+		"-i", path,
+		"-vf", videoFilter(fps, w, h, opts.ScaleMode),
+		"-f", "rawvideo",
+		"-pix_fmt", "rgba",
+		"-",
+	}
+
+	var stderr strings.Builder
+
+	cmd := exec.CommandContext(ctx, "ffmpeg", args...)
+	cmd.Stderr = &stderr
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("cannot create ffmpeg stdout: %w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("cannot start ffmpeg: %w", err)
+	}
+
+	var frames []animation.Frame[*image.RGBA]
+	var readErr error
+	for {
+		img := image.NewRGBA(opts.Bounds)
+		if _, err := io.ReadFull(stdout, img.Pix); err != nil {
+			if !errors.Is(err, io.EOF) {
+				readErr = err
+			}
+			break
+		}
+
+		// Round the total time rather than each frame so that the durations
+		// add up to the right time.
+		n := len(frames)
+		duration := (n+1)*1000/fps - n*1000/fps
+
+		frames = append(frames, animation.Frame[*image.RGBA]{
+			Image:      img,
+			DurationMs: animation.Milliseconds(duration),
+		})
+	}
+
+	if err := cmd.Wait(); err != nil {
+		return nil, fmt.Errorf("ffmpeg failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	if readErr != nil {
+		return nil, fmt.Errorf("cannot read frames from ffmpeg: %w", readErr)
+	}
+	if len(frames) == 0 {
+		return nil, errors.New("video has no frames")
+	}
+
+	return frames, nil
+}
+
+// videoFilter returns the ffmpeg filter that samples the video at fps and
+// scales it to w x h the same way xdraw.ScaleImage would.
+func videoFilter(fps, w, h int, mode xdraw.ScaleMode) string {
+	var scale string
+	switch mode {
+	case xdraw.ScaleFit:
+		scale = fmt.Sprintf(
+			"scale=%[1]d:%[2]d:force_original_aspect_ratio=decrease,"+
+				"pad=%[1]d:%[2]d:(ow-iw)/2:(oh-ih)/2:color=black", w, h)
+	default:
+		scale = fmt.Sprintf(
+			"scale=%[1]d:%[2]d:force_original_aspect_ratio=increase,"+
+				"crop=%[1]d:%[2]d", w, h)
+	}
+	return fmt.Sprintf("fps=%d,%s", fps, scale)
+}
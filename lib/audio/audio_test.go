@@ -0,0 +1,205 @@
+package audio
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+// testFormat plays one byte every millisecond.
+var testFormat = Format{
+	Encoding:      EncodingPCM,
+	SampleRate:    1000,
+	Channels:      1,
+	BitsPerSample: 8,
+}
+
+func TestWAV(t *testing.T) {
+	format := Format{
+		Encoding:      EncodingPCM,
+		SampleRate:    44100,
+		Channels:      2,
+		BitsPerSample: 16,
+	}
+	data := bytes.Repeat([]byte{1, 2, 3, 4}, 44100)
+
+	var buf bytes.Buffer
+	assert.NoError(t, EncodeWAV(&buf, format, data))
+
+	w, err := DecodeWAV(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, format, w.Format)
+	assert.Equal(t, time.Second, w.Duration())
+
+	got, err := io.ReadAll(w.Data)
+	assert.NoError(t, err)
+	assert.Equal(t, data, got)
+
+	t.Run("streamed", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, EncodeWAV(&buf, format, data))
+		b := buf.Bytes()
+		copy(b[40:], []byte{0xFF, 0xFF, 0xFF, 0xFF})
+
+		w, err := DecodeWAV(bytes.NewReader(b))
+		assert.NoError(t, err)
+		assert.Equal(t, time.Duration(-1), w.Duration())
+
+		got, err := io.ReadAll(w.Data)
+		assert.NoError(t, err)
+		assert.Equal(t, data, got)
+	})
+
+	t.Run("not_wav", func(t *testing.T) {
+		_, err := DecodeWAV(bytes.NewReader([]byte("RIFF....AVI LIST")))
+		assert.Error(t, err)
+	})
+}
+
+func TestDecodeMono(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+		data   []byte
+		want   []float64
+	}{
+		{
+			name:   "u8",
+			format: Format{EncodingPCM, 1000, 1, 8},
+			data:   []byte{0x80, 0xC0, 0x00},
+			want:   []float64{0, 0.5, -1},
+		},
+		{
+			name:   "s16_stereo",
+			format: Format{EncodingPCM, 1000, 2, 16},
+			data:   []byte{0x00, 0x40, 0x00, 0xC0, 0x00, 0x40, 0x00, 0x40},
+			want:   []float64{0, 0.5},
+		},
+		{
+			name:   "s24",
+			format: Format{EncodingPCM, 1000, 1, 24},
+			data:   []byte{0x00, 0x00, 0xC0},
+			want:   []float64{-0.5},
+		},
+		{
+			name:   "f32",
+			format: Format{EncodingFloat, 1000, 1, 32},
+			data:   []byte{0x00, 0x00, 0x00, 0x3F},
+			want:   []float64{0.5},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := make([]float64, len(test.want)+1)
+			n := test.format.DecodeMono(got, test.data)
+			assert.Equal(t, test.want, got[:n])
+		})
+	}
+}
+
+// blockingOutput accepts each write only once the test releases it, like a
+// sound card that is busy playing.
+type blockingOutput struct {
+	writes  chan []byte
+	release chan struct{}
+	latency time.Duration
+}
+
+func (o *blockingOutput) Write(b []byte) (int, error) {
+	o.writes <- b
+	<-o.release
+	return len(b), nil
+}
+
+func (o *blockingOutput) Latency() time.Duration { return o.latency }
+func (o *blockingOutput) Close() error           { return nil }
+
+type fakeTime struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (f *fakeTime) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+func (f *fakeTime) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+}
+
+func TestPlayerClock(t *testing.T) {
+	out := &blockingOutput{
+		writes:  make(chan []byte),
+		release: make(chan struct{}),
+		latency: 50 * time.Millisecond,
+	}
+	systemTime := &fakeTime{now: time.Unix(0, 0)}
+
+	p := NewPlayer(testFormat, out)
+	p.SystemTime = systemTime.Now
+	assert.Equal(t, time.Duration(0), p.Now())
+
+	done := make(chan error, 1)
+	go func() { done <- p.Play(context.Background(), bytes.NewReader(make([]byte, 200))) }()
+
+	// Once the next write is waiting, the previous one was accounted for.
+	assert.Equal(t, 10, len(<-out.writes))
+	acceptWrites := func(n int) {
+		for i := 0; i < n; i++ {
+			out.release <- struct{}{}
+			assert.Equal(t, 10, len(<-out.writes))
+		}
+	}
+
+	// Nothing is heard until the output's buffer is full.
+	acceptWrites(5)
+	assert.Equal(t, time.Duration(0), p.Now())
+
+	// 60ms were written and 50ms of them are buffered.
+	acceptWrites(1)
+	assert.Equal(t, 10*time.Millisecond, p.Now())
+
+	// The clock runs with the system clock between writes.
+	systemTime.Advance(5 * time.Millisecond)
+	assert.Equal(t, 15*time.Millisecond, p.Now())
+
+	// but it can't get ahead of the audio that was written, which happens if
+	// the output falls behind.
+	systemTime.Advance(time.Second)
+	assert.Equal(t, 60*time.Millisecond, p.Now())
+
+	// The clock never goes backwards, even though only 20ms were heard.
+	acceptWrites(1)
+	assert.Equal(t, 60*time.Millisecond, p.Now())
+
+	acceptWrites(12)
+	out.release <- struct{}{}
+	assert.NoError(t, <-done)
+
+	// Once the audio ends, the clock keeps running with the system clock.
+	now := p.Now()
+	systemTime.Advance(time.Second)
+	assert.Equal(t, now+time.Second, p.Now())
+}
+
+func TestNullOutput(t *testing.T) {
+	p := NewPlayer(testFormat, NewNullOutput(testFormat))
+
+	start := time.Now()
+	assert.NoError(t, p.Play(context.Background(), bytes.NewReader(make([]byte, 200))))
+	elapsed := time.Since(start)
+
+	// The last 100ms are in the pretend buffer.
+	assert.True(t, elapsed >= 100*time.Millisecond && elapsed < 150*time.Millisecond, "played in %v", elapsed)
+	assert.True(t, p.Now() >= 100*time.Millisecond, "clock is at %v", p.Now())
+}
@@ -0,0 +1,128 @@
+package audio
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+)
+
+// Output is an audio output, such as a sound card.
+type Output interface {
+	// Write blocks until the output has room for the audio.
+	io.WriteCloser
+	// Latency returns how much audio the output buffers before it is heard.
+	Latency() time.Duration
+}
+
+// alsaBufferTime is the size of the buffer that aplay is asked to use.
+const alsaBufferTime = 100 * time.Millisecond
+
+// pipeSize is the size of the pipe to aplay on Linux, which also buffers
+// audio.
+const pipeSize = 64 << 10
+
+type alsaOutput struct {
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	latency time.Duration
+}
+
+// OpenALSA plays audio of the given format on an ALSA device, such as
+// "default" or "hw:0,0", using aplay.
+func OpenALSA(format Format, device string) (Output, error) {
+	sampleFormat, err := alsaSampleFormat(format)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command("aplay",
+		"-q",
+		"-t", "raw",
+		"-D", device,
+		"-f", sampleFormat,
+		"-c", strconv.Itoa(format.Channels),
+		"-r", strconv.Itoa(format.SampleRate),
+		"--buffer-time", strconv.FormatInt(alsaBufferTime.Microseconds(), 10),
+		"-",
+	)
+	cmd.Stderr = os.Stderr
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("cannot start aplay: %w", err)
+	}
+
+	return &alsaOutput{
+		cmd:     cmd,
+		stdin:   stdin,
+		latency: alsaBufferTime + format.Duration(pipeSize),
+	}, nil
+}
+
+func alsaSampleFormat(f Format) (string, error) {
+	switch {
+	case f.Encoding == EncodingPCM && f.BitsPerSample == 8:
+		return "U8", nil
+	case f.Encoding == EncodingPCM && f.BitsPerSample == 16:
+		return "S16_LE", nil
+	case f.Encoding == EncodingPCM && f.BitsPerSample == 24:
+		return "S24_3LE", nil
+	case f.Encoding == EncodingPCM && f.BitsPerSample == 32:
+		return "S32_LE", nil
+	case f.Encoding == EncodingFloat && f.BitsPerSample == 32:
+		return "FLOAT_LE", nil
+	case f.Encoding == EncodingFloat && f.BitsPerSample == 64:
+		return "FLOAT64_LE", nil
+	default:
+		return "", errors.New("unsupported sample format")
+	}
+}
+
+func (o *alsaOutput) Write(b []byte) (int, error) { return o.stdin.Write(b) }
+func (o *alsaOutput) Latency() time.Duration      { return o.latency }
+
+// Close waits for aplay to play the rest of the audio.
+func (o *alsaOutput) Close() error {
+	o.stdin.Close()
+	return o.cmd.Wait()
+}
+
+// nullBufferTime is how much audio the null output pretends to buffer.
+const nullBufferTime = 100 * time.Millisecond
+
+type nullOutput struct {
+	format  Format
+	start   time.Time
+	written int64
+}
+
+// NewNullOutput returns an output that discards the audio, but accepts it at
+// the speed that it would be played. Playing on it keeps a virtual clock that
+// behaves like a sound card, so synchronization can be checked without one.
+func NewNullOutput(format Format) Output {
+	return &nullOutput{format: format}
+}
+
+func (o *nullOutput) Write(b []byte) (int, error) {
+	if o.start.IsZero() {
+		o.start = time.Now()
+	}
+
+	// Accept the audio once there is room for it in the pretend buffer.
+	o.written += int64(len(b))
+	playedAt := o.start.Add(o.format.Duration(o.written) - nullBufferTime)
+	time.Sleep(time.Until(playedAt))
+
+	return len(b), nil
+}
+
+func (o *nullOutput) Latency() time.Duration { return nullBufferTime }
+func (o *nullOutput) Close() error           { return nil }
@@ -0,0 +1,147 @@
+package audio
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"dev.acmcsuf.com/christmas/lib/animation"
+)
+
+// chunkDuration is how much audio is written to the output at once. The
+// clock is corrected after every chunk.
+const chunkDuration = 10 * time.Millisecond
+
+// Player plays audio to an output and keeps track of the position that is
+// being heard. It is an animation.Clock, so animations can be played on it to
+// stay in sync with the audio, even if the audio device runs slightly faster
+// or slower than the system clock.
+//
+// The position is estimated from how much audio the output has accepted, minus
+// what it buffers. The clock stays at 0 until Play is called and keeps
+// running at the speed of the system clock after the audio ends.
+type Player struct {
+	// Latency is how much audio the output buffers before it is heard. It
+	// defaults to the output's latency and can be adjusted to sync the lights
+	// to the audio by ear.
+	Latency time.Duration
+	// SystemTime returns the current system time. It defaults to time.Now.
+	SystemTime func() time.Time
+
+	format Format
+	out    Output
+
+	mu         sync.Mutex
+	written    int64         // bytes written to the output
+	anchorPos  time.Duration // position heard at anchorTime
+	anchorTime time.Time
+	last       time.Duration // last position returned by Now
+	started    bool
+	finished   bool
+}
+
+var _ animation.Clock = (*Player)(nil)
+
+// NewPlayer creates a new Player for audio of the given format.
+func NewPlayer(format Format, out Output) *Player {
+	return &Player{
+		Latency:    out.Latency(),
+		SystemTime: time.Now,
+		format:     format,
+		out:        out,
+	}
+}
+
+// Play writes the audio from r to the output until r ends or the context is
+// canceled. It doesn't close the output. Play must only be called once.
+func (p *Player) Play(ctx context.Context, r io.Reader) error {
+	p.mu.Lock()
+	if p.started {
+		p.mu.Unlock()
+		return errors.New("audio is already playing")
+	}
+	p.started = true
+	p.anchorTime = p.SystemTime()
+	p.mu.Unlock()
+
+	defer p.finish()
+
+	buf := make([]byte, max(p.format.Bytes(chunkDuration), int64(p.format.BlockSize())))
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			if _, err := p.out.Write(buf[:n]); err != nil {
+				return err
+			}
+			p.wrote(n)
+		}
+
+		if err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// wrote moves the clock to what is being heard after n more bytes were
+// accepted by the output. The output blocks while its buffer is full, so
+// everything but the buffer has been heard.
+func (p *Player) wrote(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.written += int64(n)
+	p.anchorPos = max(p.format.Duration(p.written)-p.Latency, 0)
+	p.anchorTime = p.SystemTime()
+}
+
+// finish lets the clock run on the system clock from the end of the audio.
+func (p *Player) finish() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.anchorPos = p.now()
+	p.anchorTime = p.SystemTime()
+	p.finished = true
+}
+
+// Now returns the position of the audio that is being heard. It implements
+// animation.Clock.
+func (p *Player) Now() time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.now()
+}
+
+func (p *Player) now() time.Duration {
+	if !p.started {
+		return 0
+	}
+
+	pos := p.anchorPos + p.SystemTime().Sub(p.anchorTime)
+	if !p.finished {
+		// The output can't play what wasn't written to it yet.
+		pos = min(pos, p.format.Duration(p.written))
+	}
+
+	// Anchors may jump back a bit when the output accepts a burst of audio,
+	// but the clock must not.
+	pos = max(pos, p.last)
+	p.last = pos
+
+	return pos
+}
+
+// NewTimer implements animation.Clock.
+func (p *Player) NewTimer() animation.Timer {
+	return animation.NewSleepTimer(p.Now)
+}
@@ -0,0 +1,86 @@
+package audio
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+)
+
+// DecodeMono decodes whole blocks of interleaved samples from b into dst as
+// mono samples in [-1, 1], averaging the channels. It returns the number of
+// samples decoded, which is the smaller of len(dst) and the number of blocks
+// in b.
+func (f Format) DecodeMono(dst []float64, b []byte) int {
+	blockSize := f.BlockSize()
+	sampleSize := f.BitsPerSample / 8
+
+	n := min(len(dst), len(b)/blockSize)
+	for i := 0; i < n; i++ {
+		block := b[i*blockSize : (i+1)*blockSize]
+
+		var sum float64
+		for c := 0; c < f.Channels; c++ {
+			sum += f.decodeSample(block[c*sampleSize : (c+1)*sampleSize])
+		}
+		dst[i] = sum / float64(f.Channels)
+	}
+	return n
+}
+
+func (f Format) decodeSample(b []byte) float64 {
+	switch {
+	case f.Encoding == EncodingFloat && f.BitsPerSample == 32:
+		return float64(math.Float32frombits(binary.LittleEndian.Uint32(b)))
+	case f.Encoding == EncodingFloat && f.BitsPerSample == 64:
+		return math.Float64frombits(binary.LittleEndian.Uint64(b))
+	case f.BitsPerSample == 8:
+		// 8-bit samples are unsigned.
+		return (float64(b[0]) - 128) / 128
+	case f.BitsPerSample == 16:
+		return float64(int16(binary.LittleEndian.Uint16(b))) / (1 << 15)
+	case f.BitsPerSample == 24:
+		v := int32(uint32(b[0])<<8|uint32(b[1])<<16|uint32(b[2])<<24) >> 8
+		return float64(v) / (1 << 23)
+	case f.BitsPerSample == 32:
+		return float64(int32(binary.LittleEndian.Uint32(b))) / (1 << 31)
+	default:
+		return 0
+	}
+}
+
+// SampleReader reads mono samples from PCM audio.
+type SampleReader struct {
+	format Format
+	r      io.Reader
+	buf    []byte
+}
+
+// NewSampleReader creates a new SampleReader that reads audio of the given
+// format from r.
+func NewSampleReader(format Format, r io.Reader) *SampleReader {
+	return &SampleReader{format: format, r: r}
+}
+
+// Format returns the format of the audio.
+func (r *SampleReader) Format() Format {
+	return r.format
+}
+
+// ReadFull reads exactly len(dst) mono samples into dst. Like io.ReadFull, it
+// returns io.EOF if no samples were read and io.ErrUnexpectedEOF if only some
+// were, along with how many.
+func (r *SampleReader) ReadFull(dst []float64) (int, error) {
+	size := len(dst) * r.format.BlockSize()
+	if cap(r.buf) < size {
+		r.buf = make([]byte, size)
+	}
+	buf := r.buf[:size]
+
+	n, err := io.ReadFull(r.r, buf)
+	samples := r.format.DecodeMono(dst, buf[:n])
+	if errors.Is(err, io.ErrUnexpectedEOF) && samples == 0 {
+		err = io.EOF
+	}
+	return samples, err
+}
@@ -0,0 +1,228 @@
+// Package audio plays PCM audio and keeps a clock of the playback position, so
+// that animations can be synchronized to music.
+package audio
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// Encoding is how samples are encoded.
+type Encoding uint16
+
+const (
+	// EncodingPCM is signed integer samples, or unsigned for 8-bit samples.
+	EncodingPCM Encoding = 1
+	// EncodingFloat is IEEE floating point samples.
+	EncodingFloat Encoding = 3
+)
+
+const encodingExtensible = 0xFFFE
+
+// Format describes interleaved little-endian PCM audio.
+type Format struct {
+	Encoding      Encoding
+	SampleRate    int
+	Channels      int
+	BitsPerSample int
+}
+
+// BlockSize returns the size of one sample for every channel in bytes.
+func (f Format) BlockSize() int {
+	return f.Channels * f.BitsPerSample / 8
+}
+
+// BytesPerSecond returns the number of bytes that are played every second.
+func (f Format) BytesPerSecond() int {
+	return f.SampleRate * f.BlockSize()
+}
+
+// Duration returns how long the given number of bytes plays for.
+func (f Format) Duration(bytes int64) time.Duration {
+	return time.Duration(bytes) * time.Second / time.Duration(f.BytesPerSecond())
+}
+
+// Bytes returns the number of bytes that play for the given duration, rounded
+// down to whole blocks.
+func (f Format) Bytes(d time.Duration) int64 {
+	blocks := int64(d) * int64(f.SampleRate) / int64(time.Second)
+	return blocks * int64(f.BlockSize())
+}
+
+func (f Format) validate() error {
+	switch {
+	case f.Encoding == EncodingPCM && (f.BitsPerSample == 8 || f.BitsPerSample == 16 || f.BitsPerSample == 24 || f.BitsPerSample == 32):
+	case f.Encoding == EncodingFloat && (f.BitsPerSample == 32 || f.BitsPerSample == 64):
+	default:
+		return fmt.Errorf("unsupported encoding %d with %d bits per sample", f.Encoding, f.BitsPerSample)
+	}
+	if f.SampleRate <= 0 || f.Channels <= 0 {
+		return fmt.Errorf("invalid sample rate %d or channel count %d", f.SampleRate, f.Channels)
+	}
+	return nil
+}
+
+// WAV is a WAV file whose samples are read from Data.
+type WAV struct {
+	Format Format
+	// Data reads the samples.
+	Data io.Reader
+	// Size is the size of the samples in bytes, or -1 if it is unknown, such
+	// as when ffmpeg writes a WAV file to a pipe.
+	Size int64
+
+	closer io.Closer
+}
+
+// OpenWAV opens the WAV file at the given path. The WAV must be closed when it
+// is no longer needed.
+func OpenWAV(path string) (*WAV, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := DecodeWAV(bufio.NewReader(f))
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	w.closer = f
+
+	return w, nil
+}
+
+// DecodeWAV reads the header of a WAV file from r. The samples are read from
+// r afterwards through the Data reader.
+func DecodeWAV(r io.Reader) (*WAV, error) {
+	var riff [12]byte
+	if _, err := io.ReadFull(r, riff[:]); err != nil {
+		return nil, fmt.Errorf("cannot read RIFF header: %w", err)
+	}
+	if string(riff[0:4]) != "RIFF" || string(riff[8:12]) != "WAVE" {
+		return nil, errors.New("not a WAV file")
+	}
+
+	var format *Format
+	for {
+		var chunk [8]byte
+		if _, err := io.ReadFull(r, chunk[:]); err != nil {
+			return nil, fmt.Errorf("cannot read chunk header: %w", err)
+		}
+		id := string(chunk[:4])
+		size := int64(binary.LittleEndian.Uint32(chunk[4:]))
+
+		switch id {
+		case "fmt ":
+			if size < 16 || size > 1024 {
+				return nil, fmt.Errorf("invalid fmt chunk size %d", size)
+			}
+
+			b := make([]byte, size+size%2)
+			if _, err := io.ReadFull(r, b); err != nil {
+				return nil, fmt.Errorf("cannot read fmt chunk: %w", err)
+			}
+
+			f, err := parseFormat(b[:size])
+			if err != nil {
+				return nil, err
+			}
+			format = &f
+
+		case "data":
+			if format == nil {
+				return nil, errors.New("data chunk before fmt chunk")
+			}
+
+			w := &WAV{Format: *format, Data: r, Size: -1}
+			// Streamed WAV files have an unknown size.
+			if size != 0 && size != 0xFFFFFFFF {
+				w.Size = size
+				w.Data = io.LimitReader(r, size)
+			}
+			return w, nil
+
+		default:
+			if _, err := io.CopyN(io.Discard, r, size+size%2); err != nil {
+				return nil, fmt.Errorf("cannot skip %q chunk: %w", id, err)
+			}
+		}
+	}
+}
+
+func parseFormat(b []byte) (Format, error) {
+	f := Format{
+		Encoding:      Encoding(binary.LittleEndian.Uint16(b[0:])),
+		Channels:      int(binary.LittleEndian.Uint16(b[2:])),
+		SampleRate:    int(binary.LittleEndian.Uint32(b[4:])),
+		BitsPerSample: int(binary.LittleEndian.Uint16(b[14:])),
+	}
+
+	if f.Encoding == encodingExtensible {
+		if len(b) < 26 {
+			return Format{}, errors.New("fmt chunk too short for WAVE_FORMAT_EXTENSIBLE")
+		}
+		// The sub-format GUID starts with the actual encoding.
+		f.Encoding = Encoding(binary.LittleEndian.Uint16(b[24:]))
+	}
+
+	if err := f.validate(); err != nil {
+		return Format{}, err
+	}
+	return f, nil
+}
+
+// Duration returns how long the WAV file plays for, or -1 if it is unknown.
+func (w *WAV) Duration() time.Duration {
+	if w.Size < 0 {
+		return -1
+	}
+	return w.Format.Duration(w.Size)
+}
+
+// Close closes the file if the WAV was opened with OpenWAV.
+func (w *WAV) Close() error {
+	if w.closer == nil {
+		return nil
+	}
+	return w.closer.Close()
+}
+
+// EncodeWAV writes a WAV file with the given samples to w.
+func EncodeWAV(w io.Writer, format Format, data []byte) error {
+	if err := format.validate(); err != nil {
+		return err
+	}
+
+	b := make([]byte, 0, 44)
+	b = append(b, "RIFF"...)
+	b = binary.LittleEndian.AppendUint32(b, uint32(36+len(data)))
+	b = append(b, "WAVEfmt "...)
+	b = binary.LittleEndian.AppendUint32(b, 16)
+	b = binary.LittleEndian.AppendUint16(b, uint16(format.Encoding))
+	b = binary.LittleEndian.AppendUint16(b, uint16(format.Channels))
+	b = binary.LittleEndian.AppendUint32(b, uint32(format.SampleRate))
+	b = binary.LittleEndian.AppendUint32(b, uint32(format.BytesPerSecond()))
+	b = binary.LittleEndian.AppendUint16(b, uint16(format.BlockSize()))
+	b = binary.LittleEndian.AppendUint16(b, uint16(format.BitsPerSample))
+	b = append(b, "data"...)
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(data)))
+
+	if _, err := w.Write(b); err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if len(data)%2 == 1 {
+		if _, err := w.Write([]byte{0}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
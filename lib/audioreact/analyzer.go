@@ -0,0 +1,280 @@
+// Package audioreact draws patterns on the LEDs that react to sound, such as
+// spectrum bars up the tree and pulses on every beat.
+package audioreact
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// DefaultFPS is the default number of frames analyzed every second.
+	DefaultFPS = 30
+	// DefaultBands is the default number of frequency bands.
+	DefaultBands = 16
+)
+
+const (
+	minFrequency = 40    // Hz
+	maxFrequency = 12000 // Hz
+
+	// noiseFloor is the amplitude below which sound counts as silence.
+	noiseFloor = 1e-3
+	// peakHalfLife is how fast the loudest level that everything is
+	// normalized to falls, so that quiet songs still fill the tree.
+	peakHalfLife = 2 * time.Second
+	// fallHalfLife is how fast levels fall after a peak, so that the lights
+	// don't flicker.
+	fallHalfLife = 80 * time.Millisecond
+
+	// beatSensitivity is how many standard deviations above the average
+	// spectral flux of the last second an onset has to be to count as a
+	// beat.
+	beatSensitivity = 1.5
+	// minBeatFlux is the spectral flux that an onset needs at the very least.
+	minBeatFlux = 0.05
+	// minBeatInterval limits beats to 240 BPM.
+	minBeatInterval = 250 * time.Millisecond
+)
+
+// Opts are the options for an Analyzer.
+type Opts struct {
+	// FPS is the number of frames analyzed every second. It defaults to
+	// DefaultFPS.
+	FPS int
+	// Bands is the number of frequency bands. It defaults to DefaultBands.
+	Bands int
+}
+
+// Analysis is what an Analyzer found out about a frame of audio.
+type Analysis struct {
+	// Time is the time at the end of the frame.
+	Time time.Duration
+	// RMS is the root mean square of the samples in the frame.
+	RMS float64
+	// Level is the loudness in [0, 1] relative to the recent loudest level.
+	Level float64
+	// Bands are the levels of the frequency bands in [0, 1], from the lowest
+	// to the highest frequency, relative to their recent loudest levels.
+	Bands []float64
+	// Beat is true if a beat starts in the frame.
+	Beat bool
+	// Beats is the number of beats so far, including this one.
+	Beats int
+	// SinceBeat is the time since the last beat started, or since the start
+	// if there was no beat yet.
+	SinceBeat time.Duration
+}
+
+// Analyzer analyzes mono audio frame by frame.
+type Analyzer struct {
+	sampleRate int
+	fps        int
+	frames     int64
+	samples    int64
+
+	window []float64
+	buf    []float64 // the last len(window) samples
+	re, im []float64
+
+	bandEdges []int // FFT bins, len(bands)+1
+	bandMags  []float64
+	bandPeaks []float64
+	levels    []float64 // normalized band levels without the fall
+	rmsPeak   float64
+
+	flux     []float64 // ring of the spectral flux over the last second
+	fluxNext int
+	lastBeat time.Duration
+
+	peakDecay float64
+	fallDecay float64
+
+	analysis Analysis
+}
+
+// NewAnalyzer creates a new Analyzer for audio at the given sample rate.
+func NewAnalyzer(sampleRate int, opts Opts) *Analyzer {
+	if opts.FPS <= 0 {
+		opts.FPS = DefaultFPS
+	}
+	if opts.Bands <= 0 {
+		opts.Bands = DefaultBands
+	}
+
+	// About 40ms of audio gives enough resolution for the bass.
+	windowSize := nextPow2(max(sampleRate/opts.FPS+1, sampleRate/25, 64))
+
+	a := &Analyzer{
+		sampleRate: sampleRate,
+		fps:        opts.FPS,
+		window:     hann(windowSize),
+		buf:        make([]float64, windowSize),
+		re:         make([]float64, windowSize),
+		im:         make([]float64, windowSize),
+		bandEdges:  bandEdges(opts.Bands, windowSize, sampleRate),
+		bandMags:   make([]float64, opts.Bands),
+		bandPeaks:  make([]float64, opts.Bands),
+		levels:     make([]float64, opts.Bands),
+		flux:       make([]float64, 0, opts.FPS),
+		lastBeat:   -minBeatInterval,
+		peakDecay:  halfLifeDecay(peakHalfLife, opts.FPS),
+		fallDecay:  halfLifeDecay(fallHalfLife, opts.FPS),
+	}
+	a.analysis.Bands = make([]float64, opts.Bands)
+
+	return a
+}
+
+// bandEdges returns the FFT bins between logarithmically spaced bands. Every
+// band has at least one bin.
+func bandEdges(bands, windowSize, sampleRate int) []int {
+	nyquist := float64(sampleRate) / 2
+	lo := math.Min(minFrequency, nyquist/4)
+	hi := math.Min(maxFrequency, nyquist*0.9)
+
+	edges := make([]int, bands+1)
+	for i := range edges {
+		f := lo * math.Pow(hi/lo, float64(i)/float64(bands))
+		edges[i] = int(math.Round(f * float64(windowSize) / float64(sampleRate)))
+		if i > 0 {
+			edges[i] = max(edges[i], edges[i-1]+1)
+		}
+		edges[i] = min(edges[i], windowSize/2-bands+i)
+	}
+	return edges
+}
+
+// halfLifeDecay returns the factor that halves a value after halfLife when
+// applied fps times a second.
+func halfLifeDecay(halfLife time.Duration, fps int) float64 {
+	return math.Pow(0.5, 1/(halfLife.Seconds()*float64(fps)))
+}
+
+// HopSize returns the number of samples in the next frame. Frames differ by
+// a sample when the sample rate isn't a multiple of the frame rate, so that
+// they stay in sync with the audio.
+func (a *Analyzer) HopSize() int {
+	return int(a.frameEnd(a.frames+1) - a.frameEnd(a.frames))
+}
+
+// frameEnd returns the sample at the end of the given frame.
+func (a *Analyzer) frameEnd(frame int64) int64 {
+	return frame * int64(a.sampleRate) / int64(a.fps)
+}
+
+// SampleRate returns the sample rate of the audio.
+func (a *Analyzer) SampleRate() int {
+	return a.sampleRate
+}
+
+// Analyze analyzes the next frame of audio, which must have HopSize samples.
+// The returned Analysis is reused by the next call.
+func (a *Analyzer) Analyze(hop []float64) *Analysis {
+	// Slide the window along.
+	n := min(len(hop), len(a.buf))
+	copy(a.buf, a.buf[n:])
+	copy(a.buf[len(a.buf)-n:], hop[len(hop)-n:])
+
+	a.frames++
+	a.samples += int64(len(hop))
+	a.analysis.Time = time.Duration(a.samples) * time.Second / time.Duration(a.sampleRate)
+
+	a.analyzeLevel(hop)
+	a.analyzeBands()
+	a.analyzeBeat()
+
+	return &a.analysis
+}
+
+func (a *Analyzer) analyzeLevel(hop []float64) {
+	var sum float64
+	for _, s := range hop {
+		sum += s * s
+	}
+	rms := math.Sqrt(sum / float64(max(len(hop), 1)))
+
+	a.rmsPeak = math.Max(rms, a.rmsPeak*a.peakDecay)
+	a.analysis.RMS = rms
+	a.analysis.Level = math.Max(normalize(rms, a.rmsPeak), a.analysis.Level*a.fallDecay)
+}
+
+func (a *Analyzer) analyzeBands() {
+	for i, s := range a.buf {
+		a.re[i] = s * a.window[i]
+		a.im[i] = 0
+	}
+	fft(a.re, a.im)
+
+	// A sine wave of amplitude 1 peaks at a quarter of the window size with
+	// a Hann window.
+	scale := 4 / float64(len(a.buf))
+
+	for b := range a.bandMags {
+		var mag float64
+		for k := a.bandEdges[b]; k < a.bandEdges[b+1]; k++ {
+			mag = math.Max(mag, math.Hypot(a.re[k], a.im[k])*scale)
+		}
+		a.bandMags[b] = mag
+		a.bandPeaks[b] = math.Max(mag, a.bandPeaks[b]*a.peakDecay)
+	}
+}
+
+func (a *Analyzer) analyzeBeat() {
+	// The spectral flux is how much louder the bands got since the last
+	// frame, which jumps at the start of every note and drum hit.
+	var flux float64
+	for b, mag := range a.bandMags {
+		level := normalize(mag, a.bandPeaks[b])
+		flux += math.Max(level-a.levels[b], 0)
+
+		a.levels[b] = level
+		a.analysis.Bands[b] = math.Max(level, a.analysis.Bands[b]*a.fallDecay)
+	}
+	flux /= float64(len(a.bandMags))
+
+	mean, stddev := meanStddev(a.flux)
+	threshold := math.Max(mean+beatSensitivity*stddev, minBeatFlux)
+
+	now := a.analysis.Time
+	a.analysis.Beat = flux > threshold && now-a.lastBeat >= minBeatInterval
+	if a.analysis.Beat {
+		a.analysis.Beats++
+		a.lastBeat = now
+	}
+	a.analysis.SinceBeat = now - max(a.lastBeat, 0)
+
+	if len(a.flux) < cap(a.flux) {
+		a.flux = append(a.flux, flux)
+	} else {
+		a.flux[a.fluxNext] = flux
+		a.fluxNext = (a.fluxNext + 1) % len(a.flux)
+	}
+}
+
+// normalize returns v relative to peak in [0, 1]. Anything below the noise
+// floor is silent.
+func normalize(v, peak float64) float64 {
+	if peak < noiseFloor {
+		return 0
+	}
+	return math.Min(v/peak, 1)
+}
+
+func meanStddev(values []float64) (mean, stddev float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+
+	for _, v := range values {
+		stddev += (v - mean) * (v - mean)
+	}
+	stddev = math.Sqrt(stddev / float64(len(values)))
+
+	return mean, stddev
+}
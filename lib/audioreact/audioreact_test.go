@@ -0,0 +1,208 @@
+package audioreact
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"dev.acmcsuf.com/christmas/lib/animation"
+	"dev.acmcsuf.com/christmas/lib/audio"
+	"dev.acmcsuf.com/christmas/lib/leddraw"
+	"dev.acmcsuf.com/christmas/lib/patterns"
+	"github.com/alecthomas/assert/v2"
+)
+
+const testSampleRate = 8000
+
+// wavFixture encodes the samples as a 16-bit WAV file and opens it again, so
+// that the tests go through the same path as recorded audio.
+func wavFixture(t *testing.T, samples []float64) *audio.SampleReader {
+	t.Helper()
+
+	data := make([]byte, 2*len(samples))
+	for i, s := range samples {
+		binary.LittleEndian.PutUint16(data[2*i:], uint16(int16(s*math.MaxInt16)))
+	}
+
+	var buf bytes.Buffer
+	format := audio.Format{
+		Encoding:      audio.EncodingPCM,
+		SampleRate:    testSampleRate,
+		Channels:      1,
+		BitsPerSample: 16,
+	}
+	assert.NoError(t, audio.EncodeWAV(&buf, format, data))
+
+	w, err := audio.DecodeWAV(&buf)
+	assert.NoError(t, err)
+	return audio.NewSampleReader(w.Format, w.Data)
+}
+
+func sine(freq, amplitude float64, d time.Duration) []float64 {
+	samples := make([]float64, int(d.Seconds()*testSampleRate))
+	for i := range samples {
+		samples[i] = amplitude * math.Sin(2*math.Pi*freq*float64(i)/testSampleRate)
+	}
+	return samples
+}
+
+// clicks returns bursts of noise every interval over a quiet hum.
+func clicks(interval, d time.Duration) []float64 {
+	rng := rand.New(rand.NewSource(1))
+
+	samples := sine(220, 0.01, d)
+	burst := int(0.02 * testSampleRate)
+	step := int(interval.Seconds() * testSampleRate)
+	for start := 0; start < len(samples); start += step {
+		for i := start; i < min(start+burst, len(samples)); i++ {
+			samples[i] += 0.8 * (rng.Float64()*2 - 1)
+		}
+	}
+	return samples
+}
+
+// analyze analyzes all of the audio and returns a copy of every analysis.
+func analyze(t *testing.T, r *audio.SampleReader) []Analysis {
+	t.Helper()
+
+	a := NewAnalyzer(testSampleRate, Opts{})
+	var analyses []Analysis
+	for {
+		hop := make([]float64, a.HopSize())
+		if _, err := r.ReadFull(hop); err != nil {
+			return analyses
+		}
+		analysis := *a.Analyze(hop)
+		analysis.Bands = append([]float64(nil), analysis.Bands...)
+		analyses = append(analyses, analysis)
+	}
+}
+
+func loudestBand(bands []float64) int {
+	loudest := 0
+	for b, level := range bands {
+		if level > bands[loudest] {
+			loudest = b
+		}
+	}
+	return loudest
+}
+
+func TestAnalyzer(t *testing.T) {
+	t.Run("bands", func(t *testing.T) {
+		low := analyze(t, wavFixture(t, sine(100, 0.5, time.Second)))
+		high := analyze(t, wavFixture(t, sine(2000, 0.5, time.Second)))
+
+		lowBand := loudestBand(low[len(low)-1].Bands)
+		highBand := loudestBand(high[len(high)-1].Bands)
+		assert.True(t, lowBand < 4, "100 Hz is in band %d", lowBand)
+		assert.True(t, highBand > 8, "2 kHz is in band %d", highBand)
+	})
+
+	t.Run("rms", func(t *testing.T) {
+		analyses := analyze(t, wavFixture(t, sine(440, 0.5, time.Second)))
+		last := analyses[len(analyses)-1]
+		assert.True(t, math.Abs(last.RMS-0.5/math.Sqrt2) < 0.01, "RMS is %f", last.RMS)
+		assert.True(t, last.Level > 0.95, "level is %f", last.Level)
+		assert.Equal(t, time.Second, last.Time)
+	})
+
+	t.Run("silence", func(t *testing.T) {
+		analyses := analyze(t, wavFixture(t, make([]float64, testSampleRate)))
+		for _, a := range analyses {
+			assert.Equal(t, 0.0, a.Level)
+			assert.Equal(t, 0, a.Beats)
+		}
+	})
+
+	t.Run("beats", func(t *testing.T) {
+		// 120 BPM.
+		analyses := analyze(t, wavFixture(t, clicks(500*time.Millisecond, 4*time.Second)))
+
+		var beats []time.Duration
+		for _, a := range analyses {
+			if a.Beat {
+				beats = append(beats, a.Time)
+			}
+		}
+
+		assert.Equal(t, 8, len(beats), "beats at %v", beats)
+		for i, beat := range beats {
+			want := time.Duration(i) * 500 * time.Millisecond
+			assert.True(t, beat >= want && beat <= want+50*time.Millisecond, "beat %d at %v", i, beat)
+		}
+	})
+}
+
+func testLayout() *patterns.Layout {
+	// A 4x4 grid of LEDs, from the bottom left.
+	var pts []image.Point
+	for y := 3; y >= 0; y-- {
+		for x := 0; x < 4; x++ {
+			pts = append(pts, image.Pt(x, y))
+		}
+	}
+	return patterns.NewLayout(pts)
+}
+
+func TestEffects(t *testing.T) {
+	layout := testLayout()
+	leds := make(leddraw.LEDStrip, layout.Len())
+
+	t.Run("spectrum", func(t *testing.T) {
+		a := &Analysis{Bands: []float64{1, 0, 0.5, 0}}
+		Spectrum.Render(leds, layout, a)
+
+		// The first column is full, the bottom half of the third is lit and
+		// the others are dark.
+		for i, led := range leds {
+			column, row := i%4, i/4
+			lit := led != (leddraw.LEDStrip{{}})[0]
+			assert.Equal(t, column == 0 || (column == 2 && row <= 1), lit, "LED %d is %v", i, led)
+		}
+	})
+
+	t.Run("pulse", func(t *testing.T) {
+		Pulse.Render(leds, layout, &Analysis{Beats: 1, Level: 1})
+		bright := leds[0]
+
+		Pulse.Render(leds, layout, &Analysis{Beats: 1, Level: 1, SinceBeat: pulseHalfLife})
+		faded := leds[0]
+
+		assert.True(t, int(faded.R)+int(faded.G)+int(faded.B) < int(bright.R)+int(bright.G)+int(bright.B))
+	})
+
+	t.Run("meter", func(t *testing.T) {
+		Meter.Render(leds, layout, &Analysis{Level: 0})
+		for _, led := range leds {
+			assert.Zero(t, led)
+		}
+	})
+
+	t.Run("find", func(t *testing.T) {
+		e, ok := FindEffect("spectrum")
+		assert.True(t, ok)
+		assert.Equal(t, "Spectrum", e.Name)
+	})
+}
+
+func TestEngineRender(t *testing.T) {
+	layout := testLayout()
+	e := NewEngine(testSampleRate, layout, Meter, Opts{FPS: 30})
+
+	frames, err := e.Render(wavFixture(t, sine(440, 0.5, 2*time.Second)))
+	assert.NoError(t, err)
+	assert.Equal(t, 60, len(frames))
+
+	var total animation.Milliseconds
+	for _, frame := range frames {
+		assert.Equal(t, layout.Len(), len(frame.Image))
+		assert.True(t, frame.DurationMs == 33 || frame.DurationMs == 34, "frame lasts %dms", frame.DurationMs)
+		total += frame.DurationMs
+	}
+	assert.Equal(t, animation.Milliseconds(2000), total)
+}
@@ -0,0 +1,110 @@
+package audioreact
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"dev.acmcsuf.com/christmas/lib/leddraw"
+	"dev.acmcsuf.com/christmas/lib/patterns"
+	"dev.acmcsuf.com/christmas/lib/xcolor"
+)
+
+// Effect is a pattern that reacts to sound.
+type Effect struct {
+	// Name is the human-readable name of the effect.
+	Name string
+	// Render draws the effect for the analyzed frame onto the LEDs.
+	Render func(leds leddraw.LEDStrip, layout *patterns.Layout, a *Analysis)
+}
+
+// Effects is the list of all effects.
+var Effects = []Effect{
+	Spectrum,
+	Pulse,
+	Meter,
+}
+
+// FindEffect returns the effect with the given name. Names are
+// case-insensitive.
+func FindEffect(name string) (Effect, bool) {
+	for _, e := range Effects {
+		if strings.EqualFold(e.Name, name) {
+			return e, true
+		}
+	}
+	return Effect{}, false
+}
+
+// edgeSoftness is how much of the tree's height the top of a bar fades over.
+const edgeSoftness = 0.1
+
+// barBrightness returns how bright an LED at the given height is in a bar
+// that reaches level. A bar at level 1 lights the top of the tree fully.
+func barBrightness(height, level float64) float64 {
+	top := level * (1 + edgeSoftness)
+	return math.Max(0, math.Min(1, (top-height)/edgeSoftness))
+}
+
+// Spectrum draws a bar for each frequency band up the tree, from the bass on
+// the left to the treble on the right, in the colors of the rainbow.
+var Spectrum = Effect{
+	Name: "Spectrum",
+	Render: func(leds leddraw.LEDStrip, layout *patterns.Layout, a *Analysis) {
+		bands := len(a.Bands)
+		for i := range leds {
+			b := min(int(layout.X(i)*float64(bands)), bands-1)
+			hue := float64(b) / float64(bands) * 0.8
+			leds[i] = xcolor.HSV(hue, 1, barBrightness(layout.Height(i), a.Bands[b]))
+		}
+	},
+}
+
+// pulseHalfLife is how fast a pulse fades after a beat.
+const pulseHalfLife = 150 * time.Millisecond
+
+// Pulse flashes the whole tree on every beat in a new color, fading out until
+// the next beat.
+var Pulse = Effect{
+	Name: "Pulse",
+	Render: func(leds leddraw.LEDStrip, layout *patterns.Layout, a *Analysis) {
+		if a.Beats == 0 {
+			leds.Clear()
+			return
+		}
+
+		fade := math.Pow(0.5, a.SinceBeat.Seconds()/pulseHalfLife.Seconds())
+		// Step the hue by the golden ratio so that consecutive beats look
+		// different.
+		hue := float64(a.Beats) * 0.618
+		c := xcolor.HSV(hue, 1, fade*(0.4+0.6*a.Level))
+		for i := range leds {
+			leds[i] = c
+		}
+	},
+}
+
+var (
+	meterLow  = xcolor.RGB{G: 0xFF}
+	meterMid  = xcolor.RGB{R: 0xFF, G: 0xFF}
+	meterHigh = xcolor.RGB{R: 0xFF}
+)
+
+// Meter fills the tree from the bottom with the loudness, like the level
+// meter on a mixer: green, then yellow, then red at the top.
+var Meter = Effect{
+	Name: "Meter",
+	Render: func(leds leddraw.LEDStrip, layout *patterns.Layout, a *Analysis) {
+		for i := range leds {
+			h := layout.Height(i)
+
+			var c xcolor.RGB
+			if h < 0.5 {
+				c = xcolor.Lerp(meterLow, meterMid, h*2)
+			} else {
+				c = xcolor.Lerp(meterMid, meterHigh, (h-0.5)*2)
+			}
+			leds[i] = c.Scale(barBrightness(h, a.Level))
+		}
+	},
+}
@@ -0,0 +1,113 @@
+package audioreact
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"dev.acmcsuf.com/christmas/lib/animation"
+	"dev.acmcsuf.com/christmas/lib/audio"
+	"dev.acmcsuf.com/christmas/lib/leddraw"
+	"dev.acmcsuf.com/christmas/lib/patterns"
+)
+
+// Engine analyzes audio and renders an effect for every frame.
+type Engine struct {
+	analyzer *Analyzer
+	layout   *patterns.Layout
+	effect   Effect
+	samples  []float64
+	leds     leddraw.LEDStrip
+}
+
+// NewEngine creates a new Engine that renders the effect onto the LEDs of the
+// layout for audio at the given sample rate.
+func NewEngine(sampleRate int, layout *patterns.Layout, effect Effect, opts Opts) *Engine {
+	analyzer := NewAnalyzer(sampleRate, opts)
+	return &Engine{
+		analyzer: analyzer,
+		layout:   layout,
+		effect:   effect,
+		samples:  make([]float64, analyzer.HopSize()+1),
+		leds:     make(leddraw.LEDStrip, layout.Len()),
+	}
+}
+
+// next reads and renders the next frame. It returns io.EOF once the audio
+// ends. A partial frame at the end is padded with silence.
+func (e *Engine) next(r *audio.SampleReader) (*Analysis, error) {
+	samples := e.samples[:e.analyzer.HopSize()]
+
+	n, err := r.ReadFull(samples)
+	if err != nil {
+		if !errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, err
+		}
+		clear(samples[n:])
+	}
+
+	a := e.analyzer.Analyze(samples)
+	e.effect.Render(e.leds, e.layout, a)
+	return a, nil
+}
+
+// Run reads audio from r and calls fn with the LEDs for every frame until the
+// audio ends or the context is canceled. Each frame is shown once the clock
+// reaches the end of the audio that it was analyzed from, so audio that is
+// read faster than it plays, such as a file, isn't rushed. When the audio is
+// captured live, frames are shown as soon as they are read.
+//
+// The strip given to fn is reused for every frame and must not be retained.
+func (e *Engine) Run(ctx context.Context, r *audio.SampleReader, clock animation.Clock, fn func(leddraw.LEDStrip) error) error {
+	timer := clock.NewTimer()
+	defer timer.Stop()
+
+	start := clock.Now()
+	for {
+		a, err := e.next(r)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+
+		timer.Reset(start + a.Time)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C():
+		}
+
+		if err := fn(e.leds); err != nil {
+			return err
+		}
+	}
+}
+
+// Render reads all of the audio from r and renders every frame. The frame
+// durations add up to the duration of the audio, so the frames can be played
+// along with it.
+func (e *Engine) Render(r *audio.SampleReader) ([]animation.Frame[leddraw.LEDStrip], error) {
+	var frames []animation.Frame[leddraw.LEDStrip]
+	var end animation.Milliseconds
+
+	for {
+		a, err := e.next(r)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return frames, nil
+			}
+			return nil, err
+		}
+
+		// Round the end of each frame rather than each duration so that
+		// the rounding errors don't add up.
+		frameEnd := animation.DurationToMs(a.Time)
+		frames = append(frames, animation.Frame[leddraw.LEDStrip]{
+			Image:      append(leddraw.LEDStrip(nil), e.leds...),
+			DurationMs: frameEnd - end,
+		})
+		end = frameEnd
+	}
+}
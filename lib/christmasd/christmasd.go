@@ -0,0 +1,308 @@
+// Package christmasd implements the daemon that controls the LEDs. It accepts
+// commands over HTTP and renders them onto the LEDs.
+package christmasd
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"log"
+	"sync"
+	"time"
+
+	"dev.acmcsuf.com/christmas/lib/animation"
+	"dev.acmcsuf.com/christmas/lib/leddraw"
+	"dev.acmcsuf.com/christmas/lib/ledout"
+	"dev.acmcsuf.com/christmas/lib/schedule"
+	"dev.acmcsuf.com/christmas/lib/xcolor"
+	"dev.acmcsuf.com/christmas/lib/xdraw"
+	"golang.org/x/sync/errgroup"
+)
+
+// Opts is the options for a Server.
+type Opts struct {
+	// LEDPoints is the position of each LED.
+	LEDPoints []image.Point
+	// CanvasOpts is the options used to create the LED canvas that images are
+	// rendered onto.
+	CanvasOpts leddraw.LEDCanvasOpts
+	// Output is where the LED colors are written to.
+	Output ledout.Writer
+	// SchedulePath is the file that the schedule is loaded from and saved to
+	// when it is changed. If it is empty, the schedule starts out disabled
+	// and changes to it are not saved.
+	SchedulePath string
+	// LEDWatts is the power drawn by one LED at full white, which is used to
+	// estimate the power drawn by the LEDs. It defaults to DefaultLEDWatts.
+	LEDWatts float64
+}
+
+// Server is the christmasd server. It owns the LED canvas and the animation
+// player, and it writes the resulting colors to the output. Layers can be
+// drawn on top of the animation, each with its own animation player.
+type Server struct {
+	opts     Opts
+	animated *leddraw.LEDCanvasAnimated
+	showCh   chan leddraw.LEDStrip
+
+	canvas   *leddraw.LEDCanvas
+	canvases map[canvasKey]*leddraw.LEDCanvas // with non-default options
+	canvasMu sync.Mutex
+
+	leds   leddraw.LEDStrip // currently shown, without the layers
+	ledsMu sync.Mutex
+
+	compositor      *leddraw.Compositor
+	layers          map[string]*serverLayer
+	layersMu        sync.Mutex
+	newLayerCh      chan *serverLayer
+	layerFrameCh    chan layerFrame
+	layersChangedCh chan struct{}
+
+	preview   previewHub
+	scheduler *schedule.Scheduler
+	metrics   *serverMetrics
+}
+
+// NewServer creates a new Server. Run must be called for the server to
+// actually write anything.
+func NewServer(opts Opts) (*Server, error) {
+	if opts.Output == nil {
+		return nil, fmt.Errorf("no output given")
+	}
+	if opts.LEDWatts == 0 {
+		opts.LEDWatts = DefaultLEDWatts
+	}
+
+	// NewLEDCanvas translates the points in place, so give each canvas its own
+	// copy.
+	canvas, err := leddraw.NewLEDCanvas(clonePoints(opts.LEDPoints), opts.CanvasOpts)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create LED canvas: %w", err)
+	}
+
+	animated, err := leddraw.NewLEDCanvasAnimated(clonePoints(opts.LEDPoints), opts.CanvasOpts)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create animated LED canvas: %w", err)
+	}
+
+	s := &Server{
+		opts:     opts,
+		animated: animated,
+		showCh:   make(chan leddraw.LEDStrip),
+		canvas:   canvas,
+		canvases: make(map[canvasKey]*leddraw.LEDCanvas),
+		leds:     make(leddraw.LEDStrip, len(opts.LEDPoints)),
+		metrics:  newServerMetrics(),
+
+		compositor:      leddraw.NewCompositor(),
+		layers:          make(map[string]*serverLayer),
+		newLayerCh:      make(chan *serverLayer),
+		layerFrameCh:    make(chan layerFrame),
+		layersChangedCh: make(chan struct{}, 1),
+	}
+
+	s.scheduler = schedule.NewScheduler(s, schedule.SystemClock())
+	if opts.SchedulePath != "" {
+		cfg, err := schedule.LoadConfig(opts.SchedulePath)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load schedule: %w", err)
+		}
+		if err := s.scheduler.SetConfig(cfg); err != nil {
+			return nil, fmt.Errorf("cannot load schedule: %w", err)
+		}
+	}
+
+	return s, nil
+}
+
+func clonePoints(pts []image.Point) []image.Point {
+	return append([]image.Point(nil), pts...)
+}
+
+// Run runs the server until the context is canceled. It plays the animation,
+// the layers and the schedule, and writes every frame to the output.
+func (s *Server) Run(ctx context.Context) error {
+	errg, ctx := errgroup.WithContext(ctx)
+	errg.Go(func() error { return s.animated.Run(ctx) })
+	errg.Go(func() error { return s.writeLoop(ctx) })
+	errg.Go(func() error { return s.runLayers(ctx) })
+	errg.Go(func() error { return s.scheduler.Run(ctx) })
+	return errg.Wait()
+}
+
+// writeLoop writes the animation or the shown colors, with the layers on top,
+// whenever either of them changes.
+func (s *Server) writeLoop(ctx context.Context) error {
+	base := make(leddraw.LEDStrip, s.LEDCount())
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case frame := <-s.animated.C:
+			base = frame.Image
+			s.ledsMu.Lock()
+			copy(s.leds, base)
+			s.ledsMu.Unlock()
+		case base = <-s.showCh:
+		case frame := <-s.layerFrameCh:
+			if !s.setLayerLEDs(frame) {
+				continue
+			}
+		case <-s.layersChangedCh:
+		}
+
+		strip := s.compositor.Composite(base)
+
+		start := time.Now()
+		err := s.opts.Output.Write(strip)
+		s.metrics.observeWrite(time.Since(start), estimatePower(strip, s.opts.LEDWatts), err)
+		if err != nil {
+			log.Println("failed to write LEDs:", err)
+		}
+
+		s.preview.publish(strip)
+	}
+}
+
+// LEDCount returns the number of LEDs.
+func (s *Server) LEDCount() int {
+	return len(s.opts.LEDPoints)
+}
+
+// CanvasBounds returns the bounds of the image canvas. Images given to the
+// server are scaled to these bounds.
+func (s *Server) CanvasBounds() image.Rectangle {
+	return s.canvas.CanvasBounds()
+}
+
+// LEDBounds returns the boundary box of the LEDs.
+func (s *Server) LEDBounds() image.Rectangle {
+	return s.canvas.LEDBounds()
+}
+
+// LEDs returns a copy of the colors that are currently shown, without the
+// layers on top of them.
+func (s *Server) LEDs() leddraw.LEDStrip {
+	s.ledsMu.Lock()
+	defer s.ledsMu.Unlock()
+	return append(leddraw.LEDStrip(nil), s.leds...)
+}
+
+// SetLEDs shows the given colors. The colors stay until they are replaced or
+// until the next animation frame is played. The colors are copied, so strip
+// can be reused once SetLEDs returns.
+func (s *Server) SetLEDs(ctx context.Context, strip leddraw.LEDStrip) error {
+	if len(strip) != s.LEDCount() {
+		return fmt.Errorf("expected %d LEDs, got %d", s.LEDCount(), len(strip))
+	}
+
+	strip = append(leddraw.LEDStrip(nil), strip...)
+
+	s.ledsMu.Lock()
+	copy(s.leds, strip)
+	s.ledsMu.Unlock()
+
+	return s.show(ctx, strip)
+}
+
+// SetLED sets the color of the LED at index i, leaving the other LEDs as they
+// currently are.
+func (s *Server) SetLED(ctx context.Context, i int, color xcolor.RGB) error {
+	if i < 0 || i >= s.LEDCount() {
+		return fmt.Errorf("LED index %d out of range [0, %d)", i, s.LEDCount())
+	}
+
+	s.ledsMu.Lock()
+	s.leds[i] = color
+	strip := append(leddraw.LEDStrip(nil), s.leds...)
+	s.ledsMu.Unlock()
+
+	return s.show(ctx, strip)
+}
+
+// SetImage renders the given image onto the LEDs and shows it. The image is
+// scaled to the canvas bounds.
+func (s *Server) SetImage(ctx context.Context, img image.Image, opts RenderOpts) error {
+	strip, err := s.renderImage(img, opts)
+	if err != nil {
+		return err
+	}
+	return s.SetLEDs(ctx, strip)
+}
+
+func (s *Server) renderImage(img image.Image, opts RenderOpts) (leddraw.LEDStrip, error) {
+	s.canvasMu.Lock()
+	defer s.canvasMu.Unlock()
+
+	canvas, err := s.canvasFor(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	scaled := xdraw.ScaleImage(img, s.CanvasBounds(), opts.ScaleMode)
+	start := time.Now()
+	if err := canvas.Render(scaled); err != nil {
+		return nil, fmt.Errorf("cannot render image: %w", err)
+	}
+	s.metrics.renderTime.Observe(time.Since(start))
+
+	return append(leddraw.LEDStrip(nil), canvas.LEDs()...), nil
+}
+
+// AddFrames renders the given frames onto the LEDs and adds them to the
+// animation player.
+func (s *Server) AddFrames(ctx context.Context, frames []animation.Frame[image.Image], opts RenderOpts) error {
+	rendered, err := s.RenderFrames(frames, opts)
+	if err != nil {
+		return err
+	}
+	return s.animated.AddLEDFrames(ctx, rendered)
+}
+
+// ReplaceFrames renders the given frames onto the LEDs and replaces the
+// animation with them, changing to them with the given transition.
+func (s *Server) ReplaceFrames(ctx context.Context, frames []animation.Frame[image.Image], opts RenderOpts, transition leddraw.Transition) error {
+	rendered, err := s.RenderFrames(frames, opts)
+	if err != nil {
+		return err
+	}
+	return s.animated.ReplaceLEDFrames(ctx, rendered, transition)
+}
+
+// PlayLEDSource replaces the animation with the frames of src, which are
+// already rendered onto the LEDs, and plays them until src ends or the context
+// is canceled. Frames can't be added or replaced until it returns.
+func (s *Server) PlayLEDSource(ctx context.Context, src animation.FrameSource[leddraw.LEDStrip], transition leddraw.Transition) error {
+	return s.animated.PlayLEDSource(ctx, src, transition)
+}
+
+// ClearFrames removes all frames from the animation player, stopping the
+// animation. The LEDs keep showing the last frame until something else is
+// shown.
+func (s *Server) ClearFrames(ctx context.Context) error {
+	return s.animated.ClearFrames(ctx)
+}
+
+// RenderFrames renders the given frames onto the LEDs the same way AddFrames
+// would, but returns them instead of playing them.
+func (s *Server) RenderFrames(frames []animation.Frame[image.Image], opts RenderOpts) ([]animation.Frame[leddraw.LEDStrip], error) {
+	rendered := make([]animation.Frame[leddraw.LEDStrip], len(frames))
+	for i, frame := range frames {
+		strip, err := s.renderImage(frame.Image, opts)
+		if err != nil {
+			return nil, fmt.Errorf("frame %d: %w", i, err)
+		}
+		rendered[i] = animation.WithImage(frame, strip)
+	}
+	return rendered, nil
+}
+
+func (s *Server) show(ctx context.Context, strip leddraw.LEDStrip) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case s.showCh <- strip:
+		return nil
+	}
+}
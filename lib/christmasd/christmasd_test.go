@@ -0,0 +1,715 @@
+package christmasd_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"dev.acmcsuf.com/christmas/lib/animation"
+	"dev.acmcsuf.com/christmas/lib/christmasd"
+	"dev.acmcsuf.com/christmas/lib/christmasd/client"
+	"dev.acmcsuf.com/christmas/lib/leddraw"
+	"dev.acmcsuf.com/christmas/lib/ledseq"
+	"dev.acmcsuf.com/christmas/lib/schedule"
+	"dev.acmcsuf.com/christmas/lib/xcolor"
+	"dev.acmcsuf.com/christmas/lib/xdraw"
+	"dev.acmcsuf.com/christmas/proto/christmasdpb"
+	"github.com/alecthomas/assert/v2"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"nhooyr.io/websocket"
+)
+
+var testLEDPoints = []image.Point{
+	{0, 0}, {10, 0}, {0, 10}, {10, 10},
+}
+
+type fakeWriter struct {
+	ch chan leddraw.LEDStrip
+}
+
+func (w fakeWriter) Write(leds leddraw.LEDStrip) error {
+	w.ch <- append(leddraw.LEDStrip(nil), leds...)
+	return nil
+}
+
+type testServer struct {
+	*christmasd.Server
+	url    string
+	client *client.Client
+	writes <-chan leddraw.LEDStrip
+}
+
+func startServer(t *testing.T) (context.Context, *testServer) {
+	return startServerWithOpts(t, christmasd.Opts{})
+}
+
+// startServerWithOpts starts a server with the test LEDs and the given
+// options.
+func startServerWithOpts(t *testing.T, opts christmasd.Opts) (context.Context, *testServer) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+
+	writes := make(chan leddraw.LEDStrip, 10)
+
+	opts.LEDPoints = testLEDPoints
+	opts.CanvasOpts = leddraw.LEDCanvasOpts{PPI: 10, Intensity: leddraw.NewStepIntensity(2)}
+	opts.Output = fakeWriter{writes}
+
+	s, err := christmasd.NewServer(opts)
+	assert.NoError(t, err)
+
+	go s.Run(ctx)
+
+	h := httptest.NewServer(s.Handler())
+	t.Cleanup(h.Close)
+
+	return ctx, &testServer{s, h.URL, client.New(h.URL), writes}
+}
+
+func (s *testServer) expectWrite(t *testing.T, expect leddraw.LEDStrip) {
+	t.Helper()
+	select {
+	case got := <-s.writes:
+		assert.Equal(t, expect, got)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for LED write")
+	}
+}
+
+var (
+	black = xcolor.RGB{}
+	red   = xcolor.RGB{R: 0xFF}
+	green = xcolor.RGB{G: 0xFF}
+)
+
+func TestServer(t *testing.T) {
+	t.Run("info", func(t *testing.T) {
+		ctx, s := startServer(t)
+
+		info, err := s.client.Info(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, uint32(len(testLEDPoints)), info.LedCount)
+		assert.Equal(t, s.CanvasBounds(), christmasd.RectFromProto(info.CanvasBounds))
+		assert.Equal(t, s.LEDBounds(), christmasd.RectFromProto(info.LedBounds))
+	})
+
+	t.Run("set_leds", func(t *testing.T) {
+		ctx, s := startServer(t)
+
+		strip := leddraw.LEDStrip{red, green, black, red}
+		assert.NoError(t, s.client.SetLEDs(ctx, strip))
+		s.expectWrite(t, strip)
+
+		leds, err := s.client.LEDs(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, strip, leds)
+	})
+
+	t.Run("set_leds_wrong_count", func(t *testing.T) {
+		ctx, s := startServer(t)
+
+		err := s.client.SetLEDs(ctx, leddraw.LEDStrip{red})
+		assertStatusCode(t, http.StatusBadRequest, err)
+	})
+
+	t.Run("set_led", func(t *testing.T) {
+		ctx, s := startServer(t)
+
+		assert.NoError(t, s.client.SetLED(ctx, 1, green))
+		s.expectWrite(t, leddraw.LEDStrip{black, green, black, black})
+
+		assert.NoError(t, s.client.SetLED(ctx, 3, red))
+		s.expectWrite(t, leddraw.LEDStrip{black, green, black, red})
+	})
+
+	t.Run("set_led_out_of_range", func(t *testing.T) {
+		ctx, s := startServer(t)
+
+		err := s.client.SetLED(ctx, 4, green)
+		assertStatusCode(t, http.StatusNotFound, err)
+	})
+
+	t.Run("set_led_json", func(t *testing.T) {
+		_, s := startServer(t)
+
+		resp, err := http.DefaultClient.Do(mustRequest(t,
+			"PUT", s.url+"/api/v1/led", `{"index": 2, "color": 65280}`))
+		assert.NoError(t, err)
+		resp.Body.Close()
+
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+		s.expectWrite(t, leddraw.LEDStrip{black, black, green, black})
+	})
+
+	t.Run("image", func(t *testing.T) {
+		ctx, s := startServer(t)
+
+		assert.NoError(t, s.client.SetImage(ctx, uniformImage(color.RGBA{0xFF, 0, 0, 0xFF}), xdraw.ScaleFill))
+		s.expectWrite(t, leddraw.LEDStrip{red, red, red, red})
+	})
+
+	t.Run("frames", func(t *testing.T) {
+		ctx, s := startServer(t)
+
+		assert.NoError(t, s.client.AddFrames(ctx, []animation.Frame[image.Image]{
+			{Image: uniformImage(color.RGBA{0xFF, 0, 0, 0xFF}), DurationMs: 50},
+			{Image: uniformImage(color.RGBA{0, 0xFF, 0, 0xFF}), DurationMs: 50},
+		}, xdraw.ScaleFill))
+		s.expectWrite(t, leddraw.LEDStrip{red, red, red, red})
+		s.expectWrite(t, leddraw.LEDStrip{green, green, green, green})
+	})
+
+	t.Run("replace_frames", func(t *testing.T) {
+		ctx, s := startServer(t)
+
+		assert.NoError(t, s.client.AddFrames(ctx, []animation.Frame[image.Image]{
+			{Image: uniformImage(color.RGBA{0xFF, 0, 0, 0xFF}), DurationMs: 50},
+			{Image: uniformImage(color.RGBA{0xFF, 0, 0, 0xFF}), DurationMs: 50, JumpBackAmount: 1},
+		}, xdraw.ScaleFill))
+		s.expectWrite(t, leddraw.LEDStrip{red, red, red, red})
+
+		assert.NoError(t, s.client.ReplaceFrames(ctx, []animation.Frame[image.Image]{
+			{Image: uniformImage(color.RGBA{0, 0xFF, 0, 0xFF}), DurationMs: 50},
+		}, xdraw.ScaleFill, leddraw.Transition{
+			Kind:     leddraw.TransitionCrossfade,
+			Duration: 200 * time.Millisecond,
+		}))
+
+		// The LEDs fade from red to green rather than switching at once.
+		var blended int
+		for {
+			got := <-s.writes
+			if got[0] == green {
+				break
+			}
+			if got[0] != red {
+				blended++
+				assert.True(t, got[0].R > 0 && got[0].G > 0, "not a blend: %v", got[0])
+			}
+		}
+		assert.True(t, blended > 0, "no blended frames")
+	})
+
+	t.Run("clear_frames", func(t *testing.T) {
+		ctx, s := startServer(t)
+
+		assert.NoError(t, s.client.AddFrames(ctx, []animation.Frame[image.Image]{
+			{Image: uniformImage(color.RGBA{0xFF, 0, 0, 0xFF}), DurationMs: 50},
+			{Image: uniformImage(color.RGBA{0, 0xFF, 0, 0xFF}), DurationMs: 50, JumpBackAmount: 1},
+		}, xdraw.ScaleFill))
+		s.expectWrite(t, leddraw.LEDStrip{red, red, red, red})
+
+		assert.NoError(t, s.client.ClearFrames(ctx))
+		assert.NoError(t, s.client.SetLEDs(ctx, leddraw.LEDStrip{black, black, black, black}))
+		for {
+			// The frame that was already playing may still be written.
+			got := <-s.writes
+			if got[0] == black {
+				break
+			}
+		}
+
+		select {
+		case got := <-s.writes:
+			t.Fatalf("animation still playing after clear: %v", got)
+		case <-time.After(200 * time.Millisecond):
+		}
+	})
+
+	t.Run("render_gif", func(t *testing.T) {
+		_, s := startServer(t)
+
+		g := &gif.GIF{
+			Image: []*image.Paletted{
+				uniformPaletted(color.RGBA{0xFF, 0, 0, 0xFF}),
+				uniformPaletted(color.RGBA{0, 0xFF, 0, 0xFF}),
+			},
+			Delay: []int{5, 10},
+		}
+		var buf bytes.Buffer
+		assert.NoError(t, gif.EncodeAll(&buf, g))
+
+		resp, err := http.DefaultClient.Do(mustRequest(t, "POST", s.url+"/api/v1/render",
+			fmt.Sprintf(`{"frames": [{"image": %q}], "canvasOptions": {"averaging": "AVERAGING_NEAREST"}}`,
+				base64.StdEncoding.EncodeToString(buf.Bytes()))))
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		b, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+
+		var rendered christmasdpb.RenderResponse
+		assert.NoError(t, protojson.Unmarshal(b, &rendered))
+		assert.Equal(t, 2, len(rendered.Frames))
+		assert.Equal(t, leddraw.LEDStrip{red, red, red, red},
+			christmasd.LEDStripFromProto(rendered.Frames[0].Leds))
+		assert.Equal(t, uint32(50), rendered.Frames[0].DurationMs)
+		assert.Equal(t, uint32(100), rendered.Frames[1].DurationMs)
+		// The GIF loops forever.
+		assert.Equal(t, int32(1), rendered.Frames[1].JumpBackAmount)
+	})
+
+	t.Run("render_bad_canvas_options", func(t *testing.T) {
+		_, s := startServer(t)
+
+		resp, err := http.DefaultClient.Do(mustRequest(t, "POST", s.url+"/api/v1/render",
+			`{"canvasOptions": {"intensity": "INTENSITY_CUBIC"}, "frames": [{}]}`))
+		assert.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("web_ui", func(t *testing.T) {
+		_, s := startServer(t)
+
+		resp, err := http.Get(s.url + "/")
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		b, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Contains(t, string(b), "app.js")
+	})
+
+	t.Run("layers", func(t *testing.T) {
+		ctx, s := startServer(t)
+
+		assert.NoError(t, s.client.SetImage(ctx, uniformImage(color.RGBA{0xFF, 0, 0, 0xFF}), xdraw.ScaleFill))
+		s.expectWrite(t, leddraw.LEDStrip{red, red, red, red})
+
+		// A green sparkle added on top of the red image makes it yellow.
+		sparkle := leddraw.Layer{Name: "sparkle", Blend: leddraw.BlendAdd, Opacity: 1}
+		assert.NoError(t, s.client.PlayLayer(ctx, sparkle, []animation.Frame[image.Image]{
+			{Image: uniformImage(color.RGBA{0, 0xFF, 0, 0xFF}), DurationMs: 50},
+		}, xdraw.ScaleFill))
+		yellow := xcolor.RGB{R: 0xFF, G: 0xFF}
+		s.expectWrite(t, leddraw.LEDStrip{yellow, yellow, yellow, yellow})
+
+		layers, err := s.client.Layers(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, []leddraw.Layer{sparkle}, layers)
+
+		// The LEDs are composited again when the layer changes.
+		sparkle.Opacity = 0
+		assert.NoError(t, s.client.SetLayer(ctx, sparkle))
+		s.expectWrite(t, leddraw.LEDStrip{red, red, red, red})
+
+		sparkle.Opacity = 2
+		assertStatusCode(t, http.StatusBadRequest, s.client.SetLayer(ctx, sparkle))
+
+		assert.NoError(t, s.client.RemoveLayer(ctx, "sparkle"))
+		s.expectWrite(t, leddraw.LEDStrip{red, red, red, red})
+		assertStatusCode(t, http.StatusNotFound, s.client.RemoveLayer(ctx, "sparkle"))
+
+		layers, err = s.client.Layers(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, len(layers))
+	})
+
+	t.Run("metrics", func(t *testing.T) {
+		ctx, s := startServerWithOpts(t, christmasd.Opts{LEDWatts: 3})
+
+		// The first write is measured by the time the second one is made.
+		for i := 0; i < 2; i++ {
+			assert.NoError(t, s.client.SetImage(ctx, uniformImage(color.RGBA{0xFF, 0, 0, 0xFF}), xdraw.ScaleFill))
+			s.expectWrite(t, leddraw.LEDStrip{red, red, red, red})
+		}
+
+		resp, err := http.Get(s.url + "/metrics")
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, christmasd.ContentTypeMetrics, resp.Header.Get("Content-Type"))
+
+		metrics := parseMetrics(t, resp.Body)
+		assert.True(t, metrics["christmasd_uptime_seconds"] > 0)
+		assert.True(t, metrics["christmasd_frames_written_total"] >= 1)
+		assert.True(t, metrics["christmasd_led_write_duration_seconds_count"] >= 1)
+		assert.Equal(t, 2.0, metrics["christmasd_render_duration_seconds_count"])
+		assert.Equal(t, 2.0, metrics[`christmasd_render_duration_seconds_bucket{le="+Inf"}`])
+		assert.Equal(t, 0.0, metrics["christmasd_queued_frames"])
+		// Each red LED draws a third of 3W.
+		assert.Equal(t, 4.0, metrics["christmasd_power_watts"])
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		ctx, s := startServer(t)
+
+		stream, err := s.client.Stream(ctx)
+		assert.NoError(t, err)
+		defer stream.Close()
+
+		strip := leddraw.LEDStrip{red, green, black, red}
+		assert.NoError(t, stream.SendLEDs(ctx, strip))
+		s.expectWrite(t, strip)
+
+		assert.NoError(t, stream.SendImage(ctx, uniformImage(color.RGBA{0, 0xFF, 0, 0xFF}), xdraw.ScaleFill))
+		s.expectWrite(t, leddraw.LEDStrip{green, green, green, green})
+
+		assert.NoError(t, stream.SendLEDs(ctx, leddraw.LEDStrip{red}))
+
+		stats := waitStreamStats(t, stream, allHandled(3))
+		assert.Equal(t, uint64(2), stats.Shown)
+		assert.Equal(t, uint64(1), stats.Failed)
+		assert.Equal(t, "expected 4 LEDs, got 1", stats.Error)
+	})
+
+	t.Run("stream_drops_stale_frames", func(t *testing.T) {
+		ctx, s := startServer(t)
+
+		stream, err := s.client.Stream(ctx)
+		assert.NoError(t, err)
+		defer stream.Close()
+
+		// Nothing reads the writes until all frames are sent, so the output
+		// falls behind and frames must be dropped.
+		const n = 30
+		for i := 0; i < n; i++ {
+			c := xcolor.RGB{R: uint8(i)}
+			assert.NoError(t, stream.SendLEDs(ctx, leddraw.LEDStrip{c, c, c, c}))
+		}
+
+		// Only drain the writes once christmasd has received every frame.
+		waitStreamStats(t, stream, func(stats *christmasdpb.StreamStats) bool {
+			return stats.Received == n
+		})
+
+		last := xcolor.RGB{R: n - 1}
+		for {
+			got := <-s.writes
+			if got[0] == last {
+				break
+			}
+		}
+
+		stats := waitStreamStats(t, stream, allHandled(n))
+		assert.True(t, stats.Dropped > 0, "expected dropped frames, got %v", stats)
+		assert.Equal(t, uint64(n), stats.Shown+stats.Dropped)
+	})
+}
+
+func TestPreview(t *testing.T) {
+	t.Run("websocket", func(t *testing.T) {
+		ctx, s := startServer(t)
+
+		conn, _, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(s.url, "http")+"/api/v1/preview", nil)
+		assert.NoError(t, err)
+		defer conn.CloseNow()
+
+		readMessage := func() *christmasdpb.PreviewMessage {
+			t.Helper()
+			typ, b, err := conn.Read(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, websocket.MessageBinary, typ)
+
+			var msg christmasdpb.PreviewMessage
+			assert.NoError(t, proto.Unmarshal(b, &msg))
+			return &msg
+		}
+
+		layout := readMessage().GetLayout()
+		assert.Equal(t, len(testLEDPoints), len(layout.LedPoints))
+		assert.Equal(t, int32(10), layout.LedPoints[1].X)
+
+		// The currently shown colors come first.
+		leds := readMessage().GetLeds()
+		assert.Equal(t, leddraw.LEDStrip{black, black, black, black}, christmasd.LEDStripFromProto(leds))
+
+		strip := leddraw.LEDStrip{red, green, black, red}
+		assert.NoError(t, s.SetLEDs(ctx, strip))
+		s.expectWrite(t, strip)
+
+		leds = readMessage().GetLeds()
+		assert.Equal(t, strip, christmasd.LEDStripFromProto(leds))
+	})
+
+	t.Run("sse", func(t *testing.T) {
+		ctx, s := startServer(t)
+
+		req, err := http.NewRequestWithContext(ctx, "GET", s.url+"/api/v1/preview?fps=60", nil)
+		assert.NoError(t, err)
+		req.Header.Set("Accept", "text/event-stream")
+
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+		scanner := bufio.NewScanner(resp.Body)
+		readEvent := func() (event, data string) {
+			t.Helper()
+			for scanner.Scan() {
+				line := scanner.Text()
+				switch {
+				case strings.HasPrefix(line, "event: "):
+					event = strings.TrimPrefix(line, "event: ")
+				case strings.HasPrefix(line, "data: "):
+					data = strings.TrimPrefix(line, "data: ")
+				case line == "":
+					return event, data
+				}
+			}
+			t.Fatal("stream ended:", scanner.Err())
+			return
+		}
+
+		event, _ := readEvent()
+		assert.Equal(t, "layout", event)
+
+		event, data := readEvent()
+		assert.Equal(t, "leds", event)
+
+		var leds christmasdpb.LEDStrip
+		assert.NoError(t, protojson.Unmarshal([]byte(data), &leds))
+		assert.Equal(t, len(testLEDPoints), len(leds.Colors))
+	})
+
+	t.Run("bad_fps", func(t *testing.T) {
+		_, s := startServer(t)
+
+		resp, err := http.Get(s.url + "/api/v1/preview?fps=0")
+		assert.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestSchedule(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schedule.json")
+	ctx, s := startServerWithOpts(t, christmasd.Opts{SchedulePath: path})
+
+	status, err := s.client.ScheduleStatus(ctx)
+	assert.NoError(t, err)
+	assert.False(t, status.Enabled)
+
+	cfg := schedule.Config{
+		Enabled: true,
+		Shows: []schedule.Show{
+			{Name: "Red", Pattern: "Solid", Colors: []string{"#ff0000"}, Duration: schedule.Duration(time.Hour)},
+		},
+		Rules: []schedule.Rule{
+			{Brightness: 0.5},
+		},
+	}
+	assert.NoError(t, s.client.SetSchedule(ctx, cfg))
+
+	dimRed := xcolor.RGB{R: 0xFF}.Scale(0.5)
+	s.expectWrite(t, leddraw.LEDStrip{dimRed, dimRed, dimRed, dimRed})
+
+	status, err = s.client.ScheduleStatus(ctx)
+	assert.NoError(t, err)
+	assert.True(t, status.Enabled)
+	assert.Equal(t, "Red", status.Show)
+	assert.Equal(t, 0.5, status.Brightness)
+
+	got, err := s.client.Schedule(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, cfg, got)
+
+	saved, err := schedule.LoadConfig(path)
+	assert.NoError(t, err)
+	assert.Equal(t, cfg, saved)
+
+	cfg.Shows[0].Pattern = "Nope"
+	err = s.client.SetSchedule(ctx, cfg)
+	assertStatusCode(t, http.StatusBadRequest, err)
+
+	resp, err := http.DefaultClient.Do(mustRequest(t, http.MethodPut, s.url+"/api/v1/schedule",
+		`{"rules":[{"from":"25:00"}]}`))
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	red := xcolor.RGB{R: 0xFF}
+	blue := xcolor.RGB{B: 0xFF}
+
+	seqPath := writeSequence(t, []animation.Frame[leddraw.LEDStrip]{
+		{Image: leddraw.LEDStrip{red, red, red, red}, DurationMs: 10},
+		{Image: leddraw.LEDStrip{blue, blue, blue, blue}, DurationMs: 10, JumpBackAmount: 1},
+	})
+
+	assert.NoError(t, s.client.SetSchedule(ctx, schedule.Config{
+		Enabled: true,
+		Shows: []schedule.Show{
+			{Name: "Sequence", Sequence: seqPath, Duration: schedule.Duration(time.Hour)},
+		},
+	}))
+
+	// The sequence loops, so it keeps alternating.
+	for i := 0; i < 2; i++ {
+		s.expectWrite(t, leddraw.LEDStrip{red, red, red, red})
+		s.expectWrite(t, leddraw.LEDStrip{blue, blue, blue, blue})
+	}
+}
+
+func TestScheduleUploads(t *testing.T) {
+	ctx, s := startServerWithOpts(t, christmasd.Opts{
+		SchedulePath: filepath.Join(t.TempDir(), "schedule.json"),
+	})
+
+	blue := xcolor.RGB{B: 0xFF}
+	seqPath := writeSequence(t, []animation.Frame[leddraw.LEDStrip]{
+		{Image: leddraw.LEDStrip{red, red, red, red}, DurationMs: 10},
+		{Image: leddraw.LEDStrip{blue, blue, blue, blue}, DurationMs: 10, JumpBackAmount: 1},
+	})
+
+	// The upload has more frames than the player holds, so it is still adding
+	// them when the show starts.
+	frames := make([]animation.Frame[image.Image], 150)
+	for i := range frames {
+		frames[i] = animation.Frame[image.Image]{Image: uniformImage(color.RGBA{0, 0xFF, 0, 0xFF}), DurationMs: 5}
+	}
+	uploaded := make(chan error, 1)
+	go func() { uploaded <- s.client.AddFrames(ctx, frames, xdraw.ScaleFill) }()
+	s.expectWrite(t, leddraw.LEDStrip{green, green, green, green})
+
+	assert.NoError(t, s.client.SetSchedule(ctx, schedule.Config{
+		Enabled: true,
+		Shows: []schedule.Show{
+			{Name: "Sequence", Sequence: seqPath, Duration: schedule.Duration(time.Hour)},
+		},
+	}))
+
+	// The show waits for the upload instead of failing.
+	waitWrite(t, s, leddraw.LEDStrip{red, red, red, red})
+	assert.NoError(t, <-uploaded)
+
+	// Frames can't be uploaded while the show plays, and trying to doesn't
+	// stop the show.
+	assert.Error(t, s.client.AddFrames(ctx, frames[:1], xdraw.ScaleFill))
+	for len(s.writes) > 0 {
+		<-s.writes
+	}
+	waitWrite(t, s, leddraw.LEDStrip{red, red, red, red})
+	waitWrite(t, s, leddraw.LEDStrip{blue, blue, blue, blue})
+}
+
+// waitWrite skips the LED writes until expect is written.
+func waitWrite(t *testing.T, s *testServer, expect leddraw.LEDStrip) {
+	t.Helper()
+	timeout := time.After(3 * time.Second)
+	for {
+		select {
+		case got := <-s.writes:
+			if slices.Equal(got, expect) {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("timed out waiting for LED write %v", expect)
+		}
+	}
+}
+
+// writeSequence writes frames to a sequence file and returns its path.
+func writeSequence(t *testing.T, frames []animation.Frame[leddraw.LEDStrip]) string {
+	var seq bytes.Buffer
+	assert.NoError(t, ledseq.Write(&seq, frames, ledseq.EncodingDelta))
+
+	path := filepath.Join(t.TempDir(), "show.lseq")
+	assert.NoError(t, os.WriteFile(path, seq.Bytes(), 0644))
+	return path
+}
+
+func waitStreamStats(t *testing.T, stream *client.Stream, ok func(*christmasdpb.StreamStats) bool) *christmasdpb.StreamStats {
+	t.Helper()
+
+	timeout := time.After(3 * time.Second)
+	for {
+		stats := stream.Stats()
+		if ok(stats) {
+			return stats
+		}
+		select {
+		case <-timeout:
+			t.Fatalf("timed out waiting for stream stats, last got %v", stats)
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
+
+// allHandled returns true once the given number of frames were received and
+// every one of them was either shown, dropped or failed.
+func allHandled(received uint64) func(*christmasdpb.StreamStats) bool {
+	return func(stats *christmasdpb.StreamStats) bool {
+		return stats.Received == received &&
+			stats.Shown+stats.Dropped+stats.Failed == received
+	}
+}
+
+// parseMetrics parses metrics in the Prometheus text format into their values
+// by name and labels.
+func parseMetrics(t *testing.T, r io.Reader) map[string]float64 {
+	metrics := make(map[string]float64)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		i := strings.LastIndexByte(line, ' ')
+		assert.True(t, i > 0, "invalid metric line %q", line)
+
+		v, err := strconv.ParseFloat(line[i+1:], 64)
+		assert.NoError(t, err, "invalid metric line %q", line)
+		metrics[line[:i]] = v
+	}
+	assert.NoError(t, scanner.Err())
+
+	return metrics
+}
+
+func assertStatusCode(t *testing.T, code int, err error) {
+	t.Helper()
+
+	var cerr *client.Error
+	if !errors.As(err, &cerr) {
+		t.Fatalf("expected *client.Error, got %v", err)
+	}
+	assert.Equal(t, code, cerr.StatusCode)
+}
+
+func mustRequest(t *testing.T, method, url, body string) *http.Request {
+	t.Helper()
+
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", christmasd.ContentTypeJSON)
+	return req
+}
+
+func uniformPaletted(c color.RGBA) *image.Paletted {
+	return image.NewPaletted(image.Rect(0, 0, 4, 4), color.Palette{c})
+}
+
+func uniformImage(c color.RGBA) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i+0] = c.R
+		img.Pix[i+1] = c.G
+		img.Pix[i+2] = c.B
+		img.Pix[i+3] = c.A
+	}
+	return img
+}
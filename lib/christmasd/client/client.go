@@ -0,0 +1,261 @@
+// Package client implements a client for the christmasd control API.
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"dev.acmcsuf.com/christmas/lib/animation"
+	"dev.acmcsuf.com/christmas/lib/christmasd"
+	"dev.acmcsuf.com/christmas/lib/leddraw"
+	"dev.acmcsuf.com/christmas/lib/schedule"
+	"dev.acmcsuf.com/christmas/lib/xcolor"
+	"dev.acmcsuf.com/christmas/lib/xdraw"
+	"dev.acmcsuf.com/christmas/proto/christmasdpb"
+	"google.golang.org/protobuf/proto"
+)
+
+// Error is returned when christmasd responds with an error.
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	return fmt.Sprintf("christmasd: %d %s: %s",
+		e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// Client is a client for christmasd. It talks binary Protobuf.
+type Client struct {
+	// HTTPClient is the HTTP client used to make requests. If nil,
+	// http.DefaultClient is used.
+	HTTPClient *http.Client
+
+	baseURL string
+}
+
+// New creates a new client for the christmasd at the given base URL, e.g.
+// "http://raspberrypi.local:8080".
+func New(baseURL string) *Client {
+	return &Client{baseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+// Info returns information about the LED canvas.
+func (c *Client) Info(ctx context.Context) (*christmasdpb.CanvasInfo, error) {
+	var info christmasdpb.CanvasInfo
+	if err := c.do(ctx, http.MethodGet, "/api/v1/info", nil, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// LEDs returns the colors that are currently shown.
+func (c *Client) LEDs(ctx context.Context) (leddraw.LEDStrip, error) {
+	var strip christmasdpb.LEDStrip
+	if err := c.do(ctx, http.MethodGet, "/api/v1/leds", nil, &strip); err != nil {
+		return nil, err
+	}
+	return christmasd.LEDStripFromProto(&strip), nil
+}
+
+// SetLEDs sets the color of every LED.
+func (c *Client) SetLEDs(ctx context.Context, strip leddraw.LEDStrip) error {
+	return c.do(ctx, http.MethodPut, "/api/v1/leds", christmasd.LEDStripToProto(strip), nil)
+}
+
+// SetLED sets the color of the LED at index i.
+func (c *Client) SetLED(ctx context.Context, i int, color xcolor.RGB) error {
+	return c.do(ctx, http.MethodPut, "/api/v1/led", &christmasdpb.SetLEDRequest{
+		Index: uint32(i),
+		Color: color.ToUint(),
+	}, nil)
+}
+
+// SetImage renders the given image onto the LEDs.
+func (c *Client) SetImage(ctx context.Context, img image.Image, mode xdraw.ScaleMode) error {
+	b, err := encodePNG(img)
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, http.MethodPost, "/api/v1/image", &christmasdpb.ImageRequest{
+		Image:     b,
+		ScaleMode: christmasd.ScaleModeToProto(mode),
+	}, nil)
+}
+
+// AddFrames adds the given frames to the animation.
+func (c *Client) AddFrames(ctx context.Context, frames []animation.Frame[image.Image], mode xdraw.ScaleMode) error {
+	req, err := framesRequest(frames, mode)
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, http.MethodPost, "/api/v1/frames", req, nil)
+}
+
+// ReplaceFrames replaces the animation with the given frames, changing to them
+// with the given transition.
+func (c *Client) ReplaceFrames(ctx context.Context, frames []animation.Frame[image.Image], mode xdraw.ScaleMode, transition leddraw.Transition) error {
+	req, err := framesRequest(frames, mode)
+	if err != nil {
+		return err
+	}
+	req.Transition = christmasd.TransitionToProto(transition)
+	return c.do(ctx, http.MethodPost, "/api/v1/frames", req, nil)
+}
+
+func framesRequest(frames []animation.Frame[image.Image], mode xdraw.ScaleMode) (*christmasdpb.FramesRequest, error) {
+	req := &christmasdpb.FramesRequest{
+		Frames:    make([]*christmasdpb.Frame, len(frames)),
+		ScaleMode: christmasd.ScaleModeToProto(mode),
+	}
+
+	for i, frame := range frames {
+		b, err := encodePNG(frame.Image)
+		if err != nil {
+			return nil, fmt.Errorf("frame %d: %w", i, err)
+		}
+		req.Frames[i] = &christmasdpb.Frame{
+			Image:          b,
+			JumpBackAmount: frame.JumpBackAmount,
+			DurationMs:     uint32(frame.DurationMs),
+			Marker:         frame.Marker,
+			JumpTo:         frame.JumpTo,
+			Repeat:         frame.Repeat,
+		}
+	}
+
+	return req, nil
+}
+
+// ClearFrames removes all frames from the animation, stopping it.
+func (c *Client) ClearFrames(ctx context.Context) error {
+	return c.do(ctx, http.MethodDelete, "/api/v1/frames", nil, nil)
+}
+
+// Layers returns the layers on top of the animation, from the bottom to the
+// top.
+func (c *Client) Layers(ctx context.Context) ([]leddraw.Layer, error) {
+	var pb christmasdpb.Layers
+	if err := c.do(ctx, http.MethodGet, "/api/v1/layers", nil, &pb); err != nil {
+		return nil, err
+	}
+	return christmasd.LayersFromProto(&pb), nil
+}
+
+// SetLayer adds a layer on top of the animation, or changes the layer with the
+// same name.
+func (c *Client) SetLayer(ctx context.Context, layer leddraw.Layer) error {
+	req := &christmasdpb.LayerRequest{Layer: christmasd.LayerToProto(layer)}
+	return c.do(ctx, http.MethodPut, "/api/v1/layers", req, nil)
+}
+
+// PlayLayer adds or changes a layer like SetLayer and replaces its animation
+// with the given frames.
+func (c *Client) PlayLayer(ctx context.Context, layer leddraw.Layer, frames []animation.Frame[image.Image], mode xdraw.ScaleMode) error {
+	framesReq, err := framesRequest(frames, mode)
+	if err != nil {
+		return err
+	}
+	req := &christmasdpb.LayerRequest{
+		Layer:  christmasd.LayerToProto(layer),
+		Frames: framesReq,
+	}
+	return c.do(ctx, http.MethodPut, "/api/v1/layers", req, nil)
+}
+
+// RemoveLayer removes the layer with the given name.
+func (c *Client) RemoveLayer(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodDelete, "/api/v1/layers?name="+url.QueryEscape(name), nil, nil)
+}
+
+// Schedule returns the schedule of the tree.
+func (c *Client) Schedule(ctx context.Context) (schedule.Config, error) {
+	var pb christmasdpb.Schedule
+	if err := c.do(ctx, http.MethodGet, "/api/v1/schedule", nil, &pb); err != nil {
+		return schedule.Config{}, err
+	}
+	return christmasd.ScheduleFromProto(&pb)
+}
+
+// SetSchedule replaces the schedule of the tree.
+func (c *Client) SetSchedule(ctx context.Context, cfg schedule.Config) error {
+	return c.do(ctx, http.MethodPut, "/api/v1/schedule", christmasd.ScheduleToProto(cfg), nil)
+}
+
+// ScheduleStatus returns what the schedule is currently doing.
+func (c *Client) ScheduleStatus(ctx context.Context) (schedule.Status, error) {
+	var pb christmasdpb.ScheduleStatus
+	if err := c.do(ctx, http.MethodGet, "/api/v1/schedule/status", nil, &pb); err != nil {
+		return schedule.Status{}, err
+	}
+	return christmasd.ScheduleStatusFromProto(&pb), nil
+}
+
+func (c *Client) do(ctx context.Context, method, path string, req, resp proto.Message) error {
+	var body io.Reader
+	if req != nil {
+		b, err := proto.Marshal(req)
+		if err != nil {
+			return fmt.Errorf("cannot encode request: %w", err)
+		}
+		body = bytes.NewReader(b)
+	}
+
+	r, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	r.Header.Set("Accept", christmasd.ContentTypeProto)
+	if req != nil {
+		r.Header.Set("Content-Type", christmasd.ContentTypeProto)
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	res, err := httpClient.Do(r)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("cannot read response: %w", err)
+	}
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		var perr christmasdpb.Error
+		if err := proto.Unmarshal(b, &perr); err != nil || perr.Message == "" {
+			perr.Message = strings.TrimSpace(string(b))
+		}
+		return &Error{StatusCode: res.StatusCode, Message: perr.Message}
+	}
+
+	if resp != nil {
+		if err := proto.Unmarshal(b, resp); err != nil {
+			return fmt.Errorf("cannot decode response: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func encodePNG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("cannot encode PNG: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,116 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"strings"
+	"sync"
+
+	"dev.acmcsuf.com/christmas/lib/christmasd"
+	"dev.acmcsuf.com/christmas/lib/leddraw"
+	"dev.acmcsuf.com/christmas/lib/xdraw"
+	"dev.acmcsuf.com/christmas/proto/christmasdpb"
+	"google.golang.org/protobuf/proto"
+	"nhooyr.io/websocket"
+)
+
+// Stream is a WebSocket stream of frames to christmasd. Frames are shown as
+// soon as possible, and frames that christmasd can't keep up with are
+// dropped instead of queued.
+type Stream struct {
+	conn   *websocket.Conn
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	stats   *christmasdpb.StreamStats
+	statsMu sync.Mutex
+}
+
+// Stream opens a stream of frames. The stream must be closed when done.
+func (c *Client) Stream(ctx context.Context) (*Stream, error) {
+	// Dial wants a WebSocket URL, but it is the same as the HTTP one.
+	u := c.baseURL + "/api/v1/stream"
+	u = "ws" + strings.TrimPrefix(u, "http")
+
+	conn, _, err := websocket.Dial(ctx, u, &websocket.DialOptions{
+		HTTPClient: c.HTTPClient,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot open stream: %w", err)
+	}
+
+	readCtx, cancel := context.WithCancel(context.Background())
+	s := &Stream{
+		conn:   conn,
+		cancel: cancel,
+		done:   make(chan struct{}),
+		stats:  &christmasdpb.StreamStats{},
+	}
+	go s.readStats(readCtx)
+
+	return s, nil
+}
+
+func (s *Stream) readStats(ctx context.Context) {
+	defer close(s.done)
+
+	for {
+		_, b, err := s.conn.Read(ctx)
+		if err != nil {
+			return
+		}
+
+		var stats christmasdpb.StreamStats
+		if err := proto.Unmarshal(b, &stats); err != nil {
+			continue
+		}
+
+		s.statsMu.Lock()
+		s.stats = &stats
+		s.statsMu.Unlock()
+	}
+}
+
+// Stats returns the latest stats reported by christmasd. They are updated
+// about once a second.
+func (s *Stream) Stats() *christmasdpb.StreamStats {
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+	return s.stats
+}
+
+// SendLEDs sends the color of every LED.
+func (s *Stream) SendLEDs(ctx context.Context, strip leddraw.LEDStrip) error {
+	return s.send(ctx, &christmasdpb.StreamFrame{
+		Frame: &christmasdpb.StreamFrame_Rgb{Rgb: christmasd.LEDStripToRGB(strip)},
+	})
+}
+
+// SendImage sends an image to be rendered onto the LEDs.
+func (s *Stream) SendImage(ctx context.Context, img image.Image, mode xdraw.ScaleMode) error {
+	b, err := encodePNG(img)
+	if err != nil {
+		return err
+	}
+	return s.send(ctx, &christmasdpb.StreamFrame{
+		Frame:     &christmasdpb.StreamFrame_Image{Image: b},
+		ScaleMode: christmasd.ScaleModeToProto(mode),
+	})
+}
+
+func (s *Stream) send(ctx context.Context, frame *christmasdpb.StreamFrame) error {
+	b, err := proto.Marshal(frame)
+	if err != nil {
+		return fmt.Errorf("cannot encode frame: %w", err)
+	}
+	return s.conn.Write(ctx, websocket.MessageBinary, b)
+}
+
+// Close closes the stream.
+func (s *Stream) Close() error {
+	err := s.conn.Close(websocket.StatusNormalClosure, "")
+	s.cancel()
+	<-s.done
+	return err
+}
@@ -0,0 +1,388 @@
+package christmasd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+
+	"dev.acmcsuf.com/christmas/lib/animation"
+	"dev.acmcsuf.com/christmas/lib/xcolor"
+	"dev.acmcsuf.com/christmas/proto/christmasdpb"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	_ "golang.org/x/image/bmp"
+)
+
+// maxBodySize is the maximum size of a request body. Frame uploads contain
+// many images, so this is fairly generous.
+const maxBodySize = 64 << 20 // 64 MiB
+
+const (
+	// ContentTypeProto is the content type of binary Protobuf messages.
+	ContentTypeProto = "application/x-protobuf"
+	// ContentTypeJSON is the content type of Protobuf JSON messages.
+	ContentTypeJSON = "application/json"
+)
+
+// Handler returns an HTTP handler that serves the control API of the server.
+// See proto/christmasd.proto for the routes. /metrics serves metrics in the
+// Prometheus text format, and everything else serves the web control panel.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/", webUIHandler())
+	mux.HandleFunc("/api/v1/info", s.handleInfo)
+	mux.HandleFunc("/api/v1/leds", s.handleLEDs)
+	mux.HandleFunc("/api/v1/led", s.handleLED)
+	mux.HandleFunc("/api/v1/image", s.handleImage)
+	mux.HandleFunc("/api/v1/frames", s.handleFrames)
+	mux.HandleFunc("/api/v1/render", s.handleRender)
+	mux.HandleFunc("/api/v1/stream", s.handleStream)
+	mux.HandleFunc("/api/v1/preview", s.handlePreview)
+	mux.HandleFunc("/api/v1/schedule", s.handleSchedule)
+	mux.HandleFunc("/api/v1/schedule/status", s.handleScheduleStatus)
+	mux.HandleFunc("/api/v1/layers", s.handleLayers)
+	mux.HandleFunc("/metrics", s.handleMetrics)
+	return mux
+}
+
+// ListenAndServe runs the server and serves its control API over HTTP on the
+// given address. It returns when the context is canceled or when either fails.
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	httpServer := &http.Server{
+		Addr:    addr,
+		Handler: s.Handler(),
+	}
+
+	errg, ctx := errgroup.WithContext(ctx)
+	errg.Go(func() error {
+		return s.Run(ctx)
+	})
+	errg.Go(func() error {
+		log.Println("listening on", addr)
+		if err := httpServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("failed to serve HTTP: %w", err)
+		}
+		return nil
+	})
+	errg.Go(func() error {
+		<-ctx.Done()
+		return httpServer.Shutdown(context.Background())
+	})
+	return errg.Wait()
+}
+
+func (s *Server) handleInfo(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+
+	writeMessage(w, r, http.StatusOK, &christmasdpb.CanvasInfo{
+		LedCount:     uint32(s.LEDCount()),
+		CanvasBounds: RectToProto(s.CanvasBounds()),
+		LedBounds:    RectToProto(s.LEDBounds()),
+		LedPoints:    PointsToProto(s.opts.LEDPoints),
+	})
+}
+
+func (s *Server) handleLEDs(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet, http.MethodPut) {
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		writeMessage(w, r, http.StatusOK, LEDStripToProto(s.LEDs()))
+		return
+	}
+
+	var req christmasdpb.LEDStrip
+	if !readMessage(w, r, &req) {
+		return
+	}
+
+	if len(req.Colors) != s.LEDCount() {
+		writeError(w, r, http.StatusBadRequest,
+			fmt.Errorf("expected %d LEDs, got %d", s.LEDCount(), len(req.Colors)))
+		return
+	}
+
+	if err := s.SetLEDs(r.Context(), LEDStripFromProto(&req)); err != nil {
+		writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleLED(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodPut) {
+		return
+	}
+
+	var req christmasdpb.SetLEDRequest
+	if !readMessage(w, r, &req) {
+		return
+	}
+
+	if req.Index >= uint32(s.LEDCount()) {
+		writeError(w, r, http.StatusNotFound,
+			fmt.Errorf("LED index %d out of range [0, %d)", req.Index, s.LEDCount()))
+		return
+	}
+
+	if err := s.SetLED(r.Context(), int(req.Index), xcolor.RGBFromUint(req.Color)); err != nil {
+		writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleImage(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodPost) {
+		return
+	}
+
+	var req christmasdpb.ImageRequest
+	if !readMessage(w, r, &req) {
+		return
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(req.Image))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, fmt.Errorf("cannot decode image: %w", err))
+		return
+	}
+
+	opts := RenderOptsFromProto(req.ScaleMode, req.CanvasOptions)
+	if err := s.SetImage(r.Context(), img, opts); err != nil {
+		writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleFrames(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodPost, http.MethodDelete) {
+		return
+	}
+
+	if r.Method == http.MethodDelete {
+		if err := s.ClearFrames(r.Context()); err != nil {
+			writeError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	var req christmasdpb.FramesRequest
+	if !readMessage(w, r, &req) {
+		return
+	}
+
+	frames, err := FramesFromProto(&req)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	opts := RenderOptsFromProto(req.ScaleMode, req.CanvasOptions)
+	if req.Transition != nil {
+		err = s.ReplaceFrames(r.Context(), frames, opts, TransitionFromProto(req.Transition))
+	} else {
+		err = s.AddFrames(r.Context(), frames, opts)
+	}
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleLayers(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet, http.MethodPut, http.MethodDelete) {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeMessage(w, r, http.StatusOK, LayersToProto(s.Layers()))
+		return
+	case http.MethodDelete:
+		if err := s.RemoveLayer(r.URL.Query().Get("name")); err != nil {
+			writeError(w, r, http.StatusNotFound, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	var req christmasdpb.LayerRequest
+	if !readMessage(w, r, &req) {
+		return
+	}
+
+	if req.Layer == nil {
+		writeError(w, r, http.StatusBadRequest, fmt.Errorf("no layer given"))
+		return
+	}
+
+	layer := LayerFromProto(req.Layer)
+	if err := layer.Validate(); err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	var frames []animation.Frame[image.Image]
+	if req.Frames != nil {
+		var err error
+		frames, err = FramesFromProto(req.Frames)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	if err := s.SetLayer(r.Context(), layer); err != nil {
+		writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	if req.Frames != nil {
+		opts := RenderOptsFromProto(req.Frames.ScaleMode, req.Frames.CanvasOptions)
+		if err := s.PlayLayer(r.Context(), layer.Name, frames, opts); err != nil {
+			code := http.StatusInternalServerError
+			if errors.Is(err, ErrLayerNotFound) {
+				code = http.StatusNotFound
+			}
+			writeError(w, r, code, err)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleRender(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodPost) {
+		return
+	}
+
+	var req christmasdpb.FramesRequest
+	if !readMessage(w, r, &req) {
+		return
+	}
+
+	frames, err := FramesFromProto(&req)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	rendered, err := s.RenderFrames(frames, RenderOptsFromProto(req.ScaleMode, req.CanvasOptions))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	writeMessage(w, r, http.StatusOK, FramesToRenderResponse(rendered))
+}
+
+func allowMethods(w http.ResponseWriter, r *http.Request, methods ...string) bool {
+	for _, method := range methods {
+		if r.Method == method {
+			return true
+		}
+	}
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	writeError(w, r, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+	return false
+}
+
+// readMessage reads the request body into msg. The body is decoded according
+// to the Content-Type header, defaulting to JSON.
+func readMessage(w http.ResponseWriter, r *http.Request, msg proto.Message) bool {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, fmt.Errorf("cannot read body: %w", err))
+		return false
+	}
+
+	switch mediaType(r.Header.Get("Content-Type")) {
+	case ContentTypeProto:
+		err = proto.Unmarshal(body, msg)
+	case ContentTypeJSON, "":
+		err = protojson.Unmarshal(body, msg)
+	default:
+		writeError(w, r, http.StatusUnsupportedMediaType,
+			fmt.Errorf("unsupported content type %q", r.Header.Get("Content-Type")))
+		return false
+	}
+
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, fmt.Errorf("cannot decode body: %w", err))
+		return false
+	}
+
+	return true
+}
+
+// writeMessage writes msg as the response. Binary Protobuf is written if the
+// client accepts it or if it sent its request as binary Protobuf, otherwise
+// JSON is written.
+func writeMessage(w http.ResponseWriter, r *http.Request, code int, msg proto.Message) {
+	var b []byte
+	var err error
+
+	if wantsProto(r) {
+		w.Header().Set("Content-Type", ContentTypeProto)
+		b, err = proto.Marshal(msg)
+	} else {
+		w.Header().Set("Content-Type", ContentTypeJSON)
+		b, err = protojson.Marshal(msg)
+	}
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(code)
+	w.Write(b)
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, code int, err error) {
+	writeMessage(w, r, code, &christmasdpb.Error{Message: err.Error()})
+}
+
+func wantsProto(r *http.Request) bool {
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		switch mediaType(accept) {
+		case ContentTypeProto:
+			return true
+		case ContentTypeJSON:
+			return false
+		}
+	}
+	return mediaType(r.Header.Get("Content-Type")) == ContentTypeProto
+}
+
+func mediaType(contentType string) string {
+	t, _, _ := mime.ParseMediaType(contentType)
+	return t
+}
@@ -0,0 +1,203 @@
+package christmasd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+
+	"dev.acmcsuf.com/christmas/lib/animation"
+	"dev.acmcsuf.com/christmas/lib/leddraw"
+	"golang.org/x/sync/errgroup"
+)
+
+// ErrLayerNotFound is returned for layers that don't exist.
+var ErrLayerNotFound = errors.New("layer not found")
+
+// serverLayer is a layer of the compositor with its own animation player.
+type serverLayer struct {
+	name    string
+	player  *animation.Player[leddraw.LEDStrip]
+	removed chan struct{} // closed once the layer is removed
+}
+
+// layerFrame is a frame played by the player of a layer.
+type layerFrame struct {
+	layer *serverLayer
+	leds  leddraw.LEDStrip
+}
+
+// context returns a context that is also canceled once the layer is removed.
+func (l *serverLayer) context(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-l.removed:
+			cancel()
+		}
+	}()
+	return ctx, cancel
+}
+
+// Layers returns the layers that are drawn on top of the animation, from the
+// bottom to the top.
+func (s *Server) Layers() []leddraw.Layer {
+	return s.compositor.Layers()
+}
+
+// SetLayer adds a layer on top of the animation, or changes the layer with
+// the same name. A new layer shows nothing until frames are played on it with
+// PlayLayer.
+func (s *Server) SetLayer(ctx context.Context, layer leddraw.Layer) error {
+	s.layersMu.Lock()
+	if err := s.compositor.SetLayer(layer); err != nil {
+		s.layersMu.Unlock()
+		return err
+	}
+	_, ok := s.layers[layer.Name]
+	if ok {
+		s.layersMu.Unlock()
+		s.layersChanged()
+		return nil
+	}
+	l := &serverLayer{
+		name:    layer.Name,
+		player:  animation.NewPlayer[leddraw.LEDStrip](),
+		removed: make(chan struct{}),
+	}
+	s.layers[layer.Name] = l
+	s.layersMu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		s.removeLayer(l)
+		return ctx.Err()
+	case s.newLayerCh <- l:
+		return nil
+	}
+}
+
+// PlayLayer renders the given frames onto the LEDs and replaces the animation
+// of the layer with them. The layer keeps showing the last frame until it is
+// removed.
+func (s *Server) PlayLayer(ctx context.Context, name string, frames []animation.Frame[image.Image], opts RenderOpts) error {
+	s.layersMu.Lock()
+	l, ok := s.layers[name]
+	s.layersMu.Unlock()
+	if !ok {
+		return fmt.Errorf("cannot play on layer %q: %w", name, ErrLayerNotFound)
+	}
+
+	rendered, err := s.RenderFrames(frames, opts)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := l.context(ctx)
+	defer cancel()
+
+	if err := l.player.Replace(ctx, rendered); err != nil {
+		select {
+		case <-l.removed:
+			return fmt.Errorf("cannot play on layer %q: %w", name, ErrLayerNotFound)
+		default:
+			return err
+		}
+	}
+	return nil
+}
+
+// RemoveLayer removes the layer with the given name and stops its animation.
+func (s *Server) RemoveLayer(name string) error {
+	s.layersMu.Lock()
+	l, ok := s.layers[name]
+	s.layersMu.Unlock()
+	if !ok {
+		return fmt.Errorf("cannot remove layer %q: %w", name, ErrLayerNotFound)
+	}
+
+	s.removeLayer(l)
+	return nil
+}
+
+func (s *Server) removeLayer(l *serverLayer) {
+	s.layersMu.Lock()
+	if s.layers[l.name] == l {
+		delete(s.layers, l.name)
+		s.compositor.RemoveLayer(l.name)
+		close(l.removed)
+	}
+	s.layersMu.Unlock()
+
+	s.layersChanged()
+}
+
+// setLayerLEDs shows a frame of a layer. False is returned if the layer was
+// removed since.
+func (s *Server) setLayerLEDs(frame layerFrame) bool {
+	s.layersMu.Lock()
+	defer s.layersMu.Unlock()
+
+	if s.layers[frame.layer.name] != frame.layer {
+		return false
+	}
+	return s.compositor.SetLayerLEDs(frame.layer.name, frame.leds)
+}
+
+// layersChanged makes the LEDs be composited again, even if no frame is
+// played.
+func (s *Server) layersChanged() {
+	select {
+	case s.layersChangedCh <- struct{}{}:
+	default:
+		// Already pending.
+	}
+}
+
+// runLayers runs the players of the layers as they are added.
+func (s *Server) runLayers(ctx context.Context) error {
+	errg, ctx := errgroup.WithContext(ctx)
+	errg.Go(func() error {
+		for {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case l := <-s.newLayerCh:
+				errg.Go(func() error { return s.runLayer(ctx, l) })
+			}
+		}
+	})
+	return errg.Wait()
+}
+
+// runLayer plays the animation of a layer until it is removed.
+func (s *Server) runLayer(ctx context.Context, l *serverLayer) error {
+	layerCtx, cancel := l.context(ctx)
+	defer cancel()
+
+	errg, layerCtx := errgroup.WithContext(layerCtx)
+	errg.Go(func() error { return l.player.Run(layerCtx) })
+	errg.Go(func() error {
+		for {
+			select {
+			case <-layerCtx.Done():
+				return layerCtx.Err()
+			case frame := <-l.player.C:
+				select {
+				case <-layerCtx.Done():
+					return layerCtx.Err()
+				case s.layerFrameCh <- layerFrame{l, frame.Image}:
+				}
+			}
+		}
+	})
+
+	err := errg.Wait()
+	select {
+	case <-l.removed:
+		return nil
+	default:
+		return err
+	}
+}
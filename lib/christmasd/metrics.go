@@ -0,0 +1,203 @@
+package christmasd
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"dev.acmcsuf.com/christmas/lib/animation"
+	"dev.acmcsuf.com/christmas/lib/leddraw"
+)
+
+// DefaultLEDWatts is the estimated power drawn by one LED at full white. 0.3W
+// is taken from the LED strips in the README's parts list, and it can be
+// overridden with the LEDWatts option.
+const DefaultLEDWatts = 0.3
+
+// ContentTypeMetrics is the content type of the Prometheus text format.
+const ContentTypeMetrics = "text/plain; version=0.0.4; charset=utf-8"
+
+// fpsWindow is how long frames are counted for to measure the frame rate.
+const fpsWindow = time.Second
+
+// serverMetrics measures how well the server keeps up with the LEDs.
+type serverMetrics struct {
+	started      time.Time
+	renderTime   *animation.Histogram
+	writeLatency *animation.Histogram
+
+	mu            sync.Mutex
+	framesWritten uint64
+	writeErrors   uint64
+	watts         float64 // estimated power of the last frame written
+	fps           float64 // frame rate of the last full window
+	windowStart   time.Time
+	windowFrames  int
+}
+
+func newServerMetrics() *serverMetrics {
+	return &serverMetrics{
+		started:      time.Now(),
+		renderTime:   animation.NewHistogram(animation.DefaultBuckets),
+		writeLatency: animation.NewHistogram(animation.DefaultBuckets),
+	}
+}
+
+// observeWrite records a frame written to the output, which took d and drew
+// an estimated watts.
+func (m *serverMetrics) observeWrite(d time.Duration, watts float64, err error) {
+	m.writeLatency.Observe(d)
+
+	now := time.Now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.framesWritten++
+	if err != nil {
+		m.writeErrors++
+	}
+	m.watts = watts
+
+	if m.windowStart.IsZero() {
+		m.windowStart = now
+	}
+	m.windowFrames++
+	if elapsed := now.Sub(m.windowStart); elapsed >= fpsWindow {
+		m.fps = float64(m.windowFrames) / elapsed.Seconds()
+		m.windowStart = now
+		m.windowFrames = 0
+	}
+}
+
+// currentFPS returns the frame rate at the given time. Once the current
+// window is over, the frames in it are counted even if no frame ended it, so
+// the frame rate drops to zero when frames stop coming.
+func (m *serverMetrics) currentFPS(now time.Time) float64 {
+	if m.windowStart.IsZero() {
+		return 0
+	}
+	if elapsed := now.Sub(m.windowStart); elapsed >= fpsWindow {
+		return float64(m.windowFrames) / elapsed.Seconds()
+	}
+	return m.fps
+}
+
+// estimatePower estimates the power drawn by the LEDs showing strip, assuming
+// that each channel of an LED draws a third of its power at full white.
+func estimatePower(strip leddraw.LEDStrip, ledWatts float64) float64 {
+	var total int
+	for _, c := range strip {
+		total += int(c.R) + int(c.G) + int(c.B)
+	}
+	return float64(total) / (3 * 0xFF) * ledWatts
+}
+
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+
+	queued, err := s.animated.Queued(r.Context())
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, fmt.Errorf("cannot get queued frames: %w", err))
+		return
+	}
+
+	m := s.metrics
+	now := time.Now()
+	stats := animation.ReadStats()
+
+	m.mu.Lock()
+	framesWritten := m.framesWritten
+	writeErrors := m.writeErrors
+	watts := m.watts
+	fps := m.currentFPS(now)
+	m.mu.Unlock()
+
+	var p promWriter
+	p.gauge("christmasd_uptime_seconds",
+		"Time since the server started.",
+		now.Sub(m.started).Seconds())
+	p.counter("christmasd_frames_written_total",
+		"Frames written to the LEDs.",
+		float64(framesWritten))
+	p.counter("christmasd_frame_write_errors_total",
+		"Frames that failed to be written to the LEDs.",
+		float64(writeErrors))
+	p.gauge("christmasd_frames_per_second",
+		"Frames written to the LEDs per second.",
+		fps)
+	p.histogram("christmasd_led_write_duration_seconds",
+		"Time taken to write a frame to the LEDs.",
+		m.writeLatency.Snapshot())
+	p.histogram("christmasd_render_duration_seconds",
+		"Time taken to render an image onto the LEDs.",
+		m.renderTime.Snapshot())
+	p.gauge("christmasd_queued_frames",
+		"Animation frames waiting to be played.",
+		float64(queued))
+	p.counter("christmasd_animation_frames_total",
+		"Animation frames played.",
+		float64(stats.TotalFrames))
+	p.counter("christmasd_animation_dropped_frames_total",
+		"Animation frames dropped because the LEDs could not keep up.",
+		float64(stats.DroppedFrames))
+	p.histogram("christmasd_animation_frame_drift_seconds",
+		"How late animation frames were played.",
+		stats.FrameDrift)
+	p.histogram("christmasd_animation_frame_jitter_seconds",
+		"How much the time between animation frames differed from their duration.",
+		stats.FrameJitter)
+	p.gauge("christmasd_power_watts",
+		"Estimated power drawn by the LEDs.",
+		watts)
+
+	w.Header().Set("Content-Type", ContentTypeMetrics)
+	w.Write(p.buf.Bytes())
+}
+
+// promWriter writes metrics in the Prometheus text format.
+type promWriter struct {
+	buf bytes.Buffer
+}
+
+func (p *promWriter) header(name, help, kind string) {
+	fmt.Fprintf(&p.buf, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(&p.buf, "# TYPE %s %s\n", name, kind)
+}
+
+func (p *promWriter) sample(name, labels string, v float64) {
+	p.buf.WriteString(name)
+	p.buf.WriteString(labels)
+	p.buf.WriteByte(' ')
+	p.buf.WriteString(formatFloat(v))
+	p.buf.WriteByte('\n')
+}
+
+func (p *promWriter) counter(name, help string, v float64) {
+	p.header(name, help, "counter")
+	p.sample(name, "", v)
+}
+
+func (p *promWriter) gauge(name, help string, v float64) {
+	p.header(name, help, "gauge")
+	p.sample(name, "", v)
+}
+
+func (p *promWriter) histogram(name, help string, h animation.HistogramSnapshot) {
+	p.header(name, help, "histogram")
+	for i, le := range h.Buckets {
+		p.sample(name+"_bucket", `{le="`+formatFloat(le.Seconds())+`"}`, float64(h.Counts[i]))
+	}
+	p.sample(name+"_bucket", `{le="+Inf"}`, float64(h.Count))
+	p.sample(name+"_sum", "", h.Sum.Seconds())
+	p.sample(name+"_count", "", float64(h.Count))
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,234 @@
+package christmasd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"dev.acmcsuf.com/christmas/lib/leddraw"
+	"dev.acmcsuf.com/christmas/lib/xdraw"
+	"dev.acmcsuf.com/christmas/proto/christmasdpb"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"nhooyr.io/websocket"
+)
+
+const (
+	// defaultPreviewFPS is the rate that preview clients get frames at unless
+	// they ask for another one.
+	defaultPreviewFPS = 30
+	// maxPreviewFPS is the highest rate that preview clients can ask for.
+	maxPreviewFPS = 120
+)
+
+// previewHub fans out every shown frame to the preview subscribers. Each
+// subscriber only holds the latest frame, so publishing never blocks.
+type previewHub struct {
+	mu   sync.Mutex
+	subs map[*previewSub]struct{}
+}
+
+// previewSub is a single preview subscriber.
+type previewSub struct {
+	latest chan leddraw.LEDStrip
+}
+
+func (h *previewHub) subscribe() *previewSub {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	sub := &previewSub{latest: make(chan leddraw.LEDStrip, 1)}
+	if h.subs == nil {
+		h.subs = make(map[*previewSub]struct{})
+	}
+	h.subs[sub] = struct{}{}
+	return sub
+}
+
+func (h *previewHub) unsubscribe(sub *previewSub) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.subs, sub)
+}
+
+// publish sends the strip to every subscriber, replacing the frames that they
+// haven't taken yet. The strip must not be modified afterwards.
+func (h *previewHub) publish(strip leddraw.LEDStrip) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for sub := range h.subs {
+		sub.offer(strip)
+	}
+}
+
+func (sub *previewSub) offer(strip leddraw.LEDStrip) {
+	for {
+		select {
+		case sub.latest <- strip:
+			return
+		default:
+		}
+		// Take out the stale frame and try again. publish holds the lock, so
+		// this is the only sender.
+		select {
+		case <-sub.latest:
+		default:
+		}
+	}
+}
+
+// next waits for the next frame. It waits at least until the given time so
+// that frames in between are skipped.
+func (sub *previewSub) next(ctx context.Context, notBefore time.Time) (leddraw.LEDStrip, error) {
+	if d := time.Until(notBefore); d > 0 {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case strip := <-sub.latest:
+		return strip, nil
+	}
+}
+
+// previewLayout returns the layout message sent to new preview clients.
+func (s *Server) previewLayout() *christmasdpb.PreviewLayout {
+	return &christmasdpb.PreviewLayout{
+		LedPoints: PointsToProto(s.opts.LEDPoints),
+		LedBounds: RectToProto(xdraw.BoundingBox(s.opts.LEDPoints)),
+	}
+}
+
+func (s *Server) handlePreview(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+
+	fps := defaultPreviewFPS
+	if v := r.URL.Query().Get("fps"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxPreviewFPS {
+			writeError(w, r, http.StatusBadRequest,
+				fmt.Errorf("fps must be between 1 and %d", maxPreviewFPS))
+			return
+		}
+		fps = n
+	}
+
+	var send func(ctx context.Context, msg *christmasdpb.PreviewMessage) error
+	if mediaType(r.Header.Get("Accept")) == "text/event-stream" {
+		send = previewSSE(w)
+		if send == nil {
+			writeError(w, r, http.StatusInternalServerError, fmt.Errorf("streaming not supported"))
+			return
+		}
+	} else {
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			// Accept has already written the error response.
+			return
+		}
+		defer conn.CloseNow()
+
+		// Nothing is read from the client, but reading is needed to handle
+		// pings and closes.
+		ctx := conn.CloseRead(r.Context())
+		r = r.WithContext(ctx)
+
+		send = previewWebSocket(conn, r.URL.Query().Get("format") == "json")
+	}
+
+	ctx := r.Context()
+	sub := s.preview.subscribe()
+	defer s.preview.unsubscribe(sub)
+
+	if err := send(ctx, &christmasdpb.PreviewMessage{
+		Message: &christmasdpb.PreviewMessage_Layout{Layout: s.previewLayout()},
+	}); err != nil {
+		return
+	}
+
+	// Start with what is currently shown rather than waiting for a change.
+	sub.offer(s.LEDs())
+
+	interval := time.Second / time.Duration(fps)
+	var lastSent time.Time
+	for {
+		strip, err := sub.next(ctx, lastSent.Add(interval))
+		if err != nil {
+			return
+		}
+		lastSent = time.Now()
+
+		if err := send(ctx, &christmasdpb.PreviewMessage{
+			Message: &christmasdpb.PreviewMessage_Leds{Leds: LEDStripToProto(strip)},
+		}); err != nil {
+			return
+		}
+	}
+}
+
+func previewWebSocket(conn *websocket.Conn, useJSON bool) func(context.Context, *christmasdpb.PreviewMessage) error {
+	return func(ctx context.Context, msg *christmasdpb.PreviewMessage) error {
+		if useJSON {
+			b, err := protojson.Marshal(msg)
+			if err != nil {
+				return err
+			}
+			return conn.Write(ctx, websocket.MessageText, b)
+		}
+
+		b, err := proto.Marshal(msg)
+		if err != nil {
+			return err
+		}
+		return conn.Write(ctx, websocket.MessageBinary, b)
+	}
+}
+
+func previewSSE(w http.ResponseWriter) func(context.Context, *christmasdpb.PreviewMessage) error {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	return func(ctx context.Context, msg *christmasdpb.PreviewMessage) error {
+		var event string
+		var data proto.Message
+		switch msg := msg.Message.(type) {
+		case *christmasdpb.PreviewMessage_Layout:
+			event, data = "layout", msg.Layout
+		case *christmasdpb.PreviewMessage_Leds:
+			event, data = "leds", msg.Leds
+		}
+
+		b, err := protojson.Marshal(data)
+		if err != nil {
+			return err
+		}
+
+		// protojson may add spaces but never newlines without Multiline.
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, b); err != nil {
+			return err
+		}
+		flusher.Flush()
+		return nil
+	}
+}
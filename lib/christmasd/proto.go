@@ -0,0 +1,380 @@
+package christmasd
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"time"
+
+	"dev.acmcsuf.com/christmas/lib/animation"
+	"dev.acmcsuf.com/christmas/lib/animdecode"
+	"dev.acmcsuf.com/christmas/lib/leddraw"
+	"dev.acmcsuf.com/christmas/lib/schedule"
+	"dev.acmcsuf.com/christmas/lib/xcolor"
+	"dev.acmcsuf.com/christmas/lib/xdraw"
+	"dev.acmcsuf.com/christmas/proto/christmasdpb"
+	"google.golang.org/protobuf/proto"
+)
+
+// LEDStripToProto converts a LEDStrip to its Protobuf representation.
+func LEDStripToProto(strip leddraw.LEDStrip) *christmasdpb.LEDStrip {
+	colors := make([]uint32, len(strip))
+	for i, c := range strip {
+		colors[i] = c.ToUint()
+	}
+	return &christmasdpb.LEDStrip{Colors: colors}
+}
+
+// LEDStripFromProto converts a Protobuf LEDStrip to a LEDStrip.
+func LEDStripFromProto(pb *christmasdpb.LEDStrip) leddraw.LEDStrip {
+	strip := make(leddraw.LEDStrip, len(pb.GetColors()))
+	for i, c := range pb.GetColors() {
+		strip[i] = xcolor.RGBFromUint(c)
+	}
+	return strip
+}
+
+// RectToProto converts an image.Rectangle to its Protobuf representation.
+func RectToProto(r image.Rectangle) *christmasdpb.Rectangle {
+	return &christmasdpb.Rectangle{
+		MinX: int32(r.Min.X),
+		MinY: int32(r.Min.Y),
+		MaxX: int32(r.Max.X),
+		MaxY: int32(r.Max.Y),
+	}
+}
+
+// RectFromProto converts a Protobuf Rectangle to an image.Rectangle.
+func RectFromProto(pb *christmasdpb.Rectangle) image.Rectangle {
+	return image.Rect(
+		int(pb.GetMinX()), int(pb.GetMinY()),
+		int(pb.GetMaxX()), int(pb.GetMaxY()))
+}
+
+// PointsToProto converts a slice of image.Points to their Protobuf
+// representation.
+func PointsToProto(pts []image.Point) []*christmasdpb.Point {
+	pbs := make([]*christmasdpb.Point, len(pts))
+	for i, pt := range pts {
+		pbs[i] = &christmasdpb.Point{X: int32(pt.X), Y: int32(pt.Y)}
+	}
+	return pbs
+}
+
+// PointsFromProto converts Protobuf Points to a slice of image.Points.
+func PointsFromProto(pbs []*christmasdpb.Point) []image.Point {
+	pts := make([]image.Point, len(pbs))
+	for i, pb := range pbs {
+		pts[i] = image.Pt(int(pb.GetX()), int(pb.GetY()))
+	}
+	return pts
+}
+
+// ScaleModeToProto converts an xdraw.ScaleMode to its Protobuf
+// representation.
+func ScaleModeToProto(mode xdraw.ScaleMode) christmasdpb.ScaleMode {
+	switch mode {
+	case xdraw.ScaleFit:
+		return christmasdpb.ScaleMode_SCALE_FIT
+	default:
+		return christmasdpb.ScaleMode_SCALE_FILL
+	}
+}
+
+// ScaleModeFromProto converts a Protobuf ScaleMode to an xdraw.ScaleMode.
+func ScaleModeFromProto(pb christmasdpb.ScaleMode) xdraw.ScaleMode {
+	switch pb {
+	case christmasdpb.ScaleMode_SCALE_FIT:
+		return xdraw.ScaleFit
+	default:
+		return xdraw.ScaleFill
+	}
+}
+
+// RenderOptsToProto converts the canvas options of RenderOpts to their
+// Protobuf representation. The scale mode is converted separately using
+// ScaleModeToProto.
+func RenderOptsToProto(opts RenderOpts) *christmasdpb.CanvasOptions {
+	return &christmasdpb.CanvasOptions{
+		Intensity:   christmasdpb.Intensity(opts.Intensity),
+		MaxDistance: opts.MaxDistance,
+		Averaging:   christmasdpb.Averaging(opts.Averaging),
+	}
+}
+
+// RenderOptsFromProto converts a Protobuf scale mode and canvas options to
+// RenderOpts. The enums have the same values as IntensityMode and
+// AveragingMode.
+func RenderOptsFromProto(mode christmasdpb.ScaleMode, pb *christmasdpb.CanvasOptions) RenderOpts {
+	return RenderOpts{
+		ScaleMode:   ScaleModeFromProto(mode),
+		Intensity:   IntensityMode(pb.GetIntensity()),
+		MaxDistance: pb.GetMaxDistance(),
+		Averaging:   AveragingMode(pb.GetAveraging()),
+	}
+}
+
+// FramesFromProto decodes the images of a Protobuf FramesRequest into frames.
+// Animated GIFs and APNGs are expanded into all of their frames.
+func FramesFromProto(pb *christmasdpb.FramesRequest) ([]animation.Frame[image.Image], error) {
+	frames := make([]animation.Frame[image.Image], 0, len(pb.GetFrames()))
+	for i, frame := range pb.GetFrames() {
+		decoded, err := animdecode.Decode(bytes.NewReader(frame.GetImage()))
+		if err != nil {
+			return nil, fmt.Errorf("cannot decode frame %d: %w", i, err)
+		}
+
+		if len(decoded) == 1 {
+			// Still images take the duration of the frame.
+			decoded[0].DurationMs = animation.Milliseconds(frame.GetDurationMs())
+		}
+		if frame.GetJumpBackAmount() > 0 || frame.GetJumpTo() != "" {
+			last := &decoded[len(decoded)-1]
+			last.JumpBackAmount = frame.GetJumpBackAmount()
+			last.JumpTo = frame.GetJumpTo()
+			last.Repeat = frame.GetRepeat()
+		}
+		if frame.GetMarker() != "" {
+			decoded[0].Marker = frame.GetMarker()
+		}
+
+		frames = append(frames, decoded...)
+	}
+	return frames, nil
+}
+
+// FramesToRenderResponse converts rendered frames to a RenderResponse.
+// RenderedFrames can only jump back by a number of frames, so the frames are
+// flattened with animation.Flatten first.
+func FramesToRenderResponse(frames []animation.Frame[leddraw.LEDStrip]) *christmasdpb.RenderResponse {
+	frames = animation.Flatten(frames)
+	pb := &christmasdpb.RenderResponse{
+		Frames: make([]*christmasdpb.RenderedFrame, len(frames)),
+	}
+	for i, frame := range frames {
+		pb.Frames[i] = &christmasdpb.RenderedFrame{
+			Leds:           LEDStripToProto(frame.Image),
+			JumpBackAmount: frame.JumpBackAmount,
+			DurationMs:     uint32(frame.DurationMs),
+		}
+	}
+	return pb
+}
+
+// LEDStripToRGB packs a LEDStrip into R, G, B bytes, as in StreamFrame.rgb.
+func LEDStripToRGB(strip leddraw.LEDStrip) []byte {
+	b := make([]byte, 0, 3*len(strip))
+	for _, c := range strip {
+		b = append(b, c.R, c.G, c.B)
+	}
+	return b
+}
+
+// LEDStripFromRGB unpacks R, G, B bytes into a LEDStrip.
+func LEDStripFromRGB(b []byte) (leddraw.LEDStrip, error) {
+	if len(b)%3 != 0 {
+		return nil, fmt.Errorf("RGB length %d is not a multiple of 3", len(b))
+	}
+	strip := make(leddraw.LEDStrip, len(b)/3)
+	for i := range strip {
+		strip[i] = xcolor.RGB{R: b[3*i], G: b[3*i+1], B: b[3*i+2]}
+	}
+	return strip, nil
+}
+
+// TransitionToProto converts a leddraw.Transition to a Protobuf Transition.
+func TransitionToProto(t leddraw.Transition) *christmasdpb.Transition {
+	return &christmasdpb.Transition{
+		Kind:       christmasdpb.TransitionKind(t.Kind),
+		DurationMs: uint32(t.Duration.Milliseconds()),
+	}
+}
+
+// TransitionFromProto converts a Protobuf Transition to a leddraw.Transition.
+// The enum has the same values as leddraw.TransitionKind.
+func TransitionFromProto(pb *christmasdpb.Transition) leddraw.Transition {
+	return leddraw.Transition{
+		Kind:     leddraw.TransitionKind(pb.GetKind()),
+		Duration: time.Duration(pb.GetDurationMs()) * time.Millisecond,
+	}
+}
+
+// LayerToProto converts a leddraw.Layer to a Protobuf Layer.
+func LayerToProto(l leddraw.Layer) *christmasdpb.Layer {
+	return &christmasdpb.Layer{
+		Name:    l.Name,
+		Blend:   christmasdpb.BlendMode(l.Blend),
+		Opacity: proto.Float64(l.Opacity),
+		Z:       int32(l.Z),
+	}
+}
+
+// LayerFromProto converts a Protobuf Layer to a leddraw.Layer. The enum has
+// the same values as leddraw.BlendMode, and a missing opacity is 1.
+func LayerFromProto(pb *christmasdpb.Layer) leddraw.Layer {
+	opacity := 1.0
+	if pb.Opacity != nil {
+		opacity = *pb.Opacity
+	}
+	return leddraw.Layer{
+		Name:    pb.GetName(),
+		Blend:   leddraw.BlendMode(pb.GetBlend()),
+		Opacity: opacity,
+		Z:       int(pb.GetZ()),
+	}
+}
+
+// LayersToProto converts layers to their Protobuf representation.
+func LayersToProto(layers []leddraw.Layer) *christmasdpb.Layers {
+	pb := &christmasdpb.Layers{Layers: make([]*christmasdpb.Layer, len(layers))}
+	for i, l := range layers {
+		pb.Layers[i] = LayerToProto(l)
+	}
+	return pb
+}
+
+// LayersFromProto converts Protobuf Layers to layers.
+func LayersFromProto(pb *christmasdpb.Layers) []leddraw.Layer {
+	layers := make([]leddraw.Layer, len(pb.GetLayers()))
+	for i, l := range pb.GetLayers() {
+		layers[i] = LayerFromProto(l)
+	}
+	return layers
+}
+
+// ScheduleToProto converts a schedule config to its Protobuf representation.
+func ScheduleToProto(cfg schedule.Config) *christmasdpb.Schedule {
+	pb := &christmasdpb.Schedule{
+		Enabled:  cfg.Enabled,
+		Shows:    make([]*christmasdpb.Show, len(cfg.Shows)),
+		Playlist: cfg.Playlist,
+		Shuffle:  cfg.Shuffle,
+		Rules:    make([]*christmasdpb.ScheduleRule, len(cfg.Rules)),
+	}
+
+	if cfg.Transition != nil {
+		pb.Transition = TransitionToProto(leddraw.Transition{
+			Kind:     cfg.Transition.Kind,
+			Duration: time.Duration(cfg.Transition.Duration),
+		})
+	}
+
+	for i, show := range cfg.Shows {
+		spb := &christmasdpb.Show{
+			Name:       show.Name,
+			DurationMs: uint32(animation.DurationToMs(time.Duration(show.Duration))),
+		}
+		switch {
+		case show.Sequence != "":
+			spb.Content = &christmasdpb.Show_Sequence{Sequence: show.Sequence}
+		case show.Image != "":
+			spb.Content = &christmasdpb.Show_Image{Image: show.Image}
+		case show.Pattern != "":
+			spb.Content = &christmasdpb.Show_Pattern{Pattern: show.Pattern}
+		}
+		for _, s := range show.Colors {
+			// The config is validated, so the colors are valid.
+			c, _ := xcolor.RGBFromString(s)
+			spb.Colors = append(spb.Colors, c.ToUint())
+		}
+		pb.Shows[i] = spb
+	}
+
+	for i, rule := range cfg.Rules {
+		pb.Rules[i] = &christmasdpb.ScheduleRule{
+			From:       rule.From.String(),
+			To:         rule.To.String(),
+			EveryMs:    uint32(animation.DurationToMs(time.Duration(rule.Every))),
+			DurationMs: uint32(animation.DurationToMs(time.Duration(rule.Duration))),
+			Off:        rule.Off,
+			Brightness: rule.Brightness,
+			Show:       rule.Show,
+		}
+	}
+
+	return pb
+}
+
+// ScheduleFromProto converts a Protobuf Schedule to a schedule config. The
+// config is not validated.
+func ScheduleFromProto(pb *christmasdpb.Schedule) (schedule.Config, error) {
+	cfg := schedule.Config{
+		Enabled:  pb.GetEnabled(),
+		Shows:    make([]schedule.Show, len(pb.GetShows())),
+		Playlist: pb.GetPlaylist(),
+		Shuffle:  pb.GetShuffle(),
+		Rules:    make([]schedule.Rule, len(pb.GetRules())),
+	}
+
+	if pb.GetTransition() != nil {
+		transition := TransitionFromProto(pb.GetTransition())
+		cfg.Transition = &schedule.Transition{
+			Kind:     transition.Kind,
+			Duration: schedule.Duration(transition.Duration),
+		}
+	}
+
+	for i, spb := range pb.GetShows() {
+		show := schedule.Show{
+			Name:     spb.GetName(),
+			Duration: schedule.Duration(time.Duration(spb.GetDurationMs()) * time.Millisecond),
+			Sequence: spb.GetSequence(),
+			Image:    spb.GetImage(),
+			Pattern:  spb.GetPattern(),
+		}
+		for _, c := range spb.GetColors() {
+			show.Colors = append(show.Colors, xcolor.RGBFromUint(c).String())
+		}
+		cfg.Shows[i] = show
+	}
+
+	for i, rpb := range pb.GetRules() {
+		rule := schedule.Rule{
+			Every:      schedule.Duration(time.Duration(rpb.GetEveryMs()) * time.Millisecond),
+			Duration:   schedule.Duration(time.Duration(rpb.GetDurationMs()) * time.Millisecond),
+			Off:        rpb.GetOff(),
+			Brightness: rpb.GetBrightness(),
+			Show:       rpb.GetShow(),
+		}
+
+		var err error
+		if rpb.GetFrom() != "" {
+			if rule.From, err = schedule.ParseTimeOfDay(rpb.GetFrom()); err != nil {
+				return schedule.Config{}, fmt.Errorf("rule %d: %w", i, err)
+			}
+		}
+		if rpb.GetTo() != "" {
+			if rule.To, err = schedule.ParseTimeOfDay(rpb.GetTo()); err != nil {
+				return schedule.Config{}, fmt.Errorf("rule %d: %w", i, err)
+			}
+		}
+
+		cfg.Rules[i] = rule
+	}
+
+	return cfg, nil
+}
+
+// ScheduleStatusToProto converts a schedule status to its Protobuf
+// representation.
+func ScheduleStatusToProto(status schedule.Status) *christmasdpb.ScheduleStatus {
+	return &christmasdpb.ScheduleStatus{
+		Enabled:     status.Enabled,
+		Show:        status.Show,
+		Off:         status.Off,
+		Brightness:  status.Brightness,
+		UntilUnixMs: status.Until.UnixMilli(),
+	}
+}
+
+// ScheduleStatusFromProto converts a Protobuf ScheduleStatus to a schedule
+// status.
+func ScheduleStatusFromProto(pb *christmasdpb.ScheduleStatus) schedule.Status {
+	return schedule.Status{
+		Enabled:    pb.GetEnabled(),
+		Show:       pb.GetShow(),
+		Off:        pb.GetOff(),
+		Brightness: pb.GetBrightness(),
+		Until:      time.UnixMilli(pb.GetUntilUnixMs()),
+	}
+}
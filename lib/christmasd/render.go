@@ -0,0 +1,120 @@
+package christmasd
+
+import (
+	"fmt"
+
+	"dev.acmcsuf.com/christmas/lib/leddraw"
+	"dev.acmcsuf.com/christmas/lib/xcolor"
+	"dev.acmcsuf.com/christmas/lib/xdraw"
+)
+
+// IntensityMode selects the intensity function of the LED canvas.
+type IntensityMode uint8
+
+const (
+	// IntensityDefault uses the intensity function the server was created
+	// with.
+	IntensityDefault IntensityMode = iota
+	// IntensityStep uses leddraw.NewStepIntensity.
+	IntensityStep
+	// IntensityLinear uses leddraw.NewLinearIntensity.
+	IntensityLinear
+	// IntensityCubic uses leddraw.NewCubicIntensity.
+	IntensityCubic
+)
+
+// AveragingMode selects the averaging function of the LED canvas.
+type AveragingMode uint8
+
+const (
+	// AveragingDefault uses the averaging function the server was created
+	// with.
+	AveragingDefault AveragingMode = iota
+	// AveragingSimple uses xcolor.NewSimpleAveraging.
+	AveragingSimple
+	// AveragingSquared uses xcolor.NewSquaredAveraging.
+	AveragingSquared
+	// AveragingNearest uses xcolor.NewNearestAveraging.
+	AveragingNearest
+)
+
+// RenderOpts are the options for rendering images onto the LEDs. The zero
+// value fills the canvas and renders it with the server's canvas options.
+type RenderOpts struct {
+	// ScaleMode is how images are scaled to the canvas bounds.
+	ScaleMode xdraw.ScaleMode
+	// Intensity overrides the intensity function of the canvas.
+	Intensity IntensityMode
+	// MaxDistance is the maximum distance in canvas pixels between a pixel
+	// and an LED. It is required if Intensity is set.
+	MaxDistance float64
+	// Averaging overrides the averaging function of the canvas.
+	Averaging AveragingMode
+}
+
+// canvasKey identifies the canvas options that a canvas was created with.
+type canvasKey struct {
+	intensity   IntensityMode
+	maxDistance float64
+	averaging   AveragingMode
+}
+
+// maxCanvases is the maximum number of canvases with non-default options that
+// are kept around. Creating a canvas is slow, so they are reused, but each one
+// takes a fair bit of memory.
+const maxCanvases = 8
+
+// canvasFor returns the canvas for the given options. s.canvasMu must be
+// held.
+func (s *Server) canvasFor(opts RenderOpts) (*leddraw.LEDCanvas, error) {
+	key := canvasKey{opts.Intensity, opts.MaxDistance, opts.Averaging}
+	if key == (canvasKey{}) {
+		return s.canvas, nil
+	}
+
+	if canvas, ok := s.canvases[key]; ok {
+		return canvas, nil
+	}
+
+	canvasOpts := s.opts.CanvasOpts
+
+	if opts.Intensity != IntensityDefault {
+		if opts.MaxDistance <= 0 {
+			return nil, fmt.Errorf("max distance must be positive, got %g", opts.MaxDistance)
+		}
+		switch opts.Intensity {
+		case IntensityStep:
+			canvasOpts.Intensity = leddraw.NewStepIntensity(opts.MaxDistance)
+		case IntensityLinear:
+			canvasOpts.Intensity = leddraw.NewLinearIntensity(opts.MaxDistance)
+		case IntensityCubic:
+			canvasOpts.Intensity = leddraw.NewCubicIntensity(opts.MaxDistance)
+		default:
+			return nil, fmt.Errorf("unknown intensity mode %d", opts.Intensity)
+		}
+	}
+
+	switch opts.Averaging {
+	case AveragingDefault:
+	case AveragingSimple:
+		canvasOpts.Average = xcolor.NewSimpleAveraging()
+	case AveragingSquared:
+		canvasOpts.Average = xcolor.NewSquaredAveraging()
+	case AveragingNearest:
+		canvasOpts.Average = xcolor.NewNearestAveraging()
+	default:
+		return nil, fmt.Errorf("unknown averaging mode %d", opts.Averaging)
+	}
+
+	canvas, err := leddraw.NewLEDCanvas(clonePoints(s.opts.LEDPoints), canvasOpts)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create LED canvas: %w", err)
+	}
+
+	if len(s.canvases) >= maxCanvases {
+		clear(s.canvases)
+	}
+	s.canvases[key] = canvas
+
+	return canvas, nil
+}
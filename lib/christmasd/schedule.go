@@ -0,0 +1,224 @@
+package christmasd
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"dev.acmcsuf.com/christmas/lib/animation"
+	"dev.acmcsuf.com/christmas/lib/animdecode"
+	"dev.acmcsuf.com/christmas/lib/fseq"
+	"dev.acmcsuf.com/christmas/lib/leddraw"
+	"dev.acmcsuf.com/christmas/lib/ledseq"
+	"dev.acmcsuf.com/christmas/lib/patterns"
+	"dev.acmcsuf.com/christmas/lib/schedule"
+	"dev.acmcsuf.com/christmas/proto/christmasdpb"
+)
+
+// patternFPS is the rate at which the patterns of scheduled shows are
+// rendered.
+const patternFPS = 30
+
+// Schedule returns the config of the scheduler.
+func (s *Server) Schedule() schedule.Config {
+	return s.scheduler.Config()
+}
+
+// SetSchedule validates and applies the config of the scheduler. If the server
+// has a SchedulePath, the config is saved there.
+func (s *Server) SetSchedule(cfg schedule.Config) error {
+	if err := s.scheduler.SetConfig(cfg); err != nil {
+		return err
+	}
+	if s.opts.SchedulePath == "" {
+		return nil
+	}
+	if err := schedule.SaveConfig(s.opts.SchedulePath, cfg); err != nil {
+		return fmt.Errorf("cannot save schedule: %w", err)
+	}
+	return nil
+}
+
+// ScheduleStatus returns what the scheduler is currently doing.
+func (s *Server) ScheduleStatus() schedule.Status {
+	return s.scheduler.Status()
+}
+
+// PlayShow plays a scheduled show on the animation player until the context
+// is canceled or until a show that doesn't loop has been added. It implements
+// schedule.Target.
+func (s *Server) PlayShow(ctx context.Context, show schedule.Show, brightness float64, transition leddraw.Transition) error {
+	src, done, err := s.showSource(ctx, show)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	src = animation.MapSource(src, func(frame animation.Frame[leddraw.LEDStrip]) animation.Frame[leddraw.LEDStrip] {
+		frame.Image = dim(frame.Image, brightness)
+		return frame
+	})
+	return s.PlayLEDSource(ctx, src, transition)
+}
+
+// TurnOff stops the animation and turns every LED off. It implements
+// schedule.Target.
+func (s *Server) TurnOff(ctx context.Context) error {
+	if err := s.ClearFrames(ctx); err != nil {
+		return err
+	}
+	return s.SetLEDs(ctx, make(leddraw.LEDStrip, s.LEDCount()))
+}
+
+// showSource returns the frames of a show. Sequences are decoded and patterns
+// are rendered as they are played. done must be called once the show is over.
+func (s *Server) showSource(ctx context.Context, show schedule.Show) (src animation.FrameSource[leddraw.LEDStrip], done func(), err error) {
+	switch {
+	case show.Pattern != "":
+		src, err = s.patternSource(show)
+		return src, func() {}, err
+	case show.Sequence != "":
+		return s.sequenceSource(show.Sequence)
+	default:
+		frames, err := s.loadImage(ctx, show.Image)
+		return animation.NewSliceSource(frames), func() {}, err
+	}
+}
+
+// patternSource renders the pattern of the show at patternFPS. Static
+// patterns are only rendered once.
+func (s *Server) patternSource(show schedule.Show) (animation.FrameSource[leddraw.LEDStrip], error) {
+	pattern, params, err := show.PatternParams()
+	if err != nil {
+		return nil, err
+	}
+
+	layout := patterns.NewLayout(s.opts.LEDPoints)
+	frameDuration := time.Second / patternFPS
+
+	return animation.NewGeneratorSource(func(ctx context.Context, i int) (animation.Frame[leddraw.LEDStrip], error) {
+		if pattern.Static && i > 0 {
+			return animation.Frame[leddraw.LEDStrip]{}, io.EOF
+		}
+
+		leds := make(leddraw.LEDStrip, s.LEDCount())
+		pattern.Render(leds, layout, time.Duration(i)*frameDuration, params)
+		return animation.Frame[leddraw.LEDStrip]{
+			Image:      leds,
+			DurationMs: animation.DurationToMs(frameDuration),
+		}, nil
+	}), nil
+}
+
+// loadImage loads the frames of an image show.
+func (s *Server) loadImage(ctx context.Context, path string) ([]animation.Frame[leddraw.LEDStrip], error) {
+	images, err := animdecode.Load(ctx, path, animdecode.Opts{
+		Bounds: s.CanvasBounds(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot load %s: %w", path, err)
+	}
+
+	frames := make([]animation.Frame[image.Image], len(images))
+	for i, frame := range images {
+		frames[i] = animation.WithImage[image.Image](frame, frame.Image)
+	}
+
+	return s.RenderFrames(frames, RenderOpts{})
+}
+
+// sequenceSource opens an LED sequence or FSEQ file. LED sequences are decoded
+// from the file as they are played, while FSEQ files are mapped from their
+// first channel up front.
+func (s *Server) sequenceSource(path string) (animation.FrameSource[leddraw.LEDStrip], func(), error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	magic := make([]byte, 4)
+	_, err = io.ReadFull(f, magic)
+	f.Close()
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot read %s: %w", path, err)
+	}
+
+	if fseq.IsFSEQ(magic) {
+		seq, err := fseq.ReadFile(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot read %s: %w", path, err)
+		}
+		frames := seq.LEDFrames(fseq.Mapping{LEDCount: s.LEDCount()})
+		return animation.NewSliceSource(frames), func() {}, nil
+	}
+
+	r, err := ledseq.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if r.LEDCount() != s.LEDCount() {
+		r.Close()
+		return nil, nil, fmt.Errorf("%s has %d LEDs, expected %d", path, r.LEDCount(), s.LEDCount())
+	}
+
+	return r.Source(), func() { r.Close() }, nil
+}
+
+// dim returns the LEDs with their colors scaled by brightness. The LEDs are
+// copied rather than changed, since looping frames are played again.
+func dim(leds leddraw.LEDStrip, brightness float64) leddraw.LEDStrip {
+	if brightness >= 1 {
+		return leds
+	}
+	dimmed := make(leddraw.LEDStrip, len(leds))
+	for i, c := range leds {
+		dimmed[i] = c.Scale(brightness)
+	}
+	return dimmed
+}
+
+func (s *Server) handleSchedule(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet, http.MethodPut) {
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		writeMessage(w, r, http.StatusOK, ScheduleToProto(s.Schedule()))
+		return
+	}
+
+	var req christmasdpb.Schedule
+	if !readMessage(w, r, &req) {
+		return
+	}
+
+	cfg, err := ScheduleFromProto(&req)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := cfg.Validate(); err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := s.SetSchedule(cfg); err != nil {
+		writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleScheduleStatus(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+
+	writeMessage(w, r, http.StatusOK, ScheduleStatusToProto(s.ScheduleStatus()))
+}
@@ -0,0 +1,240 @@
+package christmasd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"net/http"
+	"sync"
+	"time"
+
+	"dev.acmcsuf.com/christmas/proto/christmasdpb"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"nhooyr.io/websocket"
+)
+
+// streamStatsInterval is how often StreamStats are sent to the client.
+const streamStatsInterval = time.Second
+
+// streamFrame is a frame received over a stream, waiting to be shown.
+type streamFrame struct {
+	msg      *christmasdpb.StreamFrame
+	received time.Time
+}
+
+// stream is a single WebSocket stream. Frames are passed from the reader to
+// the renderer through a single slot, so that a newer frame replaces an older
+// one that hasn't been shown yet.
+type stream struct {
+	server *Server
+	conn   *websocket.Conn
+	latest chan streamFrame
+
+	mu         sync.Mutex
+	stats      christmasdpb.StreamStats
+	latency    time.Duration // total since the last stats
+	latencyN   int
+	binary     bool // whether the client last sent binary messages
+	statsDirty bool
+}
+
+func (s *Server) handleStream(w http.ResponseWriter, r *http.Request) {
+	conn, err := websocket.Accept(w, r, nil)
+	if err != nil {
+		// Accept has already written the error response.
+		return
+	}
+	defer conn.CloseNow()
+
+	conn.SetReadLimit(maxBodySize)
+
+	st := &stream{
+		server: s,
+		conn:   conn,
+		latest: make(chan streamFrame, 1),
+	}
+
+	err = st.run(r.Context())
+	switch websocket.CloseStatus(err) {
+	case websocket.StatusNormalClosure, websocket.StatusGoingAway:
+		conn.Close(websocket.StatusNormalClosure, "")
+	default:
+		if errors.Is(err, context.Canceled) {
+			conn.Close(websocket.StatusGoingAway, "server shutting down")
+		} else {
+			conn.Close(websocket.StatusInternalError, err.Error())
+		}
+	}
+}
+
+func (st *stream) run(ctx context.Context) error {
+	errg, ctx := errgroup.WithContext(ctx)
+	errg.Go(func() error { return st.readLoop(ctx) })
+	errg.Go(func() error { return st.renderLoop(ctx) })
+	errg.Go(func() error { return st.statsLoop(ctx) })
+	return errg.Wait()
+}
+
+func (st *stream) readLoop(ctx context.Context) error {
+	for {
+		typ, b, err := st.conn.Read(ctx)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+
+		msg := new(christmasdpb.StreamFrame)
+		if typ == websocket.MessageBinary {
+			err = proto.Unmarshal(b, msg)
+		} else {
+			err = protojson.Unmarshal(b, msg)
+		}
+
+		st.mu.Lock()
+		st.binary = typ == websocket.MessageBinary
+		st.stats.Received++
+		st.statsDirty = true
+		st.mu.Unlock()
+
+		if err != nil {
+			st.fail(fmt.Errorf("cannot decode frame: %w", err))
+			continue
+		}
+
+		frame := streamFrame{msg: msg, received: now}
+		select {
+		case st.latest <- frame:
+			continue
+		default:
+		}
+
+		// The renderer hasn't taken the previous frame yet, so replace it.
+		// This is the only sender, so there is room after draining.
+		select {
+		case <-st.latest:
+			st.mu.Lock()
+			st.stats.Dropped++
+			st.mu.Unlock()
+		default:
+		}
+		st.latest <- frame
+	}
+}
+
+func (st *stream) renderLoop(ctx context.Context) error {
+	for {
+		var frame streamFrame
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case frame = <-st.latest:
+		}
+
+		if err := st.show(ctx, frame.msg); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			st.fail(err)
+			continue
+		}
+
+		st.mu.Lock()
+		st.stats.Shown++
+		st.latency += time.Since(frame.received)
+		st.latencyN++
+		st.statsDirty = true
+		st.mu.Unlock()
+	}
+}
+
+func (st *stream) show(ctx context.Context, msg *christmasdpb.StreamFrame) error {
+	switch frame := msg.Frame.(type) {
+	case *christmasdpb.StreamFrame_Leds:
+		return st.server.SetLEDs(ctx, LEDStripFromProto(frame.Leds))
+	case *christmasdpb.StreamFrame_Rgb:
+		strip, err := LEDStripFromRGB(frame.Rgb)
+		if err != nil {
+			return err
+		}
+		return st.server.SetLEDs(ctx, strip)
+	case *christmasdpb.StreamFrame_Image:
+		img, _, err := image.Decode(bytes.NewReader(frame.Image))
+		if err != nil {
+			return fmt.Errorf("cannot decode image: %w", err)
+		}
+		return st.server.SetImage(ctx, img, RenderOptsFromProto(msg.ScaleMode, msg.CanvasOptions))
+	default:
+		return fmt.Errorf("empty frame")
+	}
+}
+
+func (st *stream) fail(err error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	st.stats.Failed++
+	st.stats.Error = err.Error()
+	st.statsDirty = true
+}
+
+func (st *stream) statsLoop(ctx context.Context) error {
+	ticker := time.NewTicker(streamStatsInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		typ, b, ok, err := st.takeStats()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+
+		if err := st.conn.Write(ctx, typ, b); err != nil {
+			return err
+		}
+	}
+}
+
+// takeStats encodes the stats if they changed since they were last taken.
+func (st *stream) takeStats() (websocket.MessageType, []byte, bool, error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if !st.statsDirty {
+		return 0, nil, false, nil
+	}
+	st.statsDirty = false
+
+	st.stats.LatencyUs = 0
+	if st.latencyN > 0 {
+		st.stats.LatencyUs = uint32((st.latency / time.Duration(st.latencyN)).Microseconds())
+	}
+	st.latency = 0
+	st.latencyN = 0
+
+	var b []byte
+	var err error
+	typ := websocket.MessageText
+	if st.binary {
+		typ = websocket.MessageBinary
+		b, err = proto.Marshal(&st.stats)
+	} else {
+		b, err = protojson.Marshal(&st.stats)
+	}
+	if err != nil {
+		return 0, nil, false, fmt.Errorf("cannot encode stats: %w", err)
+	}
+
+	return typ, b, true, nil
+}
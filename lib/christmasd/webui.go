@@ -0,0 +1,20 @@
+package christmasd
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+//go:embed webui
+var webUIFiles embed.FS
+
+// webUIHandler serves the web control panel. It only uses the public HTTP
+// API, so it can be used as an example client.
+func webUIHandler() http.Handler {
+	files, err := fs.Sub(webUIFiles, "webui")
+	if err != nil {
+		panic(err)
+	}
+	return http.FileServer(http.FS(files))
+}
@@ -0,0 +1,205 @@
+// app.js is the web control panel of christmasd. It only uses the HTTP API
+// described in proto/christmasd.proto, in Protobuf JSON.
+
+"use strict";
+
+const form = document.getElementById("form");
+const statusText = document.getElementById("status");
+const liveCanvas = document.getElementById("live");
+const previewCanvas = document.getElementById("preview");
+
+// points is the position of each LED, from /api/v1/info.
+let points = [];
+// previewFrames is the last rendered preview, played by playPreview.
+let previewFrames = [];
+let previewTimer = null;
+
+function setStatus(text, isError = false) {
+  statusText.textContent = text;
+  statusText.classList.toggle("error", isError);
+}
+
+async function api(method, path, body) {
+  const resp = await fetch(path, {
+    method,
+    headers: body ? { "Content-Type": "application/json" } : {},
+    body: body ? JSON.stringify(body) : undefined,
+  });
+  if (!resp.ok) {
+    let message = resp.statusText;
+    try {
+      message = (await resp.json()).message || message;
+    } catch {}
+    throw new Error(message);
+  }
+  return resp.status == 204 ? null : resp.json();
+}
+
+// drawLEDs draws the given colors as dots at the LED points. Colors are
+// 0xRRGGBB numbers. Protobuf JSON omits zeros, so missing values are black.
+function drawLEDs(canvas, colors = []) {
+  const ctx = canvas.getContext("2d");
+  ctx.fillStyle = "#000";
+  ctx.fillRect(0, 0, canvas.width, canvas.height);
+  if (points.length == 0) {
+    return;
+  }
+
+  const xs = points.map((pt) => pt.x || 0);
+  const ys = points.map((pt) => pt.y || 0);
+  const minX = Math.min(...xs);
+  const minY = Math.min(...ys);
+  const width = Math.max(...xs) - minX || 1;
+  const height = Math.max(...ys) - minY || 1;
+
+  const radius = Math.max(2, Math.min(canvas.width, canvas.height) / 80);
+  const pad = radius * 2;
+  const scale = Math.min(
+    (canvas.width - 2 * pad) / width,
+    (canvas.height - 2 * pad) / height,
+  );
+  const offsetX = (canvas.width - width * scale) / 2;
+  const offsetY = (canvas.height - height * scale) / 2;
+
+  points.forEach((pt, i) => {
+    const color = colors[i] || 0;
+    ctx.fillStyle = "#" + color.toString(16).padStart(6, "0");
+    ctx.beginPath();
+    ctx.arc(
+      offsetX + ((pt.x || 0) - minX) * scale,
+      offsetY + ((pt.y || 0) - minY) * scale,
+      radius,
+      0,
+      2 * Math.PI,
+    );
+    ctx.fill();
+  });
+}
+
+// watchLive mirrors the LEDs onto the live canvas.
+function watchLive() {
+  const events = new EventSource("/api/v1/preview?fps=20");
+  events.addEventListener("layout", (ev) => {
+    points = JSON.parse(ev.data).ledPoints || [];
+    drawLEDs(liveCanvas);
+    drawLEDs(previewCanvas);
+    setStatus(`Connected, ${points.length} LEDs`);
+  });
+  events.addEventListener("leds", (ev) => {
+    drawLEDs(liveCanvas, JSON.parse(ev.data).colors);
+  });
+  events.addEventListener("error", () => {
+    setStatus("Disconnected, reconnecting…", true);
+  });
+}
+
+function readFileBase64(file) {
+  return new Promise((resolve, reject) => {
+    const reader = new FileReader();
+    reader.onload = () => resolve(reader.result.split(",", 2)[1]);
+    reader.onerror = () => reject(reader.error);
+    reader.readAsDataURL(file);
+  });
+}
+
+// request builds the FramesRequest or ImageRequest fields from the form.
+async function request() {
+  const data = new FormData(form);
+  const file = data.get("file");
+  if (!file || file.size == 0) {
+    return null;
+  }
+
+  const canvasOptions = {
+    intensity: data.get("intensity"),
+    averaging: data.get("averaging"),
+  };
+  if (canvasOptions.intensity != "INTENSITY_DEFAULT") {
+    canvasOptions.maxDistance = Number(data.get("maxDistance"));
+  }
+
+  return {
+    image: await readFileBase64(file),
+    scaleMode: data.get("scaleMode"),
+    canvasOptions,
+  };
+}
+
+async function updatePreview() {
+  clearTimeout(previewTimer);
+
+  const req = await request();
+  if (!req) {
+    return;
+  }
+
+  setStatus("Rendering preview…");
+  try {
+    const resp = await api("POST", "/api/v1/render", {
+      frames: [{ image: req.image }],
+      scaleMode: req.scaleMode,
+      canvasOptions: req.canvasOptions,
+    });
+    previewFrames = resp.frames || [];
+    setStatus(`Preview has ${previewFrames.length} frame(s)`);
+    playPreview(0);
+  } catch (err) {
+    setStatus(`Cannot preview: ${err.message}`, true);
+  }
+}
+
+// playPreview plays the preview frames like the tree would, including loops.
+function playPreview(i) {
+  const frame = previewFrames[i];
+  if (!frame) {
+    return;
+  }
+  drawLEDs(previewCanvas, frame.leds && frame.leds.colors);
+
+  const next = frame.jumpBackAmount > 0 ? i - frame.jumpBackAmount : i + 1;
+  if (next >= 0 && next < previewFrames.length) {
+    previewTimer = setTimeout(() => playPreview(next), frame.durationMs || 0);
+  }
+}
+
+async function showOnTree(ev) {
+  ev.preventDefault();
+
+  const req = await request();
+  if (!req) {
+    return;
+  }
+
+  setStatus("Sending to the tree…");
+  try {
+    // Stop whatever is playing so that the new image isn't overridden.
+    await api("DELETE", "/api/v1/frames");
+    if (previewFrames.length > 1) {
+      await api("POST", "/api/v1/frames", {
+        frames: [{ image: req.image }],
+        scaleMode: req.scaleMode,
+        canvasOptions: req.canvasOptions,
+      });
+    } else {
+      await api("POST", "/api/v1/image", req);
+    }
+    setStatus("Shown on the tree");
+  } catch (err) {
+    setStatus(`Cannot show on the tree: ${err.message}`, true);
+  }
+}
+
+async function stopAnimation() {
+  try {
+    await api("DELETE", "/api/v1/frames");
+    setStatus("Animation stopped");
+  } catch (err) {
+    setStatus(`Cannot stop the animation: ${err.message}`, true);
+  }
+}
+
+form.addEventListener("change", updatePreview);
+form.addEventListener("submit", showOnTree);
+document.getElementById("stop").addEventListener("click", stopAnimation);
+
+watchLive();
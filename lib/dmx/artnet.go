@@ -0,0 +1,97 @@
+package dmx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// ArtNetPort is the UDP port of Art-Net.
+const ArtNetPort = 6454
+
+// ArtNetMaxUniverse is the last universe that Art-Net can carry, since its
+// port addresses are 15 bits.
+const ArtNetMaxUniverse = 0x7FFF
+
+const (
+	artNetHeaderSize = 18
+	artNetOpDmx      = 0x5000
+	artNetVersion    = 14
+)
+
+var artNetID = [8]byte{'A', 'r', 't', '-', 'N', 'e', 't', 0}
+
+// ArtDmxPacket is an Art-Net ArtDmx packet, which carries the data of a single
+// universe.
+type ArtDmxPacket struct {
+	// Sequence is incremented for every packet sent to the universe so that
+	// receivers can detect out-of-order packets. 0 disables sequencing.
+	Sequence uint8
+	// Physical is the physical input port that the data came from.
+	Physical uint8
+	// Universe is the 15-bit port address, made up of the net, sub-net and
+	// universe.
+	Universe uint16
+	// Data is the DMX512 data, up to 512 channels.
+	Data []byte
+}
+
+// AppendBinary appends the encoded packet to b. Art-Net requires an even
+// number of at least 2 channels, so the data is padded with zeros if needed.
+func (p *ArtDmxPacket) AppendBinary(b []byte) []byte {
+	data := p.Data
+	if len(data) > UniverseSize {
+		data = data[:UniverseSize]
+	}
+
+	length := len(data)
+	if length < 2 {
+		length = 2
+	} else if length%2 != 0 {
+		length++
+	}
+
+	b = append(b, artNetID[:]...)
+	b = binary.LittleEndian.AppendUint16(b, artNetOpDmx)
+	b = binary.BigEndian.AppendUint16(b, artNetVersion)
+	b = append(b, p.Sequence, p.Physical)
+	b = append(b, byte(p.Universe), byte(p.Universe>>8)&0x7F) // SubUni, Net
+	b = binary.BigEndian.AppendUint16(b, uint16(length))
+	b = append(b, data...)
+	for i := len(data); i < length; i++ {
+		b = append(b, 0)
+	}
+
+	return b
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (p *ArtDmxPacket) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(nil), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. Data points into b.
+func (p *ArtDmxPacket) UnmarshalBinary(b []byte) error {
+	if len(b) < artNetHeaderSize {
+		return fmt.Errorf("Art-Net packet too short (%d bytes)", len(b))
+	}
+	if !bytes.Equal(b[:8], artNetID[:]) {
+		return errors.New("not an Art-Net packet")
+	}
+	if op := binary.LittleEndian.Uint16(b[8:10]); op != artNetOpDmx {
+		return fmt.Errorf("not an ArtDmx packet (opcode 0x%04x)", op)
+	}
+
+	length := int(binary.BigEndian.Uint16(b[16:18]))
+	if length > UniverseSize || artNetHeaderSize+length > len(b) {
+		return fmt.Errorf("invalid ArtDmx length %d", length)
+	}
+
+	p.Sequence = b[12]
+	p.Physical = b[13]
+	p.Universe = uint16(b[14]) | uint16(b[15]&0x7F)<<8
+	p.Data = b[artNetHeaderSize : artNetHeaderSize+length]
+
+	return nil
+}
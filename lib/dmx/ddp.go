@@ -0,0 +1,96 @@
+package dmx
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// DDPPort is the UDP port of DDP.
+const DDPPort = 4048
+
+const (
+	ddpHeaderSize         = 10
+	ddpTimecodeHeaderSize = 14
+	ddpMaxDataSize        = 1440
+)
+
+// DDP header flags.
+const (
+	DDPFlagPush     = 0x01
+	DDPFlagQuery    = 0x02
+	DDPFlagReply    = 0x04
+	DDPFlagStorage  = 0x08
+	DDPFlagTimecode = 0x10
+	DDPFlagVersion1 = 0x40
+)
+
+// DDPPacket is a DDP (Distributed Display Protocol) data packet. Unlike DMX,
+// DDP addresses the whole pixel buffer of a device by byte offset, so there
+// are no universes.
+type DDPPacket struct {
+	// Flags is a bitmask of DDPFlag values.
+	Flags uint8
+	// Sequence is a 4-bit sequence number. 0 disables sequencing.
+	Sequence uint8
+	// DataType describes the pixel format. 0 means the device's default,
+	// which is 8-bit RGB for us.
+	DataType uint8
+	// ID is the destination ID. 1 is the default output device.
+	ID uint8
+	// Offset is the byte offset of Data in the pixel buffer.
+	Offset uint32
+	// Data is the pixel data.
+	Data []byte
+}
+
+// Push returns true if the device should show the pixel buffer after this
+// packet.
+func (p *DDPPacket) Push() bool {
+	return p.Flags&DDPFlagPush != 0
+}
+
+// AppendBinary appends the encoded packet to b. The timecode flag is ignored.
+func (p *DDPPacket) AppendBinary(b []byte) []byte {
+	b = append(b, p.Flags&^DDPFlagTimecode|DDPFlagVersion1, p.Sequence&0x0F, p.DataType, p.ID)
+	b = binary.BigEndian.AppendUint32(b, p.Offset)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(p.Data)))
+	b = append(b, p.Data...)
+	return b
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (p *DDPPacket) MarshalBinary() ([]byte, error) {
+	if len(p.Data) > ddpMaxDataSize {
+		return nil, fmt.Errorf("DDP data too long (%d bytes)", len(p.Data))
+	}
+	return p.AppendBinary(nil), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. Data points into b.
+func (p *DDPPacket) UnmarshalBinary(b []byte) error {
+	if len(b) < ddpHeaderSize {
+		return fmt.Errorf("DDP packet too short (%d bytes)", len(b))
+	}
+	if version := b[0] >> 6; version != 1 {
+		return fmt.Errorf("unsupported DDP version %d", version)
+	}
+
+	headerSize := ddpHeaderSize
+	if b[0]&DDPFlagTimecode != 0 {
+		headerSize = ddpTimecodeHeaderSize
+	}
+
+	length := int(binary.BigEndian.Uint16(b[8:10]))
+	if headerSize+length > len(b) {
+		return fmt.Errorf("invalid DDP data length %d", length)
+	}
+
+	p.Flags = b[0]
+	p.Sequence = b[1] & 0x0F
+	p.DataType = b[2]
+	p.ID = b[3]
+	p.Offset = binary.BigEndian.Uint32(b[4:8])
+	p.Data = b[headerSize : headerSize+length]
+
+	return nil
+}
@@ -0,0 +1,114 @@
+// Package dmx implements the network protocols that lighting software and
+// pixel controllers use to send LED colors: DMX512 universes over E1.31 (sACN)
+// and Art-Net, as well as DDP.
+package dmx
+
+import (
+	"fmt"
+	"sort"
+
+	"dev.acmcsuf.com/christmas/lib/leddraw"
+)
+
+// UniverseSize is the number of channels in a DMX universe.
+const UniverseSize = 512
+
+// channelsPerLED is the number of channels used by each LED: red, green and
+// blue, in that order.
+const channelsPerLED = 3
+
+// Mapping maps a range of LEDs onto DMX channels. LEDs are never split across
+// universes, so LEDs that don't fit in a universe continue at the first
+// channel of the next universe, like most pixel controllers expect.
+type Mapping struct {
+	// Start is the index of the first LED in the range.
+	Start int `json:"start"`
+	// Count is the number of LEDs in the range. If 0, the range continues to
+	// the last LED.
+	Count int `json:"count,omitempty"`
+	// Universe is the universe of the first LED.
+	Universe uint16 `json:"universe"`
+	// Channel is the 1-based channel of the first LED in its universe. If 0,
+	// it is 1.
+	Channel int `json:"channel,omitempty"`
+}
+
+// Validate returns an error if the mapping is invalid.
+func (m Mapping) Validate() error {
+	if m.Start < 0 || m.Count < 0 {
+		return fmt.Errorf("invalid LED range %d+%d", m.Start, m.Count)
+	}
+	if m.Channel < 0 || m.Channel > UniverseSize-channelsPerLED+1 {
+		return fmt.Errorf("channel %d out of range [1, %d]", m.Channel, UniverseSize-channelsPerLED+1)
+	}
+	return nil
+}
+
+// Universes is a set of universes and their channel data. The data of each
+// universe is only as long as the channels that are mapped.
+type Universes map[uint16][]byte
+
+// Pack packs the LEDs into the universes according to the mappings. Existing
+// universes are reused, so the same Universes can be packed into every frame
+// without allocating.
+func (u Universes) Pack(leds leddraw.LEDStrip, mappings []Mapping) {
+	eachMappedLED(mappings, len(leds), func(i int, universe uint16, channel int) {
+		data := u.grow(universe, channel+channelsPerLED)
+		data[channel+0] = leds[i].R
+		data[channel+1] = leds[i].G
+		data[channel+2] = leds[i].B
+	})
+}
+
+// eachMappedLED calls fn with the universe and 0-based channel of every LED
+// that is mapped.
+func eachMappedLED(mappings []Mapping, numLEDs int, fn func(i int, universe uint16, channel int)) {
+	for _, m := range mappings {
+		if m.Start >= numLEDs {
+			continue
+		}
+
+		end := numLEDs
+		if m.Count > 0 && m.Start+m.Count < end {
+			end = m.Start + m.Count
+		}
+
+		universe := m.Universe
+		channel := m.Channel - 1 // 0-based
+		if channel < 0 {
+			channel = 0
+		}
+
+		for i := m.Start; i < end; i++ {
+			if channel+channelsPerLED > UniverseSize {
+				universe++
+				channel = 0
+			}
+			fn(i, universe, channel)
+			channel += channelsPerLED
+		}
+	}
+}
+
+// grow returns the data of the universe, growing it to at least n channels.
+func (u Universes) grow(universe uint16, n int) []byte {
+	data := u[universe]
+	if len(data) < n {
+		if cap(data) < n {
+			data = append(make([]byte, 0, UniverseSize), data...)
+		}
+		data = data[:n]
+		u[universe] = data
+	}
+	return data
+}
+
+// Sorted returns the universe numbers in ascending order.
+func (u Universes) Sorted() []uint16 {
+	universes := make([]uint16, 0, len(u))
+	for universe := range u {
+		universes = append(universes, universe)
+	}
+	sort.Slice(universes, func(i, j int) bool { return universes[i] < universes[j] })
+	return universes
+}
@@ -0,0 +1,272 @@
+package dmx
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"dev.acmcsuf.com/christmas/lib/leddraw"
+	"dev.acmcsuf.com/christmas/lib/xcolor"
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestPack(t *testing.T) {
+	leds := make(leddraw.LEDStrip, 200)
+	for i := range leds {
+		leds[i] = xcolor.RGB{R: uint8(i), G: 0xFF, B: 0x01}
+	}
+
+	u := make(Universes)
+	u.Pack(leds, []Mapping{
+		{Start: 0, Count: 171, Universe: 1},
+		{Start: 171, Universe: 5, Channel: 10},
+	})
+
+	assert.Equal(t, []uint16{1, 2, 5}, u.Sorted())
+
+	// 170 LEDs fit in a universe, and the 171st goes to the next one.
+	assert.Equal(t, 170*3, len(u[1]))
+	assert.Equal(t, []byte{169, 0xFF, 0x01}, u[1][169*3:])
+	assert.Equal(t, []byte{170, 0xFF, 0x01}, u[2])
+
+	assert.Equal(t, 9+29*3, len(u[5]))
+	assert.Equal(t, []byte{0, 0, 0}, u[5][:3])
+	assert.Equal(t, []byte{171, 0xFF, 0x01}, u[5][9:12])
+}
+
+func TestE131Packet(t *testing.T) {
+	p := E131Packet{
+		CID:      [16]byte{1, 2, 3},
+		Source:   "christmas",
+		Priority: 100,
+		Sequence: 42,
+		Universe: 7,
+		Data:     []byte{1, 2, 3, 4},
+	}
+
+	b, err := p.MarshalBinary()
+	assert.NoError(t, err)
+	assert.Equal(t, 126+4, len(b))
+
+	var got E131Packet
+	assert.NoError(t, got.UnmarshalBinary(b))
+	assert.Equal(t, p, got)
+
+	assert.Error(t, got.UnmarshalBinary(b[:100]))
+}
+
+func TestArtDmxPacket(t *testing.T) {
+	p := ArtDmxPacket{
+		Sequence: 42,
+		Universe: 0x1234,
+		Data:     []byte{1, 2, 3},
+	}
+
+	b, err := p.MarshalBinary()
+	assert.NoError(t, err)
+	assert.Equal(t, 18+4, len(b)) // padded to an even length
+
+	var got ArtDmxPacket
+	assert.NoError(t, got.UnmarshalBinary(b))
+	assert.Equal(t, ArtDmxPacket{
+		Sequence: 42,
+		Universe: 0x1234,
+		Data:     []byte{1, 2, 3, 0},
+	}, got)
+}
+
+func TestSender(t *testing.T) {
+	leds := leddraw.LEDStrip{{R: 0xFF}, {G: 0xFF}}
+
+	t.Run("e131", func(t *testing.T) {
+		conn, addr := listenUDP(t)
+
+		s, err := NewSender(E131, Config{
+			Addr:     addr,
+			Mappings: []Mapping{{Universe: 3}},
+			Source:   "test",
+		})
+		assert.NoError(t, err)
+		defer s.Close()
+
+		for seq := uint8(1); seq <= 2; seq++ {
+			assert.NoError(t, s.Send(leds))
+
+			var p E131Packet
+			assert.NoError(t, p.UnmarshalBinary(readUDP(t, conn)))
+			assert.Equal(t, uint16(3), p.Universe)
+			assert.Equal(t, seq, p.Sequence)
+			assert.Equal(t, "test", p.Source)
+			assert.Equal(t, uint8(E131DefaultPriority), p.Priority)
+			assert.Equal(t, []byte{0xFF, 0, 0, 0, 0xFF, 0}, p.Data)
+		}
+	})
+
+	t.Run("e131_universe_range", func(t *testing.T) {
+		_, addr := listenUDP(t)
+
+		for _, universe := range []uint16{0, E131MaxUniverse + 1} {
+			_, err := NewSender(E131, Config{Addr: addr, Mappings: []Mapping{{Universe: universe}}})
+			assert.Error(t, err)
+		}
+
+		// 171 LEDs spill over from the last universe into one past it.
+		s, err := NewSender(E131, Config{Addr: addr, Mappings: []Mapping{{Universe: E131MaxUniverse}}})
+		assert.NoError(t, err)
+		defer s.Close()
+		assert.NoError(t, s.Send(make(leddraw.LEDStrip, 170)))
+		assert.Error(t, s.Send(make(leddraw.LEDStrip, 171)))
+
+		// Art-Net starts at universe 0.
+		s, err = NewSender(ArtNet, Config{Addr: addr, Mappings: []Mapping{{Universe: 0}}})
+		assert.NoError(t, err)
+		s.Close()
+	})
+
+	t.Run("artnet_universe_range", func(t *testing.T) {
+		_, addr := listenUDP(t)
+
+		_, err := NewSender(ArtNet, Config{Addr: addr, Mappings: []Mapping{{Universe: ArtNetMaxUniverse + 1}}})
+		assert.Error(t, err)
+
+		s, err := NewSender(ArtNet, Config{Addr: addr, Mappings: []Mapping{{Universe: ArtNetMaxUniverse}}})
+		assert.NoError(t, err)
+		defer s.Close()
+		assert.NoError(t, s.Send(make(leddraw.LEDStrip, 170)))
+		assert.Error(t, s.Send(make(leddraw.LEDStrip, 171)))
+	})
+
+	t.Run("artnet", func(t *testing.T) {
+		conn, addr := listenUDP(t)
+
+		s, err := NewSender(ArtNet, Config{Addr: addr})
+		assert.NoError(t, err)
+		defer s.Close()
+
+		assert.NoError(t, s.Send(leds))
+
+		var p ArtDmxPacket
+		assert.NoError(t, p.UnmarshalBinary(readUDP(t, conn)))
+		assert.Equal(t, uint16(1), p.Universe)
+		assert.Equal(t, []byte{0xFF, 0, 0, 0, 0xFF, 0}, p.Data)
+	})
+}
+
+func listenUDP(t *testing.T) (*net.UDPConn, string) {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	assert.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn, conn.LocalAddr().String()
+}
+
+func readUDP(t *testing.T, conn *net.UDPConn) []byte {
+	t.Helper()
+
+	b := make([]byte, 1024)
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, err := conn.Read(b)
+	assert.NoError(t, err)
+	return b[:n]
+}
+
+func TestReceiver(t *testing.T) {
+	t.Run("e131", func(t *testing.T) {
+		r, err := NewReceiver(E131, 4, []Mapping{
+			{Start: 0, Count: 2, Universe: 1},
+			{Start: 2, Universe: 2, Channel: 4},
+		})
+		assert.NoError(t, err)
+
+		frame := handleE131(t, r, 1, 1, []byte{1, 1, 1, 2, 2, 2})
+		assert.Zero(t, frame)
+
+		frame = handleE131(t, r, 2, 1, []byte{0, 0, 0, 3, 3, 3, 4, 4, 4})
+		assert.Equal(t, leddraw.LEDStrip{
+			{R: 1, G: 1, B: 1},
+			{R: 2, G: 2, B: 2},
+			{R: 3, G: 3, B: 3},
+			{R: 4, G: 4, B: 4},
+		}, frame)
+
+		// Universe 2 is lost, so the frame is shown once universe 1 is
+		// received again.
+		frame = handleE131(t, r, 1, 2, []byte{5, 5, 5, 5, 5, 5})
+		assert.Zero(t, frame)
+		frame = handleE131(t, r, 1, 3, []byte{6, 6, 6, 6, 6, 6})
+		assert.Equal(t, leddraw.LEDStrip{
+			{R: 5, G: 5, B: 5},
+			{R: 5, G: 5, B: 5},
+			{R: 3, G: 3, B: 3},
+			{R: 4, G: 4, B: 4},
+		}, frame)
+
+		// Out-of-order packets are dropped.
+		frame = handleE131(t, r, 2, 1, []byte{0, 0, 0, 7, 7, 7, 7, 7, 7})
+		assert.Zero(t, frame)
+	})
+
+	t.Run("ddp", func(t *testing.T) {
+		r, err := NewReceiver(DDP, 2, nil)
+		assert.NoError(t, err)
+
+		frame := handleDDP(t, r, DDPPacket{Offset: 3, Data: []byte{2, 2, 2}})
+		assert.Zero(t, frame)
+
+		frame = handleDDP(t, r, DDPPacket{Flags: DDPFlagPush, Data: []byte{1, 1, 1}})
+		assert.Equal(t, leddraw.LEDStrip{
+			{R: 1, G: 1, B: 1},
+			{R: 2, G: 2, B: 2},
+		}, frame)
+	})
+
+	t.Run("loopback", func(t *testing.T) {
+		conn, addr := listenUDP(t)
+
+		r, err := NewReceiver(ArtNet, 2, nil)
+		assert.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+
+		frames := make(chan leddraw.LEDStrip, 1)
+		go r.Serve(ctx, conn, func(leds leddraw.LEDStrip) error {
+			frames <- leds
+			return nil
+		})
+
+		s, err := NewSender(ArtNet, Config{Addr: addr})
+		assert.NoError(t, err)
+		defer s.Close()
+
+		leds := leddraw.LEDStrip{{R: 0xFF}, {B: 0xFF}}
+		assert.NoError(t, s.Send(leds))
+
+		select {
+		case frame := <-frames:
+			assert.Equal(t, leds, frame)
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for frame")
+		}
+	})
+}
+
+func handleE131(t *testing.T, r *Receiver, universe uint16, seq uint8, data []byte) leddraw.LEDStrip {
+	t.Helper()
+
+	p := E131Packet{Universe: universe, Sequence: seq, Data: data}
+	frame, err := r.HandlePacket(p.AppendBinary(nil))
+	assert.NoError(t, err)
+	return frame
+}
+
+func handleDDP(t *testing.T, r *Receiver, p DDPPacket) leddraw.LEDStrip {
+	t.Helper()
+
+	b, err := p.MarshalBinary()
+	assert.NoError(t, err)
+
+	frame, err := r.HandlePacket(b)
+	assert.NoError(t, err)
+	return frame
+}
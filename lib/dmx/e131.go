@@ -0,0 +1,155 @@
+package dmx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+)
+
+// E131Port is the UDP port of E1.31.
+const E131Port = 5568
+
+// E131DefaultPriority is the default priority of E1.31 data.
+const E131DefaultPriority = 100
+
+const (
+	// E131MinUniverse is the first universe that E1.31 can carry.
+	E131MinUniverse = 1
+	// E131MaxUniverse is the last universe that E1.31 can carry.
+	E131MaxUniverse = 63999
+)
+
+const (
+	e131HeaderSize   = 126
+	e131SourceSize   = 64
+	e131RootVector   = 0x00000004 // VECTOR_ROOT_E131_DATA
+	e131FrameVector  = 0x00000002 // VECTOR_E131_DATA_PACKET
+	e131DMPVector    = 0x02       // VECTOR_DMP_SET_PROPERTY
+	e131AddrDataType = 0xA1
+
+	e131OptionPreview    = 0x80
+	e131OptionTerminated = 0x40
+)
+
+var e131PacketID = [12]byte{'A', 'S', 'C', '-', 'E', '1', '.', '1', '7', 0, 0, 0}
+
+// E131Packet is an E1.31 data packet, which carries the data of a single
+// universe.
+type E131Packet struct {
+	// CID uniquely identifies the sender.
+	CID [16]byte
+	// Source is a human-readable name of the sender. It is truncated to 63
+	// bytes.
+	Source string
+	// Priority is the priority of the data, from 0 to 200. Receivers use the
+	// data of the source with the highest priority.
+	Priority uint8
+	// Sequence is incremented for every packet sent to the universe so that
+	// receivers can detect out-of-order packets.
+	Sequence uint8
+	// Options is a bitmask of options. It is usually 0.
+	Options uint8
+	// Universe is the universe number, from E131MinUniverse to
+	// E131MaxUniverse.
+	Universe uint16
+	// Data is the DMX512 data without the start code, up to 512 channels.
+	Data []byte
+}
+
+// AppendBinary appends the encoded packet to b.
+func (p *E131Packet) AppendBinary(b []byte) []byte {
+	data := p.Data
+	if len(data) > UniverseSize {
+		data = data[:UniverseSize]
+	}
+
+	total := e131HeaderSize + len(data)
+	b = binary.BigEndian.AppendUint16(b, 0x0010) // preamble size
+	b = binary.BigEndian.AppendUint16(b, 0x0000) // postamble size
+	b = append(b, e131PacketID[:]...)
+
+	// Root layer.
+	b = binary.BigEndian.AppendUint16(b, pduFlagsLength(total-16))
+	b = binary.BigEndian.AppendUint32(b, e131RootVector)
+	b = append(b, p.CID[:]...)
+
+	// Framing layer.
+	b = binary.BigEndian.AppendUint16(b, pduFlagsLength(total-38))
+	b = binary.BigEndian.AppendUint32(b, e131FrameVector)
+	var source [e131SourceSize]byte
+	copy(source[:e131SourceSize-1], p.Source) // always NUL-terminated
+	b = append(b, source[:]...)
+	b = append(b, p.Priority)
+	b = binary.BigEndian.AppendUint16(b, 0) // synchronization address
+	b = append(b, p.Sequence, p.Options)
+	b = binary.BigEndian.AppendUint16(b, p.Universe)
+
+	// DMP layer.
+	b = binary.BigEndian.AppendUint16(b, pduFlagsLength(total-115))
+	b = append(b, e131DMPVector, e131AddrDataType)
+	b = binary.BigEndian.AppendUint16(b, 0x0000) // first property address
+	b = binary.BigEndian.AppendUint16(b, 0x0001) // address increment
+	b = binary.BigEndian.AppendUint16(b, uint16(1+len(data)))
+	b = append(b, 0x00) // DMX start code
+	b = append(b, data...)
+
+	return b
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (p *E131Packet) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(nil), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. Data points into b.
+func (p *E131Packet) UnmarshalBinary(b []byte) error {
+	if len(b) < e131HeaderSize {
+		return fmt.Errorf("E1.31 packet too short (%d bytes)", len(b))
+	}
+	if !bytes.Equal(b[4:16], e131PacketID[:]) {
+		return errors.New("not an E1.31 packet")
+	}
+	if v := binary.BigEndian.Uint32(b[18:22]); v != e131RootVector {
+		return fmt.Errorf("unsupported E1.31 root vector 0x%08x", v)
+	}
+	if v := binary.BigEndian.Uint32(b[40:44]); v != e131FrameVector {
+		return fmt.Errorf("unsupported E1.31 framing vector 0x%08x", v)
+	}
+	if b[117] != e131DMPVector || b[118] != e131AddrDataType {
+		return errors.New("invalid E1.31 DMP layer")
+	}
+	if b[125] != 0x00 {
+		return fmt.Errorf("unsupported DMX start code 0x%02x", b[125])
+	}
+
+	count := int(binary.BigEndian.Uint16(b[123:125])) - 1
+	if count < 0 || count > UniverseSize || e131HeaderSize+count > len(b) {
+		return fmt.Errorf("invalid E1.31 property value count %d", count+1)
+	}
+
+	copy(p.CID[:], b[22:38])
+	p.Source = string(bytes.TrimRight(b[44:44+e131SourceSize], "\x00"))
+	p.Priority = b[108]
+	p.Sequence = b[111]
+	p.Options = b[112]
+	p.Universe = binary.BigEndian.Uint16(b[113:115])
+	p.Data = b[e131HeaderSize : e131HeaderSize+count]
+
+	return nil
+}
+
+// pduFlagsLength returns the flags and length field of an ACN PDU.
+func pduFlagsLength(length int) uint16 {
+	return 0x7000 | uint16(length)&0x0FFF
+}
+
+// E131MulticastAddr returns the multicast address that E1.31 receivers listen
+// on for the given universe.
+func E131MulticastAddr(universe uint16) *net.UDPAddr {
+	return &net.UDPAddr{
+		IP:   net.IPv4(239, 255, byte(universe>>8), byte(universe)),
+		Port: E131Port,
+	}
+}
@@ -0,0 +1,238 @@
+package dmx
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"sync"
+
+	"dev.acmcsuf.com/christmas/lib/leddraw"
+)
+
+// Receiver receives LED colors from lighting software such as xLights. It
+// acts like a pixel controller: incoming channels are mapped back onto the
+// LEDs, and a frame is produced once all of its data has been received.
+//
+// For E1.31 and Art-Net, a frame is complete once every mapped universe has
+// been received, or when a universe is received again before that. For DDP, a
+// frame is complete when a packet with the push flag is received.
+type Receiver struct {
+	protocol Protocol
+	numLEDs  int
+
+	mu        sync.Mutex
+	channels  map[uint16][]ledChannel // LEDs in each universe
+	received  map[uint16]bool
+	sequences map[uint16]uint8
+	pixels    []byte // RGB
+}
+
+type ledChannel struct {
+	led     int
+	channel int // 0-based
+}
+
+// NewReceiver creates a new Receiver for the given protocol and number of
+// LEDs. The mappings are only used by E1.31 and Art-Net. If there are none,
+// DefaultMappings is used.
+func NewReceiver(protocol Protocol, numLEDs int, mappings []Mapping) (*Receiver, error) {
+	switch protocol {
+	case E131, ArtNet, DDP:
+	default:
+		return nil, fmt.Errorf("unknown DMX protocol %q", protocol)
+	}
+
+	if len(mappings) == 0 {
+		mappings = DefaultMappings
+	}
+	for i, m := range mappings {
+		if err := m.Validate(); err != nil {
+			return nil, fmt.Errorf("mapping %d: %w", i, err)
+		}
+	}
+
+	channels := make(map[uint16][]ledChannel)
+	eachMappedLED(mappings, numLEDs, func(i int, universe uint16, channel int) {
+		channels[universe] = append(channels[universe], ledChannel{i, channel})
+	})
+
+	return &Receiver{
+		protocol:  protocol,
+		numLEDs:   numLEDs,
+		channels:  channels,
+		received:  make(map[uint16]bool, len(channels)),
+		sequences: make(map[uint16]uint8, len(channels)),
+		pixels:    make([]byte, numLEDs*channelsPerLED),
+	}, nil
+}
+
+// DefaultPort returns the default UDP port of the receiver's protocol.
+func (r *Receiver) DefaultPort() int {
+	switch r.protocol {
+	case E131:
+		return E131Port
+	case ArtNet:
+		return ArtNetPort
+	default:
+		return DDPPort
+	}
+}
+
+// HandlePacket handles a single packet. If the packet completes a frame, the
+// LEDs of the frame are returned. The returned strip is a copy that may be
+// retained.
+func (r *Receiver) HandlePacket(b []byte) (leddraw.LEDStrip, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch r.protocol {
+	case E131:
+		var p E131Packet
+		if err := p.UnmarshalBinary(b); err != nil {
+			return nil, err
+		}
+		// Ignore preview data and terminated streams.
+		if p.Options&(e131OptionPreview|e131OptionTerminated) != 0 {
+			return nil, nil
+		}
+		return r.handleUniverse(p.Universe, p.Sequence, p.Data), nil
+
+	case ArtNet:
+		var p ArtDmxPacket
+		if err := p.UnmarshalBinary(b); err != nil {
+			return nil, err
+		}
+		return r.handleUniverse(p.Universe, p.Sequence, p.Data), nil
+
+	default:
+		var p DDPPacket
+		if err := p.UnmarshalBinary(b); err != nil {
+			return nil, err
+		}
+		return r.handleDDP(&p), nil
+	}
+}
+
+func (r *Receiver) handleUniverse(universe uint16, seq uint8, data []byte) leddraw.LEDStrip {
+	channels, ok := r.channels[universe]
+	if !ok {
+		return nil
+	}
+
+	// Like E1.31 receivers, drop packets that are up to 20 packets older
+	// than the last one. 0 means that the sender doesn't use sequencing.
+	if last, ok := r.sequences[universe]; ok && seq != 0 {
+		if diff := int8(seq - last); diff <= 0 && diff > -20 {
+			return nil
+		}
+	}
+	r.sequences[universe] = seq
+
+	var frame leddraw.LEDStrip
+	if r.received[universe] {
+		// We missed some universes of the last frame, so show what we have
+		// before starting the next one.
+		frame = r.takeFrame()
+	}
+
+	for _, c := range channels {
+		if c.channel+channelsPerLED > len(data) {
+			break
+		}
+		copy(r.pixels[c.led*channelsPerLED:], data[c.channel:c.channel+channelsPerLED])
+	}
+
+	r.received[universe] = true
+	if len(r.received) == len(r.channels) {
+		frame = r.takeFrame()
+	}
+
+	return frame
+}
+
+func (r *Receiver) handleDDP(p *DDPPacket) leddraw.LEDStrip {
+	if p.Flags&(DDPFlagQuery|DDPFlagReply) != 0 {
+		return nil
+	}
+
+	switch p.ID {
+	case 0, 1, 255: // default output device or all devices
+	default:
+		return nil
+	}
+
+	if p.Offset < uint32(len(r.pixels)) {
+		copy(r.pixels[p.Offset:], p.Data)
+	}
+
+	if p.Push() {
+		return r.takeFrame()
+	}
+	return nil
+}
+
+// takeFrame returns the current frame and starts a new one.
+func (r *Receiver) takeFrame() leddraw.LEDStrip {
+	for universe := range r.received {
+		delete(r.received, universe)
+	}
+
+	leds := make(leddraw.LEDStrip, r.numLEDs)
+	for i := range leds {
+		leds[i].R = r.pixels[i*channelsPerLED+0]
+		leds[i].G = r.pixels[i*channelsPerLED+1]
+		leds[i].B = r.pixels[i*channelsPerLED+2]
+	}
+	return leds
+}
+
+// Serve reads packets from conn until the context is canceled, calling fn with
+// every complete frame. Invalid packets are logged and ignored.
+func (r *Receiver) Serve(ctx context.Context, conn net.PacketConn, fn func(leddraw.LEDStrip) error) error {
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	buf := make([]byte, 2048)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("cannot read packet: %w", err)
+		}
+
+		frame, err := r.HandlePacket(buf[:n])
+		if err != nil {
+			log.Printf("invalid %s packet from %s: %v", r.protocol, addr, err)
+			continue
+		}
+
+		if frame != nil {
+			if err := fn(frame); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// ListenAndServe listens for packets on the given UDP address and serves them
+// like Serve. If the address has no port, the default port of the protocol is
+// used. Only unicast is supported, so E1.31 senders must be configured to send
+// to the address of this machine.
+func (r *Receiver) ListenAndServe(ctx context.Context, addr string, fn func(leddraw.LEDStrip) error) error {
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, fmt.Sprint(r.DefaultPort()))
+	}
+
+	conn, err := net.ListenPacket("udp", addr)
+	if err != nil {
+		return fmt.Errorf("cannot listen for %s: %w", r.protocol, err)
+	}
+	defer conn.Close()
+
+	log.Println("receiving", r.protocol, "on", conn.LocalAddr())
+
+	return r.Serve(ctx, conn, fn)
+}
@@ -0,0 +1,203 @@
+package dmx
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net"
+	"os"
+
+	"dev.acmcsuf.com/christmas/lib/leddraw"
+)
+
+// Protocol is a DMX-over-network protocol.
+type Protocol string
+
+const (
+	// E131 is the E1.31 (sACN) protocol.
+	E131 Protocol = "e131"
+	// ArtNet is the Art-Net protocol.
+	ArtNet Protocol = "artnet"
+	// DDP is the DDP protocol. It can only be received.
+	DDP Protocol = "ddp"
+)
+
+// Config is the configuration of a Sender.
+type Config struct {
+	// Addr is the address to send to. If it has no port, the default port
+	// of the protocol is used. If empty, E1.31 is multicast to each universe
+	// and Art-Net is broadcast.
+	Addr string `json:"addr,omitempty"`
+	// Mappings maps the LEDs onto DMX channels. If empty, all LEDs are mapped
+	// from the first channel of universe 1.
+	Mappings []Mapping `json:"mappings,omitempty"`
+	// Source is the name of the sender. It is only used by E1.31, and it
+	// defaults to the hostname.
+	Source string `json:"source,omitempty"`
+	// Priority is the priority of the data. It is only used by E1.31, and it
+	// defaults to E131DefaultPriority.
+	Priority uint8 `json:"priority,omitempty"`
+}
+
+// DefaultMappings maps all LEDs from the first channel of universe 1.
+var DefaultMappings = []Mapping{{Universe: 1}}
+
+// Sender sends LED colors as DMX universes over UDP.
+type Sender struct {
+	protocol  Protocol
+	config    Config
+	conn      *net.UDPConn
+	addr      *net.UDPAddr // nil for E1.31 multicast
+	cid       [16]byte
+	universes Universes
+	sequences map[uint16]uint8
+	buf       []byte
+}
+
+// NewSender creates a new Sender for the given protocol.
+func NewSender(protocol Protocol, config Config) (*Sender, error) {
+	if len(config.Mappings) == 0 {
+		config.Mappings = DefaultMappings
+	}
+	for i, m := range config.Mappings {
+		if err := m.Validate(); err != nil {
+			return nil, fmt.Errorf("mapping %d: %w", i, err)
+		}
+	}
+
+	var addr *net.UDPAddr
+	var err error
+
+	switch protocol {
+	case E131:
+		for i, m := range config.Mappings {
+			if m.Universe < E131MinUniverse || m.Universe > E131MaxUniverse {
+				return nil, fmt.Errorf("mapping %d: E1.31 universe %d out of range [%d, %d]",
+					i, m.Universe, E131MinUniverse, E131MaxUniverse)
+			}
+		}
+		if config.Source == "" {
+			config.Source, _ = os.Hostname()
+		}
+		if config.Priority == 0 {
+			config.Priority = E131DefaultPriority
+		}
+		if config.Addr != "" {
+			addr, err = resolveUDPAddr(config.Addr, E131Port)
+		}
+	case ArtNet:
+		for i, m := range config.Mappings {
+			if m.Universe > ArtNetMaxUniverse {
+				return nil, fmt.Errorf("mapping %d: Art-Net universe %d out of range [0, %d]",
+					i, m.Universe, ArtNetMaxUniverse)
+			}
+		}
+		addr, err = resolveUDPAddr(config.Addr, ArtNetPort)
+	default:
+		return nil, fmt.Errorf("unknown DMX protocol %q", protocol)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := net.ListenUDP("udp", nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create UDP socket: %w", err)
+	}
+
+	s := &Sender{
+		protocol:  protocol,
+		config:    config,
+		conn:      conn,
+		addr:      addr,
+		universes: make(Universes),
+		sequences: make(map[uint16]uint8),
+		buf:       make([]byte, 0, e131HeaderSize+UniverseSize),
+	}
+
+	if _, err := rand.Read(s.cid[:]); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("cannot generate E1.31 CID: %w", err)
+	}
+
+	return s, nil
+}
+
+// resolveUDPAddr resolves addr, adding the default port if it has none. An
+// empty address is the broadcast address.
+func resolveUDPAddr(addr string, defaultPort int) (*net.UDPAddr, error) {
+	if addr == "" {
+		addr = net.IPv4bcast.String()
+	}
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, fmt.Sprint(defaultPort))
+	}
+
+	a, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve %q: %w", addr, err)
+	}
+	return a, nil
+}
+
+// Send packs the LEDs into universes and sends a packet for each universe.
+func (s *Sender) Send(leds leddraw.LEDStrip) error {
+	s.universes.Pack(leds, s.config.Mappings)
+
+	for _, universe := range s.universes.Sorted() {
+		data := s.universes[universe]
+		seq := s.sequences[universe] + 1
+		s.sequences[universe] = seq
+
+		addr := s.addr
+		switch s.protocol {
+		case E131:
+			if universe > E131MaxUniverse {
+				// The LEDs of the last mapping continue past the last
+				// universe, which receivers would drop.
+				return fmt.Errorf("cannot send universe %d: out of E1.31 range [%d, %d]",
+					universe, E131MinUniverse, E131MaxUniverse)
+			}
+			p := E131Packet{
+				CID:      s.cid,
+				Source:   s.config.Source,
+				Priority: s.config.Priority,
+				Sequence: seq,
+				Universe: universe,
+				Data:     data,
+			}
+			s.buf = p.AppendBinary(s.buf[:0])
+			if addr == nil {
+				addr = E131MulticastAddr(universe)
+			}
+		case ArtNet:
+			if universe > ArtNetMaxUniverse {
+				// Like with E1.31, the LEDs continue past the last
+				// universe, which would wrap around to universe 0.
+				return fmt.Errorf("cannot send universe %d: out of Art-Net range [0, %d]",
+					universe, ArtNetMaxUniverse)
+			}
+			if seq == 0 {
+				// 0 means that sequencing is disabled.
+				seq = 1
+				s.sequences[universe] = seq
+			}
+			p := ArtDmxPacket{
+				Sequence: seq,
+				Universe: universe,
+				Data:     data,
+			}
+			s.buf = p.AppendBinary(s.buf[:0])
+		}
+
+		if _, err := s.conn.WriteToUDP(s.buf, addr); err != nil {
+			return fmt.Errorf("cannot send universe %d: %w", universe, err)
+		}
+	}
+
+	return nil
+}
+
+// Close closes the underlying socket.
+func (s *Sender) Close() error {
+	return s.conn.Close()
+}
@@ -0,0 +1,227 @@
+package fseq
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+
+	"dev.acmcsuf.com/christmas/lib/animation"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Decode decodes an FSEQ file.
+func Decode(b []byte) (*Sequence, error) {
+	if !IsFSEQ(b) {
+		return nil, errors.New("not an FSEQ file")
+	}
+	if len(b) < 20 {
+		return nil, errShort
+	}
+
+	switch major := b[7]; major {
+	case 1:
+		return decodeV1(b)
+	case 2:
+		return decodeV2(b)
+	default:
+		return nil, fmt.Errorf("unsupported FSEQ version %d.%d", major, b[6])
+	}
+}
+
+type header struct {
+	dataOffset   int
+	channelCount int
+	frameCount   int
+	stepMs       int
+}
+
+func parseHeader(b []byte) header {
+	return header{
+		dataOffset:   int(le.Uint16(b[4:])),
+		channelCount: int(le.Uint32(b[10:])),
+		frameCount:   int(le.Uint32(b[14:])),
+		stepMs:       int(b[18]),
+	}
+}
+
+func (h header) newSequence(ranges []Range) (*Sequence, error) {
+	if h.stepMs == 0 {
+		return nil, errors.New("step time is 0")
+	}
+
+	frameSize := 0
+	for _, r := range ranges {
+		frameSize += r.Count
+	}
+	if frameSize != h.channelCount {
+		return nil, fmt.Errorf("sparse ranges have %d channels, expected %d", frameSize, h.channelCount)
+	}
+
+	return &Sequence{
+		StepMs:    animation.Milliseconds(h.stepMs),
+		Ranges:    ranges,
+		frameSize: frameSize,
+	}, nil
+}
+
+func decodeV1(b []byte) (*Sequence, error) {
+	h := parseHeader(b)
+
+	s, err := h.newSequence([]Range{{0, h.channelCount}})
+	if err != nil {
+		return nil, err
+	}
+
+	end := h.dataOffset + h.frameCount*h.channelCount
+	if end > len(b) {
+		return nil, errShort
+	}
+	s.Data = b[h.dataOffset:end]
+
+	return s, nil
+}
+
+type block struct {
+	firstFrame int
+	size       int
+}
+
+func decodeV2(b []byte) (*Sequence, error) {
+	if len(b) < 32 {
+		return nil, errShort
+	}
+
+	h := parseHeader(b)
+	compression := Compression(b[20] & 0x0F)
+	numBlocks := int(b[20]>>4)<<8 | int(b[21])
+	numRanges := int(b[22])
+
+	if 32+8*numBlocks+6*numRanges > len(b) {
+		return nil, errShort
+	}
+	if h.dataOffset > len(b) {
+		return nil, errShort
+	}
+
+	// Unused blocks are padded with zeros.
+	blocks := make([]block, 0, numBlocks)
+	for i := 0; i < numBlocks; i++ {
+		entry := b[32+8*i:]
+		blk := block{
+			firstFrame: int(le.Uint32(entry[0:])),
+			size:       int(le.Uint32(entry[4:])),
+		}
+		if blk.size > 0 {
+			blocks = append(blocks, blk)
+		}
+	}
+
+	ranges := []Range{{0, h.channelCount}}
+	if numRanges > 0 {
+		ranges = make([]Range, numRanges)
+		for i := range ranges {
+			entry := b[32+8*numBlocks+6*i:]
+			ranges[i] = Range{
+				Start: readUint24(entry[0:]),
+				Count: readUint24(entry[3:]),
+			}
+		}
+	}
+
+	s, err := h.newSequence(ranges)
+	if err != nil {
+		return nil, err
+	}
+
+	data := b[h.dataOffset:]
+	size := h.frameCount * h.channelCount
+
+	switch compression {
+	case CompressionNone:
+		if len(data) < size {
+			return nil, errShort
+		}
+		s.Data = data[:size]
+
+	case CompressionZstd, CompressionZlib:
+		s.Data, err = decompressBlocks(data, blocks, compression, h)
+		if err != nil {
+			return nil, err
+		}
+
+	default:
+		return nil, fmt.Errorf("unsupported compression %d", compression)
+	}
+
+	return s, nil
+}
+
+func decompressBlocks(data []byte, blocks []block, compression Compression, h header) ([]byte, error) {
+	var zr *zstd.Decoder
+	if compression == CompressionZstd {
+		var err error
+		zr, err = zstd.NewReader(nil)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+	}
+
+	out := make([]byte, 0, h.frameCount*h.channelCount)
+	for i, blk := range blocks {
+		if blk.size > len(data) {
+			return nil, fmt.Errorf("block %d: %w", i, errShort)
+		}
+		compressed := data[:blk.size]
+		data = data[blk.size:]
+
+		endFrame := h.frameCount
+		if i+1 < len(blocks) {
+			endFrame = blocks[i+1].firstFrame
+		}
+		if blk.firstFrame != len(out)/max(h.channelCount, 1) || endFrame < blk.firstFrame || endFrame > h.frameCount {
+			return nil, fmt.Errorf("block %d has invalid frames", i)
+		}
+		start := len(out)
+		size := (endFrame - blk.firstFrame) * h.channelCount
+
+		var err error
+		switch compression {
+		case CompressionZstd:
+			out, err = zr.DecodeAll(compressed, out)
+		case CompressionZlib:
+			out, err = inflate(compressed, out)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("block %d: %w", i, err)
+		}
+
+		// Blocks may hold more than their frames, such as padding.
+		if len(out)-start < size {
+			return nil, fmt.Errorf("block %d has %d bytes, expected %d", i, len(out)-start, size)
+		}
+		out = out[:start+size]
+	}
+
+	if len(out) != h.frameCount*h.channelCount {
+		return nil, fmt.Errorf("blocks have %d frames, expected %d", len(out)/max(h.channelCount, 1), h.frameCount)
+	}
+	return out, nil
+}
+
+func inflate(compressed, out []byte) ([]byte, error) {
+	zr, err := zlib.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return out, err
+	}
+	defer zr.Close()
+
+	buf := bytes.NewBuffer(out)
+	if _, err := io.Copy(buf, zr); err != nil {
+		return out, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,215 @@
+package fseq
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+
+	"dev.acmcsuf.com/christmas/lib/animation"
+	"dev.acmcsuf.com/christmas/lib/leddraw"
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	// blockSize is roughly how many bytes of channels go into each
+	// compressed block, so that players can start without decompressing the
+	// whole file.
+	blockSize = 1 << 20
+	// maxBlocks is the most blocks that a version 2.0 file can have.
+	maxBlocks = 255
+)
+
+// Producer is written to the file as the sequence producer.
+const Producer = "acm-christmas"
+
+// EncodeOpts are the options for Encode.
+type EncodeOpts struct {
+	// StepMs is the time between frames. It defaults to DefaultStepMs. FSEQ
+	// frames all last the same time, so the frames are sampled at this step.
+	StepMs animation.Milliseconds
+	// StartChannel is the channel of the first LED. If it is not 0, only the
+	// channels of the LEDs are stored as a sparse range.
+	StartChannel int
+	// Compression is how the channels are compressed.
+	Compression Compression
+	// Repeats is how many times the loop of a looping animation is written,
+	// since FSEQ files can't loop. It defaults to 1.
+	Repeats int
+}
+
+// Encode writes the frames as a version 2 FSEQ file. Every frame must have
+// the same number of LEDs.
+func Encode(w io.Writer, frames []animation.Frame[leddraw.LEDStrip], opts EncodeOpts) error {
+	if len(frames) == 0 {
+		return errors.New("no frames")
+	}
+	if opts.StepMs == 0 {
+		opts.StepMs = DefaultStepMs
+	}
+	if opts.StepMs > 0xFF {
+		return fmt.Errorf("step time %dms is too long", opts.StepMs)
+	}
+	if opts.StartChannel < 0 {
+		return fmt.Errorf("invalid start channel %d", opts.StartChannel)
+	}
+
+	numLEDs := len(frames[0].Image)
+	for i, frame := range frames {
+		if len(frame.Image) != numLEDs {
+			return fmt.Errorf("frame %d has %d LEDs, expected %d", i, len(frame.Image), numLEDs)
+		}
+	}
+
+	if numLEDs == 0 {
+		return errors.New("no LEDs")
+	}
+
+	channelCount := 3 * numLEDs
+	data := sample(animation.Unroll(frames, opts.Repeats), opts.StepMs)
+	frameCount := len(data) / channelCount
+
+	var ranges []Range
+	if opts.StartChannel > 0 {
+		ranges = []Range{{opts.StartChannel, channelCount}}
+	}
+
+	var blocks []block
+	switch opts.Compression {
+	case CompressionNone:
+	case CompressionZstd, CompressionZlib:
+		var err error
+		blocks, data, err = compressBlocks(data, channelCount, opts.Compression)
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported compression %d", opts.Compression)
+	}
+
+	varHeaderOffset := 32 + 8*len(blocks) + 6*len(ranges)
+
+	b := make([]byte, 32, varHeaderOffset)
+	copy(b, Magic)
+	b[6] = 0 // minor version
+	b[7] = 2 // major version
+	le.PutUint16(b[8:], uint16(varHeaderOffset))
+	le.PutUint32(b[10:], uint32(channelCount))
+	le.PutUint32(b[14:], uint32(frameCount))
+	b[18] = uint8(opts.StepMs)
+	b[20] = uint8(opts.Compression)
+	b[21] = uint8(len(blocks))
+	b[22] = uint8(len(ranges))
+
+	for _, blk := range blocks {
+		b = le.AppendUint32(b, uint32(blk.firstFrame))
+		b = le.AppendUint32(b, uint32(blk.size))
+	}
+	for _, r := range ranges {
+		b = appendUint24(b, r.Start)
+		b = appendUint24(b, r.Count)
+	}
+
+	b = appendVarHeader(b, "sp", Producer)
+	for len(b)%4 != 0 {
+		b = append(b, 0)
+	}
+	if len(b) > 0xFFFF {
+		return errors.New("header too long")
+	}
+	le.PutUint16(b[4:], uint16(len(b)))
+
+	if _, err := w.Write(b); err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	return nil
+}
+
+// sample returns the channels of the frames sampled every step.
+func sample(frames []animation.Frame[leddraw.LEDStrip], step animation.Milliseconds) []byte {
+	var total animation.Milliseconds
+	for _, frame := range frames {
+		total += frame.DurationMs
+	}
+	frameCount := max(int((total+step-1)/step), 1)
+
+	data := make([]byte, 0, frameCount*3*len(frames[0].Image))
+
+	var i int
+	var end animation.Milliseconds = frames[0].DurationMs
+	for f := 0; f < frameCount; f++ {
+		t := animation.Milliseconds(f) * step
+		for t >= end && i+1 < len(frames) {
+			i++
+			end += frames[i].DurationMs
+		}
+		for _, led := range frames[i].Image {
+			data = append(data, led.R, led.G, led.B)
+		}
+	}
+
+	return data
+}
+
+func compressBlocks(data []byte, channelCount int, compression Compression) ([]block, []byte, error) {
+	frameCount := len(data) / channelCount
+	framesPerBlock := max(blockSize/channelCount, 1)
+	framesPerBlock = max(framesPerBlock, (frameCount+maxBlocks-1)/maxBlocks)
+
+	var zw *zstd.Encoder
+	if compression == CompressionZstd {
+		var err error
+		zw, err = zstd.NewWriter(nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer zw.Close()
+	}
+
+	var blocks []block
+	var out []byte
+	for first := 0; first < frameCount; first += framesPerBlock {
+		end := min(first+framesPerBlock, frameCount)
+		frames := data[first*channelCount : end*channelCount]
+
+		start := len(out)
+		switch compression {
+		case CompressionZstd:
+			out = zw.EncodeAll(frames, out)
+		case CompressionZlib:
+			var err error
+			out, err = deflate(frames, out)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		blocks = append(blocks, block{firstFrame: first, size: len(out) - start})
+	}
+
+	return blocks, out, nil
+}
+
+func deflate(data, out []byte) ([]byte, error) {
+	buf := bytes.NewBuffer(out)
+	zw := zlib.NewWriter(buf)
+	if _, err := zw.Write(data); err != nil {
+		return out, err
+	}
+	if err := zw.Close(); err != nil {
+		return out, err
+	}
+	return buf.Bytes(), nil
+}
+
+// appendVarHeader appends a variable header with a null-terminated string.
+func appendVarHeader(b []byte, code, value string) []byte {
+	b = le.AppendUint16(b, uint16(4+len(value)+1))
+	b = append(b, code...)
+	b = append(b, value...)
+	return append(b, 0)
+}
@@ -0,0 +1,205 @@
+// Package fseq reads and writes FSEQ files, the sequence format used by
+// xLights and Falcon Player (FPP).
+//
+// An FSEQ file stores the value of every DMX-style channel for every frame,
+// with a fixed step time between frames. Versions 1 and 2 are supported,
+// including the zstd and zlib compressed blocks and the sparse channel ranges
+// of version 2. Each LED takes three consecutive channels in R, G, B order,
+// and LEDs are numbered like the points in led-points.csv.
+package fseq
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"dev.acmcsuf.com/christmas/lib/animation"
+	"dev.acmcsuf.com/christmas/lib/leddraw"
+)
+
+// Magic is the magic number at the start of every FSEQ file.
+const Magic = "PSEQ"
+
+// DefaultStepMs is the step time used by xLights by default.
+const DefaultStepMs = 50
+
+// Compression is how the channel data of a version 2 file is compressed.
+type Compression uint8
+
+const (
+	CompressionNone Compression = 0
+	CompressionZstd Compression = 1
+	CompressionZlib Compression = 2
+)
+
+// String implements fmt.Stringer.
+func (c Compression) String() string {
+	switch c {
+	case CompressionNone:
+		return "none"
+	case CompressionZstd:
+		return "zstd"
+	case CompressionZlib:
+		return "zlib"
+	default:
+		return fmt.Sprintf("Compression(%d)", c)
+	}
+}
+
+// ParseCompression parses the name of a compression as returned by String.
+func ParseCompression(s string) (Compression, error) {
+	for _, c := range []Compression{CompressionNone, CompressionZstd, CompressionZlib} {
+		if c.String() == s {
+			return c, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown compression %q", s)
+}
+
+// Range is a range of channels. Channels are numbered from 0, so channel 0
+// is shown as channel 1 in xLights.
+type Range struct {
+	Start int
+	Count int
+}
+
+// Sequence is a decoded FSEQ file.
+type Sequence struct {
+	// StepMs is the time between frames.
+	StepMs animation.Milliseconds
+	// Ranges are the channels stored in every frame, in order. Files without
+	// sparse ranges have a single range that starts at channel 0.
+	Ranges []Range
+	// Data holds the channels of every frame one after another.
+	Data []byte
+
+	frameSize int
+}
+
+// ReadFile reads the FSEQ file at the given path.
+func ReadFile(path string) (*Sequence, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := Decode(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+// IsFSEQ returns true if b starts like an FSEQ file.
+func IsFSEQ(b []byte) bool {
+	// Very old files start with FSEQ instead.
+	return len(b) >= 4 && (string(b[:4]) == Magic || string(b[:4]) == "FSEQ")
+}
+
+// FrameCount returns the number of frames in the sequence.
+func (s *Sequence) FrameCount() int {
+	if s.frameSize == 0 {
+		return 0
+	}
+	return len(s.Data) / s.frameSize
+}
+
+// ChannelCount returns the number of channels in the sequence, which is the
+// channel after the last channel that is stored.
+func (s *Sequence) ChannelCount() int {
+	var n int
+	for _, r := range s.Ranges {
+		n = max(n, r.Start+r.Count)
+	}
+	return n
+}
+
+// Step returns the time between frames as a time.Duration.
+func (s *Sequence) Step() time.Duration {
+	return time.Duration(s.StepMs) * time.Millisecond
+}
+
+// Mapping maps LEDs to channels.
+type Mapping struct {
+	// StartChannel is the channel of the red component of the first LED.
+	// Like Range, it is numbered from 0.
+	StartChannel int
+	// LEDCount is the number of LEDs, which is usually the number of points
+	// in led-points.csv. If it is 0, every channel from StartChannel on is
+	// mapped.
+	LEDCount int
+}
+
+// LEDCount returns the number of LEDs mapped from the sequence.
+func (s *Sequence) LEDCount(m Mapping) int {
+	if m.LEDCount > 0 {
+		return m.LEDCount
+	}
+	return max(s.ChannelCount()-m.StartChannel, 0) / 3
+}
+
+// ReadLEDs copies the colors of the LEDs in the given frame into dst, which
+// must have LEDCount LEDs. LEDs whose channels aren't stored are black.
+func (s *Sequence) ReadLEDs(dst leddraw.LEDStrip, m Mapping, frame int) {
+	data := s.Data[frame*s.frameSize : (frame+1)*s.frameSize]
+
+	dst.Clear()
+	for _, r := range s.Ranges {
+		channels := data[:r.Count]
+		data = data[r.Count:]
+
+		// Copy the channels in this range that belong to an LED.
+		for c := max(r.Start, m.StartChannel); c < r.Start+r.Count; c++ {
+			led := (c - m.StartChannel) / 3
+			if led >= len(dst) {
+				break
+			}
+			v := channels[c-r.Start]
+			switch (c - m.StartChannel) % 3 {
+			case 0:
+				dst[led].R = v
+			case 1:
+				dst[led].G = v
+			case 2:
+				dst[led].B = v
+			}
+		}
+	}
+}
+
+// LEDFrames returns the frames of the sequence as LED frames. Consecutive
+// frames that are the same are merged into one longer frame. FSEQ files
+// don't loop, so neither do the frames.
+func (s *Sequence) LEDFrames(m Mapping) []animation.Frame[leddraw.LEDStrip] {
+	var frames []animation.Frame[leddraw.LEDStrip]
+	for i := 0; i < s.FrameCount(); i++ {
+		leds := make(leddraw.LEDStrip, s.LEDCount(m))
+		s.ReadLEDs(leds, m, i)
+
+		if n := len(frames); n > 0 && slices.Equal(frames[n-1].Image, leds) {
+			frames[n-1].DurationMs += s.StepMs
+			continue
+		}
+
+		frames = append(frames, animation.Frame[leddraw.LEDStrip]{
+			Image:      leds,
+			DurationMs: s.StepMs,
+		})
+	}
+	return frames
+}
+
+func readUint24(b []byte) int {
+	return int(b[0]) | int(b[1])<<8 | int(b[2])<<16
+}
+
+func appendUint24(b []byte, v int) []byte {
+	return append(b, byte(v), byte(v>>8), byte(v>>16))
+}
+
+var le = binary.LittleEndian
+
+var errShort = errors.New("file too short")
@@ -0,0 +1,133 @@
+package fseq
+
+import (
+	"bytes"
+	"testing"
+
+	"dev.acmcsuf.com/christmas/lib/animation"
+	"dev.acmcsuf.com/christmas/lib/leddraw"
+	"github.com/alecthomas/assert/v2"
+)
+
+var (
+	red   = leddraw.LEDStrip{{R: 0xFF}, {R: 0xFF}}
+	green = leddraw.LEDStrip{{G: 0xFF}, {}}
+	blue  = leddraw.LEDStrip{{}, {B: 0xFF}}
+)
+
+func encode(t *testing.T, frames []animation.Frame[leddraw.LEDStrip], opts EncodeOpts) *Sequence {
+	t.Helper()
+
+	var buf bytes.Buffer
+	assert.NoError(t, Encode(&buf, frames, opts))
+
+	s, err := Decode(buf.Bytes())
+	assert.NoError(t, err)
+	return s
+}
+
+func TestRoundTrip(t *testing.T) {
+	frames := []animation.Frame[leddraw.LEDStrip]{
+		{Image: red, DurationMs: 100},
+		{Image: green, DurationMs: 50},
+		{Image: blue, DurationMs: 50},
+	}
+
+	for _, compression := range []Compression{CompressionNone, CompressionZstd, CompressionZlib} {
+		t.Run(compression.String(), func(t *testing.T) {
+			s := encode(t, frames, EncodeOpts{Compression: compression})
+			assert.Equal(t, DefaultStepMs, s.StepMs)
+			assert.Equal(t, 4, s.FrameCount())
+			assert.Equal(t, 6, s.ChannelCount())
+			assert.Equal(t, frames, s.LEDFrames(Mapping{}))
+		})
+	}
+}
+
+func TestEncodeSampling(t *testing.T) {
+	s := encode(t, []animation.Frame[leddraw.LEDStrip]{
+		{Image: red, DurationMs: 30},
+		{Image: green, DurationMs: 10}, // too short to be sampled
+		{Image: blue, DurationMs: 30, JumpBackAmount: 1},
+	}, EncodeOpts{StepMs: 20, Repeats: 2})
+
+	// 30 + (10 + 30) * 2 = 110ms, or 6 frames.
+	assert.Equal(t, 6, s.FrameCount())
+	assert.Equal(t, []animation.Frame[leddraw.LEDStrip]{
+		{Image: red, DurationMs: 40},
+		{Image: blue, DurationMs: 80},
+	}, s.LEDFrames(Mapping{}))
+}
+
+func TestSparseRanges(t *testing.T) {
+	frames := []animation.Frame[leddraw.LEDStrip]{{Image: blue, DurationMs: 50}}
+	s := encode(t, frames, EncodeOpts{StartChannel: 300})
+
+	assert.Equal(t, []Range{{300, 6}}, s.Ranges)
+	assert.Equal(t, 306, s.ChannelCount())
+	assert.Equal(t, frames, s.LEDFrames(Mapping{StartChannel: 300}))
+
+	// LEDs outside of the sparse ranges are black, and channels that don't
+	// belong to our LEDs are ignored.
+	assert.Equal(t, leddraw.LEDStrip{{}, {}, {B: 0xFF}, {}}, s.LEDFrames(Mapping{StartChannel: 297, LEDCount: 4})[0].Image)
+	assert.Equal(t, leddraw.LEDStrip{{}, {R: 0xFF}}, s.LEDFrames(Mapping{StartChannel: 302, LEDCount: 2})[0].Image)
+}
+
+func TestCompressedBlocks(t *testing.T) {
+	// Make frames big enough that every frame gets its own block.
+	frames := make([]animation.Frame[leddraw.LEDStrip], 3)
+	for i := range frames {
+		leds := make(leddraw.LEDStrip, blockSize/3)
+		leds[i].R = 0xFF
+		frames[i] = animation.Frame[leddraw.LEDStrip]{Image: leds, DurationMs: DefaultStepMs}
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, Encode(&buf, frames, EncodeOpts{Compression: CompressionZstd}))
+	assert.Equal(t, 3, int(buf.Bytes()[21]))
+
+	s, err := Decode(buf.Bytes())
+	assert.NoError(t, err)
+	assert.Equal(t, frames, s.LEDFrames(Mapping{}))
+}
+
+func TestDecodeV1(t *testing.T) {
+	b := make([]byte, 28, 40)
+	copy(b, Magic)
+	le.PutUint16(b[4:], 32) // channel data offset
+	b[6] = 0                // minor version
+	b[7] = 1                // major version
+	le.PutUint16(b[8:], 28)
+	le.PutUint32(b[10:], 6) // channels
+	le.PutUint32(b[14:], 2) // frames
+	b[18] = 25              // step time
+	b = append(b, 0, 0, 0, 0)
+	b = append(b, 0xFF, 0, 0, 0xFF, 0, 0)
+	b = append(b, 0, 0xFF, 0, 0, 0, 0)
+
+	s, err := Decode(b)
+	assert.NoError(t, err)
+	assert.Equal(t, []animation.Frame[leddraw.LEDStrip]{
+		{Image: red, DurationMs: 25},
+		{Image: green, DurationMs: 25},
+	}, s.LEDFrames(Mapping{}))
+}
+
+func TestDecodeErrors(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, Encode(&buf, []animation.Frame[leddraw.LEDStrip]{{Image: red, DurationMs: 100}}, EncodeOpts{
+		Compression: CompressionZstd,
+	}))
+	b := buf.Bytes()
+
+	_, err := Decode([]byte("nope"))
+	assert.Error(t, err)
+
+	_, err = Decode(b[:len(b)-1])
+	assert.Error(t, err)
+
+	bad := bytes.Clone(b)
+	bad[7] = 3
+	_, err = Decode(bad)
+	assert.Error(t, err)
+}
@@ -0,0 +1,246 @@
+package leddraw
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// BlendMode is how the colors of a layer are combined with the colors below
+// it.
+type BlendMode uint8
+
+const (
+	// BlendNormal replaces the colors below.
+	BlendNormal BlendMode = iota
+	// BlendAdd adds the colors together. Black leaves the colors below as
+	// they are.
+	BlendAdd
+	// BlendMultiply multiplies the colors, which darkens them. White leaves
+	// the colors below as they are.
+	BlendMultiply
+	// BlendScreen multiplies the inverse of the colors, which brightens them
+	// without clipping as much as BlendAdd. Black leaves the colors below as
+	// they are.
+	BlendScreen
+	// BlendMax takes the brightest of each channel. Black leaves the colors
+	// below as they are.
+	BlendMax
+)
+
+var blendModeNames = []string{
+	BlendNormal:   "normal",
+	BlendAdd:      "add",
+	BlendMultiply: "multiply",
+	BlendScreen:   "screen",
+	BlendMax:      "max",
+}
+
+// String returns the name of the blend mode.
+func (m BlendMode) String() string {
+	if int(m) < len(blendModeNames) {
+		return blendModeNames[m]
+	}
+	return fmt.Sprintf("BlendMode(%d)", m)
+}
+
+// ParseBlendMode parses the name of a blend mode, such as "screen".
+func ParseBlendMode(s string) (BlendMode, error) {
+	for m, name := range blendModeNames {
+		if strings.EqualFold(s, name) {
+			return BlendMode(m), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown blend mode %q", s)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (m BlendMode) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (m *BlendMode) UnmarshalText(text []byte) error {
+	v, err := ParseBlendMode(string(text))
+	if err != nil {
+		return err
+	}
+	*m = v
+	return nil
+}
+
+// blend blends one channel of a color onto the channel below it, both from 0
+// to 1.
+func (m BlendMode) blend(below, above float64) float64 {
+	switch m {
+	case BlendAdd:
+		return min(below+above, 1)
+	case BlendMultiply:
+		return below * above
+	case BlendScreen:
+		return 1 - (1-below)*(1-above)
+	case BlendMax:
+		return max(below, above)
+	default:
+		return above
+	}
+}
+
+// Layer describes a layer of a Compositor.
+type Layer struct {
+	// Name identifies the layer.
+	Name string
+	// Blend is how the layer is combined with the layers below it.
+	Blend BlendMode
+	// Opacity is how much the layer shows, from 0 (not at all) to 1.
+	Opacity float64
+	// Z orders the layers. Layers with a higher Z are drawn on top, and
+	// layers with the same Z are drawn in the order that they were added or
+	// moved to that Z.
+	Z int
+}
+
+// Validate returns an error if the layer can't be added to a Compositor.
+func (l Layer) Validate() error {
+	if l.Name == "" {
+		return fmt.Errorf("layer has no name")
+	}
+	if int(l.Blend) >= len(blendModeNames) {
+		return fmt.Errorf("layer %q: unknown blend mode %d", l.Name, l.Blend)
+	}
+	if !(l.Opacity >= 0 && l.Opacity <= 1) {
+		return fmt.Errorf("layer %q: opacity %g out of range [0, 1]", l.Name, l.Opacity)
+	}
+	return nil
+}
+
+// Compositor stacks layers of colors on top of a base LEDStrip, such as an
+// overlay or a notification flash on top of an animation. It is safe to use
+// from multiple goroutines.
+type Compositor struct {
+	mu     sync.Mutex
+	layers []*compositorLayer // from the bottom to the top
+}
+
+type compositorLayer struct {
+	Layer
+	leds LEDStrip // nil until the layer has colors
+}
+
+// NewCompositor creates a Compositor without any layers.
+func NewCompositor() *Compositor {
+	return &Compositor{}
+}
+
+// SetLayer adds a layer, or changes the layer with the same name. A new layer
+// has no colors, so it shows nothing until SetLayerLEDs is called.
+func (c *Compositor) SetLayer(layer Layer) error {
+	if err := layer.Validate(); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	l := &compositorLayer{Layer: layer}
+	if i := c.index(layer.Name); i != -1 {
+		l = c.layers[i]
+		if l.Z == layer.Z {
+			l.Layer = layer
+			return nil
+		}
+		// Moved layers go on top of the layers that are already there.
+		c.layers = slices.Delete(c.layers, i, i+1)
+		l.Layer = layer
+	}
+	c.layers = append(c.layers, l)
+
+	slices.SortStableFunc(c.layers, func(a, b *compositorLayer) int {
+		return a.Z - b.Z
+	})
+	return nil
+}
+
+// SetLayerLEDs sets the colors of the layer with the given name. The
+// compositor keeps leds, so they must not be changed afterwards. False is
+// returned if there is no such layer.
+func (c *Compositor) SetLayerLEDs(name string, leds LEDStrip) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	i := c.index(name)
+	if i == -1 {
+		return false
+	}
+	c.layers[i].leds = leds
+	return true
+}
+
+// RemoveLayer removes the layer with the given name. False is returned if
+// there is no such layer.
+func (c *Compositor) RemoveLayer(name string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	i := c.index(name)
+	if i == -1 {
+		return false
+	}
+	c.layers = slices.Delete(c.layers, i, i+1)
+	return true
+}
+
+// Layers returns the layers from the bottom to the top.
+func (c *Compositor) Layers() []Layer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	layers := make([]Layer, len(c.layers))
+	for i, l := range c.layers {
+		layers[i] = l.Layer
+	}
+	return layers
+}
+
+func (c *Compositor) index(name string) int {
+	return slices.IndexFunc(c.layers, func(l *compositorLayer) bool {
+		return l.Name == name
+	})
+}
+
+// Composite returns the colors of base with every layer blended on top of it.
+// If no layer shows anything, base itself is returned; otherwise, a new strip
+// is returned and base is left as it is.
+func (c *Compositor) Composite(base LEDStrip) LEDStrip {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	dst := base
+	copied := false
+	for _, l := range c.layers {
+		if l.leds == nil || l.Opacity == 0 {
+			continue
+		}
+		if !copied {
+			dst = append(LEDStrip(nil), base...)
+			copied = true
+		}
+
+		n := min(len(dst), len(l.leds))
+		for i := 0; i < n; i++ {
+			below, above := &dst[i], l.leds[i]
+			below.R = blendChannel(l.Blend, l.Opacity, below.R, above.R)
+			below.G = blendChannel(l.Blend, l.Opacity, below.G, above.G)
+			below.B = blendChannel(l.Blend, l.Opacity, below.B, above.B)
+		}
+	}
+	return dst
+}
+
+func blendChannel(mode BlendMode, opacity float64, below, above uint8) uint8 {
+	b := float64(below) / 0xFF
+	blended := mode.blend(b, float64(above)/0xFF)
+	return uint8(math.Round((b + (blended-b)*opacity) * 0xFF))
+}
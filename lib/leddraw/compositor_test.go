@@ -0,0 +1,103 @@
+package leddraw
+
+import (
+	"testing"
+
+	"dev.acmcsuf.com/christmas/lib/xcolor"
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestBlendMode(t *testing.T) {
+	gray := xcolor.RGB{R: 0x80, G: 0x80, B: 0x80}
+	red := xcolor.RGB{R: 0xFF, G: 0x40}
+
+	tests := []struct {
+		mode   BlendMode
+		expect xcolor.RGB
+	}{
+		{BlendNormal, red},
+		{BlendAdd, xcolor.RGB{R: 0xFF, G: 0xC0, B: 0x80}},
+		{BlendMultiply, xcolor.RGB{R: 0x80, G: 0x20}},
+		{BlendScreen, xcolor.RGB{R: 0xFF, G: 0xA0, B: 0x80}},
+		{BlendMax, xcolor.RGB{R: 0xFF, G: 0x80, B: 0x80}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.mode.String(), func(t *testing.T) {
+			c := NewCompositor()
+			assert.NoError(t, c.SetLayer(Layer{Name: "layer", Blend: test.mode, Opacity: 1}))
+			assert.True(t, c.SetLayerLEDs("layer", LEDStrip{red}))
+			assert.Equal(t, LEDStrip{test.expect}, c.Composite(LEDStrip{gray}))
+
+			parsed, err := ParseBlendMode(test.mode.String())
+			assert.NoError(t, err)
+			assert.Equal(t, test.mode, parsed)
+		})
+	}
+
+	_, err := ParseBlendMode("overlay")
+	assert.Error(t, err)
+}
+
+func TestCompositor(t *testing.T) {
+	t.Run("no_layers", func(t *testing.T) {
+		c := NewCompositor()
+		base := LEDStrip{white, black}
+		assert.Equal(t, base, c.Composite(base))
+
+		// Layers without colors don't show either.
+		assert.NoError(t, c.SetLayer(Layer{Name: "empty", Opacity: 1}))
+		assert.Equal(t, base, c.Composite(base))
+	})
+
+	t.Run("opacity", func(t *testing.T) {
+		c := NewCompositor()
+		assert.NoError(t, c.SetLayer(Layer{Name: "half", Opacity: 0.5}))
+		assert.True(t, c.SetLayerLEDs("half", LEDStrip{white, white}))
+
+		base := LEDStrip{black, white}
+		assert.Equal(t, LEDStrip{{R: 0x80, G: 0x80, B: 0x80}, white}, c.Composite(base))
+		assert.Equal(t, LEDStrip{black, white}, base)
+	})
+
+	t.Run("order", func(t *testing.T) {
+		c := NewCompositor()
+		assert.NoError(t, c.SetLayer(Layer{Name: "top", Opacity: 1, Z: 1}))
+		assert.NoError(t, c.SetLayer(Layer{Name: "bottom", Opacity: 1}))
+		assert.NoError(t, c.SetLayer(Layer{Name: "dim", Blend: BlendMultiply, Opacity: 1}))
+		assert.True(t, c.SetLayerLEDs("top", LEDStrip{white, black}))
+		assert.True(t, c.SetLayerLEDs("bottom", LEDStrip{black, white}))
+		assert.True(t, c.SetLayerLEDs("dim", LEDStrip{black, black}))
+
+		assert.Equal(t, []string{"bottom", "dim", "top"}, layerNames(c))
+		assert.Equal(t, LEDStrip{white, black}, c.Composite(LEDStrip{black, black}))
+
+		// Moving the top layer below the multiply makes it darken it too.
+		assert.NoError(t, c.SetLayer(Layer{Name: "top", Opacity: 1, Z: -1}))
+		assert.Equal(t, []string{"top", "bottom", "dim"}, layerNames(c))
+		assert.NoError(t, c.SetLayer(Layer{Name: "bottom", Opacity: 1, Z: -1}))
+		assert.Equal(t, []string{"top", "bottom", "dim"}, layerNames(c))
+		assert.Equal(t, LEDStrip{black, black}, c.Composite(LEDStrip{black, black}))
+
+		assert.True(t, c.RemoveLayer("dim"))
+		assert.False(t, c.RemoveLayer("dim"))
+		assert.False(t, c.SetLayerLEDs("dim", LEDStrip{black, black}))
+		assert.Equal(t, LEDStrip{black, white}, c.Composite(LEDStrip{black, black}))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		c := NewCompositor()
+		assert.Error(t, c.SetLayer(Layer{Opacity: 1}))
+		assert.Error(t, c.SetLayer(Layer{Name: "bright", Opacity: 2}))
+		assert.Error(t, c.SetLayer(Layer{Name: "unknown", Blend: 42, Opacity: 1}))
+		assert.Equal(t, 0, len(c.Layers()))
+	})
+}
+
+func layerNames(c *Compositor) []string {
+	var names []string
+	for _, l := range c.Layers() {
+		names = append(names, l.Name)
+	}
+	return names
+}
@@ -5,55 +5,282 @@ import (
 	"fmt"
 	"image"
 	"sync"
+	"time"
 
 	"dev.acmcsuf.com/christmas/lib/animation"
+	"golang.org/x/sync/errgroup"
 )
 
+// transitionFPS is the rate at which frames are blended during a transition.
+const transitionFPS = 60
+
 // LEDCanvas wraps an LEDCanvas and provides animation capabilities.
 // Frames are sent to the C channel.
+//
+// The canvas has two players so that it can transition from one animation to
+// the next: during a transition, the outgoing animation keeps playing and is
+// blended with the incoming one.
 type LEDCanvasAnimated struct {
 	C <-chan animation.Frame[LEDStrip]
 
-	player *animation.Player[LEDStrip]
-	canvas *LEDCanvas
-	adding sync.Mutex // lock
-	opts   LEDCanvasOpts
+	ch           chan animation.Frame[LEDStrip]
+	controlCh    chan animatedControl
+	players      [2]*animation.Player[LEDStrip]
+	transitioner *Transitioner
+	canvas       *LEDCanvas
+	adding       chan struct{} // holds a value while frames are being added
+	opts         LEDCanvasOpts
+
+	current   int // index of the player that frames are added to
+	currentMu sync.Mutex
+}
+
+// animatedControl is a request to clear or replace the animation. It is
+// handled by Run, which is the only one that reads from the players.
+type animatedControl struct {
+	replace    bool
+	transition Transition
+	done       chan int // receives the new active player
 }
 
 // NewLEDCanvasAnimated creates a new LEDCanvasAnimated.
 func NewLEDCanvasAnimated(ledPositions []image.Point, opts LEDCanvasOpts) (*LEDCanvasAnimated, error) {
+	transitioner := NewTransitioner(ledPositions)
+
 	canvas, err := NewLEDCanvas(ledPositions, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	c := &LEDCanvasAnimated{
-		player: animation.NewPlayer[LEDStrip](),
-		canvas: canvas,
-		opts:   opts,
-	}
-	c.C = c.player.C
-	return c, nil
+	ch := make(chan animation.Frame[LEDStrip])
+	return &LEDCanvasAnimated{
+		C:         ch,
+		ch:        ch,
+		controlCh: make(chan animatedControl),
+		adding:    make(chan struct{}, 1),
+		players: [2]*animation.Player[LEDStrip]{
+			animation.NewPlayer[LEDStrip](),
+			animation.NewPlayer[LEDStrip](),
+		},
+		transitioner: transitioner,
+		canvas:       canvas,
+		opts:         opts,
+	}, nil
+}
+
+// transitionState is a transition in progress.
+type transitionState struct {
+	Transition
+	from    int       // the outgoing player
+	started time.Time // zero until the incoming animation has a frame
 }
 
 // Run starts the animated canvas player.
 func (c *LEDCanvasAnimated) Run(ctx context.Context) error {
-	return c.player.Run(ctx)
+	errg, ctx := errgroup.WithContext(ctx)
+	for _, player := range c.players {
+		player := player
+		errg.Go(func() error { return player.Run(ctx) })
+	}
+	errg.Go(func() error { return c.mix(ctx) })
+	return errg.Wait()
+}
+
+// mixer passes the frames of the active player to C, blending them with the
+// outgoing player during a transition. It is only used by Run.
+type mixer struct {
+	c          *LEDCanvasAnimated
+	last       [2]LEDStrip // last frame of each player
+	shown      LEDStrip    // last frame sent to C
+	transition *transitionState
+	active     int
+	ticker     *time.Ticker
+}
+
+func (c *LEDCanvasAnimated) mix(ctx context.Context) error {
+	m := mixer{
+		c:      c,
+		ticker: time.NewTicker(time.Second / transitionFPS),
+	}
+	m.ticker.Stop()
+	defer m.ticker.Stop()
+
+	for {
+		var err error
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case ctrl := <-c.controlCh:
+			err = m.control(ctx, ctrl)
+		case frame := <-c.players[0].C:
+			err = m.frame(ctx, 0, frame)
+		case frame := <-c.players[1].C:
+			err = m.frame(ctx, 1, frame)
+		case now := <-m.ticker.C:
+			err = m.tick(ctx, now)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (m *mixer) send(ctx context.Context, frame animation.Frame[LEDStrip]) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case m.c.ch <- frame:
+		m.shown = frame.Image
+		return nil
+	}
+}
+
+func (m *mixer) control(ctx context.Context, ctrl animatedControl) error {
+	if !ctrl.replace {
+		m.transition = nil
+		m.ticker.Stop()
+		for i, player := range m.c.players {
+			if err := player.Clear(ctx); err != nil {
+				return err
+			}
+			m.last[i] = nil
+		}
+		ctrl.done <- m.active
+		return nil
+	}
+
+	// The incoming player is the one that isn't shown. If a transition is
+	// already in progress, its outgoing animation is dropped and the
+	// animation that it was transitioning to becomes the outgoing one.
+	in := 1 - m.active
+	if err := m.c.players[in].Clear(ctx); err != nil {
+		return err
+	}
+	m.last[in] = nil
+
+	m.ticker.Stop()
+	m.transition = &transitionState{Transition: ctrl.transition, from: m.active}
+	m.active = in
+
+	if ctrl.transition.IsCut() {
+		if err := m.endTransition(ctx); err != nil {
+			return err
+		}
+	}
+
+	m.c.currentMu.Lock()
+	m.c.current = in
+	m.c.currentMu.Unlock()
+
+	ctrl.done <- in
+	return nil
+}
+
+// frame handles a frame played by player i. Outside of transitions, frames of
+// the active player are sent as they are. During a transition, frames are
+// blended on every tick instead, starting from the first frame of the
+// incoming animation.
+func (m *mixer) frame(ctx context.Context, i int, frame animation.Frame[LEDStrip]) error {
+	m.last[i] = frame.Image
+
+	switch {
+	case i != m.active:
+		return nil
+	case m.transition == nil:
+		return m.send(ctx, frame)
+	case m.transition.started.IsZero():
+		m.transition.started = time.Now()
+		m.ticker.Reset(time.Second / transitionFPS)
+	}
+	return nil
+}
+
+func (m *mixer) tick(ctx context.Context, now time.Time) error {
+	to := m.last[m.active]
+
+	progress := float64(now.Sub(m.transition.started)) / float64(m.transition.Duration)
+	if progress >= 1 {
+		if err := m.endTransition(ctx); err != nil {
+			return err
+		}
+		return m.send(ctx, animation.Frame[LEDStrip]{Image: to})
+	}
+
+	from := m.last[m.transition.from]
+	if from == nil {
+		from = m.shown
+	}
+	if len(from) != len(to) {
+		// Nothing was shown before, so fade in from black.
+		from = make(LEDStrip, len(to))
+	}
+
+	blended := make(LEDStrip, len(to))
+	m.c.transitioner.Blend(blended, from, to, m.transition.Kind, progress)
+	return m.send(ctx, animation.Frame[LEDStrip]{
+		Image:      blended,
+		DurationMs: animation.DurationToMs(time.Second / transitionFPS),
+	})
+}
+
+// endTransition stops the outgoing player once it is no longer shown.
+func (m *mixer) endTransition(ctx context.Context) error {
+	m.ticker.Stop()
+	if err := m.c.players[m.transition.from].Clear(ctx); err != nil {
+		return err
+	}
+	m.last[m.transition.from] = nil
+	m.transition = nil
+	return nil
+}
+
+// player returns the player that frames are added to.
+func (c *LEDCanvasAnimated) player() *animation.Player[LEDStrip] {
+	c.currentMu.Lock()
+	defer c.currentMu.Unlock()
+	return c.players[c.current]
+}
+
+// tryLockAdding returns false if frames are already being added.
+func (c *LEDCanvasAnimated) tryLockAdding() bool {
+	select {
+	case c.adding <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// lockAdding waits until no frames are being added.
+func (c *LEDCanvasAnimated) lockAdding(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case c.adding <- struct{}{}:
+		return nil
+	}
+}
+
+func (c *LEDCanvasAnimated) unlockAdding() {
+	<-c.adding
 }
 
 // AddFrames adds frames to the animated canvas.
 func (c *LEDCanvasAnimated) AddFrames(ctx context.Context, images []animation.Frame[*image.RGBA]) error {
-	if !c.adding.TryLock() {
+	if !c.tryLockAdding() {
 		return fmt.Errorf("cannot add frames: already adding frames")
 	}
-	defer c.adding.Unlock()
+	defer c.unlockAdding()
 
+	player := c.player()
 	for i, frame := range images {
 		rendered, err := renderCanvas(c.canvas, frame)
 		if err != nil {
 			return fmt.Errorf("cannot render frame %d: %w", i, err)
 		}
-		if err := c.player.AddFrame(ctx, rendered); err != nil {
+		if err := player.AddFrame(ctx, rendered); err != nil {
 			return fmt.Errorf("cannot add frame %d: %w", i, err)
 		}
 	}
@@ -61,14 +288,108 @@ func (c *LEDCanvasAnimated) AddFrames(ctx context.Context, images []animation.Fr
 	return nil
 }
 
+// AddLEDFrames adds frames that are already rendered onto the LEDs to the
+// animated canvas.
+func (c *LEDCanvasAnimated) AddLEDFrames(ctx context.Context, frames []animation.Frame[LEDStrip]) error {
+	if !c.tryLockAdding() {
+		return fmt.Errorf("cannot add frames: already adding frames")
+	}
+	defer c.unlockAdding()
+
+	return c.addLEDFrames(ctx, c.player(), frames)
+}
+
+// ReplaceLEDFrames replaces the animation with the given frames, which are
+// already rendered onto the LEDs, using the given transition. Frames added
+// afterwards are added to the new animation.
+func (c *LEDCanvasAnimated) ReplaceLEDFrames(ctx context.Context, frames []animation.Frame[LEDStrip], transition Transition) error {
+	if !c.tryLockAdding() {
+		return fmt.Errorf("cannot replace frames: already adding frames")
+	}
+	defer c.unlockAdding()
+
+	active, err := c.control(ctx, animatedControl{replace: true, transition: transition})
+	if err != nil {
+		return err
+	}
+
+	return c.addLEDFrames(ctx, c.players[active], frames)
+}
+
+// PlayLEDSource replaces the animation with the frames of src, which are
+// already rendered onto the LEDs, using the given transition, and plays them
+// until src ends or the context is canceled. It waits for other frames to be
+// added first, and frames can't be added by anything else until it returns.
+func (c *LEDCanvasAnimated) PlayLEDSource(ctx context.Context, src animation.FrameSource[LEDStrip], transition Transition) error {
+	if err := c.lockAdding(ctx); err != nil {
+		return err
+	}
+	defer c.unlockAdding()
+
+	// The animation is only replaced once the first frame is ready, so slow
+	// sources don't leave the LEDs dark in the meantime.
+	var player *animation.Player[LEDStrip]
+	return animation.Feed(ctx, src, func(frame animation.Frame[LEDStrip]) error {
+		if player == nil {
+			active, err := c.control(ctx, animatedControl{replace: true, transition: transition})
+			if err != nil {
+				return err
+			}
+			player = c.players[active]
+		}
+		return player.AddFrame(ctx, frame)
+	})
+}
+
+func (c *LEDCanvasAnimated) addLEDFrames(ctx context.Context, player *animation.Player[LEDStrip], frames []animation.Frame[LEDStrip]) error {
+	for i, frame := range frames {
+		if err := player.AddFrame(ctx, frame); err != nil {
+			return fmt.Errorf("cannot add frame %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// ClearFrames removes all frames from the animated canvas and stops playing
+// them, cancelling any transition.
+func (c *LEDCanvasAnimated) ClearFrames(ctx context.Context) error {
+	_, err := c.control(ctx, animatedControl{})
+	return err
+}
+
+// Queued returns the number of frames that are waiting to be played, including
+// the frames of the outgoing animation during a transition.
+func (c *LEDCanvasAnimated) Queued(ctx context.Context) (int, error) {
+	var total int
+	for _, player := range c.players {
+		queued, err := player.Queued(ctx)
+		if err != nil {
+			return 0, err
+		}
+		total += queued
+	}
+	return total, nil
+}
+
+func (c *LEDCanvasAnimated) control(ctx context.Context, ctrl animatedControl) (active int, err error) {
+	ctrl.done = make(chan int, 1)
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	case c.controlCh <- ctrl:
+	}
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	case active = <-ctrl.done:
+		return active, nil
+	}
+}
+
 func renderCanvas(canvas *LEDCanvas, frame animation.Frame[*image.RGBA]) (animation.Frame[LEDStrip], error) {
 	if err := canvas.Render(frame.Image); err != nil {
 		return animation.Frame[LEDStrip]{}, err
 	}
 
-	return animation.Frame[LEDStrip]{
-		Image:          append(LEDStrip(nil), canvas.LEDs()...),
-		JumpBackAmount: frame.JumpBackAmount,
-		DurationMs:     frame.DurationMs,
-	}, nil
+	return animation.WithImage(frame, append(LEDStrip(nil), canvas.LEDs()...)), nil
 }
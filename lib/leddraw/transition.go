@@ -0,0 +1,152 @@
+package leddraw
+
+import (
+	"fmt"
+	"image"
+	"math/rand"
+	"strings"
+	"time"
+
+	"dev.acmcsuf.com/christmas/lib/xcolor"
+	"dev.acmcsuf.com/christmas/lib/xdraw"
+)
+
+// TransitionKind is how one animation changes into the next.
+type TransitionKind uint8
+
+const (
+	// TransitionCut switches to the next animation immediately.
+	TransitionCut TransitionKind = iota
+	// TransitionCrossfade fades every LED from one animation to the next.
+	TransitionCrossfade
+	// TransitionWipe switches the LEDs from the bottom of the tree to the
+	// top.
+	TransitionWipe
+	// TransitionDissolve switches the LEDs one by one in a random order.
+	TransitionDissolve
+)
+
+var transitionNames = []string{
+	TransitionCut:       "cut",
+	TransitionCrossfade: "crossfade",
+	TransitionWipe:      "wipe",
+	TransitionDissolve:  "dissolve",
+}
+
+// String returns the name of the transition kind.
+func (k TransitionKind) String() string {
+	if int(k) < len(transitionNames) {
+		return transitionNames[k]
+	}
+	return fmt.Sprintf("TransitionKind(%d)", k)
+}
+
+// ParseTransitionKind parses the name of a transition kind, such as
+// "crossfade".
+func ParseTransitionKind(s string) (TransitionKind, error) {
+	for k, name := range transitionNames {
+		if strings.EqualFold(s, name) {
+			return TransitionKind(k), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown transition %q", s)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (k TransitionKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (k *TransitionKind) UnmarshalText(text []byte) error {
+	v, err := ParseTransitionKind(string(text))
+	if err != nil {
+		return err
+	}
+	*k = v
+	return nil
+}
+
+// Transition is a transition between two animations.
+type Transition struct {
+	Kind TransitionKind
+	// Duration is how long the transition takes. A zero duration is a cut.
+	Duration time.Duration
+}
+
+// IsCut returns true if the transition switches immediately.
+func (t Transition) IsCut() bool {
+	return t.Kind == TransitionCut || t.Duration <= 0
+}
+
+// Edge widths of the transitions as a fraction of the whole transition. The
+// wider the edge, the longer each LED takes to fade.
+const (
+	wipeEdge     = 0.15
+	dissolveEdge = 0.1
+)
+
+// Transitioner blends the frames of two animations during a transition. Each
+// LED has a threshold in [0, 1) that decides when it fades during the
+// transition, which gives the shape of the transition.
+type Transitioner struct {
+	heights []float64 // from 0 at the bottom to 1 at the top
+	random  []float64
+}
+
+// NewTransitioner creates a Transitioner for LEDs at the given positions.
+func NewTransitioner(ledPositions []image.Point) *Transitioner {
+	t := &Transitioner{
+		heights: make([]float64, len(ledPositions)),
+		random:  make([]float64, len(ledPositions)),
+	}
+
+	box := xdraw.BoundingBox(ledPositions)
+	h := float64(box.Dy() - 1)
+	for i, pt := range ledPositions {
+		if h > 0 {
+			// Y grows downwards in images.
+			t.heights[i] = float64(box.Max.Y-1-pt.Y) / h
+		}
+	}
+
+	// The dissolve order is fixed so that a dissolve looks the same every
+	// time.
+	rng := rand.New(rand.NewSource(1))
+	for i := range t.random {
+		t.random[i] = rng.Float64()
+	}
+
+	return t
+}
+
+// Blend writes the LEDs at the given progress in [0, 1] of a transition from
+// one frame to another into dst. All strips must have as many LEDs as the
+// Transitioner.
+func (t *Transitioner) Blend(dst, from, to LEDStrip, kind TransitionKind, progress float64) {
+	var thresholds []float64
+	var edge float64
+
+	switch kind {
+	case TransitionCut:
+		copy(dst, to)
+		return
+	case TransitionWipe:
+		thresholds, edge = t.heights, wipeEdge
+	case TransitionDissolve:
+		thresholds, edge = t.random, dissolveEdge
+	default:
+		edge = 1
+	}
+
+	for i := range dst {
+		var threshold float64
+		if thresholds != nil {
+			threshold = thresholds[i]
+		}
+		// Scale the thresholds so that the last LED has finished fading by
+		// the end of the transition.
+		mix := (progress - threshold*(1-edge)) / edge
+		dst[i] = xcolor.Lerp(from[i], to[i], mix)
+	}
+}
@@ -0,0 +1,89 @@
+package leddraw
+
+import (
+	"image"
+	"testing"
+
+	"dev.acmcsuf.com/christmas/lib/xcolor"
+	"github.com/alecthomas/assert/v2"
+)
+
+var (
+	black = xcolor.RGB{}
+	white = xcolor.RGB{R: 0xFF, G: 0xFF, B: 0xFF}
+)
+
+// column is a column of LEDs from the top of the tree to the bottom.
+var column = []image.Point{{0, 0}, {0, 10}, {0, 20}, {0, 30}}
+
+func uniformStrip(n int, c xcolor.RGB) LEDStrip {
+	strip := make(LEDStrip, n)
+	for i := range strip {
+		strip[i] = c
+	}
+	return strip
+}
+
+func blend(t *Transitioner, kind TransitionKind, progress float64) LEDStrip {
+	from := uniformStrip(len(column), black)
+	to := uniformStrip(len(column), white)
+	dst := make(LEDStrip, len(column))
+	t.Blend(dst, from, to, kind, progress)
+	return dst
+}
+
+func TestTransitionBlend(t *testing.T) {
+	transitioner := NewTransitioner(column)
+
+	for _, kind := range []TransitionKind{TransitionCut, TransitionCrossfade, TransitionWipe, TransitionDissolve} {
+		t.Run(kind.String(), func(t *testing.T) {
+			if kind != TransitionCut {
+				assert.Equal(t, uniformStrip(len(column), black), blend(transitioner, kind, 0))
+			}
+			assert.Equal(t, uniformStrip(len(column), white), blend(transitioner, kind, 1))
+		})
+	}
+
+	t.Run("crossfade", func(t *testing.T) {
+		leds := blend(transitioner, TransitionCrossfade, 0.5)
+		for _, c := range leds {
+			assert.Equal(t, leds[0], c)
+		}
+		assert.True(t, leds[0].R > 0x70 && leds[0].R < 0x90, "not halfway: %v", leds[0])
+	})
+
+	t.Run("wipe", func(t *testing.T) {
+		// Halfway through, the bottom has switched and the top hasn't.
+		leds := blend(transitioner, TransitionWipe, 0.5)
+		assert.Equal(t, white, leds[3])
+		assert.Equal(t, black, leds[0])
+		for i := 1; i < len(leds); i++ {
+			assert.True(t, leds[i].R >= leds[i-1].R, "LED %d is darker than the one above", i)
+		}
+	})
+
+	t.Run("dissolve", func(t *testing.T) {
+		// The same LEDs dissolve first every time.
+		assert.Equal(t,
+			blend(transitioner, TransitionDissolve, 0.5),
+			blend(NewTransitioner(column), TransitionDissolve, 0.5))
+	})
+}
+
+func TestTransitionKind(t *testing.T) {
+	for _, kind := range []TransitionKind{TransitionCut, TransitionCrossfade, TransitionWipe, TransitionDissolve} {
+		parsed, err := ParseTransitionKind(kind.String())
+		assert.NoError(t, err)
+		assert.Equal(t, kind, parsed)
+	}
+
+	parsed, err := ParseTransitionKind("Crossfade")
+	assert.NoError(t, err)
+	assert.Equal(t, TransitionCrossfade, parsed)
+
+	_, err = ParseTransitionKind("spin")
+	assert.Error(t, err)
+
+	assert.True(t, Transition{Kind: TransitionWipe}.IsCut())
+	assert.False(t, Transition{Kind: TransitionWipe, Duration: 1}.IsCut())
+}
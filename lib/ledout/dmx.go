@@ -0,0 +1,31 @@
+package ledout
+
+import (
+	"dev.acmcsuf.com/christmas/lib/dmx"
+	"dev.acmcsuf.com/christmas/lib/leddraw"
+)
+
+// DMX is a driver that sends the colors to pixel controllers over the network
+// using E1.31 or Art-Net.
+type DMX struct {
+	sender *dmx.Sender
+}
+
+// NewDMX creates a new DMX driver using the given protocol.
+func NewDMX(protocol dmx.Protocol, cfg dmx.Config) (*DMX, error) {
+	sender, err := dmx.NewSender(protocol, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &DMX{sender}, nil
+}
+
+// Write implements Writer.
+func (d *DMX) Write(leds leddraw.LEDStrip) error {
+	return d.sender.Send(leds)
+}
+
+// Close implements Driver.
+func (d *DMX) Close() error {
+	return d.sender.Close()
+}
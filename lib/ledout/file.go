@@ -0,0 +1,119 @@
+package ledout
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"dev.acmcsuf.com/christmas/lib/leddraw"
+)
+
+// FileFrame is a single line written by the File driver.
+type FileFrame struct {
+	// TimeMs is the time of the write in milliseconds since the driver was
+	// opened.
+	TimeMs int64 `json:"time_ms"`
+	// LEDs is the colors that were written.
+	LEDs leddraw.LEDStrip `json:"leds"`
+}
+
+// File is a driver that writes every strip as a JSON FileFrame line. This is
+// useful to check what a tool would show without the LEDs.
+type File struct {
+	w     *bufio.Writer
+	c     io.Closer
+	start time.Time
+	mu    sync.Mutex
+}
+
+// NewFile creates a new File driver that writes to w. If w is an io.Closer, it
+// is closed when the driver is closed.
+func NewFile(w io.Writer) *File {
+	c, _ := w.(io.Closer)
+	return &File{
+		w:     bufio.NewWriter(w),
+		c:     c,
+		start: time.Now(),
+	}
+}
+
+// OpenFile creates a new File driver that writes to the file at path. The file
+// is truncated if it already exists. If path is "-", stdout is used.
+func OpenFile(path string) (*File, error) {
+	if path == "-" {
+		return NewFile(nopCloser{os.Stdout}), nil
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewFile(f), nil
+}
+
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
+
+// Write implements Writer.
+func (d *File) Write(leds leddraw.LEDStrip) error {
+	b, err := json.Marshal(FileFrame{
+		TimeMs: time.Since(d.start).Milliseconds(),
+		LEDs:   leds,
+	})
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.w.Write(b)
+	d.w.WriteByte('\n')
+	return d.w.Flush()
+}
+
+// Close implements Driver.
+func (d *File) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := d.w.Flush(); err != nil {
+		return err
+	}
+	if d.c != nil {
+		return d.c.Close()
+	}
+	return nil
+}
+
+// Recorder is a driver that keeps every strip written to it in memory. It is
+// mostly useful for tests.
+type Recorder struct {
+	strips []leddraw.LEDStrip
+	mu     sync.Mutex
+}
+
+// Write implements Writer.
+func (d *Recorder) Write(leds leddraw.LEDStrip) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.strips = append(d.strips, append(leddraw.LEDStrip(nil), leds...))
+	return nil
+}
+
+// Close implements Driver.
+func (d *Recorder) Close() error { return nil }
+
+// Strips returns the strips written so far.
+func (d *Recorder) Strips() []leddraw.LEDStrip {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return append([]leddraw.LEDStrip(nil), d.strips...)
+}
@@ -0,0 +1,89 @@
+package ledout
+
+import (
+	"strings"
+
+	"dev.acmcsuf.com/christmas/lib/dmx"
+	"github.com/spf13/pflag"
+)
+
+// Flags is a set of command-line flags that describe a Config.
+type Flags struct {
+	fs          *pflag.FlagSet
+	cfg         Config
+	configFile  string
+	dmxUniverse uint16
+}
+
+// RegisterFlags registers the flags for choosing and configuring a driver onto
+// the given flag set. Config must be called after the flags are parsed.
+func RegisterFlags(fs *pflag.FlagSet) *Flags {
+	f := &Flags{
+		fs:  fs,
+		cfg: DefaultConfig(),
+	}
+
+	fs.StringVar(&f.configFile, "led-config", "", "path to a JSON file with the LED output config, overridden by the other LED flags")
+	fs.StringVar(&f.cfg.Driver, "led-driver", f.cfg.Driver, "LED output driver, one of "+strings.Join(DriverNames(), ", "))
+	fs.StringVar(&f.cfg.File, "led-file", f.cfg.File, "file for the file LED driver to write to")
+	fs.StringVar(&f.cfg.WS281x.ColorOrder, "ws281x-order", f.cfg.WS281x.ColorOrder, "color order of the WS281x LEDs")
+	fs.UintVar(&f.cfg.WS281x.PWMFrequency, "ws281x-freq", f.cfg.WS281x.PWMFrequency, "PWM frequency of the WS281x LEDs in Hz")
+	fs.IntVar(&f.cfg.WS281x.DMAChannel, "ws281x-dma", f.cfg.WS281x.DMAChannel, "DMA channel for the WS281x LEDs")
+	fs.IntSliceVar(&f.cfg.WS281x.GPIOPins, "ws281x-gpio", f.cfg.WS281x.GPIOPins, "GPIO pins of the WS281x LEDs")
+	fs.StringVar(&f.cfg.DMX.Addr, "dmx-addr", f.cfg.DMX.Addr, "address of the E1.31 or Art-Net receiver (default: multicast or broadcast)")
+	fs.StringVar(&f.cfg.OPC.Addr, "opc-server", f.cfg.OPC.Addr, "address of the Open Pixel Control server to send to")
+	fs.Uint8Var(&f.cfg.OPC.Channel, "opc-channel", f.cfg.OPC.Channel, "Open Pixel Control channel to send to, 0 for all")
+	fs.Uint16Var(&f.dmxUniverse, "dmx-universe", 1, "DMX universe of the first LED, use --led-config for more complex mappings")
+
+	return f
+}
+
+// Config returns the config described by the flags. If a config file is
+// given, it is loaded and the flags that are explicitly set are applied on
+// top of it.
+func (f *Flags) Config() (Config, error) {
+	cfg := f.cfg
+	if f.configFile != "" {
+		var err error
+		cfg, err = LoadConfig(f.configFile)
+		if err != nil {
+			return cfg, err
+		}
+	}
+
+	f.fs.Visit(func(flag *pflag.Flag) {
+		switch flag.Name {
+		case "led-driver":
+			cfg.Driver = f.cfg.Driver
+		case "led-file":
+			cfg.File = f.cfg.File
+		case "ws281x-order":
+			cfg.WS281x.ColorOrder = f.cfg.WS281x.ColorOrder
+		case "ws281x-freq":
+			cfg.WS281x.PWMFrequency = f.cfg.WS281x.PWMFrequency
+		case "ws281x-dma":
+			cfg.WS281x.DMAChannel = f.cfg.WS281x.DMAChannel
+		case "ws281x-gpio":
+			cfg.WS281x.GPIOPins = f.cfg.WS281x.GPIOPins
+		case "dmx-addr":
+			cfg.DMX.Addr = f.cfg.DMX.Addr
+		case "opc-server":
+			cfg.OPC.Addr = f.cfg.OPC.Addr
+		case "opc-channel":
+			cfg.OPC.Channel = f.cfg.OPC.Channel
+		case "dmx-universe":
+			cfg.DMX.Mappings = []dmx.Mapping{{Universe: f.dmxUniverse}}
+		}
+	})
+
+	return cfg, nil
+}
+
+// Open opens the driver described by the flags for the given number of LEDs.
+func (f *Flags) Open(numLEDs int) (Driver, error) {
+	cfg, err := f.Config()
+	if err != nil {
+		return nil, err
+	}
+	return Open(cfg, numLEDs)
+}
@@ -0,0 +1,126 @@
+// Package ledout provides drivers that write LED colors to an output. The
+// output is usually the actual LED strip, but it can also be a stand-in for
+// testing.
+package ledout
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"dev.acmcsuf.com/christmas/lib/dmx"
+	"dev.acmcsuf.com/christmas/lib/leddraw"
+)
+
+// Writer writes colors to LEDs.
+type Writer interface {
+	// Write writes the given colors to the LEDs. The strip must not be
+	// retained after the method returns.
+	Write(leddraw.LEDStrip) error
+}
+
+// Driver is a Writer that must be closed after use.
+type Driver interface {
+	Writer
+	io.Closer
+}
+
+// Config is the configuration for opening a Driver.
+type Config struct {
+	// Driver is the name of the driver to use. See Drivers for the list of
+	// names.
+	Driver string `json:"driver"`
+	// WS281x is the configuration for the ws281x driver.
+	WS281x WS281xConfig `json:"ws281x"`
+	// File is the path of the file that the file driver writes to. "-" means
+	// stdout.
+	File string `json:"file,omitempty"`
+	// DMX is the configuration for the e131 and artnet drivers.
+	DMX dmx.Config `json:"dmx"`
+	// OPC is the configuration for the opc driver.
+	OPC OPCConfig `json:"opc"`
+}
+
+// DefaultConfig returns the default configuration, which drives the WS281x
+// LEDs on the tree.
+func DefaultConfig() Config {
+	return Config{
+		Driver: "ws281x",
+		WS281x: DefaultWS281xConfig(),
+		File:   "-",
+		OPC:    OPCConfig{Addr: "localhost"},
+	}
+}
+
+// LoadConfig reads the JSON configuration file at path. Fields that are not in
+// the file are left as their defaults.
+func LoadConfig(path string) (Config, error) {
+	cfg := DefaultConfig()
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, fmt.Errorf("cannot read LED output config: %w", err)
+	}
+
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		return cfg, fmt.Errorf("cannot parse LED output config: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// Drivers maps each driver name to a function that opens it for the given
+// number of LEDs.
+var Drivers = map[string]func(cfg Config, numLEDs int) (Driver, error){
+	"ws281x": func(cfg Config, numLEDs int) (Driver, error) {
+		return NewWS281x(cfg.WS281x, numLEDs)
+	},
+	"log": func(cfg Config, numLEDs int) (Driver, error) {
+		return NewLog(), nil
+	},
+	"noop": func(cfg Config, numLEDs int) (Driver, error) {
+		return Noop{}, nil
+	},
+	"file": func(cfg Config, numLEDs int) (Driver, error) {
+		return OpenFile(cfg.File)
+	},
+	"e131": func(cfg Config, numLEDs int) (Driver, error) {
+		return NewDMX(dmx.E131, cfg.DMX)
+	},
+	"artnet": func(cfg Config, numLEDs int) (Driver, error) {
+		return NewDMX(dmx.ArtNet, cfg.DMX)
+	},
+	"opc": func(cfg Config, numLEDs int) (Driver, error) {
+		return DialOPC(cfg.OPC)
+	},
+}
+
+// DriverNames returns the sorted names of all drivers.
+func DriverNames() []string {
+	names := make([]string, 0, len(Drivers))
+	for name := range Drivers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Open opens the driver described by the config for the given number of LEDs.
+func Open(cfg Config, numLEDs int) (Driver, error) {
+	open, ok := Drivers[cfg.Driver]
+	if !ok {
+		return nil, fmt.Errorf(
+			"unknown LED driver %q, must be one of %s",
+			cfg.Driver, strings.Join(DriverNames(), ", "))
+	}
+
+	d, err := open(cfg, numLEDs)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open %s LED driver: %w", cfg.Driver, err)
+	}
+
+	return d, nil
+}
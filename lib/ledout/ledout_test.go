@@ -0,0 +1,95 @@
+package ledout
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"dev.acmcsuf.com/christmas/lib/dmx"
+	"dev.acmcsuf.com/christmas/lib/leddraw"
+	"dev.acmcsuf.com/christmas/lib/xcolor"
+	"github.com/alecthomas/assert/v2"
+	"github.com/spf13/pflag"
+)
+
+func TestFlags(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "ledout.json")
+	err := os.WriteFile(configFile, []byte(`{
+		"driver": "file",
+		"ws281x": {"dma_channel": 5}
+	}`), 0644)
+	assert.NoError(t, err)
+
+	t.Run("defaults", func(t *testing.T) {
+		cfg := parseFlags(t)
+		assert.Equal(t, DefaultConfig(), cfg)
+	})
+
+	t.Run("flags", func(t *testing.T) {
+		cfg := parseFlags(t, "--led-driver", "log", "--ws281x-gpio", "18,13", "--dmx-universe", "4")
+		assert.Equal(t, "log", cfg.Driver)
+		assert.Equal(t, []int{18, 13}, cfg.WS281x.GPIOPins)
+		assert.Equal(t, []dmx.Mapping{{Universe: 4}}, cfg.DMX.Mappings)
+	})
+
+	t.Run("config_file", func(t *testing.T) {
+		cfg := parseFlags(t, "--led-config", configFile)
+		assert.Equal(t, "file", cfg.Driver)
+		assert.Equal(t, 5, cfg.WS281x.DMAChannel)
+		// Fields not in the file keep their defaults.
+		assert.Equal(t, "BGR", cfg.WS281x.ColorOrder)
+	})
+
+	t.Run("config_file_overridden", func(t *testing.T) {
+		cfg := parseFlags(t, "--led-config", configFile, "--led-driver", "noop")
+		assert.Equal(t, "noop", cfg.Driver)
+		assert.Equal(t, 5, cfg.WS281x.DMAChannel)
+	})
+}
+
+func parseFlags(t *testing.T, args ...string) Config {
+	t.Helper()
+
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags := RegisterFlags(fs)
+	assert.NoError(t, fs.Parse(args))
+
+	cfg, err := flags.Config()
+	assert.NoError(t, err)
+	return cfg
+}
+
+func TestOpen(t *testing.T) {
+	d, err := Open(Config{Driver: "noop"}, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, Driver(Noop{}), d)
+
+	_, err = Open(Config{Driver: "nope"}, 10)
+	assert.Error(t, err)
+}
+
+func TestFile(t *testing.T) {
+	var buf bytes.Buffer
+	d := NewFile(&buf)
+
+	strips := []leddraw.LEDStrip{
+		{xcolor.RGB{R: 0xFF}, xcolor.RGB{}},
+		{xcolor.RGB{}, xcolor.RGB{B: 0xFF}},
+	}
+	for _, strip := range strips {
+		assert.NoError(t, d.Write(strip))
+	}
+	assert.NoError(t, d.Close())
+
+	var got []leddraw.LEDStrip
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var frame FileFrame
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &frame))
+		got = append(got, frame.LEDs)
+	}
+	assert.Equal(t, strips, got)
+}
@@ -0,0 +1,35 @@
+package ledout
+
+import (
+	"log"
+
+	"dev.acmcsuf.com/christmas/lib/leddraw"
+)
+
+// Log is a driver that logs the colors instead of writing them anywhere.
+type Log struct {
+	Logger *log.Logger
+}
+
+// NewLog creates a new Log driver that logs using the standard logger.
+func NewLog() *Log {
+	return &Log{Logger: log.Default()}
+}
+
+// Write implements Writer.
+func (d *Log) Write(leds leddraw.LEDStrip) error {
+	d.Logger.Println("LEDs:", leds)
+	return nil
+}
+
+// Close implements Driver.
+func (d *Log) Close() error { return nil }
+
+// Noop is a driver that discards the colors.
+type Noop struct{}
+
+// Write implements Writer.
+func (Noop) Write(leddraw.LEDStrip) error { return nil }
+
+// Close implements Driver.
+func (Noop) Close() error { return nil }
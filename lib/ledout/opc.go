@@ -0,0 +1,48 @@
+package ledout
+
+import (
+	"context"
+	"time"
+
+	"dev.acmcsuf.com/christmas/lib/leddraw"
+	"dev.acmcsuf.com/christmas/lib/opc"
+)
+
+// OPCConfig is the configuration for the OPC driver.
+type OPCConfig struct {
+	// Addr is the address of the OPC server. If it has no port, the default
+	// OPC port is used.
+	Addr string `json:"addr"`
+	// Channel is the OPC channel to send to. 0 sends to all channels.
+	Channel uint8 `json:"channel,omitempty"`
+}
+
+// OPC is a driver that streams the colors to an Open Pixel Control server,
+// such as christmasd or an OPC visualizer.
+type OPC struct {
+	client  *opc.Client
+	channel uint8
+}
+
+// DialOPC connects to the OPC server described by the config.
+func DialOPC(cfg OPCConfig) (*OPC, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, err := opc.Dial(ctx, cfg.Addr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OPC{client, cfg.Channel}, nil
+}
+
+// Write implements Writer.
+func (d *OPC) Write(leds leddraw.LEDStrip) error {
+	return d.client.SetPixels(d.channel, leds)
+}
+
+// Close implements Driver.
+func (d *OPC) Close() error {
+	return d.client.Close()
+}
@@ -0,0 +1,25 @@
+package ledout
+
+// WS281xConfig is the configuration for the WS281x driver.
+type WS281xConfig struct {
+	// ColorOrder is the order of the color channels, e.g. "GRB".
+	ColorOrder string `json:"color_order"`
+	// PWMFrequency is the frequency of the data signal in Hz.
+	PWMFrequency uint `json:"pwm_frequency"`
+	// DMAChannel is the DMA channel to use. Getting this wrong may damage the
+	// Pi, so be careful.
+	DMAChannel int `json:"dma_channel"`
+	// GPIOPins is the list of GPIO pins that the data lines are connected to.
+	GPIOPins []int `json:"gpio_pins"`
+}
+
+// DefaultWS281xConfig returns the configuration of the LEDs on the tree: BGR
+// at 800 KHz on DMA 10 and GPIO 12.
+func DefaultWS281xConfig() WS281xConfig {
+	return WS281xConfig{
+		ColorOrder:   "BGR",
+		PWMFrequency: 800000,
+		DMAChannel:   10,
+		GPIOPins:     []int{12},
+	}
+}
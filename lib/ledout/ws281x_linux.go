@@ -0,0 +1,48 @@
+package ledout
+
+import (
+	"fmt"
+
+	"dev.acmcsuf.com/christmas/lib/leddraw"
+	"libdb.so/ledctl"
+)
+
+// WS281x is a driver for WS281x LEDs connected to a Raspberry Pi.
+type WS281x struct {
+	strip *ledctl.WS281x
+}
+
+// NewWS281x creates a new WS281x driver for the given number of LEDs.
+func NewWS281x(cfg WS281xConfig, numLEDs int) (*WS281x, error) {
+	order, ok := ledctl.StringToOrder[cfg.ColorOrder]
+	if !ok {
+		return nil, fmt.Errorf("unknown color order %q", cfg.ColorOrder)
+	}
+
+	strip, err := ledctl.NewWS281x(ledctl.WS281xConfig{
+		NumPixels:    numLEDs,
+		ColorOrder:   order,
+		ColorModel:   ledctl.RGBModel,
+		PWMFrequency: cfg.PWMFrequency,
+		DMAChannel:   cfg.DMAChannel,
+		GPIOPins:     cfg.GPIOPins,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &WS281x{strip}, nil
+}
+
+// Write implements Writer.
+func (d *WS281x) Write(leds leddraw.LEDStrip) error {
+	for i, color := range leds {
+		d.strip.SetRGBAt(i, ledctl.RGB(color))
+	}
+	return d.strip.Flush()
+}
+
+// Close releases the hardware.
+func (d *WS281x) Close() error {
+	return d.strip.Close()
+}
@@ -0,0 +1,26 @@
+//go:build !linux
+
+package ledout
+
+import (
+	"errors"
+
+	"dev.acmcsuf.com/christmas/lib/leddraw"
+)
+
+// WS281x is a driver for WS281x LEDs connected to a Raspberry Pi. It is only
+// supported on Linux.
+type WS281x struct{}
+
+// NewWS281x always fails, since WS281x LEDs can only be driven on Linux.
+func NewWS281x(cfg WS281xConfig, numLEDs int) (*WS281x, error) {
+	return nil, errors.New("WS281x LEDs are only supported on Linux")
+}
+
+// Write implements Writer.
+func (d *WS281x) Write(leds leddraw.LEDStrip) error {
+	return errors.New("WS281x LEDs are only supported on Linux")
+}
+
+// Close implements Driver.
+func (d *WS281x) Close() error { return nil }
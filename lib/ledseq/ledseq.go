@@ -0,0 +1,178 @@
+// Package ledseq implements a compact binary file format for LED sequences,
+// which are animations that are already rendered onto the LEDs. Playing a
+// sequence only needs to copy colors instead of rendering images, and files
+// are memory-mapped so that they can be played without allocating.
+//
+// A sequence file is laid out as follows, with all integers in little endian:
+//
+//	header (32 bytes):
+//	  magic        [4]byte  "LSEQ"
+//	  version      uint8    1
+//	  encoding     uint8    Encoding of the frames
+//	  reserved     uint16
+//	  led count    uint32
+//	  frame count  uint32
+//	  loop start   int32    first frame of the loop, or -1
+//	  loop end     uint32   number of frames played before looping or ending
+//	  reserved     uint64
+//	index (24 bytes per frame):
+//	  offset       uint64   offset of the frame data from the file start
+//	  size         uint32   size of the frame data
+//	  duration     uint32   in milliseconds
+//	  jump back    int32    like animation.Frame.JumpBackAmount
+//	  flags        uint32   FrameKeyframe
+//	frame data
+//
+// The loop start and end are informational and follow animation.FindLoop;
+// players follow the jump back amount of each frame.
+package ledseq
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	"dev.acmcsuf.com/christmas/lib/animation"
+)
+
+// Magic is the magic number at the start of every sequence file.
+const Magic = "LSEQ"
+
+// Version is the version of the format.
+const Version = 1
+
+const (
+	headerSize     = 32
+	indexEntrySize = 24
+)
+
+// Encoding is how the colors of each frame are stored.
+type Encoding uint8
+
+const (
+	// EncodingRaw stores every frame as R, G, B bytes for each LED.
+	EncodingRaw Encoding = iota
+	// EncodingDelta stores keyframes with run-length encoding and every other
+	// frame as the runs of LEDs that changed since the previous frame. It is
+	// much smaller for most animations.
+	EncodingDelta
+)
+
+// String implements fmt.Stringer.
+func (e Encoding) String() string {
+	switch e {
+	case EncodingRaw:
+		return "raw"
+	case EncodingDelta:
+		return "delta"
+	default:
+		return fmt.Sprintf("Encoding(%d)", e)
+	}
+}
+
+// ParseEncoding parses the name of an encoding as returned by String.
+func ParseEncoding(s string) (Encoding, error) {
+	for _, e := range []Encoding{EncodingRaw, EncodingDelta} {
+		if e.String() == s {
+			return e, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown encoding %q", s)
+}
+
+// FrameFlags are the flags of a frame.
+type FrameFlags uint32
+
+const (
+	// FrameKeyframe marks a frame that can be decoded without decoding the
+	// previous frame first. Every frame is a keyframe in EncodingRaw. In
+	// EncodingDelta, the first frame and every frame that is jumped back to
+	// are keyframes.
+	FrameKeyframe FrameFlags = 1 << iota
+)
+
+// Header is the header of a sequence file.
+type Header struct {
+	Encoding   Encoding
+	LEDCount   int
+	FrameCount int
+	// LoopStart is the index of the frame that the sequence loops back to, or
+	// -1 if it doesn't loop.
+	LoopStart int
+	// LoopEnd is the number of frames that are played in order before the
+	// sequence either loops or ends.
+	LoopEnd int
+}
+
+func (h Header) appendBinary(b []byte) []byte {
+	b = append(b, Magic...)
+	b = append(b, Version, byte(h.Encoding), 0, 0)
+	b = binary.LittleEndian.AppendUint32(b, uint32(h.LEDCount))
+	b = binary.LittleEndian.AppendUint32(b, uint32(h.FrameCount))
+	b = binary.LittleEndian.AppendUint32(b, uint32(int32(h.LoopStart)))
+	b = binary.LittleEndian.AppendUint32(b, uint32(h.LoopEnd))
+	b = binary.LittleEndian.AppendUint64(b, 0)
+	return b
+}
+
+func parseHeader(b []byte) (Header, error) {
+	if len(b) < headerSize {
+		return Header{}, errors.New("file too short for header")
+	}
+	if string(b[:4]) != Magic {
+		return Header{}, errors.New("not a sequence file")
+	}
+	if b[4] != Version {
+		return Header{}, fmt.Errorf("unsupported version %d", b[4])
+	}
+
+	h := Header{
+		Encoding:   Encoding(b[5]),
+		LEDCount:   int(binary.LittleEndian.Uint32(b[8:])),
+		FrameCount: int(binary.LittleEndian.Uint32(b[12:])),
+		LoopStart:  int(int32(binary.LittleEndian.Uint32(b[16:]))),
+		LoopEnd:    int(binary.LittleEndian.Uint32(b[20:])),
+	}
+	if h.Encoding > EncodingDelta {
+		return Header{}, fmt.Errorf("unsupported encoding %d", h.Encoding)
+	}
+	return h, nil
+}
+
+// FrameInfo describes a frame in a sequence file.
+type FrameInfo struct {
+	offset uint64
+	size   uint32
+
+	// DurationMs is how long the frame is shown for.
+	DurationMs animation.Milliseconds
+	// JumpBackAmount is like animation.Frame.JumpBackAmount.
+	JumpBackAmount int32
+	// Flags are the flags of the frame.
+	Flags FrameFlags
+}
+
+// Duration returns the duration of the frame as a time.Duration.
+func (f FrameInfo) Duration() time.Duration {
+	return time.Duration(f.DurationMs) * time.Millisecond
+}
+
+func (f FrameInfo) appendBinary(b []byte) []byte {
+	b = binary.LittleEndian.AppendUint64(b, f.offset)
+	b = binary.LittleEndian.AppendUint32(b, f.size)
+	b = binary.LittleEndian.AppendUint32(b, uint32(f.DurationMs))
+	b = binary.LittleEndian.AppendUint32(b, uint32(f.JumpBackAmount))
+	b = binary.LittleEndian.AppendUint32(b, uint32(f.Flags))
+	return b
+}
+
+func parseFrameInfo(b []byte) FrameInfo {
+	return FrameInfo{
+		offset:         binary.LittleEndian.Uint64(b[0:]),
+		size:           binary.LittleEndian.Uint32(b[8:]),
+		DurationMs:     animation.Milliseconds(binary.LittleEndian.Uint32(b[12:])),
+		JumpBackAmount: int32(binary.LittleEndian.Uint32(b[16:])),
+		Flags:          FrameFlags(binary.LittleEndian.Uint32(b[20:])),
+	}
+}
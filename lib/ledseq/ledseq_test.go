@@ -0,0 +1,248 @@
+package ledseq
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"dev.acmcsuf.com/christmas/lib/animation"
+	"dev.acmcsuf.com/christmas/lib/leddraw"
+	"dev.acmcsuf.com/christmas/lib/xcolor"
+	"github.com/alecthomas/assert/v2"
+)
+
+// testFrames returns frames that light up one LED after another, looping back
+// to the second frame.
+func testFrames(numLEDs, numFrames int) []animation.Frame[leddraw.LEDStrip] {
+	frames := make([]animation.Frame[leddraw.LEDStrip], numFrames)
+	for i := range frames {
+		leds := make(leddraw.LEDStrip, numLEDs)
+		for j := range leds {
+			leds[j] = xcolor.RGB{R: 0x10, G: 0x20, B: 0x30}
+		}
+		leds[i%numLEDs] = xcolor.RGB{R: 0xFF, G: uint8(i)}
+		frames[i] = animation.Frame[leddraw.LEDStrip]{Image: leds, DurationMs: 1}
+	}
+	frames[numFrames-1].JumpBackAmount = int32(numFrames - 2)
+	return frames
+}
+
+func writeSequence(t *testing.T, frames []animation.Frame[leddraw.LEDStrip], enc Encoding) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	assert.NoError(t, Write(&buf, frames, enc))
+	return buf.Bytes()
+}
+
+func TestRoundTrip(t *testing.T) {
+	frames := testFrames(50, 150)
+
+	for _, enc := range []Encoding{EncodingRaw, EncodingDelta} {
+		t.Run(enc.String(), func(t *testing.T) {
+			r, err := NewReader(writeSequence(t, frames, enc))
+			assert.NoError(t, err)
+
+			assert.Equal(t, Header{
+				Encoding:   enc,
+				LEDCount:   50,
+				FrameCount: 150,
+				LoopStart:  1,
+				LoopEnd:    150,
+			}, r.Header())
+
+			leds := make(leddraw.LEDStrip, r.LEDCount())
+			for i, frame := range frames {
+				assert.NoError(t, r.ApplyFrame(leds, i))
+				assert.Equal(t, frame.Image, leds, "frame %d", i)
+
+				info := r.FrameInfo(i)
+				assert.Equal(t, frame.DurationMs, info.DurationMs)
+				assert.Equal(t, frame.JumpBackAmount, info.JumpBackAmount)
+			}
+
+			// Seeking must work regardless of what was decoded before.
+			for _, i := range []int{149, 1, 0, 75, 61} {
+				clear(leds)
+				assert.NoError(t, r.DecodeFrame(leds, i))
+				assert.Equal(t, frames[i].Image, leds, "frame %d", i)
+			}
+
+			decoded, err := r.Frames()
+			assert.NoError(t, err)
+			assert.Equal(t, frames, decoded)
+		})
+	}
+}
+
+func TestSource(t *testing.T) {
+	frames := testFrames(5, 8)
+	r, err := NewReader(writeSequence(t, frames, EncodingDelta))
+	assert.NoError(t, err)
+
+	// The source follows the loop back to the second frame forever.
+	expect := animation.Unroll(frames, 2)
+	errDone := errors.New("done")
+
+	var got []animation.Frame[leddraw.LEDStrip]
+	err = animation.Feed(context.Background(), r.Source(), func(frame animation.Frame[leddraw.LEDStrip]) error {
+		got = append(got, frame)
+		if len(got) == len(expect) {
+			return errDone
+		}
+		return nil
+	})
+	assert.IsError(t, err, errDone)
+	assert.Equal(t, expect, got)
+}
+
+func TestDeltaIsSmaller(t *testing.T) {
+	frames := testFrames(500, 100)
+	raw := writeSequence(t, frames, EncodingRaw)
+	delta := writeSequence(t, frames, EncodingDelta)
+	assert.True(t, len(delta) < len(raw)/10, "delta is %d bytes, raw is %d bytes", len(delta), len(raw))
+}
+
+func TestJumpTargetsAreKeyframes(t *testing.T) {
+	r, err := NewReader(writeSequence(t, testFrames(10, 10), EncodingDelta))
+	assert.NoError(t, err)
+
+	for i := 0; i < r.FrameCount(); i++ {
+		keyframe := r.FrameInfo(i).Flags&FrameKeyframe != 0
+		assert.Equal(t, i <= 1, keyframe, "frame %d", i)
+	}
+}
+
+func TestApplyFrameAllocs(t *testing.T) {
+	r, err := NewReader(writeSequence(t, testFrames(100, 100), EncodingDelta))
+	assert.NoError(t, err)
+
+	leds := make(leddraw.LEDStrip, r.LEDCount())
+	i := 0
+	allocs := testing.AllocsPerRun(1000, func() {
+		if err := r.ApplyFrame(leds, i); err != nil {
+			t.Fatal(err)
+		}
+		i = (i + 1) % r.FrameCount()
+	})
+	assert.Equal(t, 0.0, allocs)
+}
+
+func TestOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.lseq")
+	frames := testFrames(10, 5)
+	assert.NoError(t, os.WriteFile(path, writeSequence(t, frames, EncodingDelta), 0644))
+
+	r, err := Open(path)
+	assert.NoError(t, err)
+	defer r.Close()
+
+	leds := make(leddraw.LEDStrip, r.LEDCount())
+	assert.NoError(t, r.DecodeFrame(leds, 4))
+	assert.Equal(t, frames[4].Image, leds)
+}
+
+func TestPlay(t *testing.T) {
+	t.Run("end", func(t *testing.T) {
+		frames := testFrames(3, 3)
+		frames[2].JumpBackAmount = 0
+
+		r, err := NewReader(writeSequence(t, frames, EncodingDelta))
+		assert.NoError(t, err)
+
+		var played []leddraw.LEDStrip
+		err = Play(context.Background(), r, func(leds leddraw.LEDStrip) error {
+			played = append(played, append(leddraw.LEDStrip(nil), leds...))
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []leddraw.LEDStrip{frames[0].Image, frames[1].Image, frames[2].Image}, played)
+	})
+
+	t.Run("loop", func(t *testing.T) {
+		frames := testFrames(3, 3)
+
+		r, err := NewReader(writeSequence(t, frames, EncodingDelta))
+		assert.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var played []leddraw.LEDStrip
+		err = Play(ctx, r, func(leds leddraw.LEDStrip) error {
+			played = append(played, append(leddraw.LEDStrip(nil), leds...))
+			if len(played) == 6 {
+				cancel()
+			}
+			return nil
+		})
+		assert.Equal(t, context.Canceled, err)
+		assert.Equal(t, []leddraw.LEDStrip{
+			frames[0].Image,
+			frames[1].Image, frames[2].Image,
+			frames[1].Image, frames[2].Image,
+			frames[1].Image,
+		}, played)
+	})
+
+	t.Run("zero_duration_loop", func(t *testing.T) {
+		// Like animation.Feed, the loop is played once more before it is
+		// found to take no time.
+		frames := testFrames(3, 3)
+		frames[1].DurationMs = 0
+		frames[2].DurationMs = 0
+
+		r, err := NewReader(writeSequence(t, frames, EncodingDelta))
+		assert.NoError(t, err)
+
+		var played int
+		err = Play(context.Background(), r, func(leds leddraw.LEDStrip) error {
+			played++
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 5, played)
+	})
+}
+
+func TestCorrupt(t *testing.T) {
+	data := writeSequence(t, testFrames(10, 5), EncodingDelta)
+
+	_, err := NewReader(data[:10])
+	assert.Error(t, err)
+
+	_, err = NewReader(data[:len(data)-1])
+	assert.Error(t, err)
+
+	bad := bytes.Clone(data)
+	copy(bad, "NOPE")
+	_, err = NewReader(bad)
+	assert.Error(t, err)
+
+	// An offset that overflows when the size is added to it.
+	bad = bytes.Clone(data)
+	binary.LittleEndian.PutUint64(bad[headerSize+indexEntrySize:], math.MaxUint64-1)
+	_, err = NewReader(bad)
+	assert.Error(t, err)
+
+	// Claim more LEDs than there are in every frame.
+	bad = bytes.Clone(data)
+	bad[8] = 20
+	r, err := NewReader(bad)
+	assert.NoError(t, err)
+	assert.Error(t, r.ApplyFrame(make(leddraw.LEDStrip, 20), 0))
+}
+
+func TestWriteErrors(t *testing.T) {
+	var buf bytes.Buffer
+	assert.Error(t, Write(&buf, nil, EncodingRaw))
+	assert.Error(t, Write(&buf, []animation.Frame[leddraw.LEDStrip]{
+		{Image: make(leddraw.LEDStrip, 2)},
+		{Image: make(leddraw.LEDStrip, 3)},
+	}, EncodingRaw))
+}
@@ -0,0 +1,74 @@
+package ledseq
+
+import (
+	"context"
+	"time"
+
+	"dev.acmcsuf.com/christmas/lib/animation"
+	"dev.acmcsuf.com/christmas/lib/leddraw"
+)
+
+// Play plays the sequence in real time, calling fn with every frame when it
+// is due. Frames jump back like they do in an animation.Player, so looping
+// sequences play until the context is canceled. Play returns nil once a
+// sequence that doesn't loop has ended, or once a loop turns out to take no
+// time, which would otherwise be played forever.
+//
+// The strip given to fn is reused for every frame and must not be retained.
+// Other than the strip, Play doesn't allocate.
+func Play(ctx context.Context, r *Reader, fn func(leddraw.LEDStrip) error) error {
+	return PlayWithClock(ctx, r, animation.SystemClock(), fn)
+}
+
+// PlayWithClock is like Play, but the frames follow the given clock, such as
+// the position of the audio that the sequence is synchronized to. The first
+// frame is shown at the clock's current time.
+func PlayWithClock(ctx context.Context, r *Reader, clock animation.Clock, fn func(leddraw.LEDStrip) error) error {
+	if r.FrameCount() == 0 {
+		return nil
+	}
+
+	leds := make(leddraw.LEDStrip, r.LEDCount())
+
+	timer := clock.NewTimer()
+	defer timer.Stop()
+
+	next := clock.Now()
+	var sinceJump time.Duration
+	for i := 0; i < r.FrameCount(); {
+		if err := r.ApplyFrame(leds, i); err != nil {
+			return err
+		}
+		if err := fn(leds); err != nil {
+			return err
+		}
+
+		info := r.FrameInfo(i)
+
+		// Schedule against the absolute time so that the time spent
+		// decoding and writing frames doesn't add up.
+		next += info.Duration()
+		sinceJump += info.Duration()
+		timer.Reset(next)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C():
+		}
+
+		if info.JumpBackAmount > 0 {
+			if sinceJump == 0 {
+				// The loop would be played forever without taking any time.
+				return nil
+			}
+			if i -= int(info.JumpBackAmount); i < 0 {
+				return nil
+			}
+			sinceJump = 0
+		} else {
+			i++
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,289 @@
+package ledseq
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"dev.acmcsuf.com/christmas/lib/animation"
+	"dev.acmcsuf.com/christmas/lib/leddraw"
+	"github.com/edsrzf/mmap-go"
+)
+
+var errCorrupt = errors.New("corrupt frame data")
+
+// Reader reads frames from a sequence file. Decoding frames never allocates.
+// It is safe to use from multiple goroutines.
+type Reader struct {
+	header Header
+	data   []byte
+	mmap   mmap.MMap
+}
+
+// Open memory-maps the sequence file at the given path. The Reader must be
+// closed when it is no longer needed.
+func Open(path string) (*Reader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if stat.Size() < headerSize {
+		return nil, fmt.Errorf("%s: file too short for header", path)
+	}
+
+	m, err := mmap.Map(f, mmap.RDONLY, 0)
+	if err != nil {
+		return nil, fmt.Errorf("cannot mmap %s: %w", path, err)
+	}
+
+	r, err := NewReader(m)
+	if err != nil {
+		m.Unmap()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	r.mmap = m
+
+	return r, nil
+}
+
+// NewReader creates a Reader for a sequence file that is already in memory.
+// The data must not be modified while the Reader is in use.
+func NewReader(data []byte) (*Reader, error) {
+	header, err := parseHeader(data)
+	if err != nil {
+		return nil, err
+	}
+
+	indexEnd := headerSize + uint64(header.FrameCount)*indexEntrySize
+	if indexEnd > uint64(len(data)) {
+		return nil, errors.New("file too short for frame index")
+	}
+
+	r := &Reader{header: header, data: data}
+	for i := 0; i < header.FrameCount; i++ {
+		info := r.FrameInfo(i)
+		if info.offset > uint64(len(data)) || uint64(info.size) > uint64(len(data))-info.offset {
+			return nil, fmt.Errorf("frame %d is out of bounds", i)
+		}
+		if header.Encoding == EncodingRaw && info.size != uint32(3*header.LEDCount) {
+			return nil, fmt.Errorf("frame %d has %d bytes, expected %d", i, info.size, 3*header.LEDCount)
+		}
+	}
+	if header.FrameCount > 0 && r.FrameInfo(0).Flags&FrameKeyframe == 0 {
+		return nil, errors.New("first frame is not a keyframe")
+	}
+
+	return r, nil
+}
+
+// Close unmaps the file if the Reader was created by Open.
+func (r *Reader) Close() error {
+	if r.mmap == nil {
+		return nil
+	}
+	err := r.mmap.Unmap()
+	r.mmap = nil
+	r.data = nil
+	return err
+}
+
+// Header returns the header of the sequence file.
+func (r *Reader) Header() Header {
+	return r.header
+}
+
+// LEDCount returns the number of LEDs in each frame.
+func (r *Reader) LEDCount() int {
+	return r.header.LEDCount
+}
+
+// FrameCount returns the number of frames.
+func (r *Reader) FrameCount() int {
+	return r.header.FrameCount
+}
+
+// FrameInfo returns information about the frame at index i.
+func (r *Reader) FrameInfo(i int) FrameInfo {
+	off := headerSize + i*indexEntrySize
+	return parseFrameInfo(r.data[off : off+indexEntrySize])
+}
+
+// DecodeFrame decodes the frame at index i into dst, which must have LEDCount
+// LEDs. Frames that aren't keyframes are decoded starting from the nearest
+// keyframe before them. When playing frames in order, use ApplyFrame instead.
+func (r *Reader) DecodeFrame(dst leddraw.LEDStrip, i int) error {
+	start := i
+	for start > 0 && r.FrameInfo(start).Flags&FrameKeyframe == 0 {
+		start--
+	}
+	for j := start; j <= i; j++ {
+		if err := r.ApplyFrame(dst, j); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ApplyFrame decodes the frame at index i into dst, which must have LEDCount
+// LEDs. Unless the frame is a keyframe, dst must already hold frame i-1.
+func (r *Reader) ApplyFrame(dst leddraw.LEDStrip, i int) error {
+	if i < 0 || i >= r.header.FrameCount {
+		return fmt.Errorf("frame %d out of range", i)
+	}
+	if len(dst) != r.header.LEDCount {
+		return fmt.Errorf("strip has %d LEDs, expected %d", len(dst), r.header.LEDCount)
+	}
+
+	info := r.FrameInfo(i)
+	data := r.data[info.offset : info.offset+uint64(info.size)]
+
+	switch {
+	case r.header.Encoding == EncodingRaw:
+		decodeRaw(dst, data)
+		return nil
+	case info.Flags&FrameKeyframe != 0:
+		return decodeRLE(dst, data)
+	default:
+		return decodeDelta(dst, data)
+	}
+}
+
+// Frames decodes every frame of the sequence into memory, such as to give them
+// to an animation.Player.
+func (r *Reader) Frames() ([]animation.Frame[leddraw.LEDStrip], error) {
+	frames := make([]animation.Frame[leddraw.LEDStrip], r.FrameCount())
+	leds := make(leddraw.LEDStrip, r.LEDCount())
+	for i := range frames {
+		if err := r.ApplyFrame(leds, i); err != nil {
+			return nil, err
+		}
+		info := r.FrameInfo(i)
+		frames[i] = animation.Frame[leddraw.LEDStrip]{
+			Image:          append(leddraw.LEDStrip(nil), leds...),
+			JumpBackAmount: info.JumpBackAmount,
+			DurationMs:     info.DurationMs,
+		}
+	}
+	return frames, nil
+}
+
+// Source is an animation.FrameSource that decodes the frames of a sequence as
+// they are played, so that sequences of any length can be played without
+// decoding them into memory first.
+type Source struct {
+	r       *Reader
+	leds    leddraw.LEDStrip
+	decoded int // index of the frame in leds, or -1
+	next    int
+}
+
+var _ animation.SeekableFrameSource[leddraw.LEDStrip] = (*Source)(nil)
+
+// Source returns a new Source for the frames of the sequence.
+func (r *Reader) Source() *Source {
+	return &Source{
+		r:       r,
+		leds:    make(leddraw.LEDStrip, r.LEDCount()),
+		decoded: -1,
+	}
+}
+
+// NextFrame implements animation.FrameSource. Every frame has its own copy of
+// the LEDs.
+func (s *Source) NextFrame(ctx context.Context) (animation.Frame[leddraw.LEDStrip], error) {
+	if s.next >= s.r.FrameCount() {
+		return animation.Frame[leddraw.LEDStrip]{}, io.EOF
+	}
+
+	var err error
+	if s.decoded == s.next-1 {
+		err = s.r.ApplyFrame(s.leds, s.next)
+	} else {
+		err = s.r.DecodeFrame(s.leds, s.next)
+	}
+	if err != nil {
+		s.decoded = -1
+		return animation.Frame[leddraw.LEDStrip]{}, err
+	}
+
+	info := s.r.FrameInfo(s.next)
+	s.decoded = s.next
+	s.next++
+
+	return animation.Frame[leddraw.LEDStrip]{
+		Image:          append(leddraw.LEDStrip(nil), s.leds...),
+		JumpBackAmount: info.JumpBackAmount,
+		DurationMs:     info.DurationMs,
+	}, nil
+}
+
+// SeekFrame implements animation.SeekableFrameSource.
+func (s *Source) SeekFrame(index int) error {
+	if index < 0 || index >= s.r.FrameCount() {
+		return fmt.Errorf("frame %d out of range", index)
+	}
+	s.next = index
+	return nil
+}
+
+func decodeRaw(dst leddraw.LEDStrip, data []byte) {
+	for i := range dst {
+		dst[i].R = data[3*i+0]
+		dst[i].G = data[3*i+1]
+		dst[i].B = data[3*i+2]
+	}
+}
+
+func decodeRLE(dst leddraw.LEDStrip, data []byte) error {
+	i := 0
+	for len(data) > 0 {
+		n, size := binary.Uvarint(data)
+		if size <= 0 || len(data) < size+3 || n > uint64(len(dst)-i) {
+			return errCorrupt
+		}
+		c := data[size : size+3]
+		data = data[size+3:]
+
+		for end := i + int(n); i < end; i++ {
+			dst[i].R = c[0]
+			dst[i].G = c[1]
+			dst[i].B = c[2]
+		}
+	}
+	if i != len(dst) {
+		return errCorrupt
+	}
+	return nil
+}
+
+func decodeDelta(dst leddraw.LEDStrip, data []byte) error {
+	i := 0
+	for len(data) > 0 {
+		skip, size := binary.Uvarint(data)
+		if size <= 0 || skip > uint64(len(dst)-i) {
+			return errCorrupt
+		}
+		data = data[size:]
+		i += int(skip)
+
+		n, size := binary.Uvarint(data)
+		if size <= 0 || n > uint64(len(dst)-i) || uint64(len(data)-size) < 3*n {
+			return errCorrupt
+		}
+		data = data[size:]
+
+		decodeRaw(dst[i:i+int(n)], data)
+		data = data[3*n:]
+		i += int(n)
+	}
+	return nil
+}
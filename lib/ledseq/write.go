@@ -0,0 +1,144 @@
+package ledseq
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"dev.acmcsuf.com/christmas/lib/animation"
+	"dev.acmcsuf.com/christmas/lib/leddraw"
+)
+
+// keyframeInterval is the maximum number of frames between keyframes in
+// EncodingDelta, which bounds the work needed to seek to any frame.
+const keyframeInterval = 60
+
+// Write writes the frames to w as a sequence file using the given encoding.
+// Every frame must have the same number of LEDs. Sequence files can only jump
+// back by a number of frames, so the frames are flattened with
+// animation.Flatten first.
+func Write(w io.Writer, frames []animation.Frame[leddraw.LEDStrip], enc Encoding) error {
+	frames = animation.Flatten(frames)
+	if len(frames) == 0 {
+		return errors.New("no frames")
+	}
+	if enc > EncodingDelta {
+		return fmt.Errorf("unsupported encoding %d", enc)
+	}
+
+	numLEDs := len(frames[0].Image)
+	for i, frame := range frames {
+		if len(frame.Image) != numLEDs {
+			return fmt.Errorf("frame %d has %d LEDs, expected %d", i, len(frame.Image), numLEDs)
+		}
+	}
+
+	end, loopStart := animation.FindLoop(frames)
+	header := Header{
+		Encoding:   enc,
+		LEDCount:   numLEDs,
+		FrameCount: len(frames),
+		LoopStart:  loopStart,
+		LoopEnd:    end,
+	}
+
+	// Frames that are jumped back to must be decodable without the frame
+	// that was played before them.
+	keyframes := make([]bool, len(frames))
+	for i, frame := range frames {
+		if i%keyframeInterval == 0 || enc == EncodingRaw {
+			keyframes[i] = true
+		}
+		if target := i - int(frame.JumpBackAmount); frame.JumpBackAmount > 0 && target >= 0 {
+			keyframes[target] = true
+		}
+	}
+
+	var data []byte
+	infos := make([]FrameInfo, len(frames))
+	dataOffset := uint64(headerSize + indexEntrySize*len(frames))
+
+	for i, frame := range frames {
+		start := len(data)
+		switch {
+		case enc == EncodingRaw:
+			data = appendRaw(data, frame.Image)
+		case keyframes[i]:
+			data = appendRLE(data, frame.Image)
+		default:
+			data = appendDelta(data, frames[i-1].Image, frame.Image)
+		}
+
+		infos[i] = FrameInfo{
+			offset:         dataOffset + uint64(start),
+			size:           uint32(len(data) - start),
+			DurationMs:     frame.DurationMs,
+			JumpBackAmount: frame.JumpBackAmount,
+		}
+		if keyframes[i] {
+			infos[i].Flags |= FrameKeyframe
+		}
+	}
+
+	b := make([]byte, 0, dataOffset)
+	b = header.appendBinary(b)
+	for _, info := range infos {
+		b = info.appendBinary(b)
+	}
+
+	if _, err := w.Write(b); err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	return nil
+}
+
+func appendRaw(b []byte, leds leddraw.LEDStrip) []byte {
+	for _, c := range leds {
+		b = append(b, c.R, c.G, c.B)
+	}
+	return b
+}
+
+// appendRLE appends runs of identical colors as (uvarint length, R, G, B).
+func appendRLE(b []byte, leds leddraw.LEDStrip) []byte {
+	for i := 0; i < len(leds); {
+		n := 1
+		for i+n < len(leds) && leds[i+n] == leds[i] {
+			n++
+		}
+		b = binary.AppendUvarint(b, uint64(n))
+		b = append(b, leds[i].R, leds[i].G, leds[i].B)
+		i += n
+	}
+	return b
+}
+
+// appendDelta appends the runs of LEDs that changed from prev to leds as
+// (uvarint unchanged, uvarint changed, changed × (R, G, B)).
+func appendDelta(b []byte, prev, leds leddraw.LEDStrip) []byte {
+	for i := 0; i < len(leds); {
+		skip := 0
+		for i+skip < len(leds) && leds[i+skip] == prev[i+skip] {
+			skip++
+		}
+		if i+skip == len(leds) {
+			break
+		}
+		i += skip
+
+		n := 0
+		for i+n < len(leds) && leds[i+n] != prev[i+n] {
+			n++
+		}
+
+		b = binary.AppendUvarint(b, uint64(skip))
+		b = binary.AppendUvarint(b, uint64(n))
+		b = appendRaw(b, leds[i:i+n])
+		i += n
+	}
+	return b
+}
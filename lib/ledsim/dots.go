@@ -0,0 +1,68 @@
+package ledsim
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	"dev.acmcsuf.com/christmas/lib/leddraw"
+	"dev.acmcsuf.com/christmas/lib/xdraw"
+)
+
+// Renderer renders LEDs onto an image.
+type Renderer interface {
+	// Size returns the size of the rendered image.
+	Size() image.Point
+	// Render draws the given LEDs and returns the image. The returned image
+	// may be reused by the next call to Render.
+	Render(leddraw.LEDStrip) *image.RGBA
+}
+
+var (
+	_ Renderer = (*GlowRenderer)(nil)
+	_ Renderer = (*DotRenderer)(nil)
+)
+
+// DotRenderer draws LEDs as solid dots on a black background, like
+// tree-canvas does. Since every LED is a single flat color, the rendered
+// images have few colors and compress well.
+type DotRenderer struct {
+	points []image.Point // in image space
+	radius int
+	img    *image.RGBA
+}
+
+// NewDotRenderer creates a new DotRenderer that draws onto an image of the
+// given size. The LED points are scaled to fit the image while preserving
+// their aspect ratio.
+func NewDotRenderer(ledPoints []image.Point, size image.Point, radius int) *DotRenderer {
+	bounds := image.Rectangle{Max: size}
+	return &DotRenderer{
+		points: FitPoints(ledPoints, bounds.Inset(radius)),
+		radius: radius,
+		img:    image.NewRGBA(bounds),
+	}
+}
+
+// Size returns the size of the rendered image.
+func (r *DotRenderer) Size() image.Point {
+	return r.img.Rect.Size()
+}
+
+// Points returns the position of each LED in the rendered image.
+func (r *DotRenderer) Points() []image.Point {
+	return r.points
+}
+
+// Render draws the given LEDs and returns the image. The returned image is
+// reused by the next call to Render.
+func (r *DotRenderer) Render(leds leddraw.LEDStrip) *image.RGBA {
+	draw.Draw(r.img, r.img.Rect, image.NewUniform(color.Black), image.Point{}, draw.Src)
+	for i, led := range leds {
+		if i >= len(r.points) {
+			break
+		}
+		xdraw.DrawCircle(r.img, r.points[i], r.radius, led)
+	}
+	return r.img
+}
@@ -0,0 +1,221 @@
+package ledsim
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"dev.acmcsuf.com/christmas/lib/animation"
+	"dev.acmcsuf.com/christmas/lib/ffutil"
+	"dev.acmcsuf.com/christmas/lib/leddraw"
+)
+
+var errNoFrames = errors.New("no frames to encode")
+
+// EncodeGIF renders the frames and encodes them as an animated GIF. If the
+// whole animation loops, the GIF loops forever. Otherwise, since GIFs can only
+// loop from the first frame, the loop is unrolled repeats times and the GIF is
+// played once.
+func EncodeGIF(w io.Writer, r Renderer, frames []animation.Frame[leddraw.LEDStrip], repeats int) error {
+	frames = animation.Flatten(frames)
+	end, loopStart := animation.FindLoop(frames)
+
+	g := gif.GIF{LoopCount: -1}
+	if loopStart == 0 {
+		frames = frames[:end]
+		g.LoopCount = 0
+	} else {
+		frames = animation.Unroll(frames, repeats)
+	}
+
+	if len(frames) == 0 {
+		return errNoFrames
+	}
+
+	// GIF delays are in centiseconds. Round the total elapsed time instead of
+	// each frame so that the rounding errors don't add up.
+	var elapsedMs, elapsedCs int
+	for _, frame := range frames {
+		elapsedMs += int(frame.DurationMs)
+		delay := (elapsedMs+5)/10 - elapsedCs
+		elapsedCs += delay
+
+		g.Image = append(g.Image, toPaletted(r.Render(frame.Image)))
+		g.Delay = append(g.Delay, delay)
+	}
+
+	if err := gif.EncodeAll(w, &g); err != nil {
+		return fmt.Errorf("cannot encode GIF: %w", err)
+	}
+
+	return nil
+}
+
+// toPaletted converts the image to a paletted image. If the image has at most
+// 256 colors, which is usually the case for DotRenderer, the colors are kept
+// exactly. Otherwise, the image is dithered to the Plan 9 palette.
+func toPaletted(img *image.RGBA) *image.Paletted {
+	indices := make(map[color.RGBA]uint8, 256)
+	pal := make(color.Palette, 0, 256)
+
+	exact := true
+	for i := 0; i < len(img.Pix); i += 4 {
+		c := color.RGBA{img.Pix[i+0], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3]}
+		if _, ok := indices[c]; ok {
+			continue
+		}
+		if len(pal) == 256 {
+			exact = false
+			break
+		}
+		indices[c] = uint8(len(pal))
+		pal = append(pal, c)
+	}
+
+	if !exact {
+		paletted := image.NewPaletted(img.Rect, palette.Plan9)
+		draw.FloydSteinberg.Draw(paletted, img.Rect, img, img.Rect.Min)
+		return paletted
+	}
+
+	paletted := image.NewPaletted(img.Rect, pal)
+	for i, j := 0, 0; i < len(img.Pix); i, j = i+4, j+1 {
+		c := color.RGBA{img.Pix[i+0], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3]}
+		paletted.Pix[j] = indices[c]
+	}
+	return paletted
+}
+
+// EncodeVideo renders the frames and encodes them into a video file at dst
+// using ffmpeg. The format is guessed by ffmpeg from the file extension. Since
+// videos cannot loop, the loop is unrolled repeats times. Frames are repeated
+// or dropped as needed to keep their durations at the given frame rate.
+func EncodeVideo(ctx context.Context, dst string, r Renderer, frames []animation.Frame[leddraw.LEDStrip], fps, repeats int) error {
+	frames = animation.Unroll(frames, repeats)
+	if len(frames) == 0 {
+		return errNoFrames
+	}
+
+	size := r.Size()
+	args := ffutil.FFmpegArgs{
+		"-hide_banner", "-loglevel", "error", "-y",
+		"-f", "rawvideo",
+		"-pixel_format", "rgba",
+		"-video_size", strconv.Itoa(size.X) + "x" + strconv.Itoa(size.Y),
+		"-framerate", strconv.Itoa(fps),
+		"-i", "-",
+		// yuv420p is the most compatible pixel format, but it needs an even
+		// width and height.
+		"-vf", "pad=ceil(iw/2)*2:ceil(ih/2)*2",
+		"-pix_fmt", "yuv420p",
+		dst,
+	}
+
+	var stderr strings.Builder
+
+	cmd := exec.CommandContext(ctx, "ffmpeg", args...)
+	cmd.Stderr = &stderr
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("cannot create ffmpeg stdin: %w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("cannot start ffmpeg: %w", err)
+	}
+
+	writeErr := eachFrameAtRate(r, frames, fps, func(img *image.RGBA, n int) error {
+		for i := 0; i < n; i++ {
+			if _, err := stdin.Write(img.Pix); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	stdin.Close()
+
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("ffmpeg failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	if writeErr != nil {
+		return fmt.Errorf("cannot write frames to ffmpeg: %w", writeErr)
+	}
+
+	return nil
+}
+
+// WritePNGs renders the frames into a sequence of PNG files in dir, named
+// frame-00000.png, frame-00001.png, and so on. Like EncodeVideo, the loop is
+// unrolled repeats times and the frames are timed at the given frame rate.
+func WritePNGs(dir string, r Renderer, frames []animation.Frame[leddraw.LEDStrip], fps, repeats int) error {
+	frames = animation.Unroll(frames, repeats)
+	if len(frames) == 0 {
+		return errNoFrames
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("cannot create output directory: %w", err)
+	}
+
+	var buf bytes.Buffer
+	var written int
+
+	return eachFrameAtRate(r, frames, fps, func(img *image.RGBA, n int) error {
+		// The same image is often shown for several frames, so only encode
+		// it once.
+		buf.Reset()
+		if err := png.Encode(&buf, img); err != nil {
+			return fmt.Errorf("cannot encode PNG: %w", err)
+		}
+
+		for i := 0; i < n; i++ {
+			name := filepath.Join(dir, fmt.Sprintf("frame-%05d.png", written))
+			if err := os.WriteFile(name, buf.Bytes(), 0644); err != nil {
+				return fmt.Errorf("cannot write PNG: %w", err)
+			}
+			written++
+		}
+		return nil
+	})
+}
+
+// eachFrameAtRate renders every frame and calls fn with the image and the
+// number of times that it must be shown to last its duration at the given
+// frame rate. Frames that are too short to be shown are skipped.
+func eachFrameAtRate(r Renderer, frames []animation.Frame[leddraw.LEDStrip], fps int, fn func(img *image.RGBA, n int) error) error {
+	if fps < 1 {
+		return fmt.Errorf("invalid frame rate %d", fps)
+	}
+
+	var elapsedMs, shown int
+	for _, frame := range frames {
+		// Like the GIF delays, round the total elapsed time so that rounding
+		// errors don't add up.
+		elapsedMs += int(frame.DurationMs)
+		n := (elapsedMs*fps+500)/1000 - shown
+		if n <= 0 {
+			continue
+		}
+
+		if err := fn(r.Render(frame.Image), n); err != nil {
+			return err
+		}
+		shown += n
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package ledsim
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"os"
+	"testing"
+	"time"
+
+	"dev.acmcsuf.com/christmas/lib/animation"
+	"dev.acmcsuf.com/christmas/lib/leddraw"
+	"dev.acmcsuf.com/christmas/lib/xcolor"
+	"github.com/alecthomas/assert/v2"
+)
+
+var (
+	red   = leddraw.LEDStrip{{R: 0xFF}, {}}
+	green = leddraw.LEDStrip{{}, {G: 0xFF}}
+	blue  = leddraw.LEDStrip{{B: 0xFF}, {B: 0xFF}}
+)
+
+func testRenderer() Renderer {
+	return NewDotRenderer([]image.Point{{0, 0}, {10, 10}}, image.Pt(20, 20), 2)
+}
+
+func TestEncodeGIF(t *testing.T) {
+	t.Run("loop_all", func(t *testing.T) {
+		g := encodeGIF(t, []animation.Frame[leddraw.LEDStrip]{
+			{Image: red, DurationMs: 100},
+			{Image: green, DurationMs: 15},
+			{Image: blue, DurationMs: 15, JumpBackAmount: 2},
+		}, 1)
+		assert.Equal(t, 0, g.LoopCount)
+		// 100ms, 115ms and 130ms in total round to 10cs, 12cs and 13cs.
+		assert.Equal(t, []int{10, 2, 1}, g.Delay)
+
+		pts := testRenderer().(*DotRenderer).Points()
+		assert.Equal(t,
+			color.Color(color.RGBA{0xFF, 0, 0, 0xFF}),
+			color.RGBAModel.Convert(g.Image[0].At(pts[0].X, pts[0].Y)))
+		assert.Equal(t,
+			color.Color(color.RGBA{0, 0xFF, 0, 0xFF}),
+			color.RGBAModel.Convert(g.Image[1].At(pts[1].X, pts[1].Y)))
+	})
+
+	t.Run("loop_tail", func(t *testing.T) {
+		g := encodeGIF(t, []animation.Frame[leddraw.LEDStrip]{
+			{Image: red, DurationMs: 100},
+			{Image: green, DurationMs: 100},
+			{Image: blue, DurationMs: 100, JumpBackAmount: 1},
+		}, 3)
+		assert.Equal(t, -1, g.LoopCount)
+		assert.Equal(t, []int{10, 10, 10, 10, 10, 10, 10}, g.Delay)
+	})
+
+	t.Run("no_frames", func(t *testing.T) {
+		err := EncodeGIF(&bytes.Buffer{}, testRenderer(), nil, 1)
+		assert.Error(t, err)
+	})
+}
+
+func encodeGIF(t *testing.T, frames []animation.Frame[leddraw.LEDStrip], repeats int) *gif.GIF {
+	t.Helper()
+
+	var buf bytes.Buffer
+	assert.NoError(t, EncodeGIF(&buf, testRenderer(), frames, repeats))
+
+	g, err := gif.DecodeAll(&buf)
+	assert.NoError(t, err)
+	return g
+}
+
+func TestWritePNGs(t *testing.T) {
+	dir := t.TempDir()
+
+	err := WritePNGs(dir, testRenderer(), []animation.Frame[leddraw.LEDStrip]{
+		{Image: red, DurationMs: 100},
+		{Image: green, DurationMs: 10}, // too short to be shown at 10 fps
+		{Image: blue, DurationMs: 200},
+	}, 10, 1)
+	assert.NoError(t, err)
+
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(entries))
+	assert.Equal(t, "frame-00000.png", entries[0].Name())
+}
+
+func TestRecorder(t *testing.T) {
+	now := time.Unix(0, 0)
+
+	r := NewRecorder()
+	r.Now = func() time.Time { return now }
+
+	r.Write(red)
+	now = now.Add(100 * time.Millisecond)
+
+	leds := append(leddraw.LEDStrip(nil), green...)
+	r.Write(leds)
+	leds[0] = xcolor.RGB{R: 0xFF} // must not affect the recording
+	now = now.Add(50 * time.Millisecond)
+
+	assert.Equal(t, []animation.Frame[leddraw.LEDStrip]{
+		{Image: red, DurationMs: 100},
+		{Image: green, DurationMs: 50},
+	}, r.Frames())
+}
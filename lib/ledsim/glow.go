@@ -0,0 +1,157 @@
+// Package ledsim simulates LEDs by drawing them onto images at their
+// positions.
+package ledsim
+
+import (
+	"image"
+	"math"
+
+	"dev.acmcsuf.com/christmas/lib/leddraw"
+	"dev.acmcsuf.com/christmas/lib/xdraw"
+)
+
+// GlowRenderer draws LEDs as glowing dots. The glow of each LED is added on
+// top of each other, so LEDs that are close together blend like real bulbs
+// would.
+type GlowRenderer struct {
+	points []image.Point // in image space
+	kernel []float32     // glow intensity per pixel, (2*radius+1)^2
+	radius int
+
+	img *image.RGBA
+	acc []float32 // RGB per pixel
+}
+
+// NewGlowRenderer creates a new GlowRenderer that draws onto an image of the
+// given size. The LED points are scaled to fit the image while preserving
+// their aspect ratio. Each LED glows up to radius pixels away from its center.
+func NewGlowRenderer(ledPoints []image.Point, size image.Point, radius int) *GlowRenderer {
+	if radius < 1 {
+		radius = 1
+	}
+
+	bounds := image.Rectangle{Max: size}
+
+	return &GlowRenderer{
+		points: FitPoints(ledPoints, bounds.Inset(radius)),
+		kernel: glowKernel(radius),
+		radius: radius,
+		img:    image.NewRGBA(bounds),
+		acc:    make([]float32, 3*size.X*size.Y),
+	}
+}
+
+// glowKernel precalculates the intensity of the glow around an LED. The
+// center is fully lit and the glow falls off quadratically.
+func glowKernel(radius int) []float32 {
+	size := 2*radius + 1
+	kernel := make([]float32, size*size)
+	for y := -radius; y <= radius; y++ {
+		for x := -radius; x <= radius; x++ {
+			d := math.Sqrt(float64(x*x+y*y)) / float64(radius)
+			if d > 1 {
+				continue
+			}
+			kernel[(y+radius)*size+(x+radius)] = float32((1 - d) * (1 - d))
+		}
+	}
+	return kernel
+}
+
+// FitPoints scales and translates the given points so that they fit inside the
+// given bounds, preserving their aspect ratio. The points are centered within
+// the bounds.
+func FitPoints(pts []image.Point, bounds image.Rectangle) []image.Point {
+	box := xdraw.BoundingBox(pts)
+	if box.Empty() {
+		return make([]image.Point, len(pts))
+	}
+
+	scale := math.Min(
+		float64(bounds.Dx()-1)/math.Max(float64(box.Dx()-1), 1),
+		float64(bounds.Dy()-1)/math.Max(float64(box.Dy()-1), 1))
+
+	offset := image.Point{
+		X: bounds.Min.X + (bounds.Dx()-int(float64(box.Dx()-1)*scale))/2,
+		Y: bounds.Min.Y + (bounds.Dy()-int(float64(box.Dy()-1)*scale))/2,
+	}
+
+	fitted := make([]image.Point, len(pts))
+	for i, pt := range pts {
+		pt = pt.Sub(box.Min)
+		fitted[i] = image.Point{
+			X: offset.X + int(float64(pt.X)*scale),
+			Y: offset.Y + int(float64(pt.Y)*scale),
+		}
+	}
+	return fitted
+}
+
+// Size returns the size of the rendered image.
+func (r *GlowRenderer) Size() image.Point {
+	return r.img.Rect.Size()
+}
+
+// Points returns the position of each LED in the rendered image.
+func (r *GlowRenderer) Points() []image.Point {
+	return r.points
+}
+
+// Render draws the given LEDs and returns the image. The returned image is
+// reused by the next call to Render.
+func (r *GlowRenderer) Render(leds leddraw.LEDStrip) *image.RGBA {
+	for i := range r.acc {
+		r.acc[i] = 0
+	}
+
+	w := r.img.Rect.Dx()
+	h := r.img.Rect.Dy()
+	size := 2*r.radius + 1
+
+	for i, led := range leds {
+		if i >= len(r.points) {
+			break
+		}
+		if led.R == 0 && led.G == 0 && led.B == 0 {
+			continue
+		}
+
+		center := r.points[i]
+		for ky := 0; ky < size; ky++ {
+			y := center.Y + ky - r.radius
+			if y < 0 || y >= h {
+				continue
+			}
+			for kx := 0; kx < size; kx++ {
+				x := center.X + kx - r.radius
+				if x < 0 || x >= w {
+					continue
+				}
+				k := r.kernel[ky*size+kx]
+				if k == 0 {
+					continue
+				}
+				p := 3 * (y*w + x)
+				r.acc[p+0] += k * float32(led.R)
+				r.acc[p+1] += k * float32(led.G)
+				r.acc[p+2] += k * float32(led.B)
+			}
+		}
+	}
+
+	for i, j := 0, 0; i < len(r.acc); i, j = i+3, j+4 {
+		r.img.Pix[j+0] = clamp8(r.acc[i+0])
+		r.img.Pix[j+1] = clamp8(r.acc[i+1])
+		r.img.Pix[j+2] = clamp8(r.acc[i+2])
+		r.img.Pix[j+3] = 0xFF
+	}
+
+	return r.img
+}
+
+func clamp8(v float32) uint8 {
+	if v >= 0xFF {
+		return 0xFF
+	}
+	return uint8(v)
+}
@@ -0,0 +1,40 @@
+package ledsim
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"dev.acmcsuf.com/christmas/lib/leddraw"
+	"dev.acmcsuf.com/christmas/lib/xcolor"
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestFitPoints(t *testing.T) {
+	pts := FitPoints(
+		[]image.Point{{10, 10}, {30, 10}, {20, 20}},
+		image.Rect(0, 0, 41, 41))
+	assert.Equal(t, []image.Point{{0, 10}, {40, 10}, {20, 30}}, pts)
+}
+
+func TestGlowRenderer(t *testing.T) {
+	r := NewGlowRenderer(
+		[]image.Point{{0, 0}, {100, 0}},
+		image.Pt(50, 10), 4)
+
+	img := r.Render(leddraw.LEDStrip{
+		xcolor.RGB{R: 0xFF},
+		xcolor.RGB{G: 0xFF},
+	})
+
+	pts := r.Points()
+	assert.Equal(t, color.RGBA{0xFF, 0, 0, 0xFF}, img.RGBAAt(pts[0].X, pts[0].Y))
+	assert.Equal(t, color.RGBA{0, 0xFF, 0, 0xFF}, img.RGBAAt(pts[1].X, pts[1].Y))
+
+	// The glow fades out away from the LED.
+	glow := img.RGBAAt(pts[0].X+2, pts[0].Y)
+	assert.True(t, glow.R > 0 && glow.R < 0xFF, "expected partial glow, got %v", glow)
+
+	// Pixels far away from any LED are black.
+	assert.Equal(t, color.RGBA{0, 0, 0, 0xFF}, img.RGBAAt(25, 5))
+}
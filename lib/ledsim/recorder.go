@@ -0,0 +1,55 @@
+package ledsim
+
+import (
+	"sync"
+	"time"
+
+	"dev.acmcsuf.com/christmas/lib/animation"
+	"dev.acmcsuf.com/christmas/lib/leddraw"
+)
+
+// Recorder records every LED strip written to it as an animation frame. Each
+// frame lasts until the next write. It is an ledout.Writer.
+type Recorder struct {
+	// Now returns the current time. It defaults to time.Now.
+	Now func() time.Time
+
+	frames   []animation.Frame[leddraw.LEDStrip]
+	lastTime time.Time
+	mu       sync.Mutex
+}
+
+// NewRecorder creates a new Recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{Now: time.Now}
+}
+
+// Write implements ledout.Writer.
+func (r *Recorder) Write(leds leddraw.LEDStrip) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.Now()
+	r.finishLastFrame(now)
+	r.frames = append(r.frames, animation.Frame[leddraw.LEDStrip]{
+		Image: append(leddraw.LEDStrip(nil), leds...),
+	})
+	r.lastTime = now
+
+	return nil
+}
+
+// Frames returns the frames recorded so far. The last frame lasts until now.
+func (r *Recorder) Frames() []animation.Frame[leddraw.LEDStrip] {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.finishLastFrame(r.Now())
+	return append([]animation.Frame[leddraw.LEDStrip](nil), r.frames...)
+}
+
+func (r *Recorder) finishLastFrame(now time.Time) {
+	if len(r.frames) > 0 {
+		r.frames[len(r.frames)-1].DurationMs = animation.DurationToMs(now.Sub(r.lastTime))
+	}
+}
@@ -0,0 +1,60 @@
+package opc
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"sync"
+
+	"dev.acmcsuf.com/christmas/lib/leddraw"
+)
+
+// Client is a client that streams LED colors to an OPC server.
+type Client struct {
+	conn net.Conn
+	w    *bufio.Writer
+	buf  []byte
+	mu   sync.Mutex
+}
+
+// Dial connects to the OPC server at the given address. If the address has no
+// port, DefaultPort is used.
+func Dial(ctx context.Context, addr string) (*Client, error) {
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, fmt.Sprint(DefaultPort))
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		conn: conn,
+		w:    bufio.NewWriter(conn),
+	}, nil
+}
+
+// SetPixels sets the colors of the LEDs on the given channel.
+func (c *Client) SetPixels(channel uint8, leds leddraw.LEDStrip) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.buf = AppendPixels(c.buf[:0], leds)
+	if err := WriteMessage(c.w, Message{
+		Channel: channel,
+		Command: SetPixelColors,
+		Data:    c.buf,
+	}); err != nil {
+		return err
+	}
+
+	return c.w.Flush()
+}
+
+// Close closes the connection.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
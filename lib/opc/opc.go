@@ -0,0 +1,106 @@
+// Package opc implements Open Pixel Control, a simple protocol for streaming
+// LED colors over TCP. See http://openpixelcontrol.org.
+package opc
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"dev.acmcsuf.com/christmas/lib/leddraw"
+)
+
+// DefaultPort is the default TCP port of OPC servers.
+const DefaultPort = 7890
+
+// BroadcastChannel is the channel that addresses all channels.
+const BroadcastChannel = 0
+
+// Command is an OPC command.
+type Command uint8
+
+const (
+	// SetPixelColors sets the colors of the pixels. The data is a list of
+	// RGB triplets starting from the first pixel.
+	SetPixelColors Command = 0
+	// SystemExclusive is a vendor-specific command. The first two bytes of
+	// the data are the system ID.
+	SystemExclusive Command = 255
+)
+
+const headerSize = 4
+
+// MaxDataSize is the maximum size of the data of a message.
+const MaxDataSize = 0xFFFF
+
+// Message is an OPC message.
+type Message struct {
+	Channel uint8
+	Command Command
+	Data    []byte
+}
+
+// ReadMessage reads a single message from r. The data of the message reuses
+// buf if it is big enough.
+func ReadMessage(r io.Reader, buf []byte) (Message, error) {
+	var header [headerSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return Message{}, err
+	}
+
+	length := int(binary.BigEndian.Uint16(header[2:4]))
+	if cap(buf) < length {
+		buf = make([]byte, length)
+	}
+	buf = buf[:length]
+
+	if _, err := io.ReadFull(r, buf); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return Message{}, fmt.Errorf("cannot read message data: %w", err)
+	}
+
+	return Message{
+		Channel: header[0],
+		Command: Command(header[1]),
+		Data:    buf,
+	}, nil
+}
+
+// WriteMessage writes a single message to w.
+func WriteMessage(w io.Writer, msg Message) error {
+	if len(msg.Data) > MaxDataSize {
+		return fmt.Errorf("message data too long (%d bytes)", len(msg.Data))
+	}
+
+	b := make([]byte, headerSize, headerSize+len(msg.Data))
+	b[0] = msg.Channel
+	b[1] = uint8(msg.Command)
+	binary.BigEndian.PutUint16(b[2:4], uint16(len(msg.Data)))
+	b = append(b, msg.Data...)
+
+	_, err := w.Write(b)
+	return err
+}
+
+// AppendPixels appends the colors of the LEDs to b as RGB triplets.
+func AppendPixels(b []byte, leds leddraw.LEDStrip) []byte {
+	for _, led := range leds {
+		b = append(b, led.R, led.G, led.B)
+	}
+	return b
+}
+
+// DecodePixels decodes the RGB triplets in data into leds. Only as many LEDs
+// as there are complete triplets are set; the rest are left as they are.
+func DecodePixels(leds leddraw.LEDStrip, data []byte) {
+	for i := range leds {
+		if 3*i+3 > len(data) {
+			break
+		}
+		leds[i].R = data[3*i+0]
+		leds[i].G = data[3*i+1]
+		leds[i].B = data[3*i+2]
+	}
+}
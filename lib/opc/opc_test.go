@@ -0,0 +1,74 @@
+package opc
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"dev.acmcsuf.com/christmas/lib/leddraw"
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestMessage(t *testing.T) {
+	var buf bytes.Buffer
+	msg := Message{Channel: 1, Command: SetPixelColors, Data: []byte{1, 2, 3}}
+	assert.NoError(t, WriteMessage(&buf, msg))
+	assert.Equal(t, []byte{1, 0, 0, 3, 1, 2, 3}, buf.Bytes())
+
+	got, err := ReadMessage(&buf, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, msg, got)
+
+	_, err = ReadMessage(bytes.NewReader([]byte{1, 0, 0, 3, 1}), nil)
+	assert.Error(t, err)
+}
+
+func TestServer(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	frames := make(chan leddraw.LEDStrip, 10)
+	done := make(chan error, 1)
+
+	s := NewServer(1, 3)
+	go func() {
+		done <- s.Serve(ctx, ln, func(leds leddraw.LEDStrip) error {
+			frames <- leds
+			return nil
+		})
+	}()
+
+	c, err := Dial(ctx, ln.Addr().String())
+	assert.NoError(t, err)
+	defer c.Close()
+
+	assert.NoError(t, c.SetPixels(1, leddraw.LEDStrip{{R: 1}, {G: 2}, {B: 3}}))
+	assert.Equal(t, leddraw.LEDStrip{{R: 1}, {G: 2}, {B: 3}}, expectFrame(t, frames))
+
+	// Messages for other channels are ignored.
+	assert.NoError(t, c.SetPixels(2, leddraw.LEDStrip{{R: 9}}))
+
+	// Short messages only set the first LEDs.
+	assert.NoError(t, c.SetPixels(BroadcastChannel, leddraw.LEDStrip{{R: 4}}))
+	assert.Equal(t, leddraw.LEDStrip{{R: 4}, {G: 2}, {B: 3}}, expectFrame(t, frames))
+
+	cancel()
+	assert.IsError(t, <-done, context.Canceled)
+}
+
+func expectFrame(t *testing.T, frames <-chan leddraw.LEDStrip) leddraw.LEDStrip {
+	t.Helper()
+
+	select {
+	case frame := <-frames:
+		return frame
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for frame")
+		return nil
+	}
+}
@@ -0,0 +1,118 @@
+package opc
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"sync"
+
+	"dev.acmcsuf.com/christmas/lib/leddraw"
+)
+
+// Server is an OPC server. Every client shares the same LEDs, so clients that
+// only set some of the LEDs keep the colors set by the others.
+type Server struct {
+	channel uint8
+	leds    leddraw.LEDStrip
+	mu      sync.Mutex
+}
+
+// NewServer creates a new OPC server for the given number of LEDs. It accepts
+// messages for the given channel as well as the broadcast channel.
+func NewServer(channel uint8, numLEDs int) *Server {
+	return &Server{
+		channel: channel,
+		leds:    make(leddraw.LEDStrip, numLEDs),
+	}
+}
+
+// ListenAndServe listens on the given TCP address and serves clients like
+// Serve. If the address has no port, DefaultPort is used.
+func (s *Server) ListenAndServe(ctx context.Context, addr string, fn func(leddraw.LEDStrip) error) error {
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, fmt.Sprint(DefaultPort))
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("cannot listen for OPC: %w", err)
+	}
+
+	log.Println("receiving OPC on", ln.Addr())
+
+	return s.Serve(ctx, ln, fn)
+}
+
+// Serve accepts clients from the listener until the context is canceled,
+// calling fn with the LEDs after every set pixel colors message. The strip
+// given to fn is a copy that may be retained. The listener is closed when
+// Serve returns.
+func (s *Server) Serve(ctx context.Context, ln net.Listener, fn func(leddraw.LEDStrip) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stop := context.AfterFunc(ctx, func() { ln.Close() })
+	defer stop()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("cannot accept OPC client: %w", err)
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer conn.Close()
+
+			if err := s.serveConn(ctx, conn, fn); err != nil && ctx.Err() == nil {
+				log.Printf("OPC client %s: %v", conn.RemoteAddr(), err)
+			}
+		}()
+	}
+}
+
+func (s *Server) serveConn(ctx context.Context, conn net.Conn, fn func(leddraw.LEDStrip) error) error {
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	r := bufio.NewReader(conn)
+	var buf []byte
+
+	for {
+		msg, err := ReadMessage(r, buf)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		buf = msg.Data
+
+		if msg.Command != SetPixelColors {
+			continue
+		}
+		if msg.Channel != BroadcastChannel && msg.Channel != s.channel {
+			continue
+		}
+
+		s.mu.Lock()
+		DecodePixels(s.leds, msg.Data)
+		leds := append(leddraw.LEDStrip(nil), s.leds...)
+		s.mu.Unlock()
+
+		if err := fn(leds); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package patterns
+
+import (
+	"image"
+
+	"dev.acmcsuf.com/christmas/lib/xdraw"
+)
+
+// Layout describes where each LED is on the tree, normalized so that patterns
+// don't depend on the size of the tree.
+type Layout struct {
+	xs      []float64
+	heights []float64
+}
+
+// NewLayout creates a new Layout from the position of each LED in image
+// coordinates.
+func NewLayout(pts []image.Point) *Layout {
+	box := xdraw.BoundingBox(pts)
+	w := float64(box.Dx() - 1)
+	h := float64(box.Dy() - 1)
+
+	l := &Layout{
+		xs:      make([]float64, len(pts)),
+		heights: make([]float64, len(pts)),
+	}
+	for i, pt := range pts {
+		if w > 0 {
+			l.xs[i] = float64(pt.X-box.Min.X) / w
+		}
+		if h > 0 {
+			// Y grows downwards in images, but we want the bottom of the
+			// tree to be 0.
+			l.heights[i] = float64(box.Max.Y-1-pt.Y) / h
+		}
+	}
+	return l
+}
+
+// Len returns the number of LEDs.
+func (l *Layout) Len() int {
+	return len(l.xs)
+}
+
+// X returns the horizontal position of the LED at index i, from 0 on the left
+// to 1 on the right.
+func (l *Layout) X(i int) float64 {
+	return l.xs[i]
+}
+
+// Height returns the height of the LED at index i, from 0 at the bottom to 1
+// at the top.
+func (l *Layout) Height(i int) float64 {
+	return l.heights[i]
+}
@@ -0,0 +1,243 @@
+// Package patterns implements procedural patterns that are drawn directly onto
+// the LEDs, such as rainbows and twinkling lights.
+package patterns
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"dev.acmcsuf.com/christmas/lib/leddraw"
+	"dev.acmcsuf.com/christmas/lib/xcolor"
+)
+
+// Params are the parameters of a pattern. They follow WLED's effect
+// parameters, so 128 is the default speed and intensity.
+type Params struct {
+	// Colors are the primary, secondary and tertiary colors.
+	Colors [3]xcolor.RGB
+	// Speed is how fast the pattern moves.
+	Speed uint8
+	// Intensity is a pattern-specific amount, such as the size of a band or
+	// the number of twinkling LEDs.
+	Intensity uint8
+}
+
+// DefaultParams returns the default parameters: orange on black at the
+// default speed and intensity, like WLED.
+func DefaultParams() Params {
+	return Params{
+		Colors:    [3]xcolor.RGB{{R: 0xFF, G: 0xA0}, {}, {}},
+		Speed:     128,
+		Intensity: 128,
+	}
+}
+
+// cycles returns the number of cycles that have passed at time t for a
+// pattern that repeats perSecond times per second at the default speed.
+// Every 64 steps of speed doubles or halves the rate.
+func (p Params) cycles(t time.Duration, perSecond float64) float64 {
+	rate := perSecond * math.Exp2((float64(p.Speed)-128)/64)
+	return t.Seconds() * rate
+}
+
+// intensity returns the intensity in [0, 1].
+func (p Params) intensity() float64 {
+	return float64(p.Intensity) / 0xFF
+}
+
+// Pattern is a procedural pattern.
+type Pattern struct {
+	// Name is the human-readable name of the pattern.
+	Name string
+	// Static is true if the pattern doesn't change over time, so it only has
+	// to be drawn once.
+	Static bool
+	// Render draws the pattern at time t onto the LEDs.
+	Render func(leds leddraw.LEDStrip, layout *Layout, t time.Duration, p Params)
+}
+
+// All is the list of all patterns. Solid is always first.
+var All = []Pattern{
+	Solid,
+	Blink,
+	Breathe,
+	Rainbow,
+	RainbowWave,
+	ScanUp,
+	Twinkle,
+	CandyCane,
+	Snow,
+}
+
+// Find returns the pattern with the given name. Names are case-insensitive.
+func Find(name string) (Pattern, bool) {
+	for _, p := range All {
+		if strings.EqualFold(p.Name, name) {
+			return p, true
+		}
+	}
+	return Pattern{}, false
+}
+
+// Solid lights all LEDs with the primary color.
+var Solid = Pattern{
+	Name:   "Solid",
+	Static: true,
+	Render: func(leds leddraw.LEDStrip, layout *Layout, t time.Duration, p Params) {
+		for i := range leds {
+			leds[i] = p.Colors[0]
+		}
+	},
+}
+
+// Blink alternates between the primary and secondary colors. The intensity is
+// the portion of the time that the primary color is shown.
+var Blink = Pattern{
+	Name: "Blink",
+	Render: func(leds leddraw.LEDStrip, layout *Layout, t time.Duration, p Params) {
+		color := p.Colors[1]
+		if frac(p.cycles(t, 1)) < p.intensity() {
+			color = p.Colors[0]
+		}
+		for i := range leds {
+			leds[i] = color
+		}
+	},
+}
+
+// Breathe fades the primary color in and out of the secondary color.
+var Breathe = Pattern{
+	Name: "Breathe",
+	Render: func(leds leddraw.LEDStrip, layout *Layout, t time.Duration, p Params) {
+		level := (1 - math.Cos(2*math.Pi*p.cycles(t, 0.25))) / 2
+		color := xcolor.Lerp(p.Colors[1], p.Colors[0], level)
+		for i := range leds {
+			leds[i] = color
+		}
+	},
+}
+
+// Rainbow cycles all LEDs through the colors of the rainbow.
+var Rainbow = Pattern{
+	Name: "Rainbow",
+	Render: func(leds leddraw.LEDStrip, layout *Layout, t time.Duration, p Params) {
+		color := xcolor.HSV(p.cycles(t, 0.1), 1, 1)
+		for i := range leds {
+			leds[i] = color
+		}
+	},
+}
+
+// RainbowWave draws a rainbow that moves up the tree. The intensity is the
+// number of rainbows on the tree.
+var RainbowWave = Pattern{
+	Name: "Rainbow Wave",
+	Render: func(leds leddraw.LEDStrip, layout *Layout, t time.Duration, p Params) {
+		size := 0.25 + 2*p.intensity()
+		for i := range leds {
+			leds[i] = xcolor.HSV(layout.Height(i)*size-p.cycles(t, 0.25), 1, 1)
+		}
+	},
+}
+
+// ScanUp moves a band of the primary color up the tree on top of the
+// secondary color. The intensity is the height of the band.
+var ScanUp = Pattern{
+	Name: "Scan Up",
+	Render: func(leds leddraw.LEDStrip, layout *Layout, t time.Duration, p Params) {
+		width := 0.05 + 0.45*p.intensity()
+		// Move the band from fully below the tree to fully above it.
+		center := frac(p.cycles(t, 0.5))*(1+2*width) - width
+		for i := range leds {
+			d := math.Abs(layout.Height(i) - center)
+			leds[i] = xcolor.Lerp(p.Colors[1], p.Colors[0], 1-d/width)
+		}
+	},
+}
+
+// Twinkle makes random LEDs fade in and out with the primary color on top of
+// the secondary color. The intensity is the number of twinkling LEDs.
+var Twinkle = Pattern{
+	Name: "Twinkle",
+	Render: func(leds leddraw.LEDStrip, layout *Layout, t time.Duration, p Params) {
+		density := 0.1 + 0.9*p.intensity()
+		for i := range leds {
+			// Give each LED its own phase and rate so they don't twinkle in
+			// sync.
+			phase := hash(i, 0)
+			rate := 0.5 + hash(i, 1)
+			cycle := p.cycles(t, 0.5)*rate + phase
+
+			// Only some LEDs twinkle in each cycle.
+			level := 0.0
+			if hash(i, int(cycle)) < density {
+				level = math.Sin(math.Pi * frac(cycle))
+			}
+			leds[i] = xcolor.Lerp(p.Colors[1], p.Colors[0], level*level)
+		}
+	},
+}
+
+// CandyCane draws red and white diagonal stripes that move up the tree. The
+// intensity is the number of stripes.
+var CandyCane = Pattern{
+	Name: "Candy Cane",
+	Render: func(leds leddraw.LEDStrip, layout *Layout, t time.Duration, p Params) {
+		red := xcolor.RGB{R: 0xFF}
+		white := xcolor.RGB{R: 0xFF, G: 0xFF, B: 0xFF}
+		stripes := 2 + 10*p.intensity()
+		for i := range leds {
+			pos := (layout.Height(i)+layout.X(i)/2)*stripes/2 - p.cycles(t, 0.5)
+			if frac(pos) < 0.5 {
+				leds[i] = red
+			} else {
+				leds[i] = white
+			}
+		}
+	},
+}
+
+// Snow makes flakes of the primary color fall down the tree on top of the
+// secondary color. The intensity is the number of flakes.
+var Snow = Pattern{
+	Name: "Snow",
+	Render: func(leds leddraw.LEDStrip, layout *Layout, t time.Duration, p Params) {
+		const lanes = 16
+		const length = 0.15
+		density := 0.1 + 0.9*p.intensity()
+		for i := range leds {
+			// Flakes fall in vertical lanes, each at its own offset.
+			lane := int(layout.X(i) * (lanes - 1))
+			cycle := p.cycles(t, 0.3) + hash(lane, 0)
+
+			level := 0.0
+			if hash(lane, int(cycle)) < density {
+				// The flake is at the top at the start of each cycle and
+				// falls to the bottom by the end of it.
+				flake := 1 + length - frac(cycle)*(1+2*length)
+				d := layout.Height(i) - flake
+				if d >= 0 && d < length {
+					level = 1 - d/length
+				}
+			}
+			leds[i] = xcolor.Lerp(p.Colors[1], p.Colors[0], level)
+		}
+	},
+}
+
+func frac(x float64) float64 {
+	return x - math.Floor(x)
+}
+
+// hash returns a pseudo-random number in [0, 1) for the given numbers. It is
+// used instead of math/rand so that patterns only depend on time.
+func hash(a, b int) float64 {
+	x := uint32(a)*0x9E3779B1 ^ uint32(b)*0x85EBCA77
+	x ^= x >> 15
+	x *= 0x2C1B3C6D
+	x ^= x >> 12
+	x *= 0x297A2D39
+	x ^= x >> 15
+	return float64(x) / (1 << 32)
+}
@@ -0,0 +1,70 @@
+package patterns
+
+import (
+	"image"
+	"testing"
+	"time"
+
+	"dev.acmcsuf.com/christmas/lib/leddraw"
+	"dev.acmcsuf.com/christmas/lib/xcolor"
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestLayout(t *testing.T) {
+	layout := NewLayout([]image.Point{{0, 100}, {50, 50}, {100, 0}})
+	assert.Equal(t, 3, layout.Len())
+	assert.Equal(t, 0.0, layout.Height(0))
+	assert.Equal(t, 1.0, layout.Height(2))
+	assert.Equal(t, 0.0, layout.X(0))
+	assert.Equal(t, 1.0, layout.X(2))
+}
+
+func TestPatterns(t *testing.T) {
+	pts := make([]image.Point, 50)
+	for i := range pts {
+		pts[i] = image.Pt(i%5, i)
+	}
+	layout := NewLayout(pts)
+
+	params := DefaultParams()
+	params.Colors[1] = xcolor.RGB{B: 0xFF}
+
+	for _, pattern := range All {
+		t.Run(pattern.Name, func(t *testing.T) {
+			leds := make(leddraw.LEDStrip, len(pts))
+			pattern.Render(leds, layout, 1500*time.Millisecond, params)
+
+			// Patterns only depend on their inputs.
+			again := make(leddraw.LEDStrip, len(pts))
+			pattern.Render(again, layout, 1500*time.Millisecond, params)
+			assert.Equal(t, leds, again)
+
+			if pattern.Static {
+				later := make(leddraw.LEDStrip, len(pts))
+				pattern.Render(later, layout, time.Hour, params)
+				assert.Equal(t, leds, later)
+			}
+		})
+	}
+}
+
+func TestFind(t *testing.T) {
+	p, ok := Find("candy cane")
+	assert.True(t, ok)
+	assert.Equal(t, "Candy Cane", p.Name)
+
+	_, ok = Find("nope")
+	assert.False(t, ok)
+}
+
+func TestRainbow(t *testing.T) {
+	leds := make(leddraw.LEDStrip, 2)
+	layout := NewLayout([]image.Point{{0, 0}, {0, 1}})
+
+	Rainbow.Render(leds, layout, 0, DefaultParams())
+	assert.Equal(t, leddraw.LEDStrip{{R: 0xFF}, {R: 0xFF}}, leds)
+
+	// At the default speed, the rainbow cycles every 10 seconds.
+	Rainbow.Render(leds, layout, 10*time.Second/3, DefaultParams())
+	assert.Equal(t, leddraw.LEDStrip{{G: 0xFF}, {G: 0xFF}}, leds)
+}
@@ -0,0 +1,348 @@
+// Package schedule runs the tree unattended. It plays a playlist of named
+// shows, each for a while, and applies rules based on the time of day, such as
+// dimming the tree at night, turning it off while everyone is asleep or
+// playing a special show on the hour.
+//
+// The config is JSON, for example:
+//
+//	{
+//	  "enabled": true,
+//	  "shuffle": true,
+//	  "shows": [
+//	    { "name": "Snow", "sequence": "snow.lseq", "duration": "10m" },
+//	    { "name": "Logo", "image": "acm.gif", "duration": "5m" },
+//	    { "name": "Candy Cane", "pattern": "Candy Cane", "duration": "5m" },
+//	    { "name": "Chime", "sequence": "chime.fseq", "duration": "1m" }
+//	  ],
+//	  "playlist": ["Snow", "Logo", "Candy Cane"],
+//	  "transition": { "kind": "crossfade", "duration": "2s" },
+//	  "rules": [
+//	    { "from": "22:00", "to": "07:00", "brightness": 0.3 },
+//	    { "from": "02:00", "to": "07:00", "off": true },
+//	    { "every": "1h", "show": "Chime" }
+//	  ]
+//	}
+package schedule
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"dev.acmcsuf.com/christmas/lib/leddraw"
+	"dev.acmcsuf.com/christmas/lib/patterns"
+	"dev.acmcsuf.com/christmas/lib/xcolor"
+)
+
+// Day is the length of a day. Times of day are durations since midnight.
+const Day = 24 * time.Hour
+
+// Config is the config of a Scheduler.
+type Config struct {
+	// Enabled is true if the scheduler controls the tree. A disabled
+	// scheduler leaves the tree alone.
+	Enabled bool `json:"enabled"`
+	// Shows are the shows that can be played, by name.
+	Shows []Show `json:"shows"`
+	// Playlist is the names of the shows that are played in turn. If it is
+	// empty, every show is played.
+	Playlist []string `json:"playlist,omitempty"`
+	// Shuffle plays the playlist in a random order, shuffled again every
+	// time the playlist is played through.
+	Shuffle bool `json:"shuffle,omitempty"`
+	// Rules are applied on top of the playlist. See Rule.
+	Rules []Rule `json:"rules,omitempty"`
+	// Transition is how the tree changes from one show to the next.
+	Transition *Transition `json:"transition,omitempty"`
+}
+
+// Transition is a transition between shows.
+type Transition struct {
+	// Kind is the kind of transition, such as "crossfade", "wipe" or
+	// "dissolve".
+	Kind leddraw.TransitionKind `json:"kind"`
+	// Duration is how long the transition takes.
+	Duration Duration `json:"duration"`
+}
+
+// transition returns the transition between shows, which is a cut if there is
+// none.
+func (c *Config) transition() leddraw.Transition {
+	if c.Transition == nil {
+		return leddraw.Transition{}
+	}
+	return leddraw.Transition{
+		Kind:     c.Transition.Kind,
+		Duration: time.Duration(c.Transition.Duration),
+	}
+}
+
+// Show is a named show. Exactly one of Sequence, Image and Pattern is set.
+type Show struct {
+	// Name is the name of the show, which playlists and rules refer to.
+	Name string `json:"name"`
+	// Duration is how long the show is played for in the playlist. Shows
+	// that are shorter than this are looped or, if they don't loop, keep
+	// showing their last frame.
+	Duration Duration `json:"duration"`
+	// Sequence is the path to an LED sequence or FSEQ file.
+	Sequence string `json:"sequence,omitempty"`
+	// Image is the path to an image, animated image or video.
+	Image string `json:"image,omitempty"`
+	// Pattern is the name of a procedural pattern.
+	Pattern string `json:"pattern,omitempty"`
+	// Colors are the colors of the pattern as hex strings such as "#FF0000".
+	// The pattern's defaults are used for colors that aren't given.
+	Colors []string `json:"colors,omitempty"`
+}
+
+// Rule changes what the tree does at certain times of the day.
+//
+// A rule is active between From and To, or all day if they are equal. A To
+// earlier than From spans midnight. If Every is set, the rule is instead
+// active for Duration at every multiple of Every since midnight that is
+// within From and To.
+//
+// While several rules are active, the tree is off if any of them says so, it
+// has the lowest of their brightnesses, and it plays the show of the first of
+// them with a show instead of the playlist.
+type Rule struct {
+	// From is the time of day that the rule starts at.
+	From TimeOfDay `json:"from,omitempty"`
+	// To is the time of day that the rule ends at.
+	To TimeOfDay `json:"to,omitempty"`
+	// Every makes the rule repeat, e.g. every hour.
+	Every Duration `json:"every,omitempty"`
+	// Duration is how long the rule is active for every time it repeats. It
+	// defaults to the duration of Show.
+	Duration Duration `json:"duration,omitempty"`
+	// Off turns the tree off.
+	Off bool `json:"off,omitempty"`
+	// Brightness dims the tree to this brightness in (0, 1]. Zero leaves the
+	// brightness as is.
+	Brightness float64 `json:"brightness,omitempty"`
+	// Show is the name of the show that is played instead of the playlist.
+	Show string `json:"show,omitempty"`
+}
+
+// Validate checks that the config makes sense: that the shows have unique
+// names, content and durations, that their patterns exist, and that the
+// playlist and rules refer to existing shows. It doesn't check that the files
+// of the shows exist.
+func (c *Config) Validate() error {
+	names := make(map[string]bool, len(c.Shows))
+	for i, show := range c.Shows {
+		if show.Name == "" {
+			return fmt.Errorf("show %d has no name", i)
+		}
+		if names[show.Name] {
+			return fmt.Errorf("show %q is defined twice", show.Name)
+		}
+		names[show.Name] = true
+
+		var content int
+		for _, s := range []string{show.Sequence, show.Image, show.Pattern} {
+			if s != "" {
+				content++
+			}
+		}
+		if content != 1 {
+			return fmt.Errorf("show %q must have exactly one of sequence, image or pattern", show.Name)
+		}
+		if show.Duration <= 0 {
+			return fmt.Errorf("show %q has no duration", show.Name)
+		}
+		if show.Pattern != "" {
+			if _, _, err := show.PatternParams(); err != nil {
+				return fmt.Errorf("show %q: %w", show.Name, err)
+			}
+		}
+	}
+
+	for _, name := range c.Playlist {
+		if !names[name] {
+			return fmt.Errorf("playlist: unknown show %q", name)
+		}
+	}
+
+	for i, rule := range c.Rules {
+		if rule.From < 0 || rule.From >= TimeOfDay(Day) || rule.To < 0 || rule.To >= TimeOfDay(Day) {
+			return fmt.Errorf("rule %d: time of day out of range", i)
+		}
+		if rule.Brightness < 0 || rule.Brightness > 1 {
+			return fmt.Errorf("rule %d: brightness %g out of range (0, 1]", i, rule.Brightness)
+		}
+		if rule.Show != "" && !names[rule.Show] {
+			return fmt.Errorf("rule %d: unknown show %q", i, rule.Show)
+		}
+		if rule.Every < 0 || rule.Duration < 0 {
+			return fmt.Errorf("rule %d: negative duration", i)
+		}
+		if rule.Every > 0 && rule.Duration == 0 && rule.Show == "" {
+			return fmt.Errorf("rule %d: repeating rule needs a duration or a show", i)
+		}
+	}
+
+	if c.Transition != nil && c.Transition.Duration < 0 {
+		return fmt.Errorf("transition: negative duration")
+	}
+
+	return nil
+}
+
+// PatternParams returns the pattern of the show and its parameters. It
+// returns an error if the show isn't a pattern, if the pattern doesn't exist
+// or if a color is invalid.
+func (s Show) PatternParams() (patterns.Pattern, patterns.Params, error) {
+	pattern, ok := patterns.Find(s.Pattern)
+	if !ok {
+		return patterns.Pattern{}, patterns.Params{}, fmt.Errorf("unknown pattern %q", s.Pattern)
+	}
+
+	params := patterns.DefaultParams()
+	if len(s.Colors) > len(params.Colors) {
+		return patterns.Pattern{}, patterns.Params{}, fmt.Errorf("too many colors, patterns have at most %d", len(params.Colors))
+	}
+	for i, str := range s.Colors {
+		c, err := xcolor.RGBFromString(str)
+		if err != nil {
+			return patterns.Pattern{}, patterns.Params{}, fmt.Errorf("invalid color %q", str)
+		}
+		params.Colors[i] = c
+	}
+
+	return pattern, params, nil
+}
+
+// Find returns the show with the given name.
+func (c *Config) Find(name string) (Show, bool) {
+	for _, show := range c.Shows {
+		if show.Name == name {
+			return show, true
+		}
+	}
+	return Show{}, false
+}
+
+// playlist returns the shows of the playlist.
+func (c *Config) playlist() []Show {
+	if len(c.Playlist) == 0 {
+		return c.Shows
+	}
+	shows := make([]Show, 0, len(c.Playlist))
+	for _, name := range c.Playlist {
+		show, _ := c.Find(name)
+		shows = append(shows, show)
+	}
+	return shows
+}
+
+// LoadConfig reads and validates the config at path. A missing file is an
+// empty, disabled config.
+func LoadConfig(path string) (Config, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return Config{}, nil
+		}
+		return Config{}, err
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		return Config{}, fmt.Errorf("cannot decode %s: %w", path, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return Config{}, fmt.Errorf("invalid %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// SaveConfig writes the config to path. The file is replaced atomically, so
+// a crash never leaves a half-written config behind.
+func SaveConfig(path string, cfg Config) error {
+	b, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// Duration is a time.Duration that is written as a string such as "1h30m" in
+// JSON.
+type Duration time.Duration
+
+// MarshalJSON implements json.Marshaler.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// TimeOfDay is the time since midnight. It is written as "15:04" in JSON.
+type TimeOfDay time.Duration
+
+// ParseTimeOfDay parses a time of day in the form "15:04".
+func ParseTimeOfDay(s string) (TimeOfDay, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q", s)
+	}
+	return TimeOfDay(time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute), nil
+}
+
+// String returns the time of day in the form "15:04".
+func (t TimeOfDay) String() string {
+	d := time.Duration(t)
+	return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (t TimeOfDay) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (t *TimeOfDay) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	v, err := ParseTimeOfDay(s)
+	if err != nil {
+		return err
+	}
+	*t = v
+	return nil
+}
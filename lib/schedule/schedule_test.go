@@ -0,0 +1,331 @@
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"dev.acmcsuf.com/christmas/lib/leddraw"
+	"github.com/alecthomas/assert/v2"
+)
+
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	timers  []fakeTimer
+	waiting chan struct{}
+}
+
+type fakeTimer struct {
+	at time.Time
+	ch chan time.Time
+}
+
+func newFakeClock(now time.Time) *fakeClock {
+	return &fakeClock{now: now, waiting: make(chan struct{}, 100)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	c.timers = append(c.timers, fakeTimer{c.now.Add(d), ch})
+	c.waiting <- struct{}{}
+	return ch
+}
+
+// set sets the time and fires the timers that are due.
+func (c *fakeClock) set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = now
+	timers := c.timers[:0]
+	for _, t := range c.timers {
+		if now.Before(t.at) {
+			timers = append(timers, t)
+		} else {
+			t.ch <- now
+		}
+	}
+	c.timers = timers
+}
+
+// wait waits until the scheduler sleeps again.
+func (c *fakeClock) wait(t *testing.T) {
+	t.Helper()
+	select {
+	case <-c.waiting:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the scheduler")
+	}
+}
+
+type fakeTarget chan string
+
+func (t fakeTarget) PlayShow(ctx context.Context, show Show, brightness float64, transition leddraw.Transition) error {
+	t <- fmt.Sprintf("%s %.1f", show.Name, brightness)
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (t fakeTarget) TurnOff(ctx context.Context) error {
+	t <- "off"
+	return nil
+}
+
+func (t fakeTarget) next(tt *testing.T) string {
+	tt.Helper()
+	select {
+	case s := <-t:
+		return s
+	case <-time.After(time.Second):
+		tt.Fatal("timed out waiting for the target")
+		return ""
+	}
+}
+
+func (t fakeTarget) none(tt *testing.T) {
+	tt.Helper()
+	select {
+	case s := <-t:
+		tt.Fatalf("unexpected %q", s)
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+func at(hour, min int) time.Time {
+	return time.Date(2023, 12, 24, hour, min, 0, 0, time.UTC)
+}
+
+func tod(t *testing.T, s string) TimeOfDay {
+	v, err := ParseTimeOfDay(s)
+	assert.NoError(t, err)
+	return v
+}
+
+func startScheduler(t *testing.T, cfg Config, now time.Time) (*Scheduler, *fakeClock, fakeTarget) {
+	clock := newFakeClock(now)
+	target := make(fakeTarget, 10)
+	s := NewScheduler(target, clock)
+	assert.NoError(t, s.SetConfig(cfg))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	clock.wait(t)
+	return s, clock, target
+}
+
+var testShows = []Show{
+	{Name: "Snow", Pattern: "Snow", Duration: Duration(10 * time.Minute)},
+	{Name: "Logo", Image: "logo.gif", Duration: Duration(5 * time.Minute)},
+	{Name: "Chime", Sequence: "chime.lseq", Duration: Duration(time.Minute)},
+}
+
+func TestPlaylist(t *testing.T) {
+	_, clock, target := startScheduler(t, Config{
+		Enabled:  true,
+		Shows:    testShows,
+		Playlist: []string{"Snow", "Logo"},
+	}, at(18, 0))
+
+	assert.Equal(t, "Snow 1.0", target.next(t))
+
+	clock.set(at(18, 9))
+	clock.wait(t)
+	target.none(t)
+
+	clock.set(at(18, 10))
+	clock.wait(t)
+	assert.Equal(t, "Logo 1.0", target.next(t))
+
+	clock.set(at(18, 15))
+	clock.wait(t)
+	assert.Equal(t, "Snow 1.0", target.next(t))
+}
+
+func TestShuffle(t *testing.T) {
+	s := NewScheduler(make(fakeTarget), newFakeClock(at(0, 0)))
+	assert.NoError(t, s.SetConfig(Config{
+		Enabled: true,
+		Shows:   testShows,
+		Shuffle: true,
+	}))
+
+	now := at(0, 0)
+	var last string
+	for cycle := 0; cycle < 10; cycle++ {
+		seen := make(map[string]bool)
+		for range testShows {
+			show, _, end := s.advancePlaylist(now)
+			assert.False(t, seen[show.Name], "%q played twice in a cycle", show.Name)
+			assert.NotEqual(t, last, show.Name, "%q played twice in a row", show.Name)
+			seen[show.Name] = true
+			last = show.Name
+			now = end
+		}
+	}
+}
+
+func TestRules(t *testing.T) {
+	s, clock, target := startScheduler(t, Config{
+		Enabled:  true,
+		Shows:    testShows,
+		Playlist: []string{"Snow"},
+		Rules: []Rule{
+			{From: tod(t, "22:00"), To: tod(t, "07:00"), Brightness: 0.3},
+			{From: tod(t, "02:00"), To: tod(t, "07:00"), Off: true},
+			{Every: Duration(time.Hour), Show: "Chime"},
+		},
+	}, at(21, 30))
+
+	assert.Equal(t, "Snow 1.0", target.next(t))
+	assert.Equal(t, Status{
+		Enabled:    true,
+		Show:       "Snow",
+		Brightness: 1,
+		Until:      at(21, 40),
+	}, s.Status())
+
+	clock.set(at(22, 0))
+	clock.wait(t)
+	assert.Equal(t, "Chime 0.3", target.next(t))
+
+	clock.set(at(22, 1))
+	clock.wait(t)
+	assert.Equal(t, "Snow 0.3", target.next(t))
+
+	clock.set(at(23, 0))
+	clock.wait(t)
+	assert.Equal(t, "Chime 0.3", target.next(t))
+
+	clock.set(at(2, 1).AddDate(0, 0, 1))
+	clock.wait(t)
+	assert.Equal(t, "off", target.next(t))
+	assert.Equal(t, Status{
+		Enabled: true,
+		Off:     true,
+		Until:   at(3, 0).AddDate(0, 0, 1),
+	}, s.Status())
+
+	clock.set(at(7, 0).AddDate(0, 0, 1))
+	clock.wait(t)
+	assert.Equal(t, "Chime 1.0", target.next(t))
+}
+
+func TestSetConfig(t *testing.T) {
+	s, clock, target := startScheduler(t, Config{
+		Enabled: true,
+		Shows:   testShows,
+	}, at(12, 0))
+
+	assert.Equal(t, "Snow 1.0", target.next(t))
+
+	assert.NoError(t, s.SetConfig(Config{Enabled: true, Shows: testShows[1:]}))
+	clock.wait(t)
+	assert.Equal(t, "Logo 1.0", target.next(t))
+
+	assert.NoError(t, s.SetConfig(Config{Shows: testShows}))
+	clock.wait(t)
+	target.none(t)
+	assert.Equal(t, Status{Brightness: 1, Until: at(0, 0).AddDate(0, 0, 1)}, s.Status())
+
+	err := s.SetConfig(Config{Shows: []Show{{Name: "Snow", Duration: Duration(time.Minute)}}})
+	assert.Error(t, err)
+}
+
+func TestCheckRule(t *testing.T) {
+	type check struct {
+		now    time.Time
+		active bool
+		next   time.Time
+	}
+
+	tests := []struct {
+		name   string
+		rule   Rule
+		checks []check
+	}{
+		{
+			name: "window",
+			rule: Rule{From: tod(t, "08:00"), To: tod(t, "17:00")},
+			checks: []check{
+				{at(7, 59), false, at(8, 0)},
+				{at(8, 0), true, at(17, 0)},
+				{at(17, 0), false, at(0, 0).AddDate(0, 0, 1)},
+			},
+		},
+		{
+			name: "over midnight",
+			rule: Rule{From: tod(t, "22:00"), To: tod(t, "02:00")},
+			checks: []check{
+				{at(1, 0), true, at(2, 0)},
+				{at(2, 0), false, at(22, 0)},
+				{at(23, 0), true, at(0, 0).AddDate(0, 0, 1)},
+			},
+		},
+		{
+			name: "every",
+			rule: Rule{From: tod(t, "08:00"), To: tod(t, "10:00"), Every: Duration(time.Hour)},
+			checks: []check{
+				{at(7, 0), false, at(8, 0)},
+				{at(8, 0), true, at(8, 5)},
+				{at(8, 5), false, at(9, 0)},
+				{at(10, 0), false, at(11, 0)},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, c := range test.checks {
+				active, _, next := checkRule(test.rule, 5*time.Minute, c.now, at(0, 0))
+				assert.Equal(t, c.active, active, "at %s", c.now.Format(time.Kitchen))
+				assert.Equal(t, c.next, next, "at %s", c.now.Format(time.Kitchen))
+			}
+		})
+	}
+}
+
+func TestConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schedule.json")
+
+	cfg, err := LoadConfig(path)
+	assert.NoError(t, err)
+	assert.Equal(t, Config{}, cfg)
+
+	cfg = Config{
+		Enabled: true,
+		Shows:   testShows,
+		Rules:   []Rule{{From: tod(t, "22:00"), To: tod(t, "07:30"), Brightness: 0.3}},
+	}
+	assert.NoError(t, SaveConfig(path, cfg))
+
+	loaded, err := LoadConfig(path)
+	assert.NoError(t, err)
+	assert.Equal(t, cfg, loaded)
+
+	b, err := json.Marshal(cfg.Rules[0])
+	assert.NoError(t, err)
+	assert.Equal(t, `{"from":"22:00","to":"07:30","brightness":0.3}`, string(b))
+}
@@ -0,0 +1,410 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+
+	"dev.acmcsuf.com/christmas/lib/leddraw"
+)
+
+// maxWait is the longest the scheduler sleeps before it looks at the clock
+// again, so that changes to the wall clock, such as NTP corrections, are
+// noticed.
+const maxWait = time.Minute
+
+// Target plays the shows of a Scheduler, such as a christmasd.Server.
+type Target interface {
+	// PlayShow plays the show with the LEDs scaled by brightness until the
+	// context is canceled, changing to it from whatever was shown before with
+	// the given transition. It may return early if the show ends.
+	PlayShow(ctx context.Context, show Show, brightness float64, transition leddraw.Transition) error
+	// TurnOff turns the LEDs off.
+	TurnOff(ctx context.Context) error
+}
+
+// Clock tells the time. It is a Clock so that tests can control the time.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// After returns a channel that receives the time once d has passed.
+	After(d time.Duration) <-chan time.Time
+}
+
+// SystemClock returns the system's clock.
+func SystemClock() Clock {
+	return systemClock{}
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// Status is what a Scheduler is currently doing.
+type Status struct {
+	// Enabled is true if the scheduler controls the tree.
+	Enabled bool
+	// Show is the name of the show being played, if any.
+	Show string
+	// Off is true if a rule turned the tree off.
+	Off bool
+	// Brightness is the brightness that the show is played at.
+	Brightness float64
+	// Until is the next time that the status may change.
+	Until time.Time
+}
+
+// Scheduler plays the shows of a Config on a Target.
+type Scheduler struct {
+	target Target
+	clock  Clock
+	reload chan struct{}
+
+	mu     sync.Mutex
+	cfg    Config
+	status Status
+
+	// playlist state
+	order   []int     // indices into the playlist in play order
+	pos     int       // position in order
+	started time.Time // when the current playlist show started
+}
+
+// NewScheduler creates a new Scheduler with an empty, disabled config.
+func NewScheduler(target Target, clock Clock) *Scheduler {
+	return &Scheduler{
+		target: target,
+		clock:  clock,
+		reload: make(chan struct{}, 1),
+	}
+}
+
+// Config returns the current config.
+func (s *Scheduler) Config() Config {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cfg
+}
+
+// SetConfig validates and applies the given config. The playlist starts over
+// from its first show.
+func (s *Scheduler) SetConfig(cfg Config) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.cfg = cfg
+	s.order = nil
+	s.pos = 0
+	s.started = time.Time{}
+	s.mu.Unlock()
+
+	select {
+	case s.reload <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Status returns what the scheduler is currently doing.
+func (s *Scheduler) Status() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status
+}
+
+// decision is what the tree should be doing at some time.
+type decision struct {
+	show       Show
+	start      time.Time // when this showing of show started
+	off        bool
+	brightness float64
+	until      time.Time
+}
+
+// key identifies a decision. A show is only restarted if the key changes.
+type decisionKey struct {
+	show       string
+	start      time.Time
+	off        bool
+	brightness float64
+}
+
+func (d decision) key() decisionKey {
+	return decisionKey{d.show.Name, d.start, d.off, d.brightness}
+}
+
+// Run runs the scheduler until the context is canceled. A show is restarted
+// whenever what should be played changes, including its brightness, and
+// whenever the config is changed.
+func (s *Scheduler) Run(ctx context.Context) error {
+	var current *decisionKey
+	var stop func()
+	defer func() {
+		if stop != nil {
+			stop()
+		}
+	}()
+
+	for {
+		select {
+		case <-s.reload:
+			current = nil
+		default:
+		}
+
+		now := s.clock.Now()
+
+		s.mu.Lock()
+		enabled := s.cfg.Enabled
+		transition := s.cfg.transition()
+		d := s.decide(now)
+		s.status = Status{
+			Enabled:    enabled,
+			Show:       d.show.Name,
+			Off:        d.off,
+			Brightness: d.brightness,
+			Until:      d.until,
+		}
+		s.mu.Unlock()
+
+		if key := d.key(); current == nil || *current != key {
+			current = &key
+			if stop != nil {
+				stop()
+				stop = nil
+			}
+
+			if enabled {
+				log.Println("schedule:", s.Status())
+			}
+
+			switch {
+			case !enabled:
+				// Leave the tree to whoever else controls it.
+			case d.off:
+				if err := s.target.TurnOff(ctx); err != nil && ctx.Err() == nil {
+					log.Println("failed to turn off the tree:", err)
+				}
+			case d.show.Name != "":
+				stop = s.play(ctx, d.show, d.brightness, transition)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.reload:
+			current = nil
+		case <-s.clock.After(min(d.until.Sub(now), maxWait)):
+		}
+	}
+}
+
+// play plays the show in the background. The returned function stops it and
+// waits for it to return.
+func (s *Scheduler) play(ctx context.Context, show Show, brightness float64, transition leddraw.Transition) (stop func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		if err := s.target.PlayShow(ctx, show, brightness, transition); err != nil && ctx.Err() == nil {
+			log.Printf("failed to play show %q: %v", show.Name, err)
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// decide decides what the tree should be doing at the given time. It must be
+// called with the mutex held.
+func (s *Scheduler) decide(now time.Time) decision {
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	d := decision{
+		brightness: 1,
+		until:      midnight.AddDate(0, 0, 1),
+	}
+	if !s.cfg.Enabled {
+		return d
+	}
+
+	var ruleShow string
+	var ruleStart time.Time
+	for _, rule := range s.cfg.Rules {
+		duration := time.Duration(rule.Duration)
+		if duration == 0 {
+			show, _ := s.cfg.Find(rule.Show)
+			duration = time.Duration(show.Duration)
+		}
+
+		active, start, next := checkRule(rule, duration, now, midnight)
+		if next.Before(d.until) {
+			d.until = next
+		}
+		if !active {
+			continue
+		}
+
+		if rule.Off {
+			d.off = true
+		}
+		if rule.Brightness > 0 {
+			d.brightness = min(d.brightness, rule.Brightness)
+		}
+		if rule.Show != "" && ruleShow == "" {
+			ruleShow = rule.Show
+			ruleStart = start
+		}
+	}
+
+	// The playlist keeps going while it is overridden, so it stays on time.
+	show, start, end := s.advancePlaylist(now)
+
+	switch {
+	case d.off:
+		d.brightness = 0
+	case ruleShow != "":
+		d.show, _ = s.cfg.Find(ruleShow)
+		d.start = ruleStart
+	default:
+		d.show = show
+		d.start = start
+		if !end.IsZero() && end.Before(d.until) {
+			d.until = end
+		}
+	}
+
+	return d
+}
+
+// checkRule checks whether the rule is active at the given time. start is
+// when it became active, and next is the next time that this may change.
+func checkRule(rule Rule, duration time.Duration, now, midnight time.Time) (active bool, start, next time.Time) {
+	t := now.Sub(midnight)
+	from := time.Duration(rule.From)
+	to := time.Duration(rule.To)
+
+	inWindow := func(t time.Duration) bool {
+		switch {
+		case from == to:
+			return true
+		case from < to:
+			return t >= from && t < to
+		default:
+			return t >= from || t < to
+		}
+	}
+
+	next = midnight.AddDate(0, 0, 1)
+	if from != to {
+		for _, b := range []time.Duration{from, to} {
+			if b > t && midnight.Add(b).Before(next) {
+				next = midnight.Add(b)
+			}
+		}
+	}
+
+	if rule.Every == 0 {
+		switch {
+		case from == to:
+			// Active all day, so it never restarts.
+		case t >= from:
+			start = midnight.Add(from)
+		default:
+			start = midnight.Add(from - Day)
+		}
+		return inWindow(t), start, next
+	}
+
+	every := time.Duration(rule.Every)
+	repeat := t / every * every
+	start = midnight.Add(repeat)
+
+	active = inWindow(repeat) && t < repeat+duration
+	if active && start.Add(duration).Before(next) {
+		next = start.Add(duration)
+	}
+	if start.Add(every).Before(next) {
+		next = start.Add(every)
+	}
+
+	return active, start, next
+}
+
+// advancePlaylist returns the playlist show to be played at the given time,
+// when it started and when it ends. It must be called with the mutex held.
+func (s *Scheduler) advancePlaylist(now time.Time) (show Show, start, end time.Time) {
+	shows := s.cfg.playlist()
+	if len(shows) == 0 {
+		return Show{}, time.Time{}, time.Time{}
+	}
+
+	if s.order == nil || s.started.IsZero() || now.Before(s.started) {
+		s.order = s.shuffle(len(shows), -1)
+		s.pos = 0
+		s.started = now
+	}
+
+	for i := 0; ; i++ {
+		show = shows[s.order[s.pos]]
+		end = s.started.Add(time.Duration(show.Duration))
+		if now.Before(end) {
+			return show, s.started, end
+		}
+
+		if i > len(shows) {
+			// We've fallen behind by more than the whole playlist, such as
+			// after the system was suspended, so just carry on from now.
+			s.started = now
+			continue
+		}
+
+		s.started = end
+		s.pos++
+		if s.pos == len(s.order) {
+			s.order = s.shuffle(len(shows), s.order[len(s.order)-1])
+			s.pos = 0
+		}
+	}
+}
+
+// shuffle returns the order to play n shows in. If the playlist is shuffled,
+// last is kept from being played twice in a row.
+func (s *Scheduler) shuffle(n, last int) []int {
+	if !s.cfg.Shuffle {
+		order := make([]int, n)
+		for i := range order {
+			order[i] = i
+		}
+		return order
+	}
+
+	order := rand.Perm(n)
+	if n > 1 && order[0] == last {
+		order[0], order[n-1] = order[n-1], order[0]
+	}
+	return order
+}
+
+// String returns the status in a human-readable form.
+func (s Status) String() string {
+	switch {
+	case !s.Enabled:
+		return "disabled"
+	case s.Off:
+		return fmt.Sprintf("off until %s", s.Until.Format(time.Kitchen))
+	case s.Show == "":
+		return "idle"
+	default:
+		return fmt.Sprintf("playing %q at %.0f%% until %s", s.Show, s.Brightness*100, s.Until.Format(time.Kitchen))
+	}
+}
@@ -0,0 +1,143 @@
+package wled
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxBodySize is the maximum size of a request body. WLED itself only
+// accepts small requests.
+const maxBodySize = 64 << 10 // 64 KiB
+
+// Handler returns an HTTP handler that serves the WLED JSON API.
+func (c *Controller) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/json", c.handleAll)
+	mux.HandleFunc("/json/", c.handleAll)
+	mux.HandleFunc("/json/state", c.handleState)
+	mux.HandleFunc("/json/info", c.handleInfo)
+	mux.HandleFunc("/json/si", c.handleStateInfo)
+	mux.HandleFunc("/json/eff", c.handleEffects)
+	mux.HandleFunc("/json/effects", c.handleEffects)
+	mux.HandleFunc("/json/pal", c.handlePalettes)
+	mux.HandleFunc("/json/palettes", c.handlePalettes)
+	return mux
+}
+
+func (c *Controller) handleAll(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/json" && r.URL.Path != "/json/" {
+		writeError(w, http.StatusNotFound, fmt.Errorf("%s not found", r.URL.Path))
+		return
+	}
+	if !allowMethods(w, r, http.MethodGet, http.MethodPost) {
+		return
+	}
+
+	// Posting to /json is the same as posting to /json/state.
+	if r.Method == http.MethodPost {
+		c.handleState(w, r)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"state":    c.State(),
+		"info":     c.Info(),
+		"effects":  Effects(),
+		"palettes": Palettes(),
+	})
+}
+
+func (c *Controller) handleState(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet, http.MethodPost) {
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		writeJSON(w, http.StatusOK, c.State())
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("cannot read body: %w", err))
+		return
+	}
+
+	var update stateUpdate
+	if err := json.Unmarshal(body, &update); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("cannot decode body: %w", err))
+		return
+	}
+
+	if err := c.update(&update); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if update.V {
+		writeJSON(w, http.StatusOK, c.State())
+	} else {
+		writeJSON(w, http.StatusOK, map[string]bool{"success": true})
+	}
+}
+
+func (c *Controller) handleInfo(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+	writeJSON(w, http.StatusOK, c.Info())
+}
+
+func (c *Controller) handleStateInfo(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"state": c.State(),
+		"info":  c.Info(),
+	})
+}
+
+func (c *Controller) handleEffects(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+	writeJSON(w, http.StatusOK, Effects())
+}
+
+func (c *Controller) handlePalettes(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+	writeJSON(w, http.StatusOK, Palettes())
+}
+
+func allowMethods(w http.ResponseWriter, r *http.Request, methods ...string) bool {
+	for _, method := range methods {
+		if r.Method == method {
+			return true
+		}
+	}
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(b)
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,186 @@
+package wled
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"dev.acmcsuf.com/christmas/lib/xcolor"
+)
+
+// Color is a WLED color. It is encoded as an [R, G, B] array.
+type Color [3]uint8
+
+// UnmarshalJSON implements json.Unmarshaler. Besides [R, G, B], it also
+// accepts [R, G, B, W], where W is ignored, and "RRGGBB" hex strings.
+func (c *Color) UnmarshalJSON(b []byte) error {
+	var hex string
+	if err := json.Unmarshal(b, &hex); err == nil {
+		v, err := strconv.ParseUint(strings.TrimPrefix(hex, "#"), 16, 32)
+		if err != nil || len(strings.TrimPrefix(hex, "#")) != 6 {
+			return fmt.Errorf("invalid hex color %q", hex)
+		}
+		*c = colorFromRGB(xcolor.RGBFromUint(uint32(v)))
+		return nil
+	}
+
+	var values []uint8
+	if err := json.Unmarshal(b, &values); err != nil {
+		return fmt.Errorf("invalid color: %w", err)
+	}
+	if len(values) < 3 {
+		return fmt.Errorf("invalid color %v: expected at least 3 values", values)
+	}
+	copy(c[:], values)
+	return nil
+}
+
+// RGB converts the color to an xcolor.RGB.
+func (c Color) RGB() xcolor.RGB {
+	return xcolor.RGB{R: c[0], G: c[1], B: c[2]}
+}
+
+func colorFromRGB(rgb xcolor.RGB) Color {
+	return Color{rgb.R, rgb.G, rgb.B}
+}
+
+// State is the state of the lights, as served on /json/state. Only the fields
+// that are supported are included.
+type State struct {
+	On         bool      `json:"on"`
+	Bri        uint8     `json:"bri"`
+	Transition int       `json:"transition"`
+	PS         int       `json:"ps"`
+	PL         int       `json:"pl"`
+	MainSeg    int       `json:"mainseg"`
+	Seg        []Segment `json:"seg"`
+}
+
+// Segment is the state of a segment. There is always exactly one segment
+// that spans all LEDs.
+type Segment struct {
+	ID    int      `json:"id"`
+	Start int      `json:"start"`
+	Stop  int      `json:"stop"`
+	Len   int      `json:"len"`
+	On    bool     `json:"on"`
+	Bri   uint8    `json:"bri"`
+	Col   [3]Color `json:"col"`
+	Fx    int      `json:"fx"`
+	Sx    uint8    `json:"sx"`
+	Ix    uint8    `json:"ix"`
+	Pal   int      `json:"pal"`
+	Sel   bool     `json:"sel"`
+	Rev   bool     `json:"rev"`
+	Mi    bool     `json:"mi"`
+}
+
+// Info is the information about the device, as served on /json/info.
+type Info struct {
+	Ver      string   `json:"ver"`
+	VID      int      `json:"vid"`
+	LEDs     LEDInfo  `json:"leds"`
+	Str      bool     `json:"str"`
+	Name     string   `json:"name"`
+	UDPPort  int      `json:"udpport"`
+	Live     bool     `json:"live"`
+	FxCount  int      `json:"fxcount"`
+	PalCount int      `json:"palcount"`
+	Arch     string   `json:"arch"`
+	Core     string   `json:"core"`
+	Brand    string   `json:"brand"`
+	Product  string   `json:"product"`
+	MAC      string   `json:"mac"`
+	IP       string   `json:"ip"`
+	Uptime   int      `json:"uptime"`
+	FreeHeap int      `json:"freeheap"`
+	WiFi     WiFiInfo `json:"wifi"`
+}
+
+// LEDInfo is the information about the LEDs.
+type LEDInfo struct {
+	Count  int   `json:"count"`
+	FPS    int   `json:"fps"`
+	RGBW   bool  `json:"rgbw"`
+	WV     bool  `json:"wv"`
+	CCT    bool  `json:"cct"`
+	Pwr    int   `json:"pwr"`
+	MaxPwr int   `json:"maxpwr"`
+	MaxSeg int   `json:"maxseg"`
+	LC     int   `json:"lc"`
+	SegLC  []int `json:"seglc"`
+}
+
+// WiFiInfo is the information about the WiFi connection. It is always made
+// up, since christmasd doesn't know about it.
+type WiFiInfo struct {
+	BSSID   string `json:"bssid"`
+	RSSI    int    `json:"rssi"`
+	Signal  int    `json:"signal"`
+	Channel int    `json:"channel"`
+}
+
+// stateUpdate is a partial update to the state, as posted to /json/state.
+// Absent fields are left unchanged.
+type stateUpdate struct {
+	// On is either a boolean or "t" to toggle.
+	On  *toggle `json:"on"`
+	Bri *uint8  `json:"bri"`
+	// Seg is either a single segment or an array of segments.
+	Seg json.RawMessage `json:"seg"`
+	// V asks for the new state to be returned.
+	V bool `json:"v"`
+}
+
+// segmentUpdate is a partial update to a segment.
+type segmentUpdate struct {
+	ID  *int     `json:"id"`
+	On  *toggle  `json:"on"`
+	Bri *uint8   `json:"bri"`
+	Col []*Color `json:"col"`
+	Fx  *int     `json:"fx"`
+	Sx  *uint8   `json:"sx"`
+	Ix  *uint8   `json:"ix"`
+	Pal *int     `json:"pal"`
+}
+
+// segments returns the segment updates, whether Seg is a single segment or an
+// array of them.
+func (u *stateUpdate) segments() ([]segmentUpdate, error) {
+	if len(u.Seg) == 0 {
+		return nil, nil
+	}
+
+	var segs []segmentUpdate
+	if err := json.Unmarshal(u.Seg, &segs); err == nil {
+		return segs, nil
+	}
+
+	var seg segmentUpdate
+	if err := json.Unmarshal(u.Seg, &seg); err != nil {
+		return nil, fmt.Errorf("invalid seg: %w", err)
+	}
+	return []segmentUpdate{seg}, nil
+}
+
+// toggle is a boolean that may also be "t" to flip the current value.
+type toggle struct {
+	value bool
+	flip  bool
+}
+
+func (t *toggle) UnmarshalJSON(b []byte) error {
+	if string(b) == `"t"` {
+		t.flip = true
+		return nil
+	}
+	return json.Unmarshal(b, &t.value)
+}
+
+func (t *toggle) apply(v bool) bool {
+	if t.flip {
+		return !v
+	}
+	return t.value
+}
@@ -0,0 +1,332 @@
+// Package wled emulates the JSON API of WLED, a popular firmware for LED
+// controllers. This allows the tree to be controlled by existing WLED apps and
+// integrations, such as the WLED app and Home Assistant.
+//
+// Only a useful subset of the API is implemented: turning the lights on and
+// off, the brightness, the colors and the effects, which are the patterns in
+// package patterns. There is always a single segment spanning all LEDs.
+package wled
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"image"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"dev.acmcsuf.com/christmas/lib/animation"
+	"dev.acmcsuf.com/christmas/lib/leddraw"
+	"dev.acmcsuf.com/christmas/lib/patterns"
+	"dev.acmcsuf.com/christmas/lib/xcolor"
+	"golang.org/x/sync/errgroup"
+)
+
+// DefaultPort is the port that WLED apps connect to.
+const DefaultPort = 80
+
+// FPS is the rate at which animated effects are rendered.
+const FPS = 30
+
+// version is the WLED version that is reported to clients. Clients use it to
+// decide which features are available.
+const version = "0.14.0"
+
+// Target is where the controller shows the LEDs, such as a christmasd.Server.
+type Target interface {
+	// PlayLEDSource replaces the animation with the frames of src and plays
+	// them until src ends or the context is canceled.
+	PlayLEDSource(ctx context.Context, src animation.FrameSource[leddraw.LEDStrip], transition leddraw.Transition) error
+}
+
+// Controller is an emulated WLED device.
+type Controller struct {
+	target  Target
+	layout  *patterns.Layout
+	name    string
+	started time.Time
+	changed chan struct{}
+
+	state State
+	mu    sync.Mutex
+}
+
+// NewController creates a new Controller that shows the LEDs at the given
+// points on the target. The name is shown in WLED apps.
+func NewController(target Target, ledPoints []image.Point, name string) *Controller {
+	params := patterns.DefaultParams()
+	return &Controller{
+		target:  target,
+		layout:  patterns.NewLayout(ledPoints),
+		name:    name,
+		started: time.Now(),
+		changed: make(chan struct{}, 1),
+		state: State{
+			On:         true,
+			Bri:        128,
+			Transition: 7,
+			PS:         -1,
+			PL:         -1,
+			Seg: []Segment{{
+				Stop: len(ledPoints),
+				Len:  len(ledPoints),
+				On:   true,
+				Bri:  255,
+				Col: [3]Color{
+					colorFromRGB(params.Colors[0]),
+					colorFromRGB(params.Colors[1]),
+					colorFromRGB(params.Colors[2]),
+				},
+				Sx:  params.Speed,
+				Ix:  params.Intensity,
+				Sel: true,
+			}},
+		},
+	}
+}
+
+// State returns the current state.
+func (c *Controller) State() State {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	state := c.state
+	state.Seg = append([]Segment(nil), c.state.Seg...)
+	return state
+}
+
+// Info returns the device information.
+func (c *Controller) Info() Info {
+	n := c.layout.Len()
+	return Info{
+		Ver: version,
+		LEDs: LEDInfo{
+			Count:  n,
+			FPS:    FPS,
+			MaxSeg: 1,
+			LC:     1,
+			SegLC:  []int{1},
+		},
+		Name:     c.name,
+		UDPPort:  21324,
+		FxCount:  len(patterns.All),
+		PalCount: 1,
+		Arch:     "christmasd",
+		Core:     version,
+		Brand:    "WLED",
+		Product:  "FOSS",
+		MAC:      c.mac(),
+		Uptime:   int(time.Since(c.started).Seconds()),
+		WiFi:     WiFiInfo{Signal: 100},
+	}
+}
+
+// mac returns a made-up MAC address. Clients use it to tell devices apart, so
+// it is derived from the name to stay the same across restarts.
+func (c *Controller) mac() string {
+	h := fnv.New64a()
+	h.Write([]byte(c.name))
+	return fmt.Sprintf("%012x", h.Sum64()&0xFFFFFFFFFFFF)
+}
+
+// Effects returns the names of the effects, indexed by their ID.
+func Effects() []string {
+	names := make([]string, len(patterns.All))
+	for i, p := range patterns.All {
+		names[i] = p.Name
+	}
+	return names
+}
+
+// Palettes returns the names of the palettes, indexed by their ID. Palettes
+// aren't supported, so there is only the default one.
+func Palettes() []string {
+	return []string{"Default"}
+}
+
+// update applies a partial state update.
+func (c *Controller) update(u *stateUpdate) error {
+	segs, err := u.segments()
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Validate everything first so that a bad update changes nothing.
+	for _, s := range segs {
+		if s.ID != nil && *s.ID != 0 {
+			return fmt.Errorf("segment %d does not exist", *s.ID)
+		}
+		if s.Fx != nil && (*s.Fx < 0 || *s.Fx >= len(patterns.All)) {
+			return fmt.Errorf("effect %d does not exist", *s.Fx)
+		}
+		if len(s.Col) > len(Segment{}.Col) {
+			return fmt.Errorf("too many colors: %d", len(s.Col))
+		}
+	}
+
+	if u.On != nil {
+		c.state.On = u.On.apply(c.state.On)
+	}
+	if u.Bri != nil {
+		c.state.Bri = *u.Bri
+		// Like WLED, setting the brightness to 0 turns the lights off.
+		c.state.On = c.state.On && *u.Bri > 0
+	}
+
+	seg := &c.state.Seg[0]
+	for _, s := range segs {
+		if s.On != nil {
+			seg.On = s.On.apply(seg.On)
+		}
+		if s.Bri != nil {
+			seg.Bri = *s.Bri
+		}
+		for i, col := range s.Col {
+			// null leaves the color unchanged.
+			if col != nil {
+				seg.Col[i] = *col
+			}
+		}
+		if s.Fx != nil {
+			seg.Fx = *s.Fx
+		}
+		if s.Sx != nil {
+			seg.Sx = *s.Sx
+		}
+		if s.Ix != nil {
+			seg.Ix = *s.Ix
+		}
+	}
+
+	select {
+	case c.changed <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// Run plays the state on the target until the context is canceled. Nothing is
+// shown until the state is first changed, so that the controller doesn't
+// override other inputs unless it is used. Each change replaces the animation
+// of the target, and turning the lights off replaces it with black.
+func (c *Controller) Run(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-c.changed:
+	}
+
+	for {
+		playCtx, cancel := context.WithCancel(ctx)
+		done := make(chan error, 1)
+		go func() { done <- c.play(playCtx) }()
+
+		select {
+		case <-ctx.Done():
+			cancel()
+			<-done
+			return ctx.Err()
+		case <-c.changed:
+			cancel()
+			if err := <-done; err != nil {
+				return err
+			}
+		case err := <-done:
+			cancel()
+			return err
+		}
+	}
+}
+
+// play plays the current state on the target and waits until the context is
+// canceled, which is when the state changes.
+func (c *Controller) play(ctx context.Context) error {
+	err := c.target.PlayLEDSource(ctx, c.source(), leddraw.Transition{})
+	if err != nil && ctx.Err() == nil {
+		return fmt.Errorf("cannot play effect: %w", err)
+	}
+	<-ctx.Done()
+	return nil
+}
+
+// source returns the frames that show the current state. Animated effects are
+// rendered at FPS as they are played, and everything else is a single frame.
+func (c *Controller) source() animation.FrameSource[leddraw.LEDStrip] {
+	state := c.State()
+	seg := state.Seg[0]
+	pattern := patterns.All[seg.Fx]
+
+	if !state.On || !seg.On || pattern.Static {
+		leds := make(leddraw.LEDStrip, c.layout.Len())
+		if state.On && seg.On {
+			c.render(leds, state, 0)
+		}
+		return animation.NewSliceSource([]animation.Frame[leddraw.LEDStrip]{{Image: leds}})
+	}
+
+	start := time.Since(c.started)
+	frameDuration := time.Second / FPS
+
+	return animation.NewGeneratorSource(func(ctx context.Context, i int) (animation.Frame[leddraw.LEDStrip], error) {
+		leds := make(leddraw.LEDStrip, c.layout.Len())
+		c.render(leds, state, start+time.Duration(i)*frameDuration)
+		return animation.Frame[leddraw.LEDStrip]{
+			Image:      leds,
+			DurationMs: animation.DurationToMs(frameDuration),
+		}, nil
+	})
+}
+
+// render renders the effect of the state onto leds at time t since the
+// controller started.
+func (c *Controller) render(leds leddraw.LEDStrip, state State, t time.Duration) {
+	seg := state.Seg[0]
+	patterns.All[seg.Fx].Render(leds, c.layout, t, patterns.Params{
+		Colors:    [3]xcolor.RGB{seg.Col[0].RGB(), seg.Col[1].RGB(), seg.Col[2].RGB()},
+		Speed:     seg.Sx,
+		Intensity: seg.Ix,
+	})
+
+	bri := float64(state.Bri) / 0xFF * float64(seg.Bri) / 0xFF
+	for i := range leds {
+		leds[i] = leds[i].Scale(bri)
+	}
+}
+
+// ListenAndServe runs the controller and serves the WLED API over HTTP on the
+// given address. If the address has no port, DefaultPort is used.
+func (c *Controller) ListenAndServe(ctx context.Context, addr string) error {
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, fmt.Sprint(DefaultPort))
+	}
+
+	httpServer := &http.Server{
+		Addr:    addr,
+		Handler: c.Handler(),
+	}
+
+	errg, ctx := errgroup.WithContext(ctx)
+	errg.Go(func() error {
+		return c.Run(ctx)
+	})
+	errg.Go(func() error {
+		log.Println("serving WLED API on", addr)
+		if err := httpServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("failed to serve WLED API: %w", err)
+		}
+		return nil
+	})
+	errg.Go(func() error {
+		<-ctx.Done()
+		return httpServer.Shutdown(context.Background())
+	})
+	return errg.Wait()
+}
@@ -0,0 +1,163 @@
+package wled
+
+import (
+	"context"
+	"encoding/json"
+	"image"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"dev.acmcsuf.com/christmas/lib/animation"
+	"dev.acmcsuf.com/christmas/lib/leddraw"
+	"github.com/alecthomas/assert/v2"
+)
+
+type fakeTarget chan leddraw.LEDStrip
+
+func (t fakeTarget) PlayLEDSource(ctx context.Context, src animation.FrameSource[leddraw.LEDStrip], transition leddraw.Transition) error {
+	return animation.Feed(ctx, src, func(frame animation.Frame[leddraw.LEDStrip]) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case t <- frame.Image:
+			return nil
+		}
+	})
+}
+
+func (t fakeTarget) next(tt *testing.T) leddraw.LEDStrip {
+	tt.Helper()
+	select {
+	case leds := <-t:
+		return leds
+	case <-time.After(time.Second):
+		tt.Fatal("timed out waiting for LEDs")
+		return nil
+	}
+}
+
+var testPoints = []image.Point{{0, 0}, {0, 10}}
+
+func TestController(t *testing.T) {
+	target := make(fakeTarget, 1)
+	c := NewController(target, testPoints, "tree")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go c.Run(ctx)
+
+	srv := httptest.NewServer(c.Handler())
+	defer srv.Close()
+
+	var state State
+	post(t, srv, "/json/state", `{"on":true,"bri":255,"seg":{"col":["FF0000"],"fx":0},"v":true}`, &state)
+	assert.True(t, state.On)
+	assert.Equal(t, Color{0xFF, 0, 0}, state.Seg[0].Col[0])
+	assert.Equal(t, leddraw.LEDStrip{{R: 0xFF}, {R: 0xFF}}, target.next(t))
+
+	var resp map[string]bool
+	post(t, srv, "/json/state", `{"bri":128,"seg":[{"id":0,"col":[null,[0,0,255]]}]}`, &resp)
+	assert.Equal(t, map[string]bool{"success": true}, resp)
+	assert.Equal(t, leddraw.LEDStrip{{R: 0x80}, {R: 0x80}}, target.next(t))
+	assert.Equal(t, Color{0xFF, 0, 0}, c.State().Seg[0].Col[0])
+	assert.Equal(t, Color{0, 0, 0xFF}, c.State().Seg[0].Col[1])
+
+	post(t, srv, "/json", `{"on":"t"}`, &resp)
+	assert.Equal(t, leddraw.LEDStrip{{}, {}}, target.next(t))
+	assert.False(t, c.State().On)
+}
+
+func TestControllerAnimated(t *testing.T) {
+	target := make(fakeTarget)
+	c := NewController(target, testPoints, "tree")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go c.Run(ctx)
+
+	srv := httptest.NewServer(c.Handler())
+	defer srv.Close()
+
+	// Animated effects keep playing frames until the state changes.
+	var resp map[string]bool
+	post(t, srv, "/json/state", `{"on":true,"seg":{"fx":3}}`, &resp)
+	for i := 0; i < 5; i++ {
+		target.next(t)
+	}
+
+	// Turning the lights off replaces the effect with a single black frame.
+	post(t, srv, "/json/state", `{"on":false}`, &resp)
+	for {
+		if slices.Equal(target.next(t), leddraw.LEDStrip{{}, {}}) {
+			break
+		}
+	}
+	select {
+	case leds := <-target:
+		t.Fatalf("unexpected LEDs after turning off: %v", leds)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestControllerErrors(t *testing.T) {
+	c := NewController(make(fakeTarget, 1), testPoints, "tree")
+	srv := httptest.NewServer(c.Handler())
+	defer srv.Close()
+
+	for _, body := range []string{
+		`{"seg":{"fx":1000}}`,
+		`{"seg":{"id":1,"fx":1}}`,
+		`{"seg":{"col":["nothex"]}}`,
+		`{"bri":1000}`,
+	} {
+		resp, err := http.Post(srv.URL+"/json/state", "application/json", strings.NewReader(body))
+		assert.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, body)
+	}
+
+	// A bad update changes nothing.
+	assert.Equal(t, 0, c.State().Seg[0].Fx)
+}
+
+func TestInfo(t *testing.T) {
+	c := NewController(make(fakeTarget, 1), testPoints, "tree")
+	srv := httptest.NewServer(c.Handler())
+	defer srv.Close()
+
+	var all struct {
+		Info    Info     `json:"info"`
+		Effects []string `json:"effects"`
+	}
+	get(t, srv, "/json", &all)
+	assert.Equal(t, 2, all.Info.LEDs.Count)
+	assert.Equal(t, "tree", all.Info.Name)
+	assert.Equal(t, Effects(), all.Effects)
+	assert.Equal(t, "Solid", all.Effects[0])
+}
+
+func get(t *testing.T, srv *httptest.Server, path string, v any) {
+	t.Helper()
+
+	resp, err := http.Get(srv.URL + path)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(v))
+}
+
+func post(t *testing.T, srv *httptest.Server, path, body string, v any) {
+	t.Helper()
+
+	resp, err := http.Post(srv.URL+path, "application/json", strings.NewReader(body))
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(v))
+}
@@ -0,0 +1,56 @@
+package xcolor
+
+import "math"
+
+// HSV converts a color in the HSV color space to RGB. h is the hue in turns,
+// so 0 and 1 are both red; s and v are in [0, 1].
+func HSV(h, s, v float64) RGB {
+	h = h - math.Floor(h)
+	h *= 6
+
+	i := math.Floor(h)
+	f := h - i
+	p := v * (1 - s)
+	q := v * (1 - s*f)
+	t := v * (1 - s*(1-f))
+
+	var r, g, b float64
+	switch int(i) {
+	case 0:
+		r, g, b = v, t, p
+	case 1:
+		r, g, b = q, v, p
+	case 2:
+		r, g, b = p, v, t
+	case 3:
+		r, g, b = p, q, v
+	case 4:
+		r, g, b = t, p, v
+	default:
+		r, g, b = v, p, q
+	}
+
+	return RGB{
+		R: uint8(math.Round(r * 0xFF)),
+		G: uint8(math.Round(g * 0xFF)),
+		B: uint8(math.Round(b * 0xFF)),
+	}
+}
+
+// Lerp linearly interpolates between a and b. t is clamped to [0, 1].
+func Lerp(a, b RGB, t float64) RGB {
+	t = math.Max(0, math.Min(1, t))
+	lerp := func(a, b uint8) uint8 {
+		return uint8(math.Round(float64(a) + (float64(b)-float64(a))*t))
+	}
+	return RGB{
+		R: lerp(a.R, b.R),
+		G: lerp(a.G, b.G),
+		B: lerp(a.B, b.B),
+	}
+}
+
+// Scale scales the brightness of the color by f, which is clamped to [0, 1].
+func (c RGB) Scale(f float64) RGB {
+	return Lerp(RGB{}, c, f)
+}
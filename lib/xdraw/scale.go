@@ -0,0 +1,35 @@
+package xdraw
+
+import (
+	"image"
+
+	"github.com/disintegration/imaging"
+)
+
+// ScaleMode describes how an image is scaled onto a canvas of a different
+// size.
+type ScaleMode uint8
+
+const (
+	// ScaleFill scales the image to fill the entire canvas. Parts of the image
+	// that don't fit are cropped.
+	ScaleFill ScaleMode = iota
+	// ScaleFit scales the image to fit inside the canvas. The uncovered parts
+	// of the canvas are left transparent.
+	ScaleFit
+)
+
+// ScaleImage scales src onto a new canvas with the given bounds. The image is
+// centered on the canvas. The returned image is not alpha-premultiplied; it is
+// an *image.NRGBA casted to an *image.RGBA.
+func ScaleImage(src image.Image, bounds image.Rectangle, mode ScaleMode) *image.RGBA {
+	var scaled *image.NRGBA
+	switch mode {
+	case ScaleFit:
+		scaled = imaging.Fit(src, bounds.Dx(), bounds.Dy(), imaging.NearestNeighbor)
+	default:
+		scaled = imaging.Fill(src, bounds.Dx(), bounds.Dy(), imaging.Center, imaging.NearestNeighbor)
+	}
+	scaled = imaging.PasteCenter(image.NewNRGBA(bounds), scaled)
+	return (*image.RGBA)(scaled)
+}
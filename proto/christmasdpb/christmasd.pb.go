@@ -0,0 +1,2805 @@
+// christmasd.proto describes the wire protocol of christmasd. Every message is
+// sent over HTTP, either in binary Protobuf (application/x-protobuf) or in
+// Protobuf JSON (application/json).
+//
+// The routes are:
+//
+//   GET  /api/v1/info    -> CanvasInfo
+//   GET  /api/v1/leds    -> LEDStrip
+//   PUT  /api/v1/leds    <- LEDStrip
+//   PUT  /api/v1/led     <- SetLEDRequest
+//   POST /api/v1/image   <- ImageRequest
+//   POST /api/v1/frames  <- FramesRequest
+//   DELETE /api/v1/frames
+//   POST /api/v1/render  <- FramesRequest -> RenderResponse
+//   GET  /api/v1/stream  <- StreamFrame... -> StreamStats... (WebSocket)
+//   GET  /api/v1/preview -> PreviewMessage... (WebSocket or SSE)
+//   GET  /api/v1/schedule -> Schedule
+//   PUT  /api/v1/schedule <- Schedule
+//   GET  /api/v1/schedule/status -> ScheduleStatus
+//   GET  /api/v1/layers -> Layers
+//   PUT  /api/v1/layers <- LayerRequest
+//   DELETE /api/v1/layers?name=NAME
+//
+// Failed requests respond with an Error and a non-2xx status code.
+//
+// /api/v1/stream is a WebSocket that frames are streamed over. Each message is
+// a StreamFrame, in binary Protobuf for binary messages or in Protobuf JSON
+// for text messages. The server replies with StreamStats in the same format
+// about once a second.
+//
+// Regenerate the Go code with `make proto`.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.31.0
+// 	protoc        (unknown)
+// source: proto/christmasd.proto
+
+package christmasdpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// ScaleMode describes how an image is scaled onto the LED canvas.
+type ScaleMode int32
+
+const (
+	// SCALE_FILL scales the image to fill the canvas, cropping the edges.
+	ScaleMode_SCALE_FILL ScaleMode = 0
+	// SCALE_FIT scales the image to fit inside the canvas.
+	ScaleMode_SCALE_FIT ScaleMode = 1
+)
+
+// Enum value maps for ScaleMode.
+var (
+	ScaleMode_name = map[int32]string{
+		0: "SCALE_FILL",
+		1: "SCALE_FIT",
+	}
+	ScaleMode_value = map[string]int32{
+		"SCALE_FILL": 0,
+		"SCALE_FIT":  1,
+	}
+)
+
+func (x ScaleMode) Enum() *ScaleMode {
+	p := new(ScaleMode)
+	*p = x
+	return p
+}
+
+func (x ScaleMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ScaleMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_christmasd_proto_enumTypes[0].Descriptor()
+}
+
+func (ScaleMode) Type() protoreflect.EnumType {
+	return &file_proto_christmasd_proto_enumTypes[0]
+}
+
+func (x ScaleMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ScaleMode.Descriptor instead.
+func (ScaleMode) EnumDescriptor() ([]byte, []int) {
+	return file_proto_christmasd_proto_rawDescGZIP(), []int{0}
+}
+
+// Intensity selects the function that calculates how much a pixel contributes
+// to an LED based on the distance between them.
+type Intensity int32
+
+const (
+	// INTENSITY_DEFAULT uses the intensity function of the daemon.
+	Intensity_INTENSITY_DEFAULT Intensity = 0
+	// INTENSITY_STEP gives full intensity up to the max distance.
+	Intensity_INTENSITY_STEP Intensity = 1
+	// INTENSITY_LINEAR fades linearly up to the max distance.
+	Intensity_INTENSITY_LINEAR Intensity = 2
+	// INTENSITY_CUBIC fades with a cubic ease up to the max distance.
+	Intensity_INTENSITY_CUBIC Intensity = 3
+)
+
+// Enum value maps for Intensity.
+var (
+	Intensity_name = map[int32]string{
+		0: "INTENSITY_DEFAULT",
+		1: "INTENSITY_STEP",
+		2: "INTENSITY_LINEAR",
+		3: "INTENSITY_CUBIC",
+	}
+	Intensity_value = map[string]int32{
+		"INTENSITY_DEFAULT": 0,
+		"INTENSITY_STEP":    1,
+		"INTENSITY_LINEAR":  2,
+		"INTENSITY_CUBIC":   3,
+	}
+)
+
+func (x Intensity) Enum() *Intensity {
+	p := new(Intensity)
+	*p = x
+	return p
+}
+
+func (x Intensity) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Intensity) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_christmasd_proto_enumTypes[1].Descriptor()
+}
+
+func (Intensity) Type() protoreflect.EnumType {
+	return &file_proto_christmasd_proto_enumTypes[1]
+}
+
+func (x Intensity) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Intensity.Descriptor instead.
+func (Intensity) EnumDescriptor() ([]byte, []int) {
+	return file_proto_christmasd_proto_rawDescGZIP(), []int{1}
+}
+
+// Averaging selects the function that averages the pixels around an LED.
+type Averaging int32
+
+const (
+	// AVERAGING_DEFAULT uses the averaging function of the daemon.
+	Averaging_AVERAGING_DEFAULT Averaging = 0
+	// AVERAGING_SIMPLE takes the mean of the colors.
+	Averaging_AVERAGING_SIMPLE Averaging = 1
+	// AVERAGING_SQUARED takes the root mean square of the colors.
+	Averaging_AVERAGING_SQUARED Averaging = 2
+	// AVERAGING_NEAREST takes the color with the highest intensity.
+	Averaging_AVERAGING_NEAREST Averaging = 3
+)
+
+// Enum value maps for Averaging.
+var (
+	Averaging_name = map[int32]string{
+		0: "AVERAGING_DEFAULT",
+		1: "AVERAGING_SIMPLE",
+		2: "AVERAGING_SQUARED",
+		3: "AVERAGING_NEAREST",
+	}
+	Averaging_value = map[string]int32{
+		"AVERAGING_DEFAULT": 0,
+		"AVERAGING_SIMPLE":  1,
+		"AVERAGING_SQUARED": 2,
+		"AVERAGING_NEAREST": 3,
+	}
+)
+
+func (x Averaging) Enum() *Averaging {
+	p := new(Averaging)
+	*p = x
+	return p
+}
+
+func (x Averaging) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Averaging) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_christmasd_proto_enumTypes[2].Descriptor()
+}
+
+func (Averaging) Type() protoreflect.EnumType {
+	return &file_proto_christmasd_proto_enumTypes[2]
+}
+
+func (x Averaging) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Averaging.Descriptor instead.
+func (Averaging) EnumDescriptor() ([]byte, []int) {
+	return file_proto_christmasd_proto_rawDescGZIP(), []int{2}
+}
+
+// TransitionKind is how one animation changes into the next.
+type TransitionKind int32
+
+const (
+	// TRANSITION_CUT switches to the next animation immediately.
+	TransitionKind_TRANSITION_CUT TransitionKind = 0
+	// TRANSITION_CROSSFADE fades every LED from one animation to the next.
+	TransitionKind_TRANSITION_CROSSFADE TransitionKind = 1
+	// TRANSITION_WIPE switches the LEDs from the bottom of the tree to the top.
+	TransitionKind_TRANSITION_WIPE TransitionKind = 2
+	// TRANSITION_DISSOLVE switches the LEDs one by one in a random order.
+	TransitionKind_TRANSITION_DISSOLVE TransitionKind = 3
+)
+
+// Enum value maps for TransitionKind.
+var (
+	TransitionKind_name = map[int32]string{
+		0: "TRANSITION_CUT",
+		1: "TRANSITION_CROSSFADE",
+		2: "TRANSITION_WIPE",
+		3: "TRANSITION_DISSOLVE",
+	}
+	TransitionKind_value = map[string]int32{
+		"TRANSITION_CUT":       0,
+		"TRANSITION_CROSSFADE": 1,
+		"TRANSITION_WIPE":      2,
+		"TRANSITION_DISSOLVE":  3,
+	}
+)
+
+func (x TransitionKind) Enum() *TransitionKind {
+	p := new(TransitionKind)
+	*p = x
+	return p
+}
+
+func (x TransitionKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (TransitionKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_christmasd_proto_enumTypes[3].Descriptor()
+}
+
+func (TransitionKind) Type() protoreflect.EnumType {
+	return &file_proto_christmasd_proto_enumTypes[3]
+}
+
+func (x TransitionKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use TransitionKind.Descriptor instead.
+func (TransitionKind) EnumDescriptor() ([]byte, []int) {
+	return file_proto_christmasd_proto_rawDescGZIP(), []int{3}
+}
+
+// BlendMode is how the colors of a layer are combined with the colors below
+// it.
+type BlendMode int32
+
+const (
+	// BLEND_NORMAL replaces the colors below.
+	BlendMode_BLEND_NORMAL BlendMode = 0
+	// BLEND_ADD adds the colors together.
+	BlendMode_BLEND_ADD BlendMode = 1
+	// BLEND_MULTIPLY multiplies the colors, which darkens them.
+	BlendMode_BLEND_MULTIPLY BlendMode = 2
+	// BLEND_SCREEN multiplies the inverse of the colors, which brightens them.
+	BlendMode_BLEND_SCREEN BlendMode = 3
+	// BLEND_MAX takes the brightest of each channel.
+	BlendMode_BLEND_MAX BlendMode = 4
+)
+
+// Enum value maps for BlendMode.
+var (
+	BlendMode_name = map[int32]string{
+		0: "BLEND_NORMAL",
+		1: "BLEND_ADD",
+		2: "BLEND_MULTIPLY",
+		3: "BLEND_SCREEN",
+		4: "BLEND_MAX",
+	}
+	BlendMode_value = map[string]int32{
+		"BLEND_NORMAL":   0,
+		"BLEND_ADD":      1,
+		"BLEND_MULTIPLY": 2,
+		"BLEND_SCREEN":   3,
+		"BLEND_MAX":      4,
+	}
+)
+
+func (x BlendMode) Enum() *BlendMode {
+	p := new(BlendMode)
+	*p = x
+	return p
+}
+
+func (x BlendMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (BlendMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_christmasd_proto_enumTypes[4].Descriptor()
+}
+
+func (BlendMode) Type() protoreflect.EnumType {
+	return &file_proto_christmasd_proto_enumTypes[4]
+}
+
+func (x BlendMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use BlendMode.Descriptor instead.
+func (BlendMode) EnumDescriptor() ([]byte, []int) {
+	return file_proto_christmasd_proto_rawDescGZIP(), []int{4}
+}
+
+// LEDStrip is the color of every LED on the tree.
+type LEDStrip struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// colors is the color of each LED in 0xRRGGBB format. It has exactly as
+	// many colors as there are LEDs.
+	Colors []uint32 `protobuf:"varint,1,rep,packed,name=colors,proto3" json:"colors,omitempty"`
+}
+
+func (x *LEDStrip) Reset() {
+	*x = LEDStrip{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_christmasd_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LEDStrip) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LEDStrip) ProtoMessage() {}
+
+func (x *LEDStrip) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_christmasd_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LEDStrip.ProtoReflect.Descriptor instead.
+func (*LEDStrip) Descriptor() ([]byte, []int) {
+	return file_proto_christmasd_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *LEDStrip) GetColors() []uint32 {
+	if x != nil {
+		return x.Colors
+	}
+	return nil
+}
+
+// SetLEDRequest sets the color of a single LED.
+type SetLEDRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// index is the index of the LED.
+	Index uint32 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	// color is the color of the LED in 0xRRGGBB format.
+	Color uint32 `protobuf:"varint,2,opt,name=color,proto3" json:"color,omitempty"`
+}
+
+func (x *SetLEDRequest) Reset() {
+	*x = SetLEDRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_christmasd_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SetLEDRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetLEDRequest) ProtoMessage() {}
+
+func (x *SetLEDRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_christmasd_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetLEDRequest.ProtoReflect.Descriptor instead.
+func (*SetLEDRequest) Descriptor() ([]byte, []int) {
+	return file_proto_christmasd_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SetLEDRequest) GetIndex() uint32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *SetLEDRequest) GetColor() uint32 {
+	if x != nil {
+		return x.Color
+	}
+	return 0
+}
+
+// CanvasOptions overrides how images are rendered onto the LEDs.
+type CanvasOptions struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Intensity Intensity `protobuf:"varint,1,opt,name=intensity,proto3,enum=christmasd.v1.Intensity" json:"intensity,omitempty"`
+	// max_distance is the maximum distance in canvas pixels between a pixel and
+	// an LED. It is required if intensity is set.
+	MaxDistance float64   `protobuf:"fixed64,2,opt,name=max_distance,json=maxDistance,proto3" json:"max_distance,omitempty"`
+	Averaging   Averaging `protobuf:"varint,3,opt,name=averaging,proto3,enum=christmasd.v1.Averaging" json:"averaging,omitempty"`
+}
+
+func (x *CanvasOptions) Reset() {
+	*x = CanvasOptions{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_christmasd_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CanvasOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CanvasOptions) ProtoMessage() {}
+
+func (x *CanvasOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_christmasd_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CanvasOptions.ProtoReflect.Descriptor instead.
+func (*CanvasOptions) Descriptor() ([]byte, []int) {
+	return file_proto_christmasd_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *CanvasOptions) GetIntensity() Intensity {
+	if x != nil {
+		return x.Intensity
+	}
+	return Intensity_INTENSITY_DEFAULT
+}
+
+func (x *CanvasOptions) GetMaxDistance() float64 {
+	if x != nil {
+		return x.MaxDistance
+	}
+	return 0
+}
+
+func (x *CanvasOptions) GetAveraging() Averaging {
+	if x != nil {
+		return x.Averaging
+	}
+	return Averaging_AVERAGING_DEFAULT
+}
+
+// ImageRequest renders a single image onto the LEDs.
+type ImageRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// image is an encoded PNG, JPEG, GIF or BMP image.
+	Image         []byte         `protobuf:"bytes,1,opt,name=image,proto3" json:"image,omitempty"`
+	ScaleMode     ScaleMode      `protobuf:"varint,2,opt,name=scale_mode,json=scaleMode,proto3,enum=christmasd.v1.ScaleMode" json:"scale_mode,omitempty"`
+	CanvasOptions *CanvasOptions `protobuf:"bytes,3,opt,name=canvas_options,json=canvasOptions,proto3" json:"canvas_options,omitempty"`
+}
+
+func (x *ImageRequest) Reset() {
+	*x = ImageRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_christmasd_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ImageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImageRequest) ProtoMessage() {}
+
+func (x *ImageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_christmasd_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImageRequest.ProtoReflect.Descriptor instead.
+func (*ImageRequest) Descriptor() ([]byte, []int) {
+	return file_proto_christmasd_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ImageRequest) GetImage() []byte {
+	if x != nil {
+		return x.Image
+	}
+	return nil
+}
+
+func (x *ImageRequest) GetScaleMode() ScaleMode {
+	if x != nil {
+		return x.ScaleMode
+	}
+	return ScaleMode_SCALE_FILL
+}
+
+func (x *ImageRequest) GetCanvasOptions() *CanvasOptions {
+	if x != nil {
+		return x.CanvasOptions
+	}
+	return nil
+}
+
+// Frame is a single frame of an animation.
+type Frame struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// image is an encoded PNG, JPEG, GIF or BMP image. An animated GIF or APNG
+	// is expanded into all of its frames with their own durations and loops.
+	// jump_back_amount, jump_to and repeat then apply to its last frame, and
+	// marker to its first frame.
+	Image []byte `protobuf:"bytes,1,opt,name=image,proto3" json:"image,omitempty"`
+	// jump_back_amount, if positive, makes the animation jump back this many
+	// frames after this frame instead of continuing to the next one.
+	JumpBackAmount int32 `protobuf:"varint,2,opt,name=jump_back_amount,json=jumpBackAmount,proto3" json:"jump_back_amount,omitempty"`
+	// duration_ms is how long the frame is shown for in milliseconds.
+	DurationMs uint32 `protobuf:"varint,3,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	// marker names the frame so that later frames can jump back to it.
+	Marker string `protobuf:"bytes,4,opt,name=marker,proto3" json:"marker,omitempty"`
+	// jump_to, if set, makes the animation jump back to the last frame with
+	// this marker instead of by jump_back_amount.
+	JumpTo string `protobuf:"bytes,5,opt,name=jump_to,json=jumpTo,proto3" json:"jump_to,omitempty"`
+	// repeat is how many times the jump is taken before the animation
+	// continues past this frame. Zero jumps forever. Loops can be nested: an
+	// inner loop is repeated in full every time an outer loop comes around.
+	Repeat int32 `protobuf:"varint,6,opt,name=repeat,proto3" json:"repeat,omitempty"`
+}
+
+func (x *Frame) Reset() {
+	*x = Frame{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_christmasd_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Frame) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Frame) ProtoMessage() {}
+
+func (x *Frame) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_christmasd_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Frame.ProtoReflect.Descriptor instead.
+func (*Frame) Descriptor() ([]byte, []int) {
+	return file_proto_christmasd_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Frame) GetImage() []byte {
+	if x != nil {
+		return x.Image
+	}
+	return nil
+}
+
+func (x *Frame) GetJumpBackAmount() int32 {
+	if x != nil {
+		return x.JumpBackAmount
+	}
+	return 0
+}
+
+func (x *Frame) GetDurationMs() uint32 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+func (x *Frame) GetMarker() string {
+	if x != nil {
+		return x.Marker
+	}
+	return ""
+}
+
+func (x *Frame) GetJumpTo() string {
+	if x != nil {
+		return x.JumpTo
+	}
+	return ""
+}
+
+func (x *Frame) GetRepeat() int32 {
+	if x != nil {
+		return x.Repeat
+	}
+	return 0
+}
+
+// FramesRequest adds frames to the animation.
+type FramesRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Frames        []*Frame       `protobuf:"bytes,1,rep,name=frames,proto3" json:"frames,omitempty"`
+	ScaleMode     ScaleMode      `protobuf:"varint,2,opt,name=scale_mode,json=scaleMode,proto3,enum=christmasd.v1.ScaleMode" json:"scale_mode,omitempty"`
+	CanvasOptions *CanvasOptions `protobuf:"bytes,3,opt,name=canvas_options,json=canvasOptions,proto3" json:"canvas_options,omitempty"`
+	// transition, if set, replaces the animation with the frames instead of
+	// adding them to it, transitioning from the current animation.
+	Transition *Transition `protobuf:"bytes,4,opt,name=transition,proto3" json:"transition,omitempty"`
+}
+
+func (x *FramesRequest) Reset() {
+	*x = FramesRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_christmasd_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FramesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FramesRequest) ProtoMessage() {}
+
+func (x *FramesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_christmasd_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FramesRequest.ProtoReflect.Descriptor instead.
+func (*FramesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_christmasd_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *FramesRequest) GetFrames() []*Frame {
+	if x != nil {
+		return x.Frames
+	}
+	return nil
+}
+
+func (x *FramesRequest) GetScaleMode() ScaleMode {
+	if x != nil {
+		return x.ScaleMode
+	}
+	return ScaleMode_SCALE_FILL
+}
+
+func (x *FramesRequest) GetCanvasOptions() *CanvasOptions {
+	if x != nil {
+		return x.CanvasOptions
+	}
+	return nil
+}
+
+func (x *FramesRequest) GetTransition() *Transition {
+	if x != nil {
+		return x.Transition
+	}
+	return nil
+}
+
+// Transition is a transition from one animation to the next. The outgoing
+// animation keeps playing during the transition.
+type Transition struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Kind TransitionKind `protobuf:"varint,1,opt,name=kind,proto3,enum=christmasd.v1.TransitionKind" json:"kind,omitempty"`
+	// duration_ms is how long the transition takes in milliseconds.
+	DurationMs uint32 `protobuf:"varint,2,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+}
+
+func (x *Transition) Reset() {
+	*x = Transition{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_christmasd_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Transition) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Transition) ProtoMessage() {}
+
+func (x *Transition) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_christmasd_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Transition.ProtoReflect.Descriptor instead.
+func (*Transition) Descriptor() ([]byte, []int) {
+	return file_proto_christmasd_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Transition) GetKind() TransitionKind {
+	if x != nil {
+		return x.Kind
+	}
+	return TransitionKind_TRANSITION_CUT
+}
+
+func (x *Transition) GetDurationMs() uint32 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+// Layer is a layer of colors on top of the animation, such as an overlay or a
+// notification flash. Black is see-through for every blend mode except
+// BLEND_NORMAL and BLEND_MULTIPLY.
+type Layer struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// name identifies the layer.
+	Name  string    `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Blend BlendMode `protobuf:"varint,2,opt,name=blend,proto3,enum=christmasd.v1.BlendMode" json:"blend,omitempty"`
+	// opacity is how much the layer shows, from 0 (not at all) to 1. It
+	// defaults to 1.
+	Opacity *float64 `protobuf:"fixed64,3,opt,name=opacity,proto3,oneof" json:"opacity,omitempty"`
+	// z orders the layers. Layers with a higher z are drawn on top, and layers
+	// with the same z are drawn in the order that they were added or moved to
+	// that z.
+	Z int32 `protobuf:"varint,4,opt,name=z,proto3" json:"z,omitempty"`
+}
+
+func (x *Layer) Reset() {
+	*x = Layer{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_christmasd_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Layer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Layer) ProtoMessage() {}
+
+func (x *Layer) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_christmasd_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Layer.ProtoReflect.Descriptor instead.
+func (*Layer) Descriptor() ([]byte, []int) {
+	return file_proto_christmasd_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Layer) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Layer) GetBlend() BlendMode {
+	if x != nil {
+		return x.Blend
+	}
+	return BlendMode_BLEND_NORMAL
+}
+
+func (x *Layer) GetOpacity() float64 {
+	if x != nil && x.Opacity != nil {
+		return *x.Opacity
+	}
+	return 0
+}
+
+func (x *Layer) GetZ() int32 {
+	if x != nil {
+		return x.Z
+	}
+	return 0
+}
+
+// LayerRequest adds a layer, or changes the layer with the same name.
+type LayerRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Layer *Layer `protobuf:"bytes,1,opt,name=layer,proto3" json:"layer,omitempty"`
+	// frames, if set, replace the animation of the layer. A layer keeps
+	// showing the last frame of its animation until it is removed. The
+	// transition of the frames is ignored.
+	Frames *FramesRequest `protobuf:"bytes,2,opt,name=frames,proto3" json:"frames,omitempty"`
+}
+
+func (x *LayerRequest) Reset() {
+	*x = LayerRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_christmasd_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LayerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LayerRequest) ProtoMessage() {}
+
+func (x *LayerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_christmasd_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LayerRequest.ProtoReflect.Descriptor instead.
+func (*LayerRequest) Descriptor() ([]byte, []int) {
+	return file_proto_christmasd_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *LayerRequest) GetLayer() *Layer {
+	if x != nil {
+		return x.Layer
+	}
+	return nil
+}
+
+func (x *LayerRequest) GetFrames() *FramesRequest {
+	if x != nil {
+		return x.Frames
+	}
+	return nil
+}
+
+// Layers is every layer, from the bottom to the top.
+type Layers struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Layers []*Layer `protobuf:"bytes,1,rep,name=layers,proto3" json:"layers,omitempty"`
+}
+
+func (x *Layers) Reset() {
+	*x = Layers{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_christmasd_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Layers) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Layers) ProtoMessage() {}
+
+func (x *Layers) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_christmasd_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Layers.ProtoReflect.Descriptor instead.
+func (*Layers) Descriptor() ([]byte, []int) {
+	return file_proto_christmasd_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Layers) GetLayers() []*Layer {
+	if x != nil {
+		return x.Layers
+	}
+	return nil
+}
+
+// RenderResponse is the frames of a FramesRequest rendered onto the LEDs
+// without showing them, for previewing. Loops that repeat a number of times are
+// unrolled, and jumps to markers become jump_back_amount.
+type RenderResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Frames []*RenderedFrame `protobuf:"bytes,1,rep,name=frames,proto3" json:"frames,omitempty"`
+}
+
+func (x *RenderResponse) Reset() {
+	*x = RenderResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_christmasd_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RenderResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenderResponse) ProtoMessage() {}
+
+func (x *RenderResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_christmasd_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenderResponse.ProtoReflect.Descriptor instead.
+func (*RenderResponse) Descriptor() ([]byte, []int) {
+	return file_proto_christmasd_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *RenderResponse) GetFrames() []*RenderedFrame {
+	if x != nil {
+		return x.Frames
+	}
+	return nil
+}
+
+// RenderedFrame is a Frame rendered onto the LEDs.
+type RenderedFrame struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Leds           *LEDStrip `protobuf:"bytes,1,opt,name=leds,proto3" json:"leds,omitempty"`
+	JumpBackAmount int32     `protobuf:"varint,2,opt,name=jump_back_amount,json=jumpBackAmount,proto3" json:"jump_back_amount,omitempty"`
+	DurationMs     uint32    `protobuf:"varint,3,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+}
+
+func (x *RenderedFrame) Reset() {
+	*x = RenderedFrame{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_christmasd_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RenderedFrame) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenderedFrame) ProtoMessage() {}
+
+func (x *RenderedFrame) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_christmasd_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenderedFrame.ProtoReflect.Descriptor instead.
+func (*RenderedFrame) Descriptor() ([]byte, []int) {
+	return file_proto_christmasd_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *RenderedFrame) GetLeds() *LEDStrip {
+	if x != nil {
+		return x.Leds
+	}
+	return nil
+}
+
+func (x *RenderedFrame) GetJumpBackAmount() int32 {
+	if x != nil {
+		return x.JumpBackAmount
+	}
+	return 0
+}
+
+func (x *RenderedFrame) GetDurationMs() uint32 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+// StreamFrame is a single frame streamed over /api/v1/stream. It is shown as
+// soon as possible. If a newer frame arrives before it could be shown, it is
+// dropped instead of queued.
+type StreamFrame struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Frame:
+	//	*StreamFrame_Leds
+	//	*StreamFrame_Rgb
+	//	*StreamFrame_Image
+	Frame isStreamFrame_Frame `protobuf_oneof:"frame"`
+	// scale_mode is how image is scaled onto the LED canvas.
+	ScaleMode     ScaleMode      `protobuf:"varint,4,opt,name=scale_mode,json=scaleMode,proto3,enum=christmasd.v1.ScaleMode" json:"scale_mode,omitempty"`
+	CanvasOptions *CanvasOptions `protobuf:"bytes,5,opt,name=canvas_options,json=canvasOptions,proto3" json:"canvas_options,omitempty"`
+}
+
+func (x *StreamFrame) Reset() {
+	*x = StreamFrame{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_christmasd_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StreamFrame) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamFrame) ProtoMessage() {}
+
+func (x *StreamFrame) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_christmasd_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamFrame.ProtoReflect.Descriptor instead.
+func (*StreamFrame) Descriptor() ([]byte, []int) {
+	return file_proto_christmasd_proto_rawDescGZIP(), []int{12}
+}
+
+func (m *StreamFrame) GetFrame() isStreamFrame_Frame {
+	if m != nil {
+		return m.Frame
+	}
+	return nil
+}
+
+func (x *StreamFrame) GetLeds() *LEDStrip {
+	if x, ok := x.GetFrame().(*StreamFrame_Leds); ok {
+		return x.Leds
+	}
+	return nil
+}
+
+func (x *StreamFrame) GetRgb() []byte {
+	if x, ok := x.GetFrame().(*StreamFrame_Rgb); ok {
+		return x.Rgb
+	}
+	return nil
+}
+
+func (x *StreamFrame) GetImage() []byte {
+	if x, ok := x.GetFrame().(*StreamFrame_Image); ok {
+		return x.Image
+	}
+	return nil
+}
+
+func (x *StreamFrame) GetScaleMode() ScaleMode {
+	if x != nil {
+		return x.ScaleMode
+	}
+	return ScaleMode_SCALE_FILL
+}
+
+func (x *StreamFrame) GetCanvasOptions() *CanvasOptions {
+	if x != nil {
+		return x.CanvasOptions
+	}
+	return nil
+}
+
+type isStreamFrame_Frame interface {
+	isStreamFrame_Frame()
+}
+
+type StreamFrame_Leds struct {
+	// leds is the color of every LED.
+	Leds *LEDStrip `protobuf:"bytes,1,opt,name=leds,proto3,oneof"`
+}
+
+type StreamFrame_Rgb struct {
+	// rgb is the color of every LED as packed R, G, B bytes. It is the
+	// cheapest way to stream colors.
+	Rgb []byte `protobuf:"bytes,2,opt,name=rgb,proto3,oneof"`
+}
+
+type StreamFrame_Image struct {
+	// image is an encoded PNG, JPEG, GIF or BMP image that is rendered onto
+	// the LEDs.
+	Image []byte `protobuf:"bytes,3,opt,name=image,proto3,oneof"`
+}
+
+func (*StreamFrame_Leds) isStreamFrame_Frame() {}
+
+func (*StreamFrame_Rgb) isStreamFrame_Frame() {}
+
+func (*StreamFrame_Image) isStreamFrame_Frame() {}
+
+// StreamStats reports how well the server is keeping up with a stream. The
+// counts are totals since the stream was opened.
+type StreamStats struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// received is the number of frames received.
+	Received uint64 `protobuf:"varint,1,opt,name=received,proto3" json:"received,omitempty"`
+	// shown is the number of frames shown on the LEDs.
+	Shown uint64 `protobuf:"varint,2,opt,name=shown,proto3" json:"shown,omitempty"`
+	// dropped is the number of frames that were replaced by a newer frame
+	// before they could be shown. A growing count means that frames are sent
+	// faster than the LEDs can show them.
+	Dropped uint64 `protobuf:"varint,3,opt,name=dropped,proto3" json:"dropped,omitempty"`
+	// failed is the number of frames that could not be decoded or shown.
+	Failed uint64 `protobuf:"varint,4,opt,name=failed,proto3" json:"failed,omitempty"`
+	// latency_us is the average time in microseconds between receiving a frame
+	// and showing it, over the frames shown since the last StreamStats.
+	LatencyUs uint32 `protobuf:"varint,5,opt,name=latency_us,json=latencyUs,proto3" json:"latency_us,omitempty"`
+	// error is the last error that made a frame fail, if any.
+	Error string `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
+}
+
+func (x *StreamStats) Reset() {
+	*x = StreamStats{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_christmasd_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StreamStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamStats) ProtoMessage() {}
+
+func (x *StreamStats) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_christmasd_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamStats.ProtoReflect.Descriptor instead.
+func (*StreamStats) Descriptor() ([]byte, []int) {
+	return file_proto_christmasd_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *StreamStats) GetReceived() uint64 {
+	if x != nil {
+		return x.Received
+	}
+	return 0
+}
+
+func (x *StreamStats) GetShown() uint64 {
+	if x != nil {
+		return x.Shown
+	}
+	return 0
+}
+
+func (x *StreamStats) GetDropped() uint64 {
+	if x != nil {
+		return x.Dropped
+	}
+	return 0
+}
+
+func (x *StreamStats) GetFailed() uint64 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
+}
+
+func (x *StreamStats) GetLatencyUs() uint32 {
+	if x != nil {
+		return x.LatencyUs
+	}
+	return 0
+}
+
+func (x *StreamStats) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// PreviewMessage is a message sent over /api/v1/preview, which mirrors the
+// LEDs as they are shown. The first message is always the layout, followed by
+// the currently shown colors and then every change, throttled to the fps query
+// parameter (30 by default). Frames that a client is too slow for are skipped.
+//
+// /api/v1/preview is a WebSocket, with binary Protobuf messages or, with
+// ?format=json, Protobuf JSON text messages. If the client accepts
+// text/event-stream instead, the messages are sent as Server-Sent Events
+// containing the Protobuf JSON of the layout ("layout" events) or the colors
+// ("leds" events).
+type PreviewMessage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Message:
+	//	*PreviewMessage_Layout
+	//	*PreviewMessage_Leds
+	Message isPreviewMessage_Message `protobuf_oneof:"message"`
+}
+
+func (x *PreviewMessage) Reset() {
+	*x = PreviewMessage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_christmasd_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PreviewMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PreviewMessage) ProtoMessage() {}
+
+func (x *PreviewMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_christmasd_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PreviewMessage.ProtoReflect.Descriptor instead.
+func (*PreviewMessage) Descriptor() ([]byte, []int) {
+	return file_proto_christmasd_proto_rawDescGZIP(), []int{14}
+}
+
+func (m *PreviewMessage) GetMessage() isPreviewMessage_Message {
+	if m != nil {
+		return m.Message
+	}
+	return nil
+}
+
+func (x *PreviewMessage) GetLayout() *PreviewLayout {
+	if x, ok := x.GetMessage().(*PreviewMessage_Layout); ok {
+		return x.Layout
+	}
+	return nil
+}
+
+func (x *PreviewMessage) GetLeds() *LEDStrip {
+	if x, ok := x.GetMessage().(*PreviewMessage_Leds); ok {
+		return x.Leds
+	}
+	return nil
+}
+
+type isPreviewMessage_Message interface {
+	isPreviewMessage_Message()
+}
+
+type PreviewMessage_Layout struct {
+	Layout *PreviewLayout `protobuf:"bytes,1,opt,name=layout,proto3,oneof"`
+}
+
+type PreviewMessage_Leds struct {
+	Leds *LEDStrip `protobuf:"bytes,2,opt,name=leds,proto3,oneof"`
+}
+
+func (*PreviewMessage_Layout) isPreviewMessage_Message() {}
+
+func (*PreviewMessage_Leds) isPreviewMessage_Message() {}
+
+// PreviewLayout describes where the LEDs are so that they can be drawn.
+type PreviewLayout struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// led_points is the position of each LED.
+	LedPoints []*Point `protobuf:"bytes,1,rep,name=led_points,json=ledPoints,proto3" json:"led_points,omitempty"`
+	// led_bounds is the boundary box of led_points.
+	LedBounds *Rectangle `protobuf:"bytes,2,opt,name=led_bounds,json=ledBounds,proto3" json:"led_bounds,omitempty"`
+}
+
+func (x *PreviewLayout) Reset() {
+	*x = PreviewLayout{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_christmasd_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PreviewLayout) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PreviewLayout) ProtoMessage() {}
+
+func (x *PreviewLayout) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_christmasd_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PreviewLayout.ProtoReflect.Descriptor instead.
+func (*PreviewLayout) Descriptor() ([]byte, []int) {
+	return file_proto_christmasd_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *PreviewLayout) GetLedPoints() []*Point {
+	if x != nil {
+		return x.LedPoints
+	}
+	return nil
+}
+
+func (x *PreviewLayout) GetLedBounds() *Rectangle {
+	if x != nil {
+		return x.LedBounds
+	}
+	return nil
+}
+
+// Point is an image.Point.
+type Point struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	X int32 `protobuf:"varint,1,opt,name=x,proto3" json:"x,omitempty"`
+	Y int32 `protobuf:"varint,2,opt,name=y,proto3" json:"y,omitempty"`
+}
+
+func (x *Point) Reset() {
+	*x = Point{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_christmasd_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Point) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Point) ProtoMessage() {}
+
+func (x *Point) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_christmasd_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Point.ProtoReflect.Descriptor instead.
+func (*Point) Descriptor() ([]byte, []int) {
+	return file_proto_christmasd_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *Point) GetX() int32 {
+	if x != nil {
+		return x.X
+	}
+	return 0
+}
+
+func (x *Point) GetY() int32 {
+	if x != nil {
+		return x.Y
+	}
+	return 0
+}
+
+// Rectangle is an image.Rectangle.
+type Rectangle struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	MinX int32 `protobuf:"varint,1,opt,name=min_x,json=minX,proto3" json:"min_x,omitempty"`
+	MinY int32 `protobuf:"varint,2,opt,name=min_y,json=minY,proto3" json:"min_y,omitempty"`
+	MaxX int32 `protobuf:"varint,3,opt,name=max_x,json=maxX,proto3" json:"max_x,omitempty"`
+	MaxY int32 `protobuf:"varint,4,opt,name=max_y,json=maxY,proto3" json:"max_y,omitempty"`
+}
+
+func (x *Rectangle) Reset() {
+	*x = Rectangle{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_christmasd_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Rectangle) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Rectangle) ProtoMessage() {}
+
+func (x *Rectangle) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_christmasd_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Rectangle.ProtoReflect.Descriptor instead.
+func (*Rectangle) Descriptor() ([]byte, []int) {
+	return file_proto_christmasd_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *Rectangle) GetMinX() int32 {
+	if x != nil {
+		return x.MinX
+	}
+	return 0
+}
+
+func (x *Rectangle) GetMinY() int32 {
+	if x != nil {
+		return x.MinY
+	}
+	return 0
+}
+
+func (x *Rectangle) GetMaxX() int32 {
+	if x != nil {
+		return x.MaxX
+	}
+	return 0
+}
+
+func (x *Rectangle) GetMaxY() int32 {
+	if x != nil {
+		return x.MaxY
+	}
+	return 0
+}
+
+// CanvasInfo describes the LED canvas of the daemon.
+type CanvasInfo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// led_count is the number of LEDs.
+	LedCount uint32 `protobuf:"varint,1,opt,name=led_count,json=ledCount,proto3" json:"led_count,omitempty"`
+	// canvas_bounds is the size of the image canvas. Images are scaled to this
+	// size before they are rendered.
+	CanvasBounds *Rectangle `protobuf:"bytes,2,opt,name=canvas_bounds,json=canvasBounds,proto3" json:"canvas_bounds,omitempty"`
+	// led_bounds is the boundary box of the LED positions.
+	LedBounds *Rectangle `protobuf:"bytes,3,opt,name=led_bounds,json=ledBounds,proto3" json:"led_bounds,omitempty"`
+	// led_points is the position of each LED.
+	LedPoints []*Point `protobuf:"bytes,4,rep,name=led_points,json=ledPoints,proto3" json:"led_points,omitempty"`
+}
+
+func (x *CanvasInfo) Reset() {
+	*x = CanvasInfo{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_christmasd_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CanvasInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CanvasInfo) ProtoMessage() {}
+
+func (x *CanvasInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_christmasd_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CanvasInfo.ProtoReflect.Descriptor instead.
+func (*CanvasInfo) Descriptor() ([]byte, []int) {
+	return file_proto_christmasd_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *CanvasInfo) GetLedCount() uint32 {
+	if x != nil {
+		return x.LedCount
+	}
+	return 0
+}
+
+func (x *CanvasInfo) GetCanvasBounds() *Rectangle {
+	if x != nil {
+		return x.CanvasBounds
+	}
+	return nil
+}
+
+func (x *CanvasInfo) GetLedBounds() *Rectangle {
+	if x != nil {
+		return x.LedBounds
+	}
+	return nil
+}
+
+func (x *CanvasInfo) GetLedPoints() []*Point {
+	if x != nil {
+		return x.LedPoints
+	}
+	return nil
+}
+
+// Schedule runs the tree unattended: it plays a playlist of shows and applies
+// rules based on the time of day. Setting it replaces the whole schedule and
+// saves it on the server.
+type Schedule struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// enabled is true if the schedule controls the tree. A disabled schedule
+	// leaves the tree alone.
+	Enabled bool `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	// shows are the shows that can be played, by name.
+	Shows []*Show `protobuf:"bytes,2,rep,name=shows,proto3" json:"shows,omitempty"`
+	// playlist is the names of the shows that are played in turn. If it is
+	// empty, every show is played.
+	Playlist []string `protobuf:"bytes,3,rep,name=playlist,proto3" json:"playlist,omitempty"`
+	// shuffle plays the playlist in a random order.
+	Shuffle bool `protobuf:"varint,4,opt,name=shuffle,proto3" json:"shuffle,omitempty"`
+	// rules change what the tree does at certain times of the day.
+	Rules []*ScheduleRule `protobuf:"bytes,5,rep,name=rules,proto3" json:"rules,omitempty"`
+	// transition is how the tree changes from one show to the next.
+	Transition *Transition `protobuf:"bytes,6,opt,name=transition,proto3" json:"transition,omitempty"`
+}
+
+func (x *Schedule) Reset() {
+	*x = Schedule{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_christmasd_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Schedule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Schedule) ProtoMessage() {}
+
+func (x *Schedule) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_christmasd_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Schedule.ProtoReflect.Descriptor instead.
+func (*Schedule) Descriptor() ([]byte, []int) {
+	return file_proto_christmasd_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *Schedule) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *Schedule) GetShows() []*Show {
+	if x != nil {
+		return x.Shows
+	}
+	return nil
+}
+
+func (x *Schedule) GetPlaylist() []string {
+	if x != nil {
+		return x.Playlist
+	}
+	return nil
+}
+
+func (x *Schedule) GetShuffle() bool {
+	if x != nil {
+		return x.Shuffle
+	}
+	return false
+}
+
+func (x *Schedule) GetRules() []*ScheduleRule {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
+}
+
+func (x *Schedule) GetTransition() *Transition {
+	if x != nil {
+		return x.Transition
+	}
+	return nil
+}
+
+// Show is a named show of a Schedule.
+type Show struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// duration_ms is how long the show is played for in the playlist in
+	// milliseconds. Shorter shows are looped or keep showing their last frame.
+	DurationMs uint32 `protobuf:"varint,2,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	// Types that are assignable to Content:
+	//	*Show_Sequence
+	//	*Show_Image
+	//	*Show_Pattern
+	Content isShow_Content `protobuf_oneof:"content"`
+	// colors are the colors of the pattern in 0xRRGGBB format.
+	Colors []uint32 `protobuf:"varint,6,rep,packed,name=colors,proto3" json:"colors,omitempty"`
+}
+
+func (x *Show) Reset() {
+	*x = Show{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_christmasd_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Show) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Show) ProtoMessage() {}
+
+func (x *Show) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_christmasd_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Show.ProtoReflect.Descriptor instead.
+func (*Show) Descriptor() ([]byte, []int) {
+	return file_proto_christmasd_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *Show) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Show) GetDurationMs() uint32 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+func (m *Show) GetContent() isShow_Content {
+	if m != nil {
+		return m.Content
+	}
+	return nil
+}
+
+func (x *Show) GetSequence() string {
+	if x, ok := x.GetContent().(*Show_Sequence); ok {
+		return x.Sequence
+	}
+	return ""
+}
+
+func (x *Show) GetImage() string {
+	if x, ok := x.GetContent().(*Show_Image); ok {
+		return x.Image
+	}
+	return ""
+}
+
+func (x *Show) GetPattern() string {
+	if x, ok := x.GetContent().(*Show_Pattern); ok {
+		return x.Pattern
+	}
+	return ""
+}
+
+func (x *Show) GetColors() []uint32 {
+	if x != nil {
+		return x.Colors
+	}
+	return nil
+}
+
+type isShow_Content interface {
+	isShow_Content()
+}
+
+type Show_Sequence struct {
+	// sequence is the path on the server of an LED sequence or FSEQ file.
+	Sequence string `protobuf:"bytes,3,opt,name=sequence,proto3,oneof"`
+}
+
+type Show_Image struct {
+	// image is the path on the server of an image, animated image or video.
+	Image string `protobuf:"bytes,4,opt,name=image,proto3,oneof"`
+}
+
+type Show_Pattern struct {
+	// pattern is the name of a procedural pattern, such as "Snow".
+	Pattern string `protobuf:"bytes,5,opt,name=pattern,proto3,oneof"`
+}
+
+func (*Show_Sequence) isShow_Content() {}
+
+func (*Show_Image) isShow_Content() {}
+
+func (*Show_Pattern) isShow_Content() {}
+
+// ScheduleRule changes what the tree does at certain times of the day. It is
+// active between from and to, or all day if they are equal. If every_ms is
+// set, it is instead active for duration_ms at every multiple of every_ms
+// since midnight that is between from and to.
+//
+// While several rules are active, the tree is off if any of them says so, it
+// has the lowest of their brightnesses, and it plays the show of the first of
+// them with a show instead of the playlist.
+type ScheduleRule struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// from is the time of day that the rule starts at, such as "22:00".
+	From string `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	// to is the time of day that the rule ends at. It may be earlier than from
+	// to span midnight.
+	To string `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	// every_ms makes the rule repeat, such as every hour.
+	EveryMs uint32 `protobuf:"varint,3,opt,name=every_ms,json=everyMs,proto3" json:"every_ms,omitempty"`
+	// duration_ms is how long a repeating rule is active for. It defaults to
+	// the duration of show.
+	DurationMs uint32 `protobuf:"varint,4,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	// off turns the tree off.
+	Off bool `protobuf:"varint,5,opt,name=off,proto3" json:"off,omitempty"`
+	// brightness dims the tree to this brightness in (0, 1]. Zero leaves the
+	// brightness as is.
+	Brightness float64 `protobuf:"fixed64,6,opt,name=brightness,proto3" json:"brightness,omitempty"`
+	// show is the name of the show that is played instead of the playlist.
+	Show string `protobuf:"bytes,7,opt,name=show,proto3" json:"show,omitempty"`
+}
+
+func (x *ScheduleRule) Reset() {
+	*x = ScheduleRule{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_christmasd_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ScheduleRule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScheduleRule) ProtoMessage() {}
+
+func (x *ScheduleRule) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_christmasd_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScheduleRule.ProtoReflect.Descriptor instead.
+func (*ScheduleRule) Descriptor() ([]byte, []int) {
+	return file_proto_christmasd_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ScheduleRule) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *ScheduleRule) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *ScheduleRule) GetEveryMs() uint32 {
+	if x != nil {
+		return x.EveryMs
+	}
+	return 0
+}
+
+func (x *ScheduleRule) GetDurationMs() uint32 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+func (x *ScheduleRule) GetOff() bool {
+	if x != nil {
+		return x.Off
+	}
+	return false
+}
+
+func (x *ScheduleRule) GetBrightness() float64 {
+	if x != nil {
+		return x.Brightness
+	}
+	return 0
+}
+
+func (x *ScheduleRule) GetShow() string {
+	if x != nil {
+		return x.Show
+	}
+	return ""
+}
+
+// ScheduleStatus is what the schedule is currently doing.
+type ScheduleStatus struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Enabled bool `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	// show is the name of the show being played, if any.
+	Show string `protobuf:"bytes,2,opt,name=show,proto3" json:"show,omitempty"`
+	// off is true if a rule turned the tree off.
+	Off bool `protobuf:"varint,3,opt,name=off,proto3" json:"off,omitempty"`
+	// brightness is the brightness that the show is played at.
+	Brightness float64 `protobuf:"fixed64,4,opt,name=brightness,proto3" json:"brightness,omitempty"`
+	// until_unix_ms is the next time that the status may change, in
+	// milliseconds since the Unix epoch.
+	UntilUnixMs int64 `protobuf:"varint,5,opt,name=until_unix_ms,json=untilUnixMs,proto3" json:"until_unix_ms,omitempty"`
+}
+
+func (x *ScheduleStatus) Reset() {
+	*x = ScheduleStatus{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_christmasd_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ScheduleStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScheduleStatus) ProtoMessage() {}
+
+func (x *ScheduleStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_christmasd_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScheduleStatus.ProtoReflect.Descriptor instead.
+func (*ScheduleStatus) Descriptor() ([]byte, []int) {
+	return file_proto_christmasd_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ScheduleStatus) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *ScheduleStatus) GetShow() string {
+	if x != nil {
+		return x.Show
+	}
+	return ""
+}
+
+func (x *ScheduleStatus) GetOff() bool {
+	if x != nil {
+		return x.Off
+	}
+	return false
+}
+
+func (x *ScheduleStatus) GetBrightness() float64 {
+	if x != nil {
+		return x.Brightness
+	}
+	return 0
+}
+
+func (x *ScheduleStatus) GetUntilUnixMs() int64 {
+	if x != nil {
+		return x.UntilUnixMs
+	}
+	return 0
+}
+
+// Error is the response of a failed request.
+type Error struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Message string `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+}
+
+func (x *Error) Reset() {
+	*x = Error{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_christmasd_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Error) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Error) ProtoMessage() {}
+
+func (x *Error) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_christmasd_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Error.ProtoReflect.Descriptor instead.
+func (*Error) Descriptor() ([]byte, []int) {
+	return file_proto_christmasd_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *Error) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+var File_proto_christmasd_proto protoreflect.FileDescriptor
+
+var file_proto_christmasd_proto_rawDesc = []byte{
+	0x0a, 0x16, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x63, 0x68, 0x72, 0x69, 0x73, 0x74, 0x6d, 0x61,
+	0x73, 0x64, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0d, 0x63, 0x68, 0x72, 0x69, 0x73, 0x74,
+	0x6d, 0x61, 0x73, 0x64, 0x2e, 0x76, 0x31, 0x22, 0x22, 0x0a, 0x08, 0x4c, 0x45, 0x44, 0x53, 0x74,
+	0x72, 0x69, 0x70, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x6f, 0x6c, 0x6f, 0x72, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x0d, 0x52, 0x06, 0x63, 0x6f, 0x6c, 0x6f, 0x72, 0x73, 0x22, 0x3b, 0x0a, 0x0d, 0x53,
+	0x65, 0x74, 0x4c, 0x45, 0x44, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05,
+	0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x69, 0x6e, 0x64,
+	0x65, 0x78, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6f, 0x6c, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x05, 0x63, 0x6f, 0x6c, 0x6f, 0x72, 0x22, 0xa2, 0x01, 0x0a, 0x0d, 0x43, 0x61, 0x6e,
+	0x76, 0x61, 0x73, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x36, 0x0a, 0x09, 0x69, 0x6e,
+	0x74, 0x65, 0x6e, 0x73, 0x69, 0x74, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x18, 0x2e,
+	0x63, 0x68, 0x72, 0x69, 0x73, 0x74, 0x6d, 0x61, 0x73, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x49, 0x6e,
+	0x74, 0x65, 0x6e, 0x73, 0x69, 0x74, 0x79, 0x52, 0x09, 0x69, 0x6e, 0x74, 0x65, 0x6e, 0x73, 0x69,
+	0x74, 0x79, 0x12, 0x21, 0x0a, 0x0c, 0x6d, 0x61, 0x78, 0x5f, 0x64, 0x69, 0x73, 0x74, 0x61, 0x6e,
+	0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0b, 0x6d, 0x61, 0x78, 0x44, 0x69, 0x73,
+	0x74, 0x61, 0x6e, 0x63, 0x65, 0x12, 0x36, 0x0a, 0x09, 0x61, 0x76, 0x65, 0x72, 0x61, 0x67, 0x69,
+	0x6e, 0x67, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x18, 0x2e, 0x63, 0x68, 0x72, 0x69, 0x73,
+	0x74, 0x6d, 0x61, 0x73, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x76, 0x65, 0x72, 0x61, 0x67, 0x69,
+	0x6e, 0x67, 0x52, 0x09, 0x61, 0x76, 0x65, 0x72, 0x61, 0x67, 0x69, 0x6e, 0x67, 0x22, 0xa2, 0x01,
+	0x0a, 0x0c, 0x49, 0x6d, 0x61, 0x67, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14,
+	0x0a, 0x05, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x69,
+	0x6d, 0x61, 0x67, 0x65, 0x12, 0x37, 0x0a, 0x0a, 0x73, 0x63, 0x61, 0x6c, 0x65, 0x5f, 0x6d, 0x6f,
+	0x64, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x18, 0x2e, 0x63, 0x68, 0x72, 0x69, 0x73,
+	0x74, 0x6d, 0x61, 0x73, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x63, 0x61, 0x6c, 0x65, 0x4d, 0x6f,
+	0x64, 0x65, 0x52, 0x09, 0x73, 0x63, 0x61, 0x6c, 0x65, 0x4d, 0x6f, 0x64, 0x65, 0x12, 0x43, 0x0a,
+	0x0e, 0x63, 0x61, 0x6e, 0x76, 0x61, 0x73, 0x5f, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x63, 0x68, 0x72, 0x69, 0x73, 0x74, 0x6d, 0x61,
+	0x73, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61, 0x6e, 0x76, 0x61, 0x73, 0x4f, 0x70, 0x74, 0x69,
+	0x6f, 0x6e, 0x73, 0x52, 0x0d, 0x63, 0x61, 0x6e, 0x76, 0x61, 0x73, 0x4f, 0x70, 0x74, 0x69, 0x6f,
+	0x6e, 0x73, 0x22, 0xb1, 0x01, 0x0a, 0x05, 0x46, 0x72, 0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05,
+	0x69, 0x6d, 0x61, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x69, 0x6d, 0x61,
+	0x67, 0x65, 0x12, 0x28, 0x0a, 0x10, 0x6a, 0x75, 0x6d, 0x70, 0x5f, 0x62, 0x61, 0x63, 0x6b, 0x5f,
+	0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0e, 0x6a, 0x75,
+	0x6d, 0x70, 0x42, 0x61, 0x63, 0x6b, 0x41, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x1f, 0x0a, 0x0b,
+	0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x6d, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x0a, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x73, 0x12, 0x16, 0x0a,
+	0x06, 0x6d, 0x61, 0x72, 0x6b, 0x65, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6d,
+	0x61, 0x72, 0x6b, 0x65, 0x72, 0x12, 0x17, 0x0a, 0x07, 0x6a, 0x75, 0x6d, 0x70, 0x5f, 0x74, 0x6f,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6a, 0x75, 0x6d, 0x70, 0x54, 0x6f, 0x12, 0x16,
+	0x0a, 0x06, 0x72, 0x65, 0x70, 0x65, 0x61, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06,
+	0x72, 0x65, 0x70, 0x65, 0x61, 0x74, 0x22, 0xf6, 0x01, 0x0a, 0x0d, 0x46, 0x72, 0x61, 0x6d, 0x65,
+	0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x2c, 0x0a, 0x06, 0x66, 0x72, 0x61, 0x6d,
+	0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x63, 0x68, 0x72, 0x69, 0x73,
+	0x74, 0x6d, 0x61, 0x73, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x72, 0x61, 0x6d, 0x65, 0x52, 0x06,
+	0x66, 0x72, 0x61, 0x6d, 0x65, 0x73, 0x12, 0x37, 0x0a, 0x0a, 0x73, 0x63, 0x61, 0x6c, 0x65, 0x5f,
+	0x6d, 0x6f, 0x64, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x18, 0x2e, 0x63, 0x68, 0x72,
+	0x69, 0x73, 0x74, 0x6d, 0x61, 0x73, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x63, 0x61, 0x6c, 0x65,
+	0x4d, 0x6f, 0x64, 0x65, 0x52, 0x09, 0x73, 0x63, 0x61, 0x6c, 0x65, 0x4d, 0x6f, 0x64, 0x65, 0x12,
+	0x43, 0x0a, 0x0e, 0x63, 0x61, 0x6e, 0x76, 0x61, 0x73, 0x5f, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e,
+	0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x63, 0x68, 0x72, 0x69, 0x73, 0x74,
+	0x6d, 0x61, 0x73, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61, 0x6e, 0x76, 0x61, 0x73, 0x4f, 0x70,
+	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x0d, 0x63, 0x61, 0x6e, 0x76, 0x61, 0x73, 0x4f, 0x70, 0x74,
+	0x69, 0x6f, 0x6e, 0x73, 0x12, 0x39, 0x0a, 0x0a, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x69, 0x74, 0x69,
+	0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x63, 0x68, 0x72, 0x69, 0x73,
+	0x74, 0x6d, 0x61, 0x73, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x69, 0x74,
+	0x69, 0x6f, 0x6e, 0x52, 0x0a, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x22,
+	0x60, 0x0a, 0x0a, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x31, 0x0a,
+	0x04, 0x6b, 0x69, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1d, 0x2e, 0x63, 0x68,
+	0x72, 0x69, 0x73, 0x74, 0x6d, 0x61, 0x73, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x72, 0x61, 0x6e,
+	0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x4b, 0x69, 0x6e, 0x64, 0x52, 0x04, 0x6b, 0x69, 0x6e, 0x64,
+	0x12, 0x1f, 0x0a, 0x0b, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x6d, 0x73, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4d,
+	0x73, 0x22, 0x84, 0x01, 0x0a, 0x05, 0x4c, 0x61, 0x79, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x6e,
+	0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12,
+	0x2e, 0x0a, 0x05, 0x62, 0x6c, 0x65, 0x6e, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x18,
+	0x2e, 0x63, 0x68, 0x72, 0x69, 0x73, 0x74, 0x6d, 0x61, 0x73, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x42,
+	0x6c, 0x65, 0x6e, 0x64, 0x4d, 0x6f, 0x64, 0x65, 0x52, 0x05, 0x62, 0x6c, 0x65, 0x6e, 0x64, 0x12,
+	0x1d, 0x0a, 0x07, 0x6f, 0x70, 0x61, 0x63, 0x69, 0x74, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01,
+	0x48, 0x00, 0x52, 0x07, 0x6f, 0x70, 0x61, 0x63, 0x69, 0x74, 0x79, 0x88, 0x01, 0x01, 0x12, 0x0c,
+	0x0a, 0x01, 0x7a, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x01, 0x7a, 0x42, 0x0a, 0x0a, 0x08,
+	0x5f, 0x6f, 0x70, 0x61, 0x63, 0x69, 0x74, 0x79, 0x22, 0x70, 0x0a, 0x0c, 0x4c, 0x61, 0x79, 0x65,
+	0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x2a, 0x0a, 0x05, 0x6c, 0x61, 0x79, 0x65,
+	0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x63, 0x68, 0x72, 0x69, 0x73, 0x74,
+	0x6d, 0x61, 0x73, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x61, 0x79, 0x65, 0x72, 0x52, 0x05, 0x6c,
+	0x61, 0x79, 0x65, 0x72, 0x12, 0x34, 0x0a, 0x06, 0x66, 0x72, 0x61, 0x6d, 0x65, 0x73, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x63, 0x68, 0x72, 0x69, 0x73, 0x74, 0x6d, 0x61, 0x73,
+	0x64, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x72, 0x61, 0x6d, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x52, 0x06, 0x66, 0x72, 0x61, 0x6d, 0x65, 0x73, 0x22, 0x36, 0x0a, 0x06, 0x4c, 0x61,
+	0x79, 0x65, 0x72, 0x73, 0x12, 0x2c, 0x0a, 0x06, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x73, 0x18, 0x01,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x63, 0x68, 0x72, 0x69, 0x73, 0x74, 0x6d, 0x61, 0x73,
+	0x64, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x61, 0x79, 0x65, 0x72, 0x52, 0x06, 0x6c, 0x61, 0x79, 0x65,
+	0x72, 0x73, 0x22, 0x46, 0x0a, 0x0e, 0x52, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x34, 0x0a, 0x06, 0x66, 0x72, 0x61, 0x6d, 0x65, 0x73, 0x18, 0x01,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x63, 0x68, 0x72, 0x69, 0x73, 0x74, 0x6d, 0x61, 0x73,
+	0x64, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x65, 0x64, 0x46, 0x72, 0x61,
+	0x6d, 0x65, 0x52, 0x06, 0x66, 0x72, 0x61, 0x6d, 0x65, 0x73, 0x22, 0x87, 0x01, 0x0a, 0x0d, 0x52,
+	0x65, 0x6e, 0x64, 0x65, 0x72, 0x65, 0x64, 0x46, 0x72, 0x61, 0x6d, 0x65, 0x12, 0x2b, 0x0a, 0x04,
+	0x6c, 0x65, 0x64, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x63, 0x68, 0x72,
+	0x69, 0x73, 0x74, 0x6d, 0x61, 0x73, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x45, 0x44, 0x53, 0x74,
+	0x72, 0x69, 0x70, 0x52, 0x04, 0x6c, 0x65, 0x64, 0x73, 0x12, 0x28, 0x0a, 0x10, 0x6a, 0x75, 0x6d,
+	0x70, 0x5f, 0x62, 0x61, 0x63, 0x6b, 0x5f, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x0e, 0x6a, 0x75, 0x6d, 0x70, 0x42, 0x61, 0x63, 0x6b, 0x41, 0x6d, 0x6f,
+	0x75, 0x6e, 0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f,
+	0x6d, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x4d, 0x73, 0x22, 0xef, 0x01, 0x0a, 0x0b, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x46,
+	0x72, 0x61, 0x6d, 0x65, 0x12, 0x2d, 0x0a, 0x04, 0x6c, 0x65, 0x64, 0x73, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x17, 0x2e, 0x63, 0x68, 0x72, 0x69, 0x73, 0x74, 0x6d, 0x61, 0x73, 0x64, 0x2e,
+	0x76, 0x31, 0x2e, 0x4c, 0x45, 0x44, 0x53, 0x74, 0x72, 0x69, 0x70, 0x48, 0x00, 0x52, 0x04, 0x6c,
+	0x65, 0x64, 0x73, 0x12, 0x12, 0x0a, 0x03, 0x72, 0x67, 0x62, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c,
+	0x48, 0x00, 0x52, 0x03, 0x72, 0x67, 0x62, 0x12, 0x16, 0x0a, 0x05, 0x69, 0x6d, 0x61, 0x67, 0x65,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x48, 0x00, 0x52, 0x05, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x12,
+	0x37, 0x0a, 0x0a, 0x73, 0x63, 0x61, 0x6c, 0x65, 0x5f, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x18, 0x2e, 0x63, 0x68, 0x72, 0x69, 0x73, 0x74, 0x6d, 0x61, 0x73, 0x64,
+	0x2e, 0x76, 0x31, 0x2e, 0x53, 0x63, 0x61, 0x6c, 0x65, 0x4d, 0x6f, 0x64, 0x65, 0x52, 0x09, 0x73,
+	0x63, 0x61, 0x6c, 0x65, 0x4d, 0x6f, 0x64, 0x65, 0x12, 0x43, 0x0a, 0x0e, 0x63, 0x61, 0x6e, 0x76,
+	0x61, 0x73, 0x5f, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1c, 0x2e, 0x63, 0x68, 0x72, 0x69, 0x73, 0x74, 0x6d, 0x61, 0x73, 0x64, 0x2e, 0x76, 0x31,
+	0x2e, 0x43, 0x61, 0x6e, 0x76, 0x61, 0x73, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x0d,
+	0x63, 0x61, 0x6e, 0x76, 0x61, 0x73, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x42, 0x07, 0x0a,
+	0x05, 0x66, 0x72, 0x61, 0x6d, 0x65, 0x22, 0xa6, 0x01, 0x0a, 0x0b, 0x53, 0x74, 0x72, 0x65, 0x61,
+	0x6d, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x63, 0x65, 0x69, 0x76,
+	0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x72, 0x65, 0x63, 0x65, 0x69, 0x76,
+	0x65, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x68, 0x6f, 0x77, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x05, 0x73, 0x68, 0x6f, 0x77, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x64, 0x72, 0x6f, 0x70,
+	0x70, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x64, 0x72, 0x6f, 0x70, 0x70,
+	0x65, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x66, 0x61, 0x69, 0x6c, 0x65, 0x64, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x06, 0x66, 0x61, 0x69, 0x6c, 0x65, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x6c, 0x61,
+	0x74, 0x65, 0x6e, 0x63, 0x79, 0x5f, 0x75, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x09,
+	0x6c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x55, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72,
+	0x6f, 0x72, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x22,
+	0x82, 0x01, 0x0a, 0x0e, 0x50, 0x72, 0x65, 0x76, 0x69, 0x65, 0x77, 0x4d, 0x65, 0x73, 0x73, 0x61,
+	0x67, 0x65, 0x12, 0x36, 0x0a, 0x06, 0x6c, 0x61, 0x79, 0x6f, 0x75, 0x74, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x63, 0x68, 0x72, 0x69, 0x73, 0x74, 0x6d, 0x61, 0x73, 0x64, 0x2e,
+	0x76, 0x31, 0x2e, 0x50, 0x72, 0x65, 0x76, 0x69, 0x65, 0x77, 0x4c, 0x61, 0x79, 0x6f, 0x75, 0x74,
+	0x48, 0x00, 0x52, 0x06, 0x6c, 0x61, 0x79, 0x6f, 0x75, 0x74, 0x12, 0x2d, 0x0a, 0x04, 0x6c, 0x65,
+	0x64, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x63, 0x68, 0x72, 0x69, 0x73,
+	0x74, 0x6d, 0x61, 0x73, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x45, 0x44, 0x53, 0x74, 0x72, 0x69,
+	0x70, 0x48, 0x00, 0x52, 0x04, 0x6c, 0x65, 0x64, 0x73, 0x42, 0x09, 0x0a, 0x07, 0x6d, 0x65, 0x73,
+	0x73, 0x61, 0x67, 0x65, 0x22, 0x7d, 0x0a, 0x0d, 0x50, 0x72, 0x65, 0x76, 0x69, 0x65, 0x77, 0x4c,
+	0x61, 0x79, 0x6f, 0x75, 0x74, 0x12, 0x33, 0x0a, 0x0a, 0x6c, 0x65, 0x64, 0x5f, 0x70, 0x6f, 0x69,
+	0x6e, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x63, 0x68, 0x72, 0x69,
+	0x73, 0x74, 0x6d, 0x61, 0x73, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x6f, 0x69, 0x6e, 0x74, 0x52,
+	0x09, 0x6c, 0x65, 0x64, 0x50, 0x6f, 0x69, 0x6e, 0x74, 0x73, 0x12, 0x37, 0x0a, 0x0a, 0x6c, 0x65,
+	0x64, 0x5f, 0x62, 0x6f, 0x75, 0x6e, 0x64, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18,
+	0x2e, 0x63, 0x68, 0x72, 0x69, 0x73, 0x74, 0x6d, 0x61, 0x73, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x52,
+	0x65, 0x63, 0x74, 0x61, 0x6e, 0x67, 0x6c, 0x65, 0x52, 0x09, 0x6c, 0x65, 0x64, 0x42, 0x6f, 0x75,
+	0x6e, 0x64, 0x73, 0x22, 0x23, 0x0a, 0x05, 0x50, 0x6f, 0x69, 0x6e, 0x74, 0x12, 0x0c, 0x0a, 0x01,
+	0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x01, 0x78, 0x12, 0x0c, 0x0a, 0x01, 0x79, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x01, 0x79, 0x22, 0x5f, 0x0a, 0x09, 0x52, 0x65, 0x63, 0x74,
+	0x61, 0x6e, 0x67, 0x6c, 0x65, 0x12, 0x13, 0x0a, 0x05, 0x6d, 0x69, 0x6e, 0x5f, 0x78, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x6d, 0x69, 0x6e, 0x58, 0x12, 0x13, 0x0a, 0x05, 0x6d, 0x69,
+	0x6e, 0x5f, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x6d, 0x69, 0x6e, 0x59, 0x12,
+	0x13, 0x0a, 0x05, 0x6d, 0x61, 0x78, 0x5f, 0x78, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04,
+	0x6d, 0x61, 0x78, 0x58, 0x12, 0x13, 0x0a, 0x05, 0x6d, 0x61, 0x78, 0x5f, 0x79, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x04, 0x6d, 0x61, 0x78, 0x59, 0x22, 0xd6, 0x01, 0x0a, 0x0a, 0x43, 0x61,
+	0x6e, 0x76, 0x61, 0x73, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x1b, 0x0a, 0x09, 0x6c, 0x65, 0x64, 0x5f,
+	0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x6c, 0x65, 0x64,
+	0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x3d, 0x0a, 0x0d, 0x63, 0x61, 0x6e, 0x76, 0x61, 0x73, 0x5f,
+	0x62, 0x6f, 0x75, 0x6e, 0x64, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x63,
+	0x68, 0x72, 0x69, 0x73, 0x74, 0x6d, 0x61, 0x73, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x63,
+	0x74, 0x61, 0x6e, 0x67, 0x6c, 0x65, 0x52, 0x0c, 0x63, 0x61, 0x6e, 0x76, 0x61, 0x73, 0x42, 0x6f,
+	0x75, 0x6e, 0x64, 0x73, 0x12, 0x37, 0x0a, 0x0a, 0x6c, 0x65, 0x64, 0x5f, 0x62, 0x6f, 0x75, 0x6e,
+	0x64, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x63, 0x68, 0x72, 0x69, 0x73,
+	0x74, 0x6d, 0x61, 0x73, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x63, 0x74, 0x61, 0x6e, 0x67,
+	0x6c, 0x65, 0x52, 0x09, 0x6c, 0x65, 0x64, 0x42, 0x6f, 0x75, 0x6e, 0x64, 0x73, 0x12, 0x33, 0x0a,
+	0x0a, 0x6c, 0x65, 0x64, 0x5f, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x14, 0x2e, 0x63, 0x68, 0x72, 0x69, 0x73, 0x74, 0x6d, 0x61, 0x73, 0x64, 0x2e, 0x76,
+	0x31, 0x2e, 0x50, 0x6f, 0x69, 0x6e, 0x74, 0x52, 0x09, 0x6c, 0x65, 0x64, 0x50, 0x6f, 0x69, 0x6e,
+	0x74, 0x73, 0x22, 0xf3, 0x01, 0x0a, 0x08, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x12,
+	0x18, 0x0a, 0x07, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x07, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x12, 0x29, 0x0a, 0x05, 0x73, 0x68, 0x6f,
+	0x77, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x63, 0x68, 0x72, 0x69, 0x73,
+	0x74, 0x6d, 0x61, 0x73, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x68, 0x6f, 0x77, 0x52, 0x05, 0x73,
+	0x68, 0x6f, 0x77, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x6c, 0x61, 0x79, 0x6c, 0x69, 0x73, 0x74,
+	0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x70, 0x6c, 0x61, 0x79, 0x6c, 0x69, 0x73, 0x74,
+	0x12, 0x18, 0x0a, 0x07, 0x73, 0x68, 0x75, 0x66, 0x66, 0x6c, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x07, 0x73, 0x68, 0x75, 0x66, 0x66, 0x6c, 0x65, 0x12, 0x31, 0x0a, 0x05, 0x72, 0x75,
+	0x6c, 0x65, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x63, 0x68, 0x72, 0x69,
+	0x73, 0x74, 0x6d, 0x61, 0x73, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75,
+	0x6c, 0x65, 0x52, 0x75, 0x6c, 0x65, 0x52, 0x05, 0x72, 0x75, 0x6c, 0x65, 0x73, 0x12, 0x39, 0x0a,
+	0x0a, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x19, 0x2e, 0x63, 0x68, 0x72, 0x69, 0x73, 0x74, 0x6d, 0x61, 0x73, 0x64, 0x2e, 0x76,
+	0x31, 0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0a, 0x74, 0x72,
+	0x61, 0x6e, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0xb0, 0x01, 0x0a, 0x04, 0x53, 0x68, 0x6f,
+	0x77, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x5f, 0x6d, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x64, 0x75, 0x72, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x73, 0x12, 0x1c, 0x0a, 0x08, 0x73, 0x65, 0x71, 0x75, 0x65, 0x6e,
+	0x63, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x08, 0x73, 0x65, 0x71, 0x75,
+	0x65, 0x6e, 0x63, 0x65, 0x12, 0x16, 0x0a, 0x05, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x05, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x12, 0x1a, 0x0a, 0x07,
+	0x70, 0x61, 0x74, 0x74, 0x65, 0x72, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52,
+	0x07, 0x70, 0x61, 0x74, 0x74, 0x65, 0x72, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x6f, 0x6c, 0x6f,
+	0x72, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0d, 0x52, 0x06, 0x63, 0x6f, 0x6c, 0x6f, 0x72, 0x73,
+	0x42, 0x09, 0x0a, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x22, 0xb4, 0x01, 0x0a, 0x0c,
+	0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x52, 0x75, 0x6c, 0x65, 0x12, 0x12, 0x0a, 0x04,
+	0x66, 0x72, 0x6f, 0x6d, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x66, 0x72, 0x6f, 0x6d,
+	0x12, 0x0e, 0x0a, 0x02, 0x74, 0x6f, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x74, 0x6f,
+	0x12, 0x19, 0x0a, 0x08, 0x65, 0x76, 0x65, 0x72, 0x79, 0x5f, 0x6d, 0x73, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x07, 0x65, 0x76, 0x65, 0x72, 0x79, 0x4d, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x64,
+	0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x6d, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x0a, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x73, 0x12, 0x10, 0x0a, 0x03,
+	0x6f, 0x66, 0x66, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x03, 0x6f, 0x66, 0x66, 0x12, 0x1e,
+	0x0a, 0x0a, 0x62, 0x72, 0x69, 0x67, 0x68, 0x74, 0x6e, 0x65, 0x73, 0x73, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x01, 0x52, 0x0a, 0x62, 0x72, 0x69, 0x67, 0x68, 0x74, 0x6e, 0x65, 0x73, 0x73, 0x12, 0x12,
+	0x0a, 0x04, 0x73, 0x68, 0x6f, 0x77, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73, 0x68,
+	0x6f, 0x77, 0x22, 0x94, 0x01, 0x0a, 0x0e, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x53,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x12,
+	0x12, 0x0a, 0x04, 0x73, 0x68, 0x6f, 0x77, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73,
+	0x68, 0x6f, 0x77, 0x12, 0x10, 0x0a, 0x03, 0x6f, 0x66, 0x66, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x03, 0x6f, 0x66, 0x66, 0x12, 0x1e, 0x0a, 0x0a, 0x62, 0x72, 0x69, 0x67, 0x68, 0x74, 0x6e,
+	0x65, 0x73, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0a, 0x62, 0x72, 0x69, 0x67, 0x68,
+	0x74, 0x6e, 0x65, 0x73, 0x73, 0x12, 0x22, 0x0a, 0x0d, 0x75, 0x6e, 0x74, 0x69, 0x6c, 0x5f, 0x75,
+	0x6e, 0x69, 0x78, 0x5f, 0x6d, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0b, 0x75, 0x6e,
+	0x74, 0x69, 0x6c, 0x55, 0x6e, 0x69, 0x78, 0x4d, 0x73, 0x22, 0x21, 0x0a, 0x05, 0x45, 0x72, 0x72,
+	0x6f, 0x72, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x2a, 0x2a, 0x0a, 0x09,
+	0x53, 0x63, 0x61, 0x6c, 0x65, 0x4d, 0x6f, 0x64, 0x65, 0x12, 0x0e, 0x0a, 0x0a, 0x53, 0x43, 0x41,
+	0x4c, 0x45, 0x5f, 0x46, 0x49, 0x4c, 0x4c, 0x10, 0x00, 0x12, 0x0d, 0x0a, 0x09, 0x53, 0x43, 0x41,
+	0x4c, 0x45, 0x5f, 0x46, 0x49, 0x54, 0x10, 0x01, 0x2a, 0x61, 0x0a, 0x09, 0x49, 0x6e, 0x74, 0x65,
+	0x6e, 0x73, 0x69, 0x74, 0x79, 0x12, 0x15, 0x0a, 0x11, 0x49, 0x4e, 0x54, 0x45, 0x4e, 0x53, 0x49,
+	0x54, 0x59, 0x5f, 0x44, 0x45, 0x46, 0x41, 0x55, 0x4c, 0x54, 0x10, 0x00, 0x12, 0x12, 0x0a, 0x0e,
+	0x49, 0x4e, 0x54, 0x45, 0x4e, 0x53, 0x49, 0x54, 0x59, 0x5f, 0x53, 0x54, 0x45, 0x50, 0x10, 0x01,
+	0x12, 0x14, 0x0a, 0x10, 0x49, 0x4e, 0x54, 0x45, 0x4e, 0x53, 0x49, 0x54, 0x59, 0x5f, 0x4c, 0x49,
+	0x4e, 0x45, 0x41, 0x52, 0x10, 0x02, 0x12, 0x13, 0x0a, 0x0f, 0x49, 0x4e, 0x54, 0x45, 0x4e, 0x53,
+	0x49, 0x54, 0x59, 0x5f, 0x43, 0x55, 0x42, 0x49, 0x43, 0x10, 0x03, 0x2a, 0x66, 0x0a, 0x09, 0x41,
+	0x76, 0x65, 0x72, 0x61, 0x67, 0x69, 0x6e, 0x67, 0x12, 0x15, 0x0a, 0x11, 0x41, 0x56, 0x45, 0x52,
+	0x41, 0x47, 0x49, 0x4e, 0x47, 0x5f, 0x44, 0x45, 0x46, 0x41, 0x55, 0x4c, 0x54, 0x10, 0x00, 0x12,
+	0x14, 0x0a, 0x10, 0x41, 0x56, 0x45, 0x52, 0x41, 0x47, 0x49, 0x4e, 0x47, 0x5f, 0x53, 0x49, 0x4d,
+	0x50, 0x4c, 0x45, 0x10, 0x01, 0x12, 0x15, 0x0a, 0x11, 0x41, 0x56, 0x45, 0x52, 0x41, 0x47, 0x49,
+	0x4e, 0x47, 0x5f, 0x53, 0x51, 0x55, 0x41, 0x52, 0x45, 0x44, 0x10, 0x02, 0x12, 0x15, 0x0a, 0x11,
+	0x41, 0x56, 0x45, 0x52, 0x41, 0x47, 0x49, 0x4e, 0x47, 0x5f, 0x4e, 0x45, 0x41, 0x52, 0x45, 0x53,
+	0x54, 0x10, 0x03, 0x2a, 0x6c, 0x0a, 0x0e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x69, 0x74, 0x69, 0x6f,
+	0x6e, 0x4b, 0x69, 0x6e, 0x64, 0x12, 0x12, 0x0a, 0x0e, 0x54, 0x52, 0x41, 0x4e, 0x53, 0x49, 0x54,
+	0x49, 0x4f, 0x4e, 0x5f, 0x43, 0x55, 0x54, 0x10, 0x00, 0x12, 0x18, 0x0a, 0x14, 0x54, 0x52, 0x41,
+	0x4e, 0x53, 0x49, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x43, 0x52, 0x4f, 0x53, 0x53, 0x46, 0x41, 0x44,
+	0x45, 0x10, 0x01, 0x12, 0x13, 0x0a, 0x0f, 0x54, 0x52, 0x41, 0x4e, 0x53, 0x49, 0x54, 0x49, 0x4f,
+	0x4e, 0x5f, 0x57, 0x49, 0x50, 0x45, 0x10, 0x02, 0x12, 0x17, 0x0a, 0x13, 0x54, 0x52, 0x41, 0x4e,
+	0x53, 0x49, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x44, 0x49, 0x53, 0x53, 0x4f, 0x4c, 0x56, 0x45, 0x10,
+	0x03, 0x2a, 0x61, 0x0a, 0x09, 0x42, 0x6c, 0x65, 0x6e, 0x64, 0x4d, 0x6f, 0x64, 0x65, 0x12, 0x10,
+	0x0a, 0x0c, 0x42, 0x4c, 0x45, 0x4e, 0x44, 0x5f, 0x4e, 0x4f, 0x52, 0x4d, 0x41, 0x4c, 0x10, 0x00,
+	0x12, 0x0d, 0x0a, 0x09, 0x42, 0x4c, 0x45, 0x4e, 0x44, 0x5f, 0x41, 0x44, 0x44, 0x10, 0x01, 0x12,
+	0x12, 0x0a, 0x0e, 0x42, 0x4c, 0x45, 0x4e, 0x44, 0x5f, 0x4d, 0x55, 0x4c, 0x54, 0x49, 0x50, 0x4c,
+	0x59, 0x10, 0x02, 0x12, 0x10, 0x0a, 0x0c, 0x42, 0x4c, 0x45, 0x4e, 0x44, 0x5f, 0x53, 0x43, 0x52,
+	0x45, 0x45, 0x4e, 0x10, 0x03, 0x12, 0x0d, 0x0a, 0x09, 0x42, 0x4c, 0x45, 0x4e, 0x44, 0x5f, 0x4d,
+	0x41, 0x58, 0x10, 0x04, 0x42, 0x2e, 0x5a, 0x2c, 0x64, 0x65, 0x76, 0x2e, 0x61, 0x63, 0x6d, 0x63,
+	0x73, 0x75, 0x66, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x63, 0x68, 0x72, 0x69, 0x73, 0x74, 0x6d, 0x61,
+	0x73, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x63, 0x68, 0x72, 0x69, 0x73, 0x74, 0x6d, 0x61,
+	0x73, 0x64, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_proto_christmasd_proto_rawDescOnce sync.Once
+	file_proto_christmasd_proto_rawDescData = file_proto_christmasd_proto_rawDesc
+)
+
+func file_proto_christmasd_proto_rawDescGZIP() []byte {
+	file_proto_christmasd_proto_rawDescOnce.Do(func() {
+		file_proto_christmasd_proto_rawDescData = protoimpl.X.CompressGZIP(file_proto_christmasd_proto_rawDescData)
+	})
+	return file_proto_christmasd_proto_rawDescData
+}
+
+var file_proto_christmasd_proto_enumTypes = make([]protoimpl.EnumInfo, 5)
+var file_proto_christmasd_proto_msgTypes = make([]protoimpl.MessageInfo, 24)
+var file_proto_christmasd_proto_goTypes = []interface{}{
+	(ScaleMode)(0),         // 0: christmasd.v1.ScaleMode
+	(Intensity)(0),         // 1: christmasd.v1.Intensity
+	(Averaging)(0),         // 2: christmasd.v1.Averaging
+	(TransitionKind)(0),    // 3: christmasd.v1.TransitionKind
+	(BlendMode)(0),         // 4: christmasd.v1.BlendMode
+	(*LEDStrip)(nil),       // 5: christmasd.v1.LEDStrip
+	(*SetLEDRequest)(nil),  // 6: christmasd.v1.SetLEDRequest
+	(*CanvasOptions)(nil),  // 7: christmasd.v1.CanvasOptions
+	(*ImageRequest)(nil),   // 8: christmasd.v1.ImageRequest
+	(*Frame)(nil),          // 9: christmasd.v1.Frame
+	(*FramesRequest)(nil),  // 10: christmasd.v1.FramesRequest
+	(*Transition)(nil),     // 11: christmasd.v1.Transition
+	(*Layer)(nil),          // 12: christmasd.v1.Layer
+	(*LayerRequest)(nil),   // 13: christmasd.v1.LayerRequest
+	(*Layers)(nil),         // 14: christmasd.v1.Layers
+	(*RenderResponse)(nil), // 15: christmasd.v1.RenderResponse
+	(*RenderedFrame)(nil),  // 16: christmasd.v1.RenderedFrame
+	(*StreamFrame)(nil),    // 17: christmasd.v1.StreamFrame
+	(*StreamStats)(nil),    // 18: christmasd.v1.StreamStats
+	(*PreviewMessage)(nil), // 19: christmasd.v1.PreviewMessage
+	(*PreviewLayout)(nil),  // 20: christmasd.v1.PreviewLayout
+	(*Point)(nil),          // 21: christmasd.v1.Point
+	(*Rectangle)(nil),      // 22: christmasd.v1.Rectangle
+	(*CanvasInfo)(nil),     // 23: christmasd.v1.CanvasInfo
+	(*Schedule)(nil),       // 24: christmasd.v1.Schedule
+	(*Show)(nil),           // 25: christmasd.v1.Show
+	(*ScheduleRule)(nil),   // 26: christmasd.v1.ScheduleRule
+	(*ScheduleStatus)(nil), // 27: christmasd.v1.ScheduleStatus
+	(*Error)(nil),          // 28: christmasd.v1.Error
+}
+var file_proto_christmasd_proto_depIdxs = []int32{
+	1,  // 0: christmasd.v1.CanvasOptions.intensity:type_name -> christmasd.v1.Intensity
+	2,  // 1: christmasd.v1.CanvasOptions.averaging:type_name -> christmasd.v1.Averaging
+	0,  // 2: christmasd.v1.ImageRequest.scale_mode:type_name -> christmasd.v1.ScaleMode
+	7,  // 3: christmasd.v1.ImageRequest.canvas_options:type_name -> christmasd.v1.CanvasOptions
+	9,  // 4: christmasd.v1.FramesRequest.frames:type_name -> christmasd.v1.Frame
+	0,  // 5: christmasd.v1.FramesRequest.scale_mode:type_name -> christmasd.v1.ScaleMode
+	7,  // 6: christmasd.v1.FramesRequest.canvas_options:type_name -> christmasd.v1.CanvasOptions
+	11, // 7: christmasd.v1.FramesRequest.transition:type_name -> christmasd.v1.Transition
+	3,  // 8: christmasd.v1.Transition.kind:type_name -> christmasd.v1.TransitionKind
+	4,  // 9: christmasd.v1.Layer.blend:type_name -> christmasd.v1.BlendMode
+	12, // 10: christmasd.v1.LayerRequest.layer:type_name -> christmasd.v1.Layer
+	10, // 11: christmasd.v1.LayerRequest.frames:type_name -> christmasd.v1.FramesRequest
+	12, // 12: christmasd.v1.Layers.layers:type_name -> christmasd.v1.Layer
+	16, // 13: christmasd.v1.RenderResponse.frames:type_name -> christmasd.v1.RenderedFrame
+	5,  // 14: christmasd.v1.RenderedFrame.leds:type_name -> christmasd.v1.LEDStrip
+	5,  // 15: christmasd.v1.StreamFrame.leds:type_name -> christmasd.v1.LEDStrip
+	0,  // 16: christmasd.v1.StreamFrame.scale_mode:type_name -> christmasd.v1.ScaleMode
+	7,  // 17: christmasd.v1.StreamFrame.canvas_options:type_name -> christmasd.v1.CanvasOptions
+	20, // 18: christmasd.v1.PreviewMessage.layout:type_name -> christmasd.v1.PreviewLayout
+	5,  // 19: christmasd.v1.PreviewMessage.leds:type_name -> christmasd.v1.LEDStrip
+	21, // 20: christmasd.v1.PreviewLayout.led_points:type_name -> christmasd.v1.Point
+	22, // 21: christmasd.v1.PreviewLayout.led_bounds:type_name -> christmasd.v1.Rectangle
+	22, // 22: christmasd.v1.CanvasInfo.canvas_bounds:type_name -> christmasd.v1.Rectangle
+	22, // 23: christmasd.v1.CanvasInfo.led_bounds:type_name -> christmasd.v1.Rectangle
+	21, // 24: christmasd.v1.CanvasInfo.led_points:type_name -> christmasd.v1.Point
+	25, // 25: christmasd.v1.Schedule.shows:type_name -> christmasd.v1.Show
+	26, // 26: christmasd.v1.Schedule.rules:type_name -> christmasd.v1.ScheduleRule
+	11, // 27: christmasd.v1.Schedule.transition:type_name -> christmasd.v1.Transition
+	28, // [28:28] is the sub-list for method output_type
+	28, // [28:28] is the sub-list for method input_type
+	28, // [28:28] is the sub-list for extension type_name
+	28, // [28:28] is the sub-list for extension extendee
+	0,  // [0:28] is the sub-list for field type_name
+}
+
+func init() { file_proto_christmasd_proto_init() }
+func file_proto_christmasd_proto_init() {
+	if File_proto_christmasd_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_proto_christmasd_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LEDStrip); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_christmasd_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SetLEDRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_christmasd_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CanvasOptions); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_christmasd_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ImageRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_christmasd_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Frame); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_christmasd_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FramesRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_christmasd_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Transition); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_christmasd_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Layer); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_christmasd_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LayerRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_christmasd_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Layers); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_christmasd_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RenderResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_christmasd_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RenderedFrame); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_christmasd_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StreamFrame); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_christmasd_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StreamStats); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_christmasd_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PreviewMessage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_christmasd_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PreviewLayout); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_christmasd_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Point); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_christmasd_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Rectangle); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_christmasd_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CanvasInfo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_christmasd_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Schedule); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_christmasd_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Show); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_christmasd_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ScheduleRule); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_christmasd_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ScheduleStatus); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_christmasd_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Error); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_proto_christmasd_proto_msgTypes[7].OneofWrappers = []interface{}{}
+	file_proto_christmasd_proto_msgTypes[12].OneofWrappers = []interface{}{
+		(*StreamFrame_Leds)(nil),
+		(*StreamFrame_Rgb)(nil),
+		(*StreamFrame_Image)(nil),
+	}
+	file_proto_christmasd_proto_msgTypes[14].OneofWrappers = []interface{}{
+		(*PreviewMessage_Layout)(nil),
+		(*PreviewMessage_Leds)(nil),
+	}
+	file_proto_christmasd_proto_msgTypes[20].OneofWrappers = []interface{}{
+		(*Show_Sequence)(nil),
+		(*Show_Image)(nil),
+		(*Show_Pattern)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_proto_christmasd_proto_rawDesc,
+			NumEnums:      5,
+			NumMessages:   24,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_proto_christmasd_proto_goTypes,
+		DependencyIndexes: file_proto_christmasd_proto_depIdxs,
+		EnumInfos:         file_proto_christmasd_proto_enumTypes,
+		MessageInfos:      file_proto_christmasd_proto_msgTypes,
+	}.Build()
+	File_proto_christmasd_proto = out.File
+	file_proto_christmasd_proto_rawDesc = nil
+	file_proto_christmasd_proto_goTypes = nil
+	file_proto_christmasd_proto_depIdxs = nil
+}
@@ -20,6 +20,18 @@ pkgs.mkShell {
 		gopls
 		gotools
 		go-tools # staticcheck
+		protobuf
+		protoc-gen-go
+
+		# Gio dependencies for christmas-gio.
+		pkg-config
+		wayland
+		libxkbcommon
+		libGL
+		vulkan-headers
+		xorg.libX11
+		xorg.libXcursor
+		xorg.libXfixes
 	];
 
 	shellHook = ''